@@ -4,6 +4,7 @@ package cache
 import (
 	"encoding/binary"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,11 +16,28 @@ const bucketName = "cache"
 // DB wraps a bbolt database for caching test/build results.
 type DB struct {
 	db *bolt.DB
+
+	// commit is recorded in every entry written by Mark (see SetCommit).
+	commit string
+	// branch is recorded in every entry written by Mark (see SetBranch).
+	branch string
+
+	// readOnly is set when OpenShared fell back to a read-only open. If even
+	// that was blocked, the DB is detached: an empty stand-in at scratch,
+	// which is removed on Close.
+	readOnly bool
+	scratch  string
 }
 
 // Open opens or creates a cache database at the given path.
 func Open(path string) (*DB, error) {
-	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	return open(path, 1*time.Second)
+}
+
+// open opens or creates a cache database, waiting up to timeout for the file
+// lock.
+func open(path string, timeout time.Duration) (*DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +57,22 @@ func Open(path string) (*DB, error) {
 
 // Close closes the cache database.
 func (c *DB) Close() error {
-	return c.db.Close()
+	err := c.db.Close()
+	if c.scratch != "" {
+		os.Remove(c.scratch)
+	}
+	return err
+}
+
+// SetCommit sets the git commit recorded with subsequent Mark calls.
+func (c *DB) SetCommit(commit string) {
+	c.commit = commit
+}
+
+// SetBranch sets the git branch recorded with subsequent Mark calls, so
+// entries of deleted branches can be pruned (see DeleteBranchEntries).
+func (c *DB) SetBranch(branch string) {
+	c.branch = branch
 }
 
 // Path returns the path to the database file.
@@ -54,6 +87,9 @@ type Entry struct {
 	Success   bool   // Whether the last run succeeded
 	Output    []byte // Captured stdout from the run
 	Args      string // The args used for this run (e.g., "test --no-build")
+	Commit    string // Git HEAD commit at the time of the run (empty for old entries)
+	Duration  int64  // Wall-clock duration of the run in milliseconds (0 if unknown)
+	Branch    string // Git branch at the time of the run (empty if detached or for old entries)
 }
 
 // Result contains the result of a cache lookup.
@@ -79,11 +115,13 @@ func ParseKey(key string) (contentHash, argsHash, projectPath string) {
 }
 
 // encodeEntry encodes a cache entry to bytes.
-// Format: [LastRun:8][CreatedAt:8][OutputLen:4][Output:OutputLen][Success:1][ArgsLen:4][Args:ArgsLen]
+// Format: [LastRun:8][CreatedAt:8][OutputLen:4][Output:OutputLen][Success:1][ArgsLen:4][Args:ArgsLen][CommitLen:4][Commit:CommitLen][Duration:8][BranchLen:4][Branch:BranchLen]
 func encodeEntry(e Entry) []byte {
 	outputLen := len(e.Output)
 	argsLen := len(e.Args)
-	buf := make([]byte, 25+outputLen+argsLen)
+	commitLen := len(e.Commit)
+	branchLen := len(e.Branch)
+	buf := make([]byte, 41+outputLen+argsLen+commitLen+branchLen)
 	binary.LittleEndian.PutUint64(buf[0:8], uint64(e.LastRun))
 	binary.LittleEndian.PutUint64(buf[8:16], uint64(e.CreatedAt))
 	binary.LittleEndian.PutUint32(buf[16:20], uint32(outputLen))
@@ -99,13 +137,31 @@ func encodeEntry(e Entry) []byte {
 	pos++
 	binary.LittleEndian.PutUint32(buf[pos:pos+4], uint32(argsLen))
 	if argsLen > 0 {
-		copy(buf[pos+4:], e.Args)
+		copy(buf[pos+4:pos+4+argsLen], e.Args)
+	}
+	pos += 4 + argsLen
+	binary.LittleEndian.PutUint32(buf[pos:pos+4], uint32(commitLen))
+	if commitLen > 0 {
+		copy(buf[pos+4:pos+4+commitLen], e.Commit)
+	}
+	pos += 4 + commitLen
+	binary.LittleEndian.PutUint64(buf[pos:pos+8], uint64(e.Duration))
+	pos += 8
+	binary.LittleEndian.PutUint32(buf[pos:pos+4], uint32(branchLen))
+	if branchLen > 0 {
+		copy(buf[pos+4:pos+4+branchLen], e.Branch)
 	}
 	return buf
 }
 
 // decodeEntry decodes a cache entry from bytes.
 func decodeEntry(data []byte) Entry {
+	return decodeEntryFields(data, true)
+}
+
+// decodeEntryFields decodes a cache entry from bytes, copying its output only
+// if withOutput is set, so scans that don't need it stay cheap.
+func decodeEntryFields(data []byte, withOutput bool) Entry {
 	if len(data) < 16 {
 		return Entry{}
 	}
@@ -119,8 +175,10 @@ func decodeEntry(data []byte) Entry {
 		outputLen := binary.LittleEndian.Uint32(data[16:20])
 		if len(data) >= 20+int(outputLen) {
 			// Must copy - bbolt's buffer is only valid during transaction
-			entry.Output = make([]byte, outputLen)
-			copy(entry.Output, data[20:20+outputLen])
+			if withOutput {
+				entry.Output = make([]byte, outputLen)
+				copy(entry.Output, data[20:20+outputLen])
+			}
 			pos := 20 + int(outputLen)
 			// Check for success byte (new format)
 			if len(data) >= pos+1 {
@@ -131,6 +189,27 @@ func decodeEntry(data []byte) Entry {
 					argsLen := binary.LittleEndian.Uint32(data[pos : pos+4])
 					if len(data) >= pos+4+int(argsLen) {
 						entry.Args = string(data[pos+4 : pos+4+int(argsLen)])
+						pos += 4 + int(argsLen)
+						// Check for commit (added after args)
+						if len(data) >= pos+4 {
+							commitLen := binary.LittleEndian.Uint32(data[pos : pos+4])
+							if len(data) >= pos+4+int(commitLen) {
+								entry.Commit = string(data[pos+4 : pos+4+int(commitLen)])
+								pos += 4 + int(commitLen)
+								// Check for duration (added after commit)
+								if len(data) >= pos+8 {
+									entry.Duration = int64(binary.LittleEndian.Uint64(data[pos : pos+8]))
+									pos += 8
+									// Check for branch (added after duration)
+									if len(data) >= pos+4 {
+										branchLen := binary.LittleEndian.Uint32(data[pos : pos+4])
+										if len(data) >= pos+4+int(branchLen) {
+											entry.Branch = string(data[pos+4 : pos+4+int(branchLen)])
+										}
+									}
+								}
+							}
+						}
 					}
 				}
 			}
@@ -192,6 +271,13 @@ func (c *DB) LookupAny(key string) *Result {
 
 // Mark records a test/build result for the given key.
 func (c *DB) Mark(key string, t time.Time, success bool, output []byte, args string) error {
+	return c.MarkWithDuration(key, t, 0, success, output, args)
+}
+
+// MarkWithDuration records a test/build result for the given key along with
+// how long the run took. A zero duration keeps the previously recorded one;
+// other durations are also appended to the project's duration history.
+func (c *DB) MarkWithDuration(key string, t time.Time, d time.Duration, success bool, output []byte, args string) error {
 	return c.db.Update(func(tx *bolt.Tx) error {
 		b := tx.Bucket([]byte(bucketName))
 		if b == nil {
@@ -206,13 +292,29 @@ func (c *DB) Mark(key string, t time.Time, success bool, output []byte, args str
 			Success:   success,
 			Output:    output,
 			Args:      args,
+			Commit:    c.commit,
+			Duration:  d.Milliseconds(),
+			Branch:    c.branch,
 		}
 		if existing != nil {
 			old := decodeEntry(existing)
 			entry.CreatedAt = old.CreatedAt
+			if entry.Duration == 0 {
+				entry.Duration = old.Duration
+			}
 		}
 
-		return b.Put([]byte(key), encodeEntry(entry))
+		if err := b.Put([]byte(key), encodeEntry(entry)); err != nil {
+			return err
+		}
+		if d <= 0 {
+			return nil
+		}
+		_, argsHash, projectPath := ParseKey(key)
+		if projectPath == "" {
+			return nil
+		}
+		return appendDuration(tx, argsHash, projectPath, args, DurationSample{At: t, Duration: d})
 	})
 }
 
@@ -286,6 +388,41 @@ func (c *DB) DeleteOldEntries(maxAge time.Duration) (deleted int, err error) {
 	return
 }
 
+// DeleteBranchEntries removes cache entries last run on a branch that is not
+// in branches (e.g. branches deleted after merging). Entries without a
+// branch (detached HEAD, or written before branches were recorded) are kept.
+func (c *DB) DeleteBranchEntries(branches []string) (deleted int, err error) {
+	keep := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		keep[b] = true
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return nil
+		}
+
+		var keysToDelete [][]byte
+		cur := b.Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			entry := decodeEntry(v)
+			if entry.Branch != "" && !keep[entry.Branch] {
+				keysToDelete = append(keysToDelete, append([]byte{}, k...))
+			}
+		}
+
+		for _, k := range keysToDelete {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	return
+}
+
 // FailedEntry contains info about a failed cache entry.
 type FailedEntry struct {
 	ProjectPath string
@@ -341,6 +478,199 @@ func (c *DB) GetFailed(argsHash string) []FailedEntry {
 	return failed
 }
 
+// GetLastSuccess scans the cache for successful entries matching the given argsHash.
+// Returns the most recent successful run time per project path.
+func (c *DB) GetLastSuccess(argsHash string) map[string]time.Time {
+	lastSuccess := make(map[string]time.Time)
+
+	c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return nil
+		}
+		cur := b.Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			_, keyArgsHash, projectPath := ParseKey(string(k))
+			if keyArgsHash != argsHash || projectPath == "" {
+				continue
+			}
+			entry := decodeEntry(v)
+			if !entry.Success {
+				continue
+			}
+			t := time.Unix(entry.LastRun, 0)
+			if t.After(lastSuccess[projectPath]) {
+				lastSuccess[projectPath] = t
+			}
+		}
+		return nil
+	})
+
+	return lastSuccess
+}
+
+// RunHistory holds what scheduling uses from past runs of each project.
+type RunHistory struct {
+	// LastFailures holds the time of the most recent run of each project
+	// whose most recent run failed
+	LastFailures map[string]time.Time
+	// Durations holds the duration of the most recent timed run of each
+	// project; projects without a recorded duration are not included
+	Durations map[string]time.Duration
+}
+
+// GetRunHistory collects the run history of every project for argsHashes in
+// a single pass over the cache. A project with runs under several of them
+// takes its failure and duration from the first argsHash that has one.
+func (c *DB) GetRunHistory(argsHashes ...string) RunHistory {
+	rank := make(map[string]int, len(argsHashes))
+	for i, h := range argsHashes {
+		if _, ok := rank[h]; !ok {
+			rank[h] = i
+		}
+	}
+
+	type latest struct {
+		lastRun  int64
+		success  bool
+		timedRun int64
+		duration time.Duration
+	}
+	byRank := make([]map[string]*latest, len(argsHashes))
+	for i := range byRank {
+		byRank[i] = make(map[string]*latest)
+	}
+
+	c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return nil
+		}
+		cur := b.Cursor()
+		for k, v := cur.First(); k != nil; k, v = cur.Next() {
+			_, keyArgsHash, projectPath := ParseKey(string(k))
+			i, ok := rank[keyArgsHash]
+			if !ok || projectPath == "" {
+				continue
+			}
+			entry := decodeEntryFields(v, false)
+			l := byRank[i][projectPath]
+			if l == nil {
+				l = &latest{lastRun: -1, timedRun: -1}
+				byRank[i][projectPath] = l
+			}
+			if entry.LastRun > l.lastRun {
+				l.lastRun = entry.LastRun
+				l.success = entry.Success
+			}
+			if entry.Duration != 0 && entry.LastRun > l.timedRun {
+				l.timedRun = entry.LastRun
+				l.duration = time.Duration(entry.Duration) * time.Millisecond
+			}
+		}
+		return nil
+	})
+
+	h := RunHistory{
+		LastFailures: make(map[string]time.Time),
+		Durations:    make(map[string]time.Duration),
+	}
+	seenRun := make(map[string]bool)
+	for _, projects := range byRank {
+		for projectPath, l := range projects {
+			if !seenRun[projectPath] {
+				seenRun[projectPath] = true
+				if !l.success {
+					h.LastFailures[projectPath] = time.Unix(l.lastRun, 0)
+				}
+			}
+			if _, ok := h.Durations[projectPath]; !ok && l.timedRun >= 0 {
+				h.Durations[projectPath] = l.duration
+			}
+		}
+	}
+	return h
+}
+
+// GetDurations returns the duration of the most recent timed run of each
+// project with the given argsHash. Projects without a recorded duration
+// are not included.
+func (c *DB) GetDurations(argsHash string) map[string]time.Duration {
+	return c.GetRunHistory(argsHash).Durations
+}
+
+// ProjectDuration summarizes the recorded run durations of one project for
+// one set of args. Each cache entry (one per content hash) counts as one run.
+type ProjectDuration struct {
+	ProjectPath string
+	Args        string
+	Runs        int
+	Last        time.Duration // Duration of the most recent timed run
+	Average     time.Duration
+}
+
+// GetProjectDurations returns duration summaries for all projects with
+// timed runs, slowest most recent run first.
+func (c *DB) GetProjectDurations() []ProjectDuration {
+	type summary struct {
+		ProjectDuration
+		lastRun int64
+		total   time.Duration
+	}
+	byKey := make(map[string]*summary)
+	c.View(func(key string, entry Entry) error {
+		_, argsHash, projectPath := ParseKey(key)
+		if projectPath == "" || entry.Duration == 0 {
+			return nil
+		}
+		d := time.Duration(entry.Duration) * time.Millisecond
+		s, ok := byKey[argsHash+":"+projectPath]
+		if !ok {
+			s = &summary{ProjectDuration: ProjectDuration{ProjectPath: projectPath}}
+			byKey[argsHash+":"+projectPath] = s
+		}
+		s.Runs++
+		s.total += d
+		if entry.LastRun >= s.lastRun {
+			s.lastRun = entry.LastRun
+			s.Last = d
+			s.Args = entry.Args
+		}
+		return nil
+	})
+
+	result := make([]ProjectDuration, 0, len(byKey))
+	for _, s := range byKey {
+		s.Average = s.total / time.Duration(s.Runs)
+		result = append(result, s.ProjectDuration)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Last != result[j].Last {
+			return result[i].Last > result[j].Last
+		}
+		return result[i].ProjectPath < result[j].ProjectPath
+	})
+	return result
+}
+
+// LastSuccessEntry returns the most recent successful entry for projectPath
+// with the given argsHash, or nil if there is none.
+func (c *DB) LastSuccessEntry(argsHash, projectPath string) *Entry {
+	var last *Entry
+	c.View(func(key string, entry Entry) error {
+		_, keyArgsHash, keyPath := ParseKey(key)
+		if keyArgsHash != argsHash || keyPath != projectPath || !entry.Success {
+			return nil
+		}
+		if last == nil || entry.LastRun > last.LastRun {
+			e := entry
+			last = &e
+		}
+		return nil
+	})
+	return last
+}
+
 // View provides read-only access to iterate over cache entries.
 // The callback receives each key-value pair.
 func (c *DB) View(fn func(key string, entry Entry) error) error {
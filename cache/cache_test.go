@@ -50,6 +50,9 @@ func TestEncodeDecodeEntry(t *testing.T) {
 			Success:   true,
 			Output:    []byte("test output"),
 			Args:      "test --no-build",
+			Commit:    "abc1234",
+			Duration:  4321,
+			Branch:    "feature/x",
 		},
 		{
 			LastRun:   1234567890,
@@ -86,6 +89,15 @@ func TestEncodeDecodeEntry(t *testing.T) {
 		if decoded.Args != tt.Args {
 			t.Errorf("test %d: Args = %q, want %q", i, decoded.Args, tt.Args)
 		}
+		if decoded.Commit != tt.Commit {
+			t.Errorf("test %d: Commit = %q, want %q", i, decoded.Commit, tt.Commit)
+		}
+		if decoded.Duration != tt.Duration {
+			t.Errorf("test %d: Duration = %d, want %d", i, decoded.Duration, tt.Duration)
+		}
+		if decoded.Branch != tt.Branch {
+			t.Errorf("test %d: Branch = %q, want %q", i, decoded.Branch, tt.Branch)
+		}
 	}
 }
 
@@ -235,6 +247,37 @@ func TestDeleteOldEntries(t *testing.T) {
 	}
 }
 
+func TestDeleteBranchEntries(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	db.Mark(MakeKey("nobranch", "args", "a.csproj"), now, true, nil, "")
+	db.SetBranch("main")
+	db.Mark(MakeKey("main", "args", "a.csproj"), now, true, nil, "")
+	db.SetBranch("feature/gone")
+	db.Mark(MakeKey("gone", "args", "a.csproj"), now, true, nil, "")
+
+	deleted, err := db.DeleteBranchEntries([]string{"main", "feature/other"})
+	if err != nil {
+		t.Fatalf("DeleteBranchEntries() failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("deleted = %d, want 1", deleted)
+	}
+	if db.Lookup(MakeKey("gone", "args", "a.csproj")) != nil {
+		t.Error("entry of deleted branch should be removed")
+	}
+	for _, hash := range []string{"nobranch", "main"} {
+		if db.Lookup(MakeKey(hash, "args", "a.csproj")) == nil {
+			t.Errorf("entry %q should be kept", hash)
+		}
+	}
+}
+
 func TestGetFailed(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "cache-failed-*")
 	if err != nil {
@@ -269,3 +312,178 @@ func TestGetFailed(t *testing.T) {
 		t.Errorf("GetFailed()[0].ProjectPath = %q, want %q", failed[0].ProjectPath, "failed/project.csproj")
 	}
 }
+
+func TestGetRunHistory(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	argsHash := "abc123"
+	old := time.Unix(1700000000, 0)
+	recent := time.Unix(1700086400, 0)
+
+	// Failed, then fixed: not a failure any more
+	db.Mark(MakeKey("content1", argsHash, "app/app.csproj"), old, false, nil, "test")
+	db.Mark(MakeKey("content2", argsHash, "app/app.csproj"), recent, true, nil, "test")
+
+	// Passed, then broke
+	db.Mark(MakeKey("content3", argsHash, "lib/lib.csproj"), old, true, nil, "test")
+	db.Mark(MakeKey("content4", argsHash, "lib/lib.csproj"), recent, false, nil, "test")
+
+	// Failure with a different argsHash
+	db.Mark(MakeKey("content5", "different", "other/other.csproj"), recent, false, nil, "build")
+
+	got := db.GetRunHistory(argsHash)
+	if len(got.LastFailures) != 1 {
+		t.Fatalf("GetRunHistory().LastFailures = %v, want only lib/lib.csproj", got.LastFailures)
+	}
+	if !got.LastFailures["lib/lib.csproj"].Equal(recent) {
+		t.Errorf("lib/lib.csproj failed at %v, want %v", got.LastFailures["lib/lib.csproj"], recent)
+	}
+	if len(got.Durations) != 0 {
+		t.Errorf("GetRunHistory().Durations = %v, want none without timed runs", got.Durations)
+	}
+
+	// Timed runs under a second argsHash fill in what the first lacks, but
+	// never override it
+	db.MarkWithDuration(MakeKey("content6", argsHash, "lib/lib.csproj"), old, 3*time.Second, true, nil, "test")
+	db.MarkWithDuration(MakeKey("content7", "different", "lib/lib.csproj"), recent, 7*time.Second, true, nil, "build")
+	db.MarkWithDuration(MakeKey("content8", "different", "other/other.csproj"), recent.Add(time.Hour), 5*time.Second, true, nil, "build")
+
+	got = db.GetRunHistory(argsHash, "different")
+	if !got.LastFailures["lib/lib.csproj"].Equal(recent) {
+		t.Errorf("lib/lib.csproj failed at %v, want %v", got.LastFailures["lib/lib.csproj"], recent)
+	}
+	if _, ok := got.LastFailures["other/other.csproj"]; ok {
+		t.Error("other/other.csproj passed on its latest run and should not be a failure")
+	}
+	if got.Durations["lib/lib.csproj"] != 3*time.Second {
+		t.Errorf("lib/lib.csproj duration = %v, want 3s from the first argsHash", got.Durations["lib/lib.csproj"])
+	}
+	if got.Durations["other/other.csproj"] != 5*time.Second {
+		t.Errorf("other/other.csproj duration = %v, want 5s", got.Durations["other/other.csproj"])
+	}
+}
+
+func TestGetLastSuccess(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	argsHash := "abc123"
+	old := time.Unix(1700000000, 0)
+	recent := time.Unix(1700086400, 0)
+
+	// Two successful runs with different content hashes: most recent wins
+	db.Mark(MakeKey("content1", argsHash, "app/app.csproj"), old, true, nil, "test")
+	db.Mark(MakeKey("content2", argsHash, "app/app.csproj"), recent, true, nil, "test")
+
+	// A newer failure does not count as a success
+	db.Mark(MakeKey("content3", argsHash, "lib/lib.csproj"), old, true, nil, "test")
+	db.Mark(MakeKey("content4", argsHash, "lib/lib.csproj"), recent, false, nil, "test")
+
+	// Only failures, and a different argsHash
+	db.Mark(MakeKey("content5", argsHash, "broken/broken.csproj"), recent, false, nil, "test")
+	db.Mark(MakeKey("content6", "different", "other/other.csproj"), recent, true, nil, "build")
+
+	got := db.GetLastSuccess(argsHash)
+	if len(got) != 2 {
+		t.Fatalf("GetLastSuccess() returned %d entries, want 2: %v", len(got), got)
+	}
+	if !got["app/app.csproj"].Equal(recent) {
+		t.Errorf("app: got %v, want %v", got["app/app.csproj"], recent)
+	}
+	if !got["lib/lib.csproj"].Equal(old) {
+		t.Errorf("lib: got %v, want %v", got["lib/lib.csproj"], old)
+	}
+}
+
+func TestLastSuccessEntry(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	argsHash := "abc123"
+	db.SetCommit("c0ffee1")
+	db.Mark(MakeKey("content1", argsHash, "app/app.csproj"), time.Unix(1700000000, 0), true, nil, "test")
+	db.SetCommit("c0ffee2")
+	db.Mark(MakeKey("content2", argsHash, "app/app.csproj"), time.Unix(1700000100, 0), false, nil, "test")
+
+	entry := db.LastSuccessEntry(argsHash, "app/app.csproj")
+	if entry == nil {
+		t.Fatal("LastSuccessEntry() returned nil")
+	}
+	if entry.Commit != "c0ffee1" {
+		t.Errorf("Commit = %q, want %q", entry.Commit, "c0ffee1")
+	}
+
+	if db.LastSuccessEntry(argsHash, "other/other.csproj") != nil {
+		t.Error("expected nil for project without entries")
+	}
+}
+
+func TestGetDurations(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	argsHash := "abc123"
+	old := time.Unix(1700000000, 0)
+	recent := time.Unix(1700000100, 0)
+
+	// The most recent timed run wins, regardless of outcome
+	db.MarkWithDuration(MakeKey("content1", argsHash, "app/app.csproj"), old, 40*time.Second, true, nil, "test")
+	db.MarkWithDuration(MakeKey("content2", argsHash, "app/app.csproj"), recent, 5*time.Second, false, nil, "test")
+
+	// Untimed marks (e.g. transitive dependencies) don't count
+	db.Mark(MakeKey("content3", argsHash, "lib/lib.csproj"), recent, true, nil, "test")
+	db.MarkWithDuration(MakeKey("content4", "different", "other/other.csproj"), recent, time.Second, true, nil, "build")
+
+	got := db.GetDurations(argsHash)
+	if len(got) != 1 {
+		t.Fatalf("GetDurations() returned %d entries, want 1: %v", len(got), got)
+	}
+	if got["app/app.csproj"] != 5*time.Second {
+		t.Errorf("app: got %v, want 5s", got["app/app.csproj"])
+	}
+
+	// Re-marking without a duration keeps the recorded one
+	key := MakeKey("content2", argsHash, "app/app.csproj")
+	db.Mark(key, recent, true, nil, "test")
+	if got := db.GetDurations(argsHash)["app/app.csproj"]; got != 5*time.Second {
+		t.Errorf("after untimed Mark: got %v, want 5s", got)
+	}
+}
+
+func TestGetProjectDurations(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	db.MarkWithDuration(MakeKey("c1", "args", "app/app.csproj"), time.Unix(1700000000, 0), 10*time.Second, true, nil, "test")
+	db.MarkWithDuration(MakeKey("c2", "args", "app/app.csproj"), time.Unix(1700000100, 0), 20*time.Second, true, nil, "test")
+	db.MarkWithDuration(MakeKey("c3", "args", "lib/lib.csproj"), time.Unix(1700000100, 0), time.Minute, false, nil, "test")
+	db.Mark(MakeKey("c4", "args", "untimed/untimed.csproj"), time.Unix(1700000100, 0), true, nil, "test")
+
+	got := db.GetProjectDurations()
+	if len(got) != 2 {
+		t.Fatalf("GetProjectDurations() returned %d entries, want 2: %+v", len(got), got)
+	}
+	if got[0].ProjectPath != "lib/lib.csproj" || got[0].Last != time.Minute {
+		t.Errorf("got[0] = %+v, want lib/lib.csproj at 1m", got[0])
+	}
+	app := got[1]
+	if app.ProjectPath != "app/app.csproj" || app.Runs != 2 || app.Last != 20*time.Second || app.Average != 15*time.Second {
+		t.Errorf("got[1] = %+v, want app/app.csproj with 2 runs, last 20s, avg 15s", app)
+	}
+}
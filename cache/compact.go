@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// compactTxMaxSize bounds the size of each write transaction during compaction.
+const compactTxMaxSize = 64 * 1024
+
+// Compact rewrites the database at path into a fresh file holding only live
+// data, then atomically replaces the original. bbolt never shrinks its file
+// after deletions, so this is how space freed by DeleteOldEntries is
+// reclaimed. The database must not be open elsewhere. Returns the file size
+// before and after compaction.
+func Compact(path string) (before, after int64, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	before = info.Size()
+
+	src, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second, ReadOnly: true})
+	if err != nil {
+		return 0, 0, err
+	}
+	defer src.Close()
+
+	tmpPath := path + ".compact"
+	os.Remove(tmpPath)
+	dst, err := bolt.Open(tmpPath, info.Mode().Perm(), &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := bolt.Compact(dst, src, compactTxMaxSize); err != nil {
+		dst.Close()
+		os.Remove(tmpPath)
+		return 0, 0, fmt.Errorf("compacting %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, err
+	}
+	src.Close()
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, 0, err
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	return before, info.Size(), nil
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompact(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	output := bytes.Repeat([]byte("x"), 4096)
+	for i := 0; i < 200; i++ {
+		db.Mark(MakeKey(fmt.Sprintf("old%d", i), "args", "old.csproj"), old, true, output, "test")
+	}
+	db.Mark(MakeKey("new", "args", "new.csproj"), now, true, output, "test")
+
+	if _, err := db.DeleteOldEntries(24 * time.Hour); err != nil {
+		t.Fatalf("DeleteOldEntries() failed: %v", err)
+	}
+	db.Close()
+
+	before, after, err := Compact(dbPath)
+	if err != nil {
+		t.Fatalf("Compact() failed: %v", err)
+	}
+	if after >= before {
+		t.Errorf("expected compaction to shrink the file, before=%d after=%d", before, after)
+	}
+	if info, err := os.Stat(dbPath); err != nil || info.Size() != after {
+		t.Errorf("reported size %d does not match file: %v %v", after, info, err)
+	}
+
+	// Live entries survive
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() after Compact failed: %v", err)
+	}
+	defer db.Close()
+	if db.Lookup(MakeKey("new", "args", "new.csproj")) == nil {
+		t.Error("live entry missing after compaction")
+	}
+	if stats := db.GetStats(); stats.TotalEntries != 1 {
+		t.Errorf("TotalEntries = %d after compaction, want 1", stats.TotalEntries)
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const durationsBucketName = "durations"
+
+// DurationHistoryLength is how many recent run durations are kept per
+// project and set of args.
+const DurationHistoryLength = 20
+
+// DurationSample is one recorded run duration.
+type DurationSample struct {
+	At       time.Time
+	Duration time.Duration
+}
+
+// DurationHistory holds the most recent run durations of one project for one
+// set of args, oldest first.
+type DurationHistory struct {
+	ProjectPath string
+	Args        string
+	Samples     []DurationSample
+}
+
+// Last returns the most recent duration.
+func (h DurationHistory) Last() time.Duration {
+	if len(h.Samples) == 0 {
+		return 0
+	}
+	return h.Samples[len(h.Samples)-1].Duration
+}
+
+// MinMedianMax returns the shortest, median and longest duration.
+func (h DurationHistory) MinMedianMax() (shortest, median, longest time.Duration) {
+	if len(h.Samples) == 0 {
+		return 0, 0, 0
+	}
+	sorted := make([]time.Duration, len(h.Samples))
+	for i, s := range h.Samples {
+		sorted[i] = s.Duration
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	median = sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	return sorted[0], median, sorted[n-1]
+}
+
+// durationHistoryKey is argsHash:projectPath, matching the tail of cache keys.
+func durationHistoryKey(argsHash, projectPath string) []byte {
+	return []byte(argsHash + ":" + projectPath)
+}
+
+// encodeDurationHistory encodes args and samples.
+// Format: [ArgsLen:4][Args:ArgsLen] followed by [At:8][DurationMs:8] per sample
+func encodeDurationHistory(args string, samples []DurationSample) []byte {
+	buf := make([]byte, 4+len(args)+16*len(samples))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(args)))
+	copy(buf[4:], args)
+	pos := 4 + len(args)
+	for _, s := range samples {
+		binary.LittleEndian.PutUint64(buf[pos:pos+8], uint64(s.At.Unix()))
+		binary.LittleEndian.PutUint64(buf[pos+8:pos+16], uint64(s.Duration.Milliseconds()))
+		pos += 16
+	}
+	return buf
+}
+
+// decodeDurationHistory decodes a value written by encodeDurationHistory.
+func decodeDurationHistory(data []byte) (args string, samples []DurationSample) {
+	if len(data) < 4 {
+		return "", nil
+	}
+	argsLen := int(binary.LittleEndian.Uint32(data[0:4]))
+	if len(data) < 4+argsLen {
+		return "", nil
+	}
+	args = string(data[4 : 4+argsLen])
+	for pos := 4 + argsLen; pos+16 <= len(data); pos += 16 {
+		samples = append(samples, DurationSample{
+			At:       time.Unix(int64(binary.LittleEndian.Uint64(data[pos:pos+8])), 0),
+			Duration: time.Duration(binary.LittleEndian.Uint64(data[pos+8:pos+16])) * time.Millisecond,
+		})
+	}
+	return args, samples
+}
+
+// appendDuration adds a sample to the history of projectPath for argsHash,
+// keeping the last DurationHistoryLength samples.
+func appendDuration(tx *bolt.Tx, argsHash, projectPath, args string, sample DurationSample) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(durationsBucketName))
+	if err != nil {
+		return err
+	}
+	key := durationHistoryKey(argsHash, projectPath)
+	_, samples := decodeDurationHistory(b.Get(key))
+	samples = append(samples, sample)
+	if len(samples) > DurationHistoryLength {
+		samples = samples[len(samples)-DurationHistoryLength:]
+	}
+	return b.Put(key, encodeDurationHistory(args, samples))
+}
+
+// GetDurationHistories returns the recorded duration history of every
+// project and set of args, ordered by project path and args.
+func (c *DB) GetDurationHistories() []DurationHistory {
+	var histories []DurationHistory
+	c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(durationsBucketName))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			_, projectPath, ok := strings.Cut(string(k), ":")
+			if !ok {
+				return nil
+			}
+			args, samples := decodeDurationHistory(v)
+			if len(samples) > 0 {
+				histories = append(histories, DurationHistory{ProjectPath: projectPath, Args: args, Samples: samples})
+			}
+			return nil
+		})
+	})
+	sort.Slice(histories, func(i, j int) bool {
+		if histories[i].ProjectPath != histories[j].ProjectPath {
+			return histories[i].ProjectPath < histories[j].ProjectPath
+		}
+		return histories[i].Args < histories[j].Args
+	})
+	return histories
+}
+
+// Regressed reports whether the latest run took more than factor times the
+// median of the history. Histories with fewer than three samples never count
+// as regressed.
+func (h DurationHistory) Regressed(factor float64) bool {
+	if len(h.Samples) < 3 {
+		return false
+	}
+	_, median, _ := h.MinMedianMax()
+	return float64(h.Last()) > factor*float64(median)
+}
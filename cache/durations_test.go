@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDurationHistory(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < DurationHistoryLength+5; i++ {
+		// Each run has a different content hash, the history is per project
+		key := MakeKey("hash"+string(rune('a'+i)), "args1", "src/App/App.csproj")
+		db.MarkWithDuration(key, start.Add(time.Duration(i)*time.Minute), time.Duration(i+1)*time.Second, true, nil, "test")
+	}
+	db.MarkWithDuration(MakeKey("h", "args2", "src/App/App.csproj"), start, 3*time.Second, true, nil, "build")
+	// Runs without a duration are not recorded
+	db.Mark(MakeKey("h", "args1", "src/Lib/Lib.csproj"), start, true, nil, "test")
+
+	histories := db.GetDurationHistories()
+	if len(histories) != 2 {
+		t.Fatalf("expected 2 histories, got %+v", histories)
+	}
+
+	// Sorted by project path, then args
+	build, test := histories[0], histories[1]
+	if build.Args != "build" || test.Args != "test" {
+		t.Fatalf("unexpected order: %q, %q", build.Args, test.Args)
+	}
+
+	if len(test.Samples) != DurationHistoryLength {
+		t.Fatalf("expected history capped at %d samples, got %d", DurationHistoryLength, len(test.Samples))
+	}
+	if test.Samples[0].Duration != 6*time.Second || test.Last() != 25*time.Second {
+		t.Errorf("expected the oldest samples to be dropped, got first=%s last=%s", test.Samples[0].Duration, test.Last())
+	}
+	if !test.Samples[0].At.Equal(start.Add(5 * time.Minute)) {
+		t.Errorf("first sample At = %s", test.Samples[0].At)
+	}
+}
+
+func TestDurationHistoryStats(t *testing.T) {
+	h := DurationHistory{}
+	for _, s := range []int{4, 2, 3, 10} {
+		h.Samples = append(h.Samples, DurationSample{Duration: time.Duration(s) * time.Second})
+	}
+
+	shortest, median, longest := h.MinMedianMax()
+	if shortest != 2*time.Second || median != 3500*time.Millisecond || longest != 10*time.Second {
+		t.Errorf("MinMedianMax() = %s, %s, %s", shortest, median, longest)
+	}
+	if !h.Regressed(1.5) {
+		t.Error("expected 10s against a 3.5s median to be a regression")
+	}
+
+	h.Samples[3].Duration = 5 * time.Second
+	if h.Regressed(1.5) {
+		t.Error("expected 5s against a 3.5s median not to be a regression")
+	}
+
+	if (DurationHistory{Samples: h.Samples[:2]}).Regressed(1.5) {
+		t.Error("expected short histories never to regress")
+	}
+}
@@ -0,0 +1,214 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CompactFreeRatio is the fraction of the database file that must be free
+// pages before MaintainSize compacts it.
+const CompactFreeRatio = 0.5
+
+// sizeUnits maps size suffixes to their multiplier, longest suffixes first so
+// "MiB" is not mistaken for "B".
+var sizeUnits = []struct {
+	suffix string
+	mult   int64
+}{
+	{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+	{"KB", 1000}, {"MB", 1000 * 1000}, {"GB", 1000 * 1000 * 1000},
+	{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+	{"B", 1},
+}
+
+// ParseSize parses a byte size such as "500MB", "1GiB", "64k" or "1048576".
+// Suffixes are case-insensitive; KB/MB/GB are decimal, K/M/G and KiB/MiB/GiB
+// are binary.
+func ParseSize(s string) (int64, error) {
+	str := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str = strings.TrimSpace(strings.TrimSuffix(str, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 500MB, 1GiB)", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// FormatSize renders a byte count with a binary unit, e.g. "1.5 MiB".
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 2; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMG"[exp])
+}
+
+// usage returns the size of the data in the database file and the bytes of
+// it held by free pages. The file itself may be larger, since bbolt grows it
+// ahead of use.
+func (c *DB) usage() (size, free int64, err error) {
+	err = c.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return size, int64(c.db.Stats().FreeAlloc), err
+}
+
+// fileSize returns the size of the database file on disk.
+func (c *DB) fileSize() (int64, error) {
+	info, err := os.Stat(c.db.Path())
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// EvictToSize deletes least-recently-used cache entries (by LastRun) until
+// the space in use by the database is at most maxBytes. Space in use is the
+// data size minus its free pages. Returns the number of entries deleted
+// and the key and value bytes they held.
+func (c *DB) EvictToSize(maxBytes int64) (deleted int, freed int64, err error) {
+	for {
+		size, free, err := c.usage()
+		if err != nil {
+			return deleted, freed, err
+		}
+		used := size - free
+		if used <= maxBytes {
+			return deleted, freed, nil
+		}
+		n, f, err := c.evictOldest(used, used-maxBytes)
+		deleted += n
+		freed += f
+		if err != nil || n == 0 {
+			return deleted, freed, err
+		}
+	}
+}
+
+// evictOldest deletes the least-recently-used entries in one transaction
+// until roughly excess bytes of the used file space are released. Page
+// overhead means entries take more file space than their key and value, so
+// their sizes are scaled by the ratio of used space to live data.
+func (c *DB) evictOldest(used, excess int64) (deleted int, freed int64, err error) {
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucketName))
+		if b == nil {
+			return nil
+		}
+
+		type candidate struct {
+			key     []byte
+			lastRun int64
+			size    int64
+		}
+		var candidates []candidate
+		var live int64
+		err := b.ForEach(func(k, v []byte) error {
+			size := int64(len(k) + len(v))
+			candidates = append(candidates, candidate{
+				key:     append([]byte{}, k...),
+				lastRun: decodeEntry(v).LastRun,
+				size:    size,
+			})
+			live += size
+			return nil
+		})
+		if err != nil || live == 0 {
+			return err
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].lastRun < candidates[j].lastRun
+		})
+
+		overhead := float64(used) / float64(live)
+		for _, cand := range candidates {
+			if float64(freed)*overhead >= float64(excess) {
+				break
+			}
+			if err := b.Delete(cand.key); err != nil {
+				return err
+			}
+			deleted++
+			freed += cand.size
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return deleted, freed, nil
+}
+
+// MaintainResult reports what MaintainSize reclaimed.
+type MaintainResult struct {
+	Deleted   int   // entries evicted
+	Freed     int64 // bytes held by the evicted entries
+	Compacted bool  // whether the file was compacted
+	Before    int64 // file size before maintenance
+	After     int64 // file size after maintenance
+	// Used is the space in use after eviction. It stays above the limit when
+	// evicting every run result isn't enough, e.g. because the limit is below
+	// what test lists, durations and test outcomes take.
+	Used int64
+}
+
+// MaintainSize evicts least-recently-used entries from the database at path
+// until its data fits in maxBytes, then compacts the file when more than
+// CompactFreeRatio of the data is free pages. The database must not be open
+// elsewhere.
+func MaintainSize(path string, maxBytes int64) (MaintainResult, error) {
+	var res MaintainResult
+
+	db, err := Open(path)
+	if err != nil {
+		return res, err
+	}
+	res.Before, err = db.fileSize()
+	if err != nil {
+		db.Close()
+		return res, err
+	}
+	res.Deleted, res.Freed, err = db.EvictToSize(maxBytes)
+	if err != nil {
+		db.Close()
+		return res, err
+	}
+	size, free, err := db.usage()
+	if err == nil {
+		res.Used = size - free
+		res.After, err = db.fileSize()
+	}
+	if err != nil {
+		db.Close()
+		return res, err
+	}
+	if err := db.Close(); err != nil {
+		return res, err
+	}
+
+	if size > 0 && float64(free)/float64(size) > CompactFreeRatio {
+		if _, res.After, err = Compact(path); err != nil {
+			return res, err
+		}
+		res.Compacted = true
+	}
+	return res, nil
+}
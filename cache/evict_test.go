@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1048576", 1048576},
+		{"512B", 512},
+		{"64k", 64 << 10},
+		{"500MB", 500 * 1000 * 1000},
+		{"1GiB", 1 << 30},
+		{"1.5 MiB", 3 << 19},
+		{"2g", 2 << 30},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil {
+			t.Errorf("ParseSize(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "MB", "lots", "-1MB"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) should fail", in)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{
+		512:               "512 B",
+		1536:              "1.5 KiB",
+		5 << 20:           "5.0 MiB",
+		3 << 30:           "3.0 GiB",
+		int64(2048) << 30: "2048.0 GiB",
+	}
+	for in, want := range tests {
+		if got := FormatSize(in); got != want {
+			t.Errorf("FormatSize(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// fillForEviction marks n entries of roughly 4KB each, entry i last run i
+// minutes after base.
+func fillForEviction(t *testing.T, db *DB, n int, base time.Time) {
+	t.Helper()
+	output := bytes.Repeat([]byte("x"), 4096)
+	for i := 0; i < n; i++ {
+		key := MakeKey(fmt.Sprintf("hash%03d", i), "args", "p.csproj")
+		if err := db.Mark(key, base.Add(time.Duration(i)*time.Minute), true, output, "test"); err != nil {
+			t.Fatalf("Mark() failed: %v", err)
+		}
+	}
+}
+
+func TestEvictToSizeRemovesLeastRecentlyUsed(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	fillForEviction(t, db, 100, time.Now().Add(-24*time.Hour))
+	size, free, err := db.usage()
+	if err != nil {
+		t.Fatalf("usage() failed: %v", err)
+	}
+	limit := size - free - 40*1024
+
+	deleted, freed, err := db.EvictToSize(limit)
+	if err != nil {
+		t.Fatalf("EvictToSize() failed: %v", err)
+	}
+	if deleted == 0 || deleted >= 100 {
+		t.Fatalf("deleted = %d, want some but not all entries", deleted)
+	}
+	if freed == 0 {
+		t.Error("freed = 0, want the evicted entries' size")
+	}
+	if size, free, _ := db.usage(); size-free > limit {
+		t.Errorf("used = %d after eviction, want at most %d", size-free, limit)
+	}
+
+	// The oldest entries go first
+	for i := 0; i < 100; i++ {
+		found := db.Lookup(MakeKey(fmt.Sprintf("hash%03d", i), "args", "p.csproj")) != nil
+		if want := i >= deleted; found != want {
+			t.Errorf("entry %d present = %v, want %v (deleted %d)", i, found, want, deleted)
+		}
+	}
+}
+
+func TestEvictToSizeUnderLimit(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	fillForEviction(t, db, 10, time.Now())
+	deleted, freed, err := db.EvictToSize(1 << 30)
+	if err != nil {
+		t.Fatalf("EvictToSize() failed: %v", err)
+	}
+	if deleted != 0 || freed != 0 {
+		t.Errorf("EvictToSize() = %d, %d, want nothing evicted", deleted, freed)
+	}
+}
+
+func TestMaintainSize(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	fillForEviction(t, db, 200, time.Now().Add(-24*time.Hour))
+	db.Close()
+
+	res, err := MaintainSize(dbPath, 100*1024)
+	if err != nil {
+		t.Fatalf("MaintainSize() failed: %v", err)
+	}
+	if res.Deleted == 0 {
+		t.Error("expected entries to be evicted")
+	}
+	if !res.Compacted {
+		t.Error("expected the file to be compacted")
+	}
+	if res.After >= res.Before {
+		t.Errorf("expected the file to shrink, before=%d after=%d", res.Before, res.After)
+	}
+
+	db, err = Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() after MaintainSize failed: %v", err)
+	}
+	defer db.Close()
+	if db.Lookup(MakeKey("hash199", "args", "p.csproj")) == nil {
+		t.Error("most recent entry should survive eviction")
+	}
+	if stats := db.GetStats(); stats.TotalEntries != 200-res.Deleted {
+		t.Errorf("TotalEntries = %d, want %d", stats.TotalEntries, 200-res.Deleted)
+	}
+}
+
+func TestMaintainSizeUnreachableLimit(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := Open(dbPath)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	fillForEviction(t, db, 10, time.Now())
+	db.Close()
+
+	// Even an empty database takes a few pages, so a 1 byte limit can't be met
+	res, err := MaintainSize(dbPath, 1)
+	if err != nil {
+		t.Fatalf("MaintainSize() failed: %v", err)
+	}
+	if res.Deleted != 10 {
+		t.Errorf("Deleted = %d, want every entry evicted", res.Deleted)
+	}
+	if res.Used <= 1 {
+		t.Errorf("Used = %d, want the space still in use reported", res.Used)
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultLockWait is how long OpenShared waits for another process to
+// release the cache before falling back to read-only.
+const DefaultLockWait = 10 * time.Second
+
+const (
+	lockAttemptTimeout = 100 * time.Millisecond
+	maxLockBackoff     = 1 * time.Second
+)
+
+// OpenShared opens the cache at path like Open, but when another process
+// holds it, retries with backoff for up to wait. If the lock is still held,
+// it falls back to a read-only open: lookups work, while writes such as Mark
+// fail with bolt.ErrDatabaseReadOnly. Check ReadOnly to tell the two apart.
+// When the holder is writing, even the read-only open is blocked, and the
+// returned DB is detached (see Detached).
+func OpenShared(path string, wait time.Duration) (*DB, error) {
+	deadline := time.Now().Add(wait)
+	backoff := 50 * time.Millisecond
+	for {
+		db, err := open(path, lockAttemptTimeout)
+		if !errors.Is(err, bolt.ErrTimeout) {
+			return db, err
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxLockBackoff)
+	}
+	return openReadOnly(path)
+}
+
+// OpenReadOnly opens the cache at path for lookups only, without creating it
+// or taking the write lock. A missing cache, or one a writer holds, yields a
+// detached DB.
+func OpenReadOnly(path string) (*DB, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return openDetached()
+	}
+	return openReadOnly(path)
+}
+
+// ReadOnly reports whether the cache was opened read-only, by OpenReadOnly
+// or as a fallback of OpenShared.
+func (c *DB) ReadOnly() bool {
+	return c.readOnly
+}
+
+// Detached reports whether OpenShared could not open the cache at all, so
+// the DB is an empty, read-only stand-in: lookups always miss.
+func (c *DB) Detached() bool {
+	return c.scratch != ""
+}
+
+// openReadOnly opens path read-only. A process writing to the cache holds an
+// exclusive lock that blocks read-only opens too. Copying the file then could
+// capture a commit in progress, whose pages bbolt can't validate, so an empty
+// stand-in is opened instead.
+func openReadOnly(path string) (*DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockAttemptTimeout, ReadOnly: true})
+	if err == nil {
+		return &DB{db: db, readOnly: true}, nil
+	}
+	if !errors.Is(err, bolt.ErrTimeout) {
+		return nil, err
+	}
+	return openDetached()
+}
+
+// openDetached creates an empty cache in a temporary file and opens it
+// read-only.
+func openDetached() (*DB, error) {
+	f, err := os.CreateTemp("", "donotnet-cache-*.db")
+	if err != nil {
+		return nil, err
+	}
+	scratch := f.Name()
+	f.Close()
+	// bbolt initializes empty files; create the bucket so lookups just miss
+	empty, err := open(scratch, lockAttemptTimeout)
+	if err != nil {
+		os.Remove(scratch)
+		return nil, err
+	}
+	empty.Close()
+
+	db, err := bolt.Open(scratch, 0600, &bolt.Options{Timeout: lockAttemptTimeout, ReadOnly: true})
+	if err != nil {
+		os.Remove(scratch)
+		return nil, err
+	}
+	return &DB{db: db, readOnly: true, scratch: scratch}, nil
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestOpenSharedFallsBackToReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	writer, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	if err := writer.Mark("key", time.Now(), true, []byte("output"), "test"); err != nil {
+		t.Fatalf("Mark() failed: %v", err)
+	}
+	writer.Close()
+
+	// A read-only holder shares its lock with other readers
+	holder, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("bolt.Open() failed: %v", err)
+	}
+	defer holder.Close()
+
+	db, err := OpenShared(path, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("OpenShared() failed: %v", err)
+	}
+	if !db.ReadOnly() || db.Detached() {
+		t.Errorf("ReadOnly() = %v, Detached() = %v, want a read-only open of the cache", db.ReadOnly(), db.Detached())
+	}
+	if db.Lookup("key") == nil {
+		t.Error("Lookup() found nothing, want the stored entry")
+	}
+	if err := db.Mark("other", time.Now(), true, nil, "test"); err == nil {
+		t.Error("Mark() on a read-only cache should fail")
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+}
+
+func TestOpenSharedDetachesFromWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	holder, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer holder.Close()
+	if err := holder.Mark("key", time.Now(), true, []byte("output"), "test"); err != nil {
+		t.Fatalf("Mark() failed: %v", err)
+	}
+
+	db, err := OpenShared(path, 300*time.Millisecond)
+	if err != nil {
+		t.Fatalf("OpenShared() failed: %v", err)
+	}
+	if !db.ReadOnly() || !db.Detached() {
+		t.Errorf("ReadOnly() = %v, Detached() = %v, want a detached cache while another process writes", db.ReadOnly(), db.Detached())
+	}
+	if db.Lookup("key") != nil {
+		t.Error("Lookup() on a detached cache should miss")
+	}
+	if err := db.Mark("other", time.Now(), true, nil, "test"); err == nil {
+		t.Error("Mark() on a read-only cache should fail")
+	}
+	if err := db.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+}
+
+func TestOpenSharedWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	holder, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		holder.Close()
+	}()
+
+	db, err := OpenShared(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("OpenShared() failed: %v", err)
+	}
+	defer db.Close()
+	if db.ReadOnly() {
+		t.Error("ReadOnly() = true, want a writable cache once the lock is released")
+	}
+	if err := db.Mark("key", time.Now(), true, nil, "test"); err != nil {
+		t.Errorf("Mark() failed: %v", err)
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing.db")
+	db, err := OpenReadOnly(missing)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() of a missing cache failed: %v", err)
+	}
+	if !db.Detached() {
+		t.Error("Detached() = false, want a detached cache when the file is missing")
+	}
+	db.Close()
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("OpenReadOnly() should not create the cache, stat err = %v", err)
+	}
+
+	path := filepath.Join(dir, "test.db")
+	writer, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	writer.Mark("key", time.Now(), true, nil, "test")
+	writer.Close()
+
+	db, err = OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() failed: %v", err)
+	}
+	defer db.Close()
+	if !db.ReadOnly() || db.Detached() {
+		t.Errorf("ReadOnly() = %v, Detached() = %v, want a read-only open of the cache", db.ReadOnly(), db.Detached())
+	}
+	if db.Lookup("key") == nil {
+		t.Error("Lookup() found nothing, want the stored entry")
+	}
+	if err := db.Mark("other", time.Now(), true, nil, "test"); err == nil {
+		t.Error("Mark() on a read-only cache should fail")
+	}
+}
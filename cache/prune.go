@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"strings"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DeleteProjectEntries removes the run results, duration histories and test
+// outcomes of every project for which exists returns false, e.g. projects
+// that were deleted or moved. exists is called once per project path.
+// Returns the number of run results removed.
+func (c *DB) DeleteProjectEntries(exists func(projectPath string) bool) (deleted int, err error) {
+	known := make(map[string]bool)
+	gone := func(projectPath string) bool {
+		ok, seen := known[projectPath]
+		if !seen {
+			ok = exists(projectPath)
+			known[projectPath] = ok
+		}
+		return !ok
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		// Run results and duration histories end in :projectPath, test
+		// outcomes are keyed by the project path itself
+		buckets := []struct {
+			name        string
+			projectPath func(key string) string
+		}{
+			{bucketName, func(key string) string { _, _, p := ParseKey(key); return p }},
+			{durationsBucketName, func(key string) string { _, p, _ := strings.Cut(key, ":"); return p }},
+			{testOutcomesBucketName, func(key string) string { return key }},
+		}
+		for _, bucket := range buckets {
+			b := tx.Bucket([]byte(bucket.name))
+			if b == nil {
+				continue
+			}
+
+			var keysToDelete [][]byte
+			cur := b.Cursor()
+			for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+				if p := bucket.projectPath(string(k)); p != "" && gone(p) {
+					keysToDelete = append(keysToDelete, append([]byte{}, k...))
+				}
+			}
+
+			for _, k := range keysToDelete {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+				if bucket.name == bucketName {
+					deleted++
+				}
+			}
+		}
+		return nil
+	})
+	return
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeleteProjectEntries(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	now := time.Now()
+	for _, p := range []string{"src/Kept/Kept.csproj", "src/Gone/Gone.csproj"} {
+		db.MarkWithDuration(MakeKey("hash1", "args", p), now, time.Second, true, nil, "test")
+		db.MarkWithDuration(MakeKey("hash2", "args", p), now, time.Second, false, nil, "test")
+		db.RecordTestOutcomes(p, []TestOutcome{{Name: "T", Failed: true, At: now}})
+	}
+
+	calls := 0
+	deleted, err := db.DeleteProjectEntries(func(p string) bool {
+		calls++
+		return p == "src/Kept/Kept.csproj"
+	})
+	if err != nil {
+		t.Fatalf("DeleteProjectEntries() failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted = %d, want 2", deleted)
+	}
+	if calls != 2 {
+		t.Errorf("exists called %d times, want once per project", calls)
+	}
+
+	if db.LookupAny(MakeKey("hash1", "args", "src/Gone/Gone.csproj")) != nil {
+		t.Error("run result of deleted project should be removed")
+	}
+	if db.LookupAny(MakeKey("hash1", "args", "src/Kept/Kept.csproj")) == nil {
+		t.Error("run result of existing project should be kept")
+	}
+	if got := db.GetFailingTests("src/Gone/Gone.csproj"); len(got) != 0 {
+		t.Errorf("test outcomes of deleted project should be removed, got %v", got)
+	}
+	if got := db.GetFailingTests("src/Kept/Kept.csproj"); len(got) != 1 {
+		t.Errorf("test outcomes of existing project should be kept, got %v", got)
+	}
+	for _, h := range db.GetDurationHistories() {
+		if h.ProjectPath == "src/Gone/Gone.csproj" {
+			t.Error("duration history of deleted project should be removed")
+		}
+	}
+}
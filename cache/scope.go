@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// DefaultFileName is the cache database file name in the cache directory.
+const DefaultFileName = "cache.db"
+
+// FileName returns the cache database file name for scope. An empty scope
+// uses the shared DefaultFileName; any other scope (e.g. a solution path)
+// gets its own cache-<hash>.db, so concurrent runs with different scopes
+// never contend for the same file lock.
+func FileName(scope string) string {
+	if scope == "" {
+		return DefaultFileName
+	}
+	sum := sha256.Sum256([]byte(scope))
+	return "cache-" + hex.EncodeToString(sum[:])[:12] + ".db"
+}
@@ -0,0 +1,23 @@
+package cache
+
+import "testing"
+
+func TestFileName(t *testing.T) {
+	if got := FileName(""); got != DefaultFileName {
+		t.Errorf("FileName(\"\") = %q, want %q", got, DefaultFileName)
+	}
+
+	a := FileName("src/App.sln")
+	if a == DefaultFileName {
+		t.Errorf("scoped file name equals the default: %q", a)
+	}
+	if len(a) != len("cache-")+12+len(".db") {
+		t.Errorf("unexpected scoped file name %q", a)
+	}
+	if again := FileName("src/App.sln"); again != a {
+		t.Errorf("FileName is not stable: %q != %q", again, a)
+	}
+	if b := FileName("src/Other.sln"); b == a {
+		t.Errorf("different scopes share file name %q", a)
+	}
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"encoding/binary"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const testOutcomesBucketName = "test_outcomes"
+
+// TestOutcome is the last recorded outcome of one test of a project.
+type TestOutcome struct {
+	Name   string // fully qualified test name
+	Failed bool
+	At     time.Time
+}
+
+// encodeTestOutcomes encodes the outcomes of one project.
+// Format: [NameLen:4][Name:NameLen][Failed:1][At:8] per test
+func encodeTestOutcomes(outcomes map[string]TestOutcome) []byte {
+	size := 0
+	for name := range outcomes {
+		size += 13 + len(name)
+	}
+	buf := make([]byte, 0, size)
+	for name, o := range outcomes {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(name)))
+		buf = append(buf, name...)
+		if o.Failed {
+			buf = append(buf, 1)
+		} else {
+			buf = append(buf, 0)
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(o.At.Unix()))
+	}
+	return buf
+}
+
+// decodeTestOutcomes decodes a value written by encodeTestOutcomes.
+func decodeTestOutcomes(data []byte) map[string]TestOutcome {
+	outcomes := make(map[string]TestOutcome)
+	for pos := 0; pos+4 <= len(data); {
+		nameLen := int(binary.LittleEndian.Uint32(data[pos : pos+4]))
+		pos += 4
+		if pos+nameLen+9 > len(data) {
+			break
+		}
+		name := string(data[pos : pos+nameLen])
+		pos += nameLen
+		outcomes[name] = TestOutcome{
+			Name:   name,
+			Failed: data[pos] == 1,
+			At:     time.Unix(int64(binary.LittleEndian.Uint64(data[pos+1:pos+9])), 0),
+		}
+		pos += 9
+	}
+	return outcomes
+}
+
+// RecordTestOutcomes merges the outcomes of a test run into the per-test
+// history of projectPath. Tests not in outcomes (e.g. filtered out of the run)
+// keep their previous outcome.
+func (c *DB) RecordTestOutcomes(projectPath string, outcomes []TestOutcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(testOutcomesBucketName))
+		if err != nil {
+			return err
+		}
+		key := []byte(projectPath)
+		stored := decodeTestOutcomes(b.Get(key))
+		for _, o := range outcomes {
+			stored[o.Name] = o
+		}
+		return b.Put(key, encodeTestOutcomes(stored))
+	})
+}
+
+// GetFailingTests returns the names of the tests of projectPath whose last
+// recorded outcome was a failure, sorted.
+func (c *DB) GetFailingTests(projectPath string) []string {
+	var failing []string
+	c.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(testOutcomesBucketName))
+		if b == nil {
+			return nil
+		}
+		for name, o := range decodeTestOutcomes(b.Get([]byte(projectPath))) {
+			if o.Failed {
+				failing = append(failing, name)
+			}
+		}
+		return nil
+	})
+	sort.Strings(failing)
+	return failing
+}
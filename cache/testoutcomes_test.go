@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTestOutcomes(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	const app = "tests/App.Tests/App.Tests.csproj"
+	if got := db.GetFailingTests(app); got != nil {
+		t.Fatalf("expected no failing tests before any run, got %v", got)
+	}
+
+	start := time.Unix(1700000000, 0)
+	db.RecordTestOutcomes(app, []TestOutcome{
+		{Name: "App.Tests.A", Failed: true, At: start},
+		{Name: "App.Tests.B", Failed: true, At: start},
+		{Name: "App.Tests.C", At: start},
+	})
+	db.RecordTestOutcomes("tests/Other/Other.csproj", []TestOutcome{{Name: "Other.X", Failed: true, At: start}})
+
+	if got, want := db.GetFailingTests(app), []string{"App.Tests.A", "App.Tests.B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetFailingTests = %v, want %v", got, want)
+	}
+
+	// A --failed rerun only runs A and B; B now passes, C keeps its outcome
+	db.RecordTestOutcomes(app, []TestOutcome{
+		{Name: "App.Tests.A", Failed: true, At: start.Add(time.Minute)},
+		{Name: "App.Tests.B", At: start.Add(time.Minute)},
+	})
+	if got, want := db.GetFailingTests(app), []string{"App.Tests.A"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after rerun GetFailingTests = %v, want %v", got, want)
+	}
+
+	db.RecordTestOutcomes(app, []TestOutcome{{Name: "App.Tests.A", At: start.Add(2 * time.Minute)}})
+	if got := db.GetFailingTests(app); len(got) != 0 {
+		t.Errorf("expected no failing tests once all passed, got %v", got)
+	}
+	if got := db.GetFailingTests("tests/Other/Other.csproj"); len(got) != 1 {
+		t.Errorf("outcomes of other projects should be untouched, got %v", got)
+	}
+}
+
+func TestTestOutcomesRoundTrip(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	in := map[string]TestOutcome{
+		"A.B.C":           {Name: "A.B.C", Failed: true, At: at},
+		"A.B.D(x: \"y\")": {Name: "A.B.D(x: \"y\")", At: at},
+	}
+	if out := decodeTestOutcomes(encodeTestOutcomes(in)); !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+	// Truncated data is ignored rather than misread
+	data := encodeTestOutcomes(map[string]TestOutcome{"A.B.C": in["A.B.C"]})
+	if out := decodeTestOutcomes(data[:len(data)-1]); len(out) != 0 {
+		t.Errorf("expected truncated entry to be dropped, got %+v", out)
+	}
+}
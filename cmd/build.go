@@ -1,18 +1,47 @@
 package cmd
 
 import (
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Build-specific flags
-	buildFlagNoSolution   bool
-	buildFlagSolution     bool
-	buildFlagFullBuild    bool
-	buildFlagVcsChanged   bool
-	buildFlagVcsRef       string
-	buildFlagWatch        bool
-	buildFlagPrintOutput  bool
+	buildFlagSolutionFilter  bool
+	buildFlagNoSolution      bool
+	buildFlagSolution        bool
+	buildFlagFullBuild       bool
+	buildFlagVcsChanged      bool
+	buildFlagChangedFiles    string
+	buildFlagVcsRef          string
+	buildFlagVcsRefMode      string
+	buildFlagScope           string
+	buildFlagSDK             string
+	buildFlagWatch           bool
+	buildFlagWatchDebounce   time.Duration
+	buildFlagWatchClear      bool
+	buildFlagOnIdle          string
+	buildFlagOnIdleAfter     time.Duration
+	buildFlagQuietOnSuccess  bool
+	buildFlagPrintOutput     bool
+	buildFlagDryRun          bool
+	buildFlagSince           string
+	buildFlagDiffInputs      string
+	buildFlagCacheKeyDebug   bool
+	buildFlagWhy             string
+	buildFlagAffectedGraph   string
+	buildFlagGraphOut        string
+	buildFlagProjects        []string
+	buildFlagWithDeps        bool
+	buildFlagOnlyProjects    string
+	buildFlagExcludeProjects []string
+	buildFlagExclude         []string
+	buildFlagOnly            []string
+	buildFlagReportMarkdown  string
+	buildFlagNotify          string
+	buildFlagNotifyOn        string
 
 	// Mapped dotnet flags
 	buildFlagConfiguration string
@@ -37,6 +66,7 @@ Examples:
   donotnet build -- --no-restore         Pass extra args to dotnet
   donotnet build --vcs-changed           Build projects with uncommitted changes
   donotnet build --vcs-ref=main          Build projects changed vs main branch
+  donotnet build --scope=services/api    Only consider changes under services/api/
   donotnet build --watch                 Watch for changes and rebuild`,
 	RunE: runBuild,
 }
@@ -45,13 +75,40 @@ func init() {
 	// Build-specific flags
 	buildCmd.Flags().BoolVar(&buildFlagNoSolution, "no-solution", false, "Disable solution-level builds")
 	buildCmd.Flags().BoolVar(&buildFlagSolution, "solution", false, "Force solution-level builds")
+	buildCmd.Flags().BoolVar(&buildFlagSolutionFilter, "solution-filter", false, "Use solution-level builds when 2+ projects in a solution need it, limited to those projects through a generated .slnf")
 	buildCmd.Flags().BoolVar(&buildFlagFullBuild, "full-build", false, "Disable auto --no-restore detection")
 
 	// Shared test/build flags
 	buildCmd.Flags().BoolVar(&buildFlagVcsChanged, "vcs-changed", false, "Only build projects with uncommitted changes")
 	buildCmd.Flags().StringVar(&buildFlagVcsRef, "vcs-ref", "", "Only build projects changed vs specified ref")
+	buildCmd.Flags().StringVar(&buildFlagChangedFiles, "changed-files", "", "Read changed files (repo-relative, one per line) from `file` or - for stdin, instead of asking git")
+	buildCmd.Flags().StringVar(&buildFlagVcsRefMode, "vcs-ref-mode", "", "How --vcs-ref is compared: merge-base (changes since branching off it, default) or direct (vs its tip)")
+	buildCmd.Flags().StringVar(&buildFlagScope, "scope", "", "Only consider changed files under `dir` (uncommitted, or vs --vcs-ref); discovery stays repo-wide")
+	buildCmd.Flags().StringVar(&buildFlagSDK, "sdk", "", "Require the dotnet on PATH to be this SDK `version` (or prefix, e.g. 8.0); without it, a mismatch with global.json warns")
 	buildCmd.Flags().BoolVar(&buildFlagWatch, "watch", false, "Watch for file changes and rebuild")
+	buildCmd.Flags().DurationVar(&buildFlagWatchDebounce, "watch-debounce", 0, "How long to wait for more file changes before rerunning in watch mode (default 100ms, config: watch.debounce_ms)")
+	buildCmd.Flags().BoolVar(&buildFlagWatchClear, "watch-clear", false, "In watch mode, clear the screen and show the changed files before each rerun (config: watch.clear)")
+	buildCmd.Flags().StringVar(&buildFlagOnIdle, "on-idle", "", "In watch mode, run this shell `command` once idle after a successful build (cancelled by new changes)")
+	buildCmd.Flags().DurationVar(&buildFlagOnIdleAfter, "on-idle-after", 0, "How long watch mode must be idle before running --on-idle (default 30s)")
 	buildCmd.Flags().BoolVar(&buildFlagPrintOutput, "print-output", false, "Print stdout from all projects after completion")
+	buildCmd.Flags().BoolVar(&buildFlagQuietOnSuccess, "quiet-on-success", false, "Hold back all output until done: print only a summary line if everything succeeded, everything if something failed")
+	buildCmd.Flags().BoolVar(&buildFlagDryRun, "dry-run", false, "Print the dotnet commands that would run, without running them or updating the cache")
+	buildCmd.Flags().StringVar(&buildFlagSince, "since", "", "Also run projects not successfully built since a duration ago (e.g. 24h) or RFC3339 time")
+	buildCmd.Flags().StringVar(&buildFlagDiffInputs, "diff-inputs", "", "List input files of `project` changed since its last successful run, then exit")
+	buildCmd.Flags().BoolVar(&buildFlagCacheKeyDebug, "cache-key-debug", false, "Print the content hash, args hash, cache key and hit/miss verdict of each selected project, then exit")
+	buildCmd.Flags().StringVar(&buildFlagWhy, "why", "", "Explain why `project` (name, path or glob) would build or be skipped, then exit")
+	buildCmd.Flags().StringVar(&buildFlagAffectedGraph, "affected-graph", "", "Write the dependency graph in `format` (dot, mermaid), colored by changed/affected/cached, then exit")
+	buildCmd.Flags().StringVarP(&buildFlagGraphOut, "output", "o", "", "With --affected-graph, write to this `file` instead of stdout")
+	buildCmd.Flags().StringArrayVar(&buildFlagProjects, "project", nil, "Build this project (`name` or .csproj path) regardless of changes (repeatable)")
+	buildCmd.Flags().BoolVar(&buildFlagWithDeps, "with-deps", false, "With --project, also build the projects it references")
+	buildCmd.Flags().StringVar(&buildFlagOnlyProjects, "only-projects", "", "Comma-separated project names or paths; only these are built (when not cached), ignoring change detection")
+	buildCmd.Flags().StringSliceVar(&buildFlagExclude, "exclude", nil, "Never build projects matching `pattern` (name or path glob), even when affected; repeatable or comma-separated")
+	buildCmd.Flags().StringSliceVar(&buildFlagExcludeProjects, "exclude-projects", nil, "Alias for --exclude")
+	buildCmd.Flags().MarkDeprecated("exclude-projects", "use --exclude instead")
+	buildCmd.Flags().StringArrayVar(&buildFlagOnly, "only", nil, "Only build affected projects matching `pattern` (name or path glob) or depending on one; repeatable")
+	buildCmd.Flags().StringVar(&buildFlagReportMarkdown, "report-markdown", "", "Write a Markdown summary of the run to `file` (e.g. for PR comments)")
+	buildCmd.Flags().StringVar(&buildFlagNotify, "notify", "", "POST a JSON summary of each run to this webhook `url`")
+	buildCmd.Flags().StringVar(&buildFlagNotifyOn, "notify-on", "always", "When to send --notify: always, failure")
 
 	// Mapped dotnet flags (no -- needed)
 	buildCmd.Flags().StringVarP(&buildFlagConfiguration, "configuration", "c", "", "Build configuration (e.g. Debug, Release)")
@@ -74,23 +131,56 @@ func runBuild(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if err := checkChangedFilesConflict(buildFlagChangedFiles, buildFlagVcsChanged, buildFlagVcsRef); err != nil {
+		return err
+	}
+	changedFiles, err := readChangedFiles(buildFlagChangedFiles)
+	if err != nil {
+		return err
+	}
+
 	// Inject mapped flags into dotnet args
 	dotnetArgs = injectMappedFlags(dotnetArgs, "", buildFlagConfiguration)
 
 	// Build options from flags
 	opts := &RunOptions{
-		Command:       "build",
-		DotnetArgs:    dotnetArgs,
-		Targets:       targets,
-		VcsChanged:    buildFlagVcsChanged,
-		VcsRef:        buildFlagVcsRef,
-		Watch:         buildFlagWatch,
-		PrintOutput:   buildFlagPrintOutput,
-		FullBuild:     buildFlagFullBuild,
-		NoSolution:    buildFlagNoSolution,
-		ForceSolution: buildFlagSolution,
-		Force:         IsForce(),
-		Config:        GetConfig(),
+		Command:             "build",
+		DotnetArgs:          dotnetArgs,
+		Targets:             targets,
+		VcsChanged:          buildFlagVcsChanged,
+		VcsRef:              buildFlagVcsRef,
+		ChangedFiles:        changedFiles,
+		VcsRefMode:          buildFlagVcsRefMode,
+		Scope:               buildFlagScope,
+		SDK:                 buildFlagSDK,
+		Watch:               buildFlagWatch,
+		WatchDebounce:       buildFlagWatchDebounce,
+		WatchClear:          buildFlagWatchClear,
+		OnIdle:              buildFlagOnIdle,
+		OnIdleAfter:         buildFlagOnIdleAfter,
+		PrintOutput:         buildFlagPrintOutput,
+		QuietOnSuccess:      buildFlagQuietOnSuccess,
+		FullBuild:           buildFlagFullBuild,
+		NoSolution:          buildFlagNoSolution,
+		ForceSolution:       buildFlagSolution,
+		SolutionFilter:      buildFlagSolutionFilter,
+		Projects:            buildFlagProjects,
+		WithDeps:            buildFlagWithDeps,
+		OnlyProjects:        project.ParseExcludePatterns(buildFlagOnlyProjects),
+		Only:                buildFlagOnly,
+		ExcludeProjects:     append(append([]string{}, buildFlagExclude...), buildFlagExcludeProjects...),
+		ReportMarkdown:      buildFlagReportMarkdown,
+		Notify:              buildFlagNotify,
+		NotifyOn:            buildFlagNotifyOn,
+		DryRun:              buildFlagDryRun,
+		Since:               buildFlagSince,
+		DiffInputs:          buildFlagDiffInputs,
+		CacheKeyDebug:       buildFlagCacheKeyDebug,
+		Why:                 buildFlagWhy,
+		AffectedGraph:       buildFlagAffectedGraph,
+		AffectedGraphOutput: buildFlagGraphOut,
+		Force:               IsForce(),
+		Config:              GetConfig(),
 	}
 
 	return Run(opts)
@@ -16,5 +16,7 @@ func init() {
 	// Subcommands are added in their respective files:
 	// - cache_stats.go
 	// - cache_clean.go
+	// - cache_compact.go
+	// - cache_durations.go
 	// - cache_dump.go
 }
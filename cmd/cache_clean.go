@@ -1,15 +1,25 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/git"
 	"github.com/runar-rkmedia/donotnet/term"
 	"github.com/spf13/cobra"
 )
 
 var (
 	cacheCleanOlderThan int
+	cacheCleanMaxSize   string
+	cacheCleanBranches  bool
+	cacheCleanProjects  bool
+	cacheCleanMaxAge    time.Duration
+	cacheCleanCompact   bool
 )
 
 var cacheCleanCmd = &cobra.Command{
@@ -17,31 +27,151 @@ var cacheCleanCmd = &cobra.Command{
 	Short: "Clean old cache entries",
 	Long: `Remove cache entries older than the specified number of days.
 
-By default, removes entries older than 30 days.`,
+By default, removes entries older than 30 days. Use --max-age for finer
+control than whole days (e.g. 36h).
+
+With --max-size, also evicts the least recently used entries until the cache
+fits the given size, compacting the file if that leaves much of it free.
+
+With --branches, also removes entries last run on local git branches that no
+longer exist (e.g. deleted after merging). Entries written before branches
+were recorded, or on a detached HEAD, are kept.
+
+With --projects, also removes entries of projects that are no longer
+discovered (e.g. renamed or deleted). Refuses to prune when no projects are
+found, so running from the wrong directory can't wipe the cache.
+
+With --compact, finally rewrites the database to reclaim the space of the
+removed entries (like "donotnet cache compact").`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cachePath, err := getCachePath()
 		if err != nil {
 			return err
 		}
 
+		if cacheCleanMaxAge != 0 && cmd.Flags().Changed("older-than") {
+			return fmt.Errorf("--max-age and --older-than cannot be combined")
+		}
+		if cacheCleanMaxAge < 0 {
+			return fmt.Errorf("--max-age must be positive")
+		}
+
+		var maxSize int64
+		if cacheCleanMaxSize != "" {
+			if maxSize, err = cache.ParseSize(cacheCleanMaxSize); err != nil {
+				return err
+			}
+		}
+
 		db, err := cache.Open(cachePath)
 		if err != nil {
 			return err
 		}
-		defer db.Close()
 
 		maxAge := time.Duration(cacheCleanOlderThan) * 24 * time.Hour
+		age := fmt.Sprintf("%d days", cacheCleanOlderThan)
+		if cacheCleanMaxAge > 0 {
+			maxAge, age = cacheCleanMaxAge, cacheCleanMaxAge.String()
+		}
 		deleted, err := db.DeleteOldEntries(maxAge)
 		if err != nil {
+			db.Close()
 			return err
 		}
+		term.Printf("Deleted %d entries older than %s\n", deleted, age)
 
-		term.Printf("Deleted %d entries older than %d days\n", deleted, cacheCleanOlderThan)
+		if cacheCleanBranches {
+			deleted, err := deleteBranchEntries(db)
+			if err != nil {
+				db.Close()
+				return err
+			}
+			term.Printf("Deleted %d entries of deleted branches\n", deleted)
+		}
+		if cacheCleanProjects {
+			deleted, err := deleteOrphanedProjectEntries(db)
+			if err != nil {
+				db.Close()
+				return err
+			}
+			term.Printf("Deleted %d entries of projects that no longer exist\n", deleted)
+		}
+		db.Close()
+
+		compacted := false
+		if cacheCleanMaxSize != "" {
+			res, err := cache.MaintainSize(cachePath, maxSize)
+			if err != nil {
+				return err
+			}
+			term.Printf("Evicted %d least recently used entries (%s) to fit %s\n",
+				res.Deleted, cache.FormatSize(res.Freed), cache.FormatSize(maxSize))
+			if res.Used > maxSize {
+				term.Warnf("Cache still uses %s after evicting all run results", cache.FormatSize(res.Used))
+			}
+			if res.Compacted {
+				compacted = true
+				term.Printf("Compacted %s: %s -> %s\n", cachePath, cache.FormatSize(res.Before), cache.FormatSize(res.After))
+			}
+		}
+
+		if cacheCleanCompact && !compacted {
+			before, after, err := cache.Compact(cachePath)
+			if err != nil {
+				return err
+			}
+			term.Printf("Compacted %s: %s -> %s\n", cachePath, cache.FormatSize(before), cache.FormatSize(after))
+		}
 		return nil
 	},
 }
 
+// deleteBranchEntries removes the entries of branches that no longer exist in
+// the current repository.
+func deleteBranchEntries(db *cache.DB) (int, error) {
+	gitRoot, err := git.FindRoot()
+	if err != nil {
+		return 0, fmt.Errorf("finding git root: %w", err)
+	}
+	branches, err := git.ListBranches(gitRoot)
+	if err != nil {
+		return 0, fmt.Errorf("listing branches: %w", err)
+	}
+	return db.DeleteBranchEntries(branches)
+}
+
+// deleteOrphanedProjectEntries removes the entries of projects that are not
+// among the currently discovered ones. With --local only projects below the
+// working directory are discovered, so entries outside it are kept.
+func deleteOrphanedProjectEntries(db *cache.DB) (int, error) {
+	scan, err := scanProjects()
+	if err != nil {
+		return 0, err
+	}
+	if len(scan.Projects) == 0 {
+		return 0, errors.New("no projects found; refusing to prune project entries (run from inside the repository)")
+	}
+
+	live := make(map[string]bool, len(scan.Projects))
+	for _, p := range scan.Projects {
+		live[filepath.ToSlash(p.Path)] = true
+	}
+	scope := ""
+	if rel, err := filepath.Rel(scan.GitRoot, scan.ScanRoot); err == nil && rel != "." {
+		scope = filepath.ToSlash(rel) + "/"
+	}
+	return db.DeleteProjectEntries(func(projectPath string) bool {
+		projectPath = filepath.ToSlash(projectPath)
+		return live[projectPath] || !strings.HasPrefix(projectPath, scope)
+	})
+}
+
 func init() {
 	cacheCleanCmd.Flags().IntVar(&cacheCleanOlderThan, "older-than", 30, "Remove entries older than N days")
+	cacheCleanCmd.Flags().DurationVar(&cacheCleanMaxAge, "max-age", 0, "Remove entries older than this `duration` (e.g. 36h) instead of --older-than days")
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanCompact, "compact", false, "Compact the cache afterwards to reclaim the freed disk space")
+	cacheCleanCmd.Flags().StringVar(&cacheCleanMaxSize, "max-size", "", "Also evict least recently used entries until the cache fits this `size` (e.g. 500MB)")
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanBranches, "branches", false, "Also remove entries of git branches that no longer exist")
+	cacheCleanCmd.Flags().BoolVar(&cacheCleanProjects, "projects", false, "Also remove entries of projects that are no longer discovered")
 	cacheCmd.AddCommand(cacheCleanCmd)
 }
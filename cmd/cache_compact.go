@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/spf13/cobra"
+)
+
+var cacheCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Reclaim disk space freed by deleted cache entries",
+	Long: `Rewrite the cache database into a fresh file containing only live entries.
+
+The database file never shrinks on its own, even after "donotnet cache clean"
+removes entries. Run compact afterwards to reclaim the space.`,
+	Example: `  donotnet cache clean --older-than=7 && donotnet cache compact`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cachePath, err := getCachePath()
+		if err != nil {
+			return err
+		}
+
+		before, after, err := cache.Compact(cachePath)
+		if err != nil {
+			return err
+		}
+
+		term.Printf("Compacted %s: %.2f KB -> %.2f KB\n", cachePath, float64(before)/1024, float64(after)/1024)
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheCompactCmd)
+}
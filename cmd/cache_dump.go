@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -13,13 +15,39 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	cacheDumpJSON          bool
+	cacheDumpIncludeOutput bool
+)
+
+// cacheDumpEntry is one entry of the --json output of cache dump.
+type cacheDumpEntry struct {
+	Key         string    `json:"key"`
+	Project     string    `json:"project"`
+	ContentHash string    `json:"content_hash"`
+	ArgsHash    string    `json:"args_hash"`
+	Args        string    `json:"args"`
+	Success     bool      `json:"success"`
+	LastRun     time.Time `json:"last_run"`
+	Commit      string    `json:"commit,omitempty"`
+	Branch      string    `json:"branch,omitempty"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	// CurrentHash is the project's content hash now, if it could be computed
+	CurrentHash string `json:"current_hash,omitempty"`
+	OutputLen   int    `json:"output_len"`
+	Output      []byte `json:"output,omitempty"` // base64, with --include-output
+}
+
 var cacheDumpCmd = &cobra.Command{
 	Use:   "dump <project>",
 	Short: "Dump cached output for a project",
 	Long: `Display the cached output for a specific project.
 
 The project can be specified by name or path. Searches all cache entries
-for matching project paths.`,
+for matching project paths.
+
+With --json, prints the matching entries as a JSON array. Stored output is
+left out unless --include-output is given (it is then base64-encoded).`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := args[0]
@@ -37,8 +65,26 @@ for matching project paths.`,
 
 		// Load project scan for computing current content hashes
 		scan, scanErr := scanProjects()
+		var hasher *runner.ContentHasher
+		if scanErr == nil && scan != nil {
+			hasher = runner.NewContentHasher(scan.GitRoot, GetConfig().HashMode)
+		}
+
+		// currentHash returns the project's content hash now ("" if unknown)
+		currentHash := func(projectPath string) string {
+			if scanErr != nil || scan == nil {
+				return ""
+			}
+			for _, p := range scan.Projects {
+				if p.Path == projectPath {
+					return hasher.Hash(project.GetRelevantDirs(p, scan.ForwardGraph))
+				}
+			}
+			return ""
+		}
 
 		var found bool
+		var entries []cacheDumpEntry
 		err = db.View(func(key string, entry cache.Entry) error {
 			contentHash, argsHash, projectPath := cache.ParseKey(key)
 			projectName := filepath.Base(filepath.Dir(projectPath))
@@ -49,6 +95,28 @@ for matching project paths.`,
 			}
 
 			found = true
+			if cacheDumpJSON {
+				e := cacheDumpEntry{
+					Key:         key,
+					Project:     projectPath,
+					ContentHash: contentHash,
+					ArgsHash:    argsHash,
+					Args:        entry.Args,
+					Success:     entry.Success,
+					LastRun:     time.Unix(entry.LastRun, 0),
+					Commit:      entry.Commit,
+					Branch:      entry.Branch,
+					DurationMs:  entry.Duration,
+					CurrentHash: currentHash(projectPath),
+					OutputLen:   len(entry.Output),
+				}
+				if cacheDumpIncludeOutput {
+					e.Output = entry.Output
+				}
+				entries = append(entries, e)
+				return nil
+			}
+
 			status := term.ColorGreen + "PASS" + term.ColorReset
 			if !entry.Success {
 				status = term.ColorRed + "FAIL" + term.ColorReset
@@ -61,21 +129,20 @@ for matching project paths.`,
 			term.Printf("Status:       %s\n", status)
 			term.Printf("Args:         %s\n", entry.Args)
 			term.Printf("Last run:     %s\n", time.Unix(entry.LastRun, 0).Format(time.RFC3339))
+			if entry.Commit != "" {
+				term.Printf("Commit:       %s\n", entry.Commit)
+			}
+			if entry.Duration > 0 {
+				term.Printf("Duration:     %s\n", time.Duration(entry.Duration)*time.Millisecond)
+			}
 
 			// Show current content hash comparison if we have scan data
-			if scanErr == nil && scan != nil {
-				for _, p := range scan.Projects {
-					if p.Path == projectPath {
-						relevantDirs := project.GetRelevantDirs(p, scan.ForwardGraph)
-						currentHash := runner.ComputeContentHash(scan.GitRoot, relevantDirs)
-						match := term.ColorGreen + "match" + term.ColorReset
-						if currentHash != contentHash {
-							match = term.ColorYellow + "changed" + term.ColorReset
-						}
-						term.Printf("Current hash: %s (%s)\n", currentHash, match)
-						break
-					}
+			if current := currentHash(projectPath); current != "" {
+				match := term.ColorGreen + "match" + term.ColorReset
+				if current != contentHash {
+					match = term.ColorYellow + "changed" + term.ColorReset
 				}
+				term.Printf("Current hash: %s (%s)\n", current, match)
 			}
 
 			if len(entry.Output) > 0 {
@@ -95,10 +162,17 @@ for matching project paths.`,
 			return fmt.Errorf("no cache entries found matching %q", query)
 		}
 
+		if cacheDumpJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(entries)
+		}
 		return nil
 	},
 }
 
 func init() {
+	cacheDumpCmd.Flags().BoolVar(&cacheDumpJSON, "json", false, "Output as JSON")
+	cacheDumpCmd.Flags().BoolVar(&cacheDumpIncludeOutput, "include-output", false, "With --json, include the stored output (base64)")
 	cacheCmd.AddCommand(cacheDumpCmd)
 }
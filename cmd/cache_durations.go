@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/spf13/cobra"
+)
+
+// regressionFactor flags a project whose latest run took this many times its
+// median duration.
+const regressionFactor = 1.5
+
+var cacheDurationsRegressions bool
+
+var cacheDurationsCmd = &cobra.Command{
+	Use:   "durations",
+	Short: "Show recent run durations per project",
+	Long: `List the min, median, max and latest duration of each project's recent runs.
+
+The last runs of every project are kept per set of args (e.g. "test" and
+"build" separately). A project is flagged as a regression when its latest run
+took more than 1.5x its median duration.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cachePath, err := getCachePath()
+		if err != nil {
+			return err
+		}
+
+		db, err := cache.Open(cachePath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		printDurationHistories(db.GetDurationHistories(), cacheDurationsRegressions)
+		return nil
+	},
+}
+
+func init() {
+	cacheDurationsCmd.Flags().BoolVar(&cacheDurationsRegressions, "regressions", false, "Only list projects whose latest run regressed")
+	cacheCmd.AddCommand(cacheDurationsCmd)
+}
+
+// printDurationHistories prints one line per history, flagging regressions.
+func printDurationHistories(histories []cache.DurationHistory, onlyRegressions bool) {
+	if len(histories) == 0 {
+		term.Printf("No run durations recorded yet\n")
+		return
+	}
+
+	term.Printf("  %9s  %9s  %9s  %9s  %4s  %s\n", "Min", "Median", "Max", "Last", "Runs", "Project")
+	regressions := 0
+	for _, h := range histories {
+		regressed := h.Regressed(regressionFactor)
+		if regressed {
+			regressions++
+		} else if onlyRegressions {
+			continue
+		}
+
+		shortest, median, longest := h.MinMedianMax()
+		line := fmt.Sprintf("  %9s  %9s  %9s  %9s  %4d  %s (%s)",
+			shortest.Round(time.Millisecond), median.Round(time.Millisecond), longest.Round(time.Millisecond),
+			h.Last().Round(time.Millisecond), len(h.Samples), h.ProjectPath, h.Args)
+		if regressed {
+			line += fmt.Sprintf("  %sregressed %.1fx median%s",
+				term.Color(term.ColorYellow), float64(h.Last())/float64(median), term.Color(term.ColorReset))
+		}
+		term.Printf("%s\n", line)
+	}
+
+	if regressions > 0 {
+		term.Printf("\n%d project(s) regressed (latest run > %.1fx median)\n", regressions, regressionFactor)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"time"
@@ -11,10 +12,30 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	cacheStatsTop  int
+	cacheStatsJSON bool
+)
+
+// cacheStatsOutput is the --json output of cache stats.
+type cacheStatsOutput struct {
+	Path         string     `json:"path"`
+	SizeBytes    int64      `json:"size_bytes"`
+	TotalEntries int        `json:"total_entries"`
+	Oldest       *time.Time `json:"oldest,omitempty"`
+	Newest       *time.Time `json:"newest,omitempty"`
+}
+
 var cacheStatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Show cache statistics",
-	Long:  `Display statistics about the donotnet cache including size, entries, and age.`,
+	Long: `Display statistics about the donotnet cache including size, entries, and age.
+
+With --verbose, also lists the slowest projects by their most recent recorded
+run, with the average over all recorded runs.
+
+With --json, prints the path, size, entry count and oldest/newest entry times
+as a JSON object, e.g. for monitoring the cache over time.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cachePath, err := getCachePath()
 		if err != nil {
@@ -28,6 +49,16 @@ var cacheStatsCmd = &cobra.Command{
 		defer db.Close()
 
 		stats := db.GetStats()
+		if cacheStatsJSON {
+			out := cacheStatsOutput{Path: cachePath, SizeBytes: stats.DBSize, TotalEntries: stats.TotalEntries}
+			if stats.TotalEntries > 0 {
+				out.Oldest, out.Newest = &stats.OldestEntry, &stats.NewestEntry
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(out)
+		}
+
 		term.Printf("Cache statistics:\n")
 		term.Printf("  Database: %s\n", cachePath)
 		term.Printf("  Size: %d bytes (%.2f KB)\n", stats.DBSize, float64(stats.DBSize)/1024)
@@ -36,21 +67,44 @@ var cacheStatsCmd = &cobra.Command{
 			term.Printf("  Oldest entry: %s\n", stats.OldestEntry.Format(time.RFC3339))
 			term.Printf("  Newest entry: %s\n", stats.NewestEntry.Format(time.RFC3339))
 		}
+
+		if flagVerbose {
+			printSlowestProjects(db.GetProjectDurations(), cacheStatsTop)
+		}
 		return nil
 	},
 }
 
 func init() {
+	cacheStatsCmd.Flags().IntVar(&cacheStatsTop, "top", 10, "Number of slowest projects to list with --verbose")
+	cacheStatsCmd.Flags().BoolVar(&cacheStatsJSON, "json", false, "Output as JSON")
 	cacheCmd.AddCommand(cacheStatsCmd)
 }
 
+// printSlowestProjects lists the n slowest projects by most recent duration.
+func printSlowestProjects(durations []cache.ProjectDuration, n int) {
+	if len(durations) == 0 {
+		term.Printf("\nNo run durations recorded yet\n")
+		return
+	}
+	if n > 0 && len(durations) > n {
+		durations = durations[:n]
+	}
+
+	term.Printf("\nSlowest projects:\n")
+	for _, d := range durations {
+		term.Printf("  %9s  avg %9s  %3d run(s)  %s (%s)\n",
+			d.Last.Round(time.Millisecond), d.Average.Round(time.Millisecond), d.Runs, d.ProjectPath, d.Args)
+	}
+}
+
 // getCachePath returns the path to the cache database.
 func getCachePath() (string, error) {
 	cfg := GetConfig()
 
 	// Use config cache dir if set
 	if cfg != nil && cfg.CacheDir != "" {
-		return filepath.Join(cfg.CacheDir, "cache.db"), nil
+		return filepath.Join(cfg.CacheDir, cache.FileName(cfg.CacheScope)), nil
 	}
 
 	// Find git root
@@ -66,5 +120,9 @@ func getCachePath() (string, error) {
 
 	cacheDir := filepath.Join(gitRoot, ".donotnet")
 	os.MkdirAll(cacheDir, 0755)
-	return filepath.Join(cacheDir, "cache.db"), nil
+	scope := ""
+	if cfg != nil {
+		scope = cfg.CacheScope
+	}
+	return filepath.Join(cacheDir, cache.FileName(scope)), nil
 }
@@ -15,6 +15,7 @@ type FindChangedOpts struct {
 	ArgsHash     string
 	VcsFiles     []string // nil = no VCS filtering
 	Force        bool
+	HashMode     string // see runner.HashModeContent
 }
 
 // FindChangedProjects returns projects whose content hash is not in the cache.
@@ -22,6 +23,7 @@ type FindChangedOpts struct {
 func FindChangedProjects(opts FindChangedOpts) map[string]bool {
 	useVcsFilter := len(opts.VcsFiles) > 0
 	changed := make(map[string]bool)
+	hasher := runner.NewContentHasher(opts.GitRoot, opts.HashMode)
 
 	for _, p := range opts.Projects {
 		relevantDirs := project.GetRelevantDirs(p, opts.ForwardGraph)
@@ -32,7 +34,7 @@ func FindChangedProjects(opts FindChangedOpts) map[string]bool {
 			}
 		}
 
-		contentHash := runner.ComputeContentHash(opts.GitRoot, relevantDirs)
+		contentHash := hasher.Hash(relevantDirs)
 		key := cache.MakeKey(contentHash, opts.ArgsHash, p.Path)
 		if opts.Force || opts.DB.Lookup(key) == nil {
 			changed[p.Path] = true
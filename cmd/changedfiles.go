@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readChangedFiles reads the --changed-files list from a file, or stdin when
+// arg is "-". Returns nil when arg is empty (the flag wasn't given), and a
+// non-nil, possibly empty slice otherwise.
+func readChangedFiles(arg string) ([]string, error) {
+	if arg == "" {
+		return nil, nil
+	}
+	if arg == "-" {
+		return parseChangedFiles(os.Stdin)
+	}
+	f, err := os.Open(arg)
+	if err != nil {
+		return nil, fmt.Errorf("reading --changed-files: %w", err)
+	}
+	defer f.Close()
+	return parseChangedFiles(f)
+}
+
+// parseChangedFiles parses newline-separated repo-relative paths, skipping
+// blank lines. Backslashes and a leading "./" are normalized away.
+func parseChangedFiles(r io.Reader) ([]string, error) {
+	files := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		line = strings.TrimPrefix(strings.ReplaceAll(line, "\\", "/"), "./")
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading --changed-files: %w", err)
+	}
+	return files, nil
+}
+
+// checkChangedFilesConflict rejects --changed-files combined with the VCS
+// flags it replaces.
+func checkChangedFilesConflict(changedFiles string, vcsChanged bool, vcsRef string) error {
+	if changedFiles == "" {
+		return nil
+	}
+	if vcsChanged {
+		return fmt.Errorf("--changed-files and --vcs-changed cannot be combined")
+	}
+	if vcsRef != "" {
+		return fmt.Errorf("--changed-files and --vcs-ref cannot be combined")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChangedFiles(t *testing.T) {
+	input := "src/App/Foo.cs\n\n  ./src/Lib/Bar.cs  \r\nsrc\\Win\\Baz.cs\n"
+	got, err := parseChangedFiles(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseChangedFiles() failed: %v", err)
+	}
+	want := []string{"src/App/Foo.cs", "src/Lib/Bar.cs", "src/Win/Baz.cs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseChangedFiles() = %q, want %q", got, want)
+	}
+
+	// An empty list is still a list: nothing changed
+	got, err = parseChangedFiles(strings.NewReader("\n"))
+	if err != nil || got == nil || len(got) != 0 {
+		t.Errorf("parseChangedFiles(empty) = %#v, %v, want empty non-nil slice", got, err)
+	}
+}
+
+func TestCheckChangedFilesConflict(t *testing.T) {
+	if err := checkChangedFilesConflict("-", false, ""); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := checkChangedFilesConflict("", true, "main"); err != nil {
+		t.Errorf("unexpected error without --changed-files: %v", err)
+	}
+	if err := checkChangedFilesConflict("-", true, ""); err == nil {
+		t.Error("expected --vcs-changed conflict")
+	}
+	if err := checkChangedFilesConflict("changed.txt", false, "main"); err == nil {
+		t.Error("expected --vcs-ref conflict")
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/spf13/cobra"
+)
+
+var checkCyclesCmd = &cobra.Command{
+	Use:   "check-cycles",
+	Short: "Report circular project references",
+	Long: `Check the <ProjectReference> graph for cycles.
+
+Each cycle is reported with the projects in the loop. Exits with an error if
+any cycle is found, so it can be used as a CI check.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scan, err := scanProjects()
+		if err != nil {
+			return err
+		}
+
+		cycles := project.FindCycles(scan.ForwardGraph)
+		if len(cycles) == 0 {
+			term.Success("No dependency cycles in %d projects", len(scan.Projects))
+			return nil
+		}
+
+		for _, cycle := range cycles {
+			term.Errorf("dependency cycle between %d project(s):", len(cycle))
+			for _, path := range cycle {
+				term.Printf("  %s\n", path)
+			}
+		}
+		return fmt.Errorf("found %d dependency cycle(s)", len(cycles))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCyclesCmd)
+}
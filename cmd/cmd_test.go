@@ -48,7 +48,7 @@ func TestConfigCommand(t *testing.T) {
 
 func TestListSubcommands(t *testing.T) {
 	subcommands := listCmd.Commands()
-	expectedSubs := []string{"affected", "tests", "heuristics", "coverage"}
+	expectedSubs := []string{"affected", "tests", "traits", "heuristics", "coverage"}
 
 	foundSubs := make(map[string]bool)
 	for _, cmd := range subcommands {
@@ -94,6 +94,21 @@ func TestCoverageSubcommands(t *testing.T) {
 	}
 }
 
+func TestCoverageBuildIncrementalAlias(t *testing.T) {
+	defer func() { coverageBuildIncremental = false }()
+
+	for _, flag := range []string{"incremental", "coverage-incremental"} {
+		coverageBuildIncremental = false
+		if err := coverageBuildCmd.Flags().Set(flag, "true"); err != nil {
+			t.Fatalf("setting --%s: %v", flag, err)
+		}
+		if !coverageBuildIncremental {
+			t.Errorf("--%s did not enable incremental coverage builds", flag)
+		}
+		coverageBuildCmd.Flags().Set(flag, "false")
+	}
+}
+
 func TestTestCommandFlags(t *testing.T) {
 	flags := []string{
 		"coverage",
@@ -109,6 +124,7 @@ func TestTestCommandFlags(t *testing.T) {
 		"full-build",
 		"no-solution",
 		"solution",
+		"exclude",
 		"filter",
 		"configuration",
 	}
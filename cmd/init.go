@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/config"
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/runner"
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testfilter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	initFlagConfig bool
+)
+
+// gitignoreEntries keeps the cache out of git while leaving committed
+// settings (config, heuristics, exclusions, hash ignores, cache version) in
+// .donotnet/ tracked.
+var gitignoreEntries = []string{
+	"# donotnet cache",
+	config.ConfigDirName + "/*",
+	"!" + config.ConfigDirName + "/" + config.ConfigFileName + ".toml",
+	"!" + config.ConfigDirName + "/" + testfilter.HeuristicsFileName,
+	"!" + config.ConfigDirName + "/" + project.ExcludeFileName,
+	"!" + config.ConfigDirName + "/" + runner.HashIgnoreFileName,
+	"!" + config.ConfigDirName + "/" + runner.CacheVersionFileName,
+}
+
+// starterConfig is written by "donotnet init --config".
+const starterConfig = `# donotnet configuration. Run "donotnet config" to see all settings and
+# their effective values.
+#
+# Every key can also be set from the environment: DONOTNET_ + the key in
+# upper case, with sections joined by _ (e.g. DONOTNET_TEST_COVERAGE=true).
+# Command-line flags override both.
+#
+# Other files in this directory:
+#   exclude          project globs to skip, one per line (e.g. samples/**)
+#   heuristics.json  custom test-selection heuristics
+#   hashignore       files that never invalidate the cache (e.g. *.md)
+#   cache-version    change its content to invalidate all cached results
+
+# parallel = 0            # 0 = auto (number of CPUs)
+# keep_going = false
+# test_project_patterns = []  # regexes on project name; "!" prefix = never a test project
+
+[test]
+# heuristics = "default"  # default, none, or comma-separated names ("donotnet list heuristics")
+# coverage = false        # collect per-test coverage for finer test selection
+# exclude_traits = []     # test categories to never run, e.g. ["Live"]
+
+[build]
+# solution = "auto"       # auto, always, never
+
+[vcs]
+# ref = "main"            # always compare against this ref
+`
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up donotnet in this repository",
+	Long: `Prepare the current git repository for donotnet.
+
+Adds the .donotnet/ cache directory to the root .gitignore (creating it if
+needed), so cache.db, reports and coverage maps are never committed. Files
+meant to be shared (config.toml, heuristics.json, exclude, hashignore,
+cache-version) stay tracked. Running init again is safe: existing entries are left untouched.
+
+With --config, also writes a starter .donotnet/config.toml if none exists.`,
+	Example: `  donotnet init
+  donotnet init --config`,
+	RunE: runInit,
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initFlagConfig, "config", false, "Also create a starter .donotnet/config.toml")
+	rootCmd.AddCommand(initCmd)
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	gitRoot, err := git.FindRoot()
+	if err != nil {
+		return fmt.Errorf("finding git root: %w", err)
+	}
+
+	gitignorePath := filepath.Join(gitRoot, ".gitignore")
+	added, err := ensureGitignore(gitignorePath)
+	if err != nil {
+		return fmt.Errorf("updating .gitignore: %w", err)
+	}
+	if added {
+		term.Success("Added %s/ to %s", config.ConfigDirName, gitignorePath)
+	} else {
+		term.Dim("%s already ignores %s/", gitignorePath, config.ConfigDirName)
+	}
+
+	if initFlagConfig {
+		configPath := filepath.Join(gitRoot, config.ConfigDirName, config.ConfigFileName+".toml")
+		if _, err := os.Stat(configPath); err == nil {
+			term.Dim("%s already exists", configPath)
+		} else {
+			if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(configPath, []byte(starterConfig), 0644); err != nil {
+				return err
+			}
+			term.Success("Created %s", configPath)
+		}
+	}
+
+	printInitNextSteps()
+	return nil
+}
+
+// printInitNextSteps tells a new user what to run after init.
+func printInitNextSteps() {
+	term.Println()
+	term.Println("Next steps:")
+	term.Println("  donotnet test                  run the tests of changed projects (the first run fills the cache)")
+	term.Println("  donotnet coverage build        collect per-test coverage, so only tests covering a change run")
+	if !initFlagConfig {
+		term.Println("  donotnet init --config         create a starter .donotnet/config.toml")
+	}
+	term.Println("  donotnet config --locations    show which config files are in use")
+}
+
+// ensureGitignore appends the donotnet cache entries to the .gitignore at path,
+// creating the file if it does not exist. Returns false without modifying the
+// file if it already ignores the cache directory.
+func ensureGitignore(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if ignoresCacheDir(content) {
+		return false, nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(content)
+	if len(content) > 0 {
+		if !bytes.HasSuffix(content, []byte("\n")) {
+			buf.WriteString("\n")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(strings.Join(gitignoreEntries, "\n") + "\n")
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ignoresCacheDir reports whether gitignore content already ignores .donotnet.
+func ignoresCacheDir(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "/")
+		switch line {
+		case config.ConfigDirName, config.ConfigDirName + "/", config.ConfigDirName + "/*":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestEnsureGitignoreIdempotent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitignore")
+	os.WriteFile(path, []byte("bin/\nobj/"), 0644)
+
+	added, err := ensureGitignore(path)
+	if err != nil {
+		t.Fatalf("ensureGitignore() failed: %v", err)
+	}
+	if !added {
+		t.Fatal("expected entries to be added on first run")
+	}
+	first, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(first), "bin/\nobj/\n\n# donotnet cache\n.donotnet/*\n") {
+		t.Errorf("unexpected .gitignore content:\n%s", first)
+	}
+
+	added, err = ensureGitignore(path)
+	if err != nil {
+		t.Fatalf("ensureGitignore() failed: %v", err)
+	}
+	if added {
+		t.Error("expected second run to be a no-op")
+	}
+	second, _ := os.ReadFile(path)
+	if string(second) != string(first) {
+		t.Errorf("second run modified .gitignore:\n%s", second)
+	}
+}
+
+func TestEnsureGitignoreCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gitignore")
+
+	if added, err := ensureGitignore(path); err != nil || !added {
+		t.Fatalf("ensureGitignore() = %v, %v", added, err)
+	}
+	content, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(content), "# donotnet cache\n") {
+		t.Errorf("unexpected .gitignore content:\n%s", content)
+	}
+}
+
+func TestEnsureGitignoreExistingEntry(t *testing.T) {
+	for _, existing := range []string{".donotnet", ".donotnet/", "/.donotnet/", ".donotnet/*"} {
+		path := filepath.Join(t.TempDir(), ".gitignore")
+		os.WriteFile(path, []byte("bin/\n"+existing+"\n"), 0644)
+
+		if added, err := ensureGitignore(path); err != nil || added {
+			t.Errorf("%q: ensureGitignore() = %v, %v; want no-op", existing, added, err)
+		}
+	}
+}
+
+func TestStarterConfigParses(t *testing.T) {
+	// Everything is commented out, but the sections must still be valid TOML
+	var got map[string]any
+	if err := toml.Unmarshal([]byte(starterConfig), &got); err != nil {
+		t.Fatalf("starter config is not valid TOML: %v", err)
+	}
+	for _, section := range []string{"test", "build", "vcs"} {
+		if _, ok := got[section]; !ok {
+			t.Errorf("expected [%s] section in starter config", section)
+		}
+	}
+}
@@ -16,6 +16,7 @@ func init() {
 	// Subcommands are added in their respective files:
 	// - list_affected.go
 	// - list_tests.go
+	// - list_traits.go
 	// - list_heuristics.go
 	// - list_coverage.go
 }
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/runar-rkmedia/donotnet/cache"
 	"github.com/runar-rkmedia/donotnet/git"
@@ -13,10 +16,27 @@ import (
 )
 
 var (
-	listAffectedType   string
-	listAffectedVcsRef string
+	listAffectedType       string
+	listAffectedVcsRef     string
+	listAffectedVcsRefMode string
+	listAffectedAffectedBy []string
+	listAffectedShowFiles  bool
+	listAffectedJSON       bool
+	listAffectedSummary    bool
 )
 
+// affectedProject is one entry in the list affected output.
+type affectedProject struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	IsTest  bool   `json:"test"`
+	Changed bool   `json:"changed"` // changed itself, rather than only through a dependency
+	// AffectedVia is the dependency chain (project paths) from a changed
+	// project down to this project's direct dependency; empty if Changed
+	AffectedVia []string `json:"affected_via"`
+	Files       []string `json:"files,omitempty"` // changed files within the project's relevant dirs
+}
+
 var listAffectedCmd = &cobra.Command{
 	Use:   "affected",
 	Short: "List affected projects",
@@ -25,21 +45,63 @@ var listAffectedCmd = &cobra.Command{
 Projects can be filtered by type:
   all       - All affected projects (default)
   tests     - Only test projects
-  non-tests - Only non-test projects`,
+  non-tests - Only non-test projects
+
+With --affected-by, lists the projects that changing the given files would
+affect, without looking at git or the cache. The files need not exist.
+
+With --show-files, each project is followed by the changed files in its own
+or its dependencies' directories, i.e. why it is affected.
+
+With --json, each project is an object with its path, name, whether it is a
+test project, whether it changed itself, and affected_via: the chain of
+project paths from a changed project down to its direct dependency.
+
+With --summary, prints a human-readable summary (e.g. for PR descriptions):
+counts, then each changed project with its changed files and the dependents
+it pulled in.`,
+	Example: `  donotnet list affected --type=tests
+  donotnet list affected --affected-by=src/Core/Thing.cs --type=tests
+  donotnet list affected --show-files --json
+  donotnet list affected --summary --vcs-ref=main`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if listAffectedSummary && listAffectedJSON {
+			return fmt.Errorf("--summary and --json cannot be combined")
+		}
 		scan, err := scanProjects()
 		if err != nil {
 			return err
 		}
 
+		if len(listAffectedAffectedBy) > 0 {
+			if listAffectedVcsRef != "" {
+				return fmt.Errorf("--affected-by and --vcs-ref cannot be combined")
+			}
+			files, err := gitRelativePaths(scan.GitRoot, listAffectedAffectedBy)
+			if err != nil {
+				return err
+			}
+			changed := project.FindProjectsForFiles(files, scan.Projects, scan.ForwardGraph)
+			return printAffected(collectAffected(scan.Projects, scan.ForwardGraph, scan.Graph, changed, files))
+		}
+
 		// Use uncommitted changes to determine affected projects,
 		// matching the old behavior where list-affected implied VCS-changed mode.
-		vcsChangedFiles := git.GetDirtyFiles(scan.GitRoot)
+		vcs, err := vcsBackend(scan.GitRoot)
+		if err != nil {
+			return err
+		}
+		vcsChangedFiles := git.ChangedPaths(vcs.DirtyFileChanges(scan.GitRoot))
 		if listAffectedVcsRef != "" {
-			vcsChangedFiles, err = git.GetChangedFiles(scan.GitRoot, listAffectedVcsRef)
+			mode := listAffectedVcsRefMode
+			if mode == "" && cfg != nil {
+				mode = cfg.VCS.RefMode
+			}
+			changes, _, err := git.RefChanges(vcs, scan.GitRoot, listAffectedVcsRef, mode)
 			if err != nil {
 				return err
 			}
+			vcsChangedFiles = git.ChangedPaths(changes)
 		}
 		// Open cache to find changed projects
 		cacheDir := ""
@@ -49,7 +111,11 @@ Projects can be filtered by type:
 			cacheDir = filepath.Join(scan.GitRoot, ".donotnet")
 		}
 		os.MkdirAll(cacheDir, 0755)
-		cachePath := filepath.Join(cacheDir, "cache.db")
+		scope := ""
+		if cfg != nil {
+			scope = cfg.CacheScope
+		}
+		cachePath := filepath.Join(cacheDir, cache.FileName(scope))
 
 		db, err := cache.Open(cachePath)
 		if err != nil {
@@ -66,39 +132,195 @@ Projects can be filtered by type:
 			ArgsHash:     runner.HashArgs([]string{"test"}),
 			VcsFiles:     vcsChangedFiles,
 			Force:        flagForce,
+			HashMode:     GetConfig().HashMode,
 		})
 
-		affected := project.FindAffectedProjects(changed, scan.Graph, scan.Projects)
+		return printAffected(collectAffected(scan.Projects, scan.ForwardGraph, scan.Graph, changed, vcsChangedFiles))
+	},
+}
 
-		var count int
-		for _, p := range scan.Projects {
-			if !affected[p.Path] {
+// collectAffected returns the projects affected by changed (through graph,
+// the reverse dependency graph) matching --type, each with how it is
+// affected and the changed files that fall within its relevant dirs.
+func collectAffected(projects []*project.Project, forwardGraph, graph map[string][]string, changed map[string]bool, files []string) []affectedProject {
+	affected, parents := project.FindAffectedProjectsVia(changed, graph)
+	var result []affectedProject
+	for _, p := range projects {
+		if !affected[p.Path] {
+			continue
+		}
+		switch listAffectedType {
+		case "tests":
+			if !p.IsTest {
 				continue
 			}
-			switch listAffectedType {
-			case "tests":
-				if !p.IsTest {
-					continue
-				}
-			case "non-tests":
-				if p.IsTest {
-					continue
-				}
+		case "non-tests":
+			if p.IsTest {
+				continue
 			}
-			count++
-			term.Println(p.Path)
 		}
+		via := project.AffectedVia(parents, p.Path)
+		if via == nil {
+			via = []string{}
+		}
+		result = append(result, affectedProject{
+			Path:        p.Path,
+			Name:        p.Name,
+			IsTest:      p.IsTest,
+			Changed:     changed[p.Path],
+			AffectedVia: via,
+			Files:       project.FilterFilesToProject(files, project.GetRelevantDirs(p, forwardGraph)),
+		})
+	}
+	return result
+}
+
+// printAffected prints affected projects as text, JSON or a --summary, with
+// their changed files if --show-files is set.
+func printAffected(affected []affectedProject) error {
+	// The summary always lists the changed files that triggered each group
+	if listAffectedSummary {
+		term.Printf("%s", formatAffectedSummary(affected))
+		return nil
+	}
+
+	if !listAffectedShowFiles {
+		for i := range affected {
+			affected[i].Files = nil
+		}
+	}
 
-		if count == 0 {
-			term.Dim("No affected projects")
+	if listAffectedJSON {
+		if affected == nil {
+			affected = []affectedProject{}
 		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(affected)
+	}
 
+	if len(affected) == 0 {
+		term.Dim("No affected projects")
 		return nil
-	},
+	}
+	for _, a := range affected {
+		term.Println(a.Path)
+		if listAffectedShowFiles {
+			for _, f := range a.Files {
+				term.Printf("  %s\n", f)
+			}
+		}
+	}
+	return nil
+}
+
+// formatAffectedSummary renders affected projects grouped by the changed
+// project that pulled them in, e.g.
+//
+//	1 project changed directly, 2 affected transitively
+//
+//	Core (src/Core/Core.csproj)
+//	  changed: src/Core/Thing.cs
+//	  -> App (via Core)
+//	  -> App.Tests (via Core -> App)
+func formatAffectedSummary(affected []affectedProject) string {
+	if len(affected) == 0 {
+		return "No affected projects\n"
+	}
+
+	names := make(map[string]string, len(affected))
+	for _, a := range affected {
+		names[a.Path] = a.Name
+	}
+	name := func(path string) string {
+		if n, ok := names[path]; ok {
+			return n
+		}
+		return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+
+	// Group by the changed project at the start of each dependency chain,
+	// in the order the groups first appear
+	var roots []string
+	changed := make(map[string]affectedProject)
+	dependents := make(map[string][]affectedProject)
+	addRoot := func(path string) {
+		if _, ok := changed[path]; ok {
+			return
+		}
+		if _, ok := dependents[path]; ok {
+			return
+		}
+		roots = append(roots, path)
+	}
+	direct := 0
+	for _, a := range affected {
+		if a.Changed {
+			direct++
+			addRoot(a.Path)
+			changed[a.Path] = a
+			continue
+		}
+		root := a.AffectedVia[0]
+		addRoot(root)
+		dependents[root] = append(dependents[root], a)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s changed directly, %d affected transitively\n",
+		countNoun(direct, "project"), len(affected)-direct)
+	for _, root := range roots {
+		fmt.Fprintf(&sb, "\n%s (%s)\n", name(root), root)
+		for _, f := range changed[root].Files {
+			fmt.Fprintf(&sb, "  changed: %s\n", f)
+		}
+		for _, d := range dependents[root] {
+			via := make([]string, len(d.AffectedVia))
+			for i, p := range d.AffectedVia {
+				via[i] = name(p)
+			}
+			fmt.Fprintf(&sb, "  -> %s (via %s)\n", d.Name, strings.Join(via, " -> "))
+		}
+	}
+	return sb.String()
+}
+
+// countNoun renders n with noun, pluralized with a trailing s.
+func countNoun(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// gitRelativePaths converts paths (absolute or relative to the working
+// directory) to slash-separated paths relative to gitRoot.
+func gitRelativePaths(gitRoot string, paths []string) ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working directory: %w", err)
+	}
+	var result []string
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(cwd, path)
+		}
+		rel, err := filepath.Rel(gitRoot, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside the git repository %s", path, gitRoot)
+		}
+		result = append(result, filepath.ToSlash(rel))
+	}
+	return result, nil
 }
 
 func init() {
 	listAffectedCmd.Flags().StringVarP(&listAffectedType, "type", "t", "all", "Filter by type: all, tests, non-tests")
 	listAffectedCmd.Flags().StringVar(&listAffectedVcsRef, "vcs-ref", "", "Compare against a git ref (e.g., main, HEAD~3) instead of uncommitted changes")
+	listAffectedCmd.Flags().StringVar(&listAffectedVcsRefMode, "vcs-ref-mode", "", "How --vcs-ref is compared: merge-base (changes since branching off it, default) or direct (vs its tip)")
+	listAffectedCmd.Flags().StringArrayVar(&listAffectedAffectedBy, "affected-by", nil, "List projects affected by changing this `file` instead of checking git and the cache (repeatable)")
+	listAffectedCmd.Flags().BoolVar(&listAffectedShowFiles, "show-files", false, "List the changed files that affect each project")
+	listAffectedCmd.Flags().BoolVar(&listAffectedJSON, "json", false, "Output as JSON")
+	listAffectedCmd.Flags().BoolVar(&listAffectedSummary, "summary", false, "Print a summary grouping affected projects by the changed project that pulled them in")
 	listCmd.AddCommand(listAffectedCmd)
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestCollectAffectedAttributesFiles(t *testing.T) {
+	core := &project.Project{Name: "Core", Path: "src/Core/Core.csproj", Dir: "src/Core"}
+	app := &project.Project{Name: "App", Path: "src/App/App.csproj", Dir: "src/App"}
+	appTests := &project.Project{Name: "App.Tests", Path: "tests/App.Tests/App.Tests.csproj", Dir: "tests/App.Tests", IsTest: true}
+	other := &project.Project{Name: "Other", Path: "src/Other/Other.csproj", Dir: "src/Other"}
+	projects := []*project.Project{core, app, appTests, other}
+
+	forward := map[string][]string{
+		app.Path:      {core.Path},
+		appTests.Path: {app.Path},
+	}
+	reverse := map[string][]string{
+		core.Path: {app.Path},
+		app.Path:  {appTests.Path},
+	}
+	changed := map[string]bool{core.Path: true}
+	files := []string{"src/Core/Thing.cs", "src/App/Program.cs", "tests/App.Tests/ProgramTests.cs", "src/Other/Unrelated.cs"}
+
+	saved := listAffectedType
+	defer func() { listAffectedType = saved }()
+
+	listAffectedType = "all"
+	got := collectAffected(projects, forward, reverse, changed, files)
+	want := map[string][]string{
+		core.Path:     {"src/Core/Thing.cs"},
+		app.Path:      {"src/Core/Thing.cs", "src/App/Program.cs"},
+		appTests.Path: {"src/Core/Thing.cs", "src/App/Program.cs", "tests/App.Tests/ProgramTests.cs"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d projects, want %d: %+v", len(got), len(want), got)
+	}
+	for _, a := range got {
+		wantFiles := want[a.Path]
+		set := make(map[string]bool)
+		for _, f := range a.Files {
+			set[f] = true
+		}
+		if len(a.Files) != len(wantFiles) {
+			t.Errorf("%s: files = %v, want %v", a.Path, a.Files, wantFiles)
+			continue
+		}
+		for _, f := range wantFiles {
+			if !set[f] {
+				t.Errorf("%s: files = %v, want %v", a.Path, a.Files, wantFiles)
+				break
+			}
+		}
+	}
+
+	if !got[0].Changed || len(got[0].AffectedVia) != 0 {
+		t.Errorf("Core: changed = %v, affected via %v; want changed itself", got[0].Changed, got[0].AffectedVia)
+	}
+
+	listAffectedType = "tests"
+	got = collectAffected(projects, forward, reverse, changed, files)
+	if len(got) != 1 || got[0].Path != appTests.Path || !got[0].IsTest {
+		t.Fatalf("expected only App.Tests with --type=tests, got %+v", got)
+	}
+	if got[0].Changed || !reflect.DeepEqual(got[0].AffectedVia, []string{core.Path, app.Path}) {
+		t.Errorf("App.Tests: changed = %v, affected via %v; want via Core and App", got[0].Changed, got[0].AffectedVia)
+	}
+}
+
+func TestFormatAffectedSummary(t *testing.T) {
+	affected := []affectedProject{
+		{Path: "src/Core/Core.csproj", Name: "Core", Changed: true, AffectedVia: []string{}, Files: []string{"src/Core/Thing.cs"}},
+		{Path: "src/App/App.csproj", Name: "App", AffectedVia: []string{"src/Core/Core.csproj"}},
+		{Path: "tests/App.Tests/App.Tests.csproj", Name: "App.Tests", AffectedVia: []string{"src/Core/Core.csproj", "src/App/App.csproj"}},
+		{Path: "src/Other/Other.csproj", Name: "Other", Changed: true, AffectedVia: []string{}},
+	}
+
+	want := `2 projects changed directly, 2 affected transitively
+
+Core (src/Core/Core.csproj)
+  changed: src/Core/Thing.cs
+  -> App (via Core)
+  -> App.Tests (via Core -> App)
+
+Other (src/Other/Other.csproj)
+`
+	if got := formatAffectedSummary(affected); got != want {
+		t.Errorf("summary =\n%s\nwant\n%s", got, want)
+	}
+
+	// With --type=tests the changed project itself may be filtered out
+	got := formatAffectedSummary(affected[2:3])
+	want = `0 projects changed directly, 1 affected transitively
+
+Core (src/Core/Core.csproj)
+  -> App.Tests (via Core -> App)
+`
+	if got != want {
+		t.Errorf("filtered summary =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -25,12 +25,13 @@ previously collected coverage data. Run 'donotnet coverage build' first
 to generate coverage data.
 
 With --groupings, shows how tests would be grouped for each granularity
-level (method/class/file) and the reduction in test runs.
+level (method/class/namespace/file) and the reduction in test runs.
 
 Granularity levels:
-  method - Most precise, groups by individual test methods
-  class  - Groups by test class (default, good balance)
-  file   - Fastest, groups by source file`,
+  method    - Most precise, groups by individual test methods
+  class     - Groups by test class (default, good balance)
+  namespace - Groups by test namespace, fewer runs than class
+  file      - Fastest, groups by source file`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		scan, err := scanProjects()
 		if err != nil {
@@ -38,21 +39,27 @@ Granularity levels:
 		}
 
 		if listCoverageGroupings {
-			coverage.ListGroupings(context.Background(), scan.GitRoot, scan.Projects)
+			coverage.ListGroupings(context.Background(), scan.GitRoot, scan.Projects, coverage.ParseDiscovery(GetConfig().Test.Discovery))
 			return nil
 		}
 
-		// Default: show existing coverage map
+		// Default: show existing coverage map, including coverage collected
+		// by "donotnet test --coverage"
+		reportsDir := ""
+		if cachePath, err := getCachePath(); err == nil {
+			reportsDir = filepath.Join(filepath.Dir(cachePath), "reports")
+		}
 		var testProjects []coverage.TestProject
 		for _, p := range scan.Projects {
 			if !p.IsTest {
 				continue
 			}
 			projectDir := filepath.Dir(p.Path)
-			testProjects = append(testProjects, coverage.TestProject{
-				Path: p.Path,
-				Dir:  projectDir,
-			})
+			tp := coverage.TestProject{Path: p.Path, Dir: projectDir}
+			if reportsDir != "" {
+				tp.ResultsDir = coverage.RunResultsDir(reportsDir, p.Path)
+			}
+			testProjects = append(testProjects, tp)
 		}
 
 		if len(testProjects) == 0 {
@@ -103,7 +110,7 @@ Granularity levels:
 }
 
 func init() {
-	listCoverageCmd.Flags().StringVar(&listCoverageGranularity, "granularity", "class", "Coverage granularity: method, class, file")
+	listCoverageCmd.Flags().StringVar(&listCoverageGranularity, "granularity", "class", "Coverage granularity: method, class, namespace, file")
 	listCoverageCmd.Flags().BoolVar(&listCoverageGroupings, "groupings", false, "Show test groupings for each granularity level")
 	listCmd.AddCommand(listCoverageCmd)
 }
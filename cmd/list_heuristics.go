@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"path/filepath"
+
+	"github.com/runar-rkmedia/donotnet/config"
+	"github.com/runar-rkmedia/donotnet/git"
 	"github.com/runar-rkmedia/donotnet/term"
 	"github.com/runar-rkmedia/donotnet/testfilter"
 	"github.com/spf13/cobra"
@@ -12,7 +16,21 @@ var listHeuristicsCmd = &cobra.Command{
 	Long: `List all available test filter heuristics.
 
 Heuristics are used to guess which tests to run based on changed source files.
-All heuristics are opt-in and must be explicitly enabled via --heuristics flag.`,
+All heuristics are opt-in and must be explicitly enabled via --heuristics flag.
+
+Custom heuristics can be defined in .donotnet/heuristics.json at the git root:
+
+  [
+    {
+      "name": "IntegrationTests",
+      "description": "Baz.cs -> BazIntegrationTests",
+      "sourcePattern": "^(\\w+)$",
+      "testPattern": "${1}IntegrationTests"
+    }
+  ]
+
+sourcePattern is a regex matched against the changed file name (without
+extension); captures are substituted into testPattern.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		c := term.Color(term.ColorCyan)
 		r := term.Color(term.ColorReset)
@@ -35,6 +53,19 @@ All heuristics are opt-in and must be explicitly enabled via --heuristics flag.`
 			term.Printf("  %s%-20s%s %s%s%s\n", y, h.Name, r, d, h.Description, r)
 		}
 
+		if gitRoot, err := git.FindRoot(); err == nil {
+			path := filepath.Join(gitRoot, config.ConfigDirName, testfilter.HeuristicsFileName)
+			if err := testfilter.LoadCustomHeuristics(path); err != nil {
+				return err
+			}
+			if len(testfilter.CustomHeuristics) > 0 {
+				term.Printf("\n%sCustom heuristics%s (from %s, enabled with --heuristics=default):\n\n", c, r, path)
+				for _, h := range testfilter.CustomHeuristics {
+					term.Printf("  %s%-20s%s %s%s%s\n", g, h.Name, r, d, h.Description, r)
+				}
+			}
+		}
+
 		term.Printf("\n%sUsage:%s\n", d, r)
 		term.Printf("  --heuristics=%sdefault%s                      Default heuristics only%s\n", g, r, func() string {
 			if len(testfilter.AvailableHeuristics) == 0 {
@@ -1,11 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/runar-rkmedia/donotnet/cache"
 	"github.com/runar-rkmedia/donotnet/coverage"
@@ -18,8 +18,9 @@ import (
 )
 
 var (
-	listTestsJSON     bool
-	listTestsAffected bool
+	listTestsJSON      bool
+	listTestsAffected  bool
+	listTestsDiscovery string
 )
 
 // testDetail represents a single test with optional trait info.
@@ -41,6 +42,9 @@ var listTestsCmd = &cobra.Command{
 	Long: `List all tests discovered in test projects.
 
 Runs 'dotnet test --list-tests' on each test project and collects test names.
+With --discovery=source, names are instead parsed from test attributes in the
+project's .cs files, falling back to dotnet for projects where that is
+ambiguous (e.g. [Theory] with data attributes).
 Results are cached based on source file content hashes.
 By default outputs JSON. Use --json=false for plain text output.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -60,19 +64,29 @@ By default outputs JSON. Use --json=false for plain text output.`,
 		}
 		defer db.Close()
 
-		argsHash := runner.HashArgs([]string{"list-tests"})
+		// Shared with test runs and coverage builds, which list tests too
+		testCache := runner.NewTestListCache(db, scan.GitRoot, scan.ForwardGraph)
+		discovery := GetConfig().Test.Discovery
+		if listTestsDiscovery != "" {
+			discovery = listTestsDiscovery
+		}
 
 		// When --affected is set, scope to affected projects only
 		var affectedSet map[string]bool
 		if listTestsAffected {
+			vcs, err := vcsBackend(scan.GitRoot)
+			if err != nil {
+				return err
+			}
 			changed := FindChangedProjects(FindChangedOpts{
 				Projects:     scan.Projects,
 				ForwardGraph: scan.ForwardGraph,
 				GitRoot:      scan.GitRoot,
 				DB:           db,
 				ArgsHash:     runner.HashArgs([]string{"test"}),
-				VcsFiles:     git.GetDirtyFiles(scan.GitRoot),
+				VcsFiles:     git.ChangedPaths(vcs.DirtyFileChanges(scan.GitRoot)),
 				Force:        flagForce,
+				HashMode:     GetConfig().HashMode,
 			})
 			affectedSet = project.FindAffectedProjects(changed, scan.Graph, scan.Projects)
 			term.Verbose("Affected projects: %d", len(affectedSet))
@@ -88,37 +102,10 @@ By default outputs JSON. Use --json=false for plain text output.`,
 				continue
 			}
 
-			// Check cache
-			key := runner.ProjectCacheKey(p, scan.GitRoot, scan.ForwardGraph, argsHash)
-
-			var testNames []string
-			cached := false
-
-			if !flagForce {
-				if result := db.Lookup(key); result != nil && len(result.Output) > 0 {
-					names := strings.Split(strings.TrimSpace(string(result.Output)), "\n")
-					if len(names) > 0 && names[0] != "" {
-						term.Verbose("  cache hit: %s (%d tests)", p.Name, len(names))
-						testNames = names
-						cached = true
-					}
-				}
-			}
-
-			if testNames == nil {
-				// Cache miss - run dotnet test --list-tests
-				term.Verbose("  cache miss: %s", p.Name)
-				projectPath := filepath.Join(scan.GitRoot, p.Path)
-				names, listErr := coverage.ListTests(cmd.Context(), scan.GitRoot, projectPath)
-				if listErr != nil {
-					term.Warnf("Failed to list tests for %s: %v", p.Name, listErr)
-					continue
-				}
-
-				// Store in cache
-				output := strings.Join(names, "\n")
-				db.Mark(key, time.Now(), true, []byte(output), "list-tests")
-				testNames = names
+			testNames, cached, listErr := listProjectTests(cmd.Context(), testCache, scan.GitRoot, p, discovery)
+			if listErr != nil {
+				term.Warnf("Failed to list tests for %s: %v", p.Name, listErr)
+				continue
 			}
 
 			// Build trait map for this project
@@ -171,8 +158,29 @@ By default outputs JSON. Use --json=false for plain text output.`,
 	},
 }
 
+// listProjectTests returns the tests of p from the test list cache, or
+// discovers and caches them on a miss (or with --force).
+func listProjectTests(ctx context.Context, testCache coverage.TestListCache, gitRoot string, p *project.Project, discovery string) (names []string, cached bool, err error) {
+	if !flagForce {
+		if names := testCache.LookupTestList(p); len(names) > 0 {
+			term.Verbose("  cache hit: %s (%d tests)", p.Name, len(names))
+			return names, true, nil
+		}
+	}
+
+	term.Verbose("  cache miss: %s", p.Name)
+	projectPath := filepath.Join(gitRoot, p.Path)
+	names, err = coverage.DiscoverTests(ctx, gitRoot, projectPath, coverage.ParseDiscovery(discovery))
+	if err != nil {
+		return nil, false, err
+	}
+	testCache.StoreTestList(p, names)
+	return names, false, nil
+}
+
 func init() {
 	listTestsCmd.Flags().BoolVar(&listTestsJSON, "json", true, "Output as JSON")
 	listTestsCmd.Flags().BoolVar(&listTestsAffected, "affected", false, "Only list tests from affected projects (VCS-changed + cache miss)")
+	listTestsCmd.Flags().StringVar(&listTestsDiscovery, "discovery", "", "How to list tests: dotnet, or source to parse test attributes (falls back to dotnet when ambiguous)")
 	listCmd.AddCommand(listTestsCmd)
 }
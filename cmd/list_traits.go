@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/runner"
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testfilter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listTraitsJSON bool
+)
+
+// traitSummary is the trait inventory of one test project.
+type traitSummary struct {
+	Project string `json:"project"`
+	// Counts maps trait -> number of tests with it
+	Counts map[string]int `json:"counts"`
+	// Tests maps trait -> names of the tests with it
+	Tests map[string][]string `json:"tests"`
+	// Untraited is the number of tests without any trait
+	Untraited int `json:"untraited"`
+}
+
+// summarizeTraits groups tests by the traits traitMap assigns them.
+func summarizeTraits(projectName string, tests []string, traitMap testfilter.TraitMap) traitSummary {
+	s := traitSummary{
+		Project: projectName,
+		Counts:  make(map[string]int),
+		Tests:   make(map[string][]string),
+	}
+	for _, t := range tests {
+		traits := traitMap.GetTraitsForTest(t)
+		if len(traits) == 0 {
+			s.Untraited++
+			continue
+		}
+		for _, trait := range traits {
+			s.Counts[trait]++
+			s.Tests[trait] = append(s.Tests[trait], t)
+		}
+	}
+	for _, names := range s.Tests {
+		sort.Strings(names)
+	}
+	return s
+}
+
+var listTraitsCmd = &cobra.Command{
+	Use:   "traits",
+	Short: "Summarize test traits (categories) per test project",
+	Long: `Summarize the traits (xUnit [Trait("Category", ...)], NUnit [Category],
+MSTest [TestCategory]) of the tests in each test project: how many tests have
+each trait and which ones, e.g. to find projects that still have Live tests.
+
+Test names come from the same cache as 'list tests'; projects without a cached
+list are discovered first. By default outputs JSON. Use --json=false for a
+plain text summary.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scan, err := scanProjects()
+		if err != nil {
+			return err
+		}
+
+		cachePath, err := getCachePath()
+		if err != nil {
+			return err
+		}
+		db, err := cache.Open(cachePath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		testCache := runner.NewTestListCache(db, scan.GitRoot, scan.ForwardGraph)
+		discovery := GetConfig().Test.Discovery
+
+		var summaries []traitSummary
+		for _, p := range scan.Projects {
+			if !p.IsTest {
+				continue
+			}
+			tests, _, listErr := listProjectTests(cmd.Context(), testCache, scan.GitRoot, p, discovery)
+			if listErr != nil {
+				term.Warnf("Failed to list tests for %s: %v", p.Name, listErr)
+				continue
+			}
+			traitMap := testfilter.BuildTraitMap(filepath.Dir(filepath.Join(scan.GitRoot, p.Path)))
+			summaries = append(summaries, summarizeTraits(p.Name, tests, traitMap))
+		}
+
+		if len(summaries) == 0 {
+			term.Dim("No test projects found")
+			return nil
+		}
+
+		if listTraitsJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(summaries)
+		}
+
+		for _, s := range summaries {
+			traits := make([]string, 0, len(s.Counts))
+			for trait := range s.Counts {
+				traits = append(traits, trait)
+			}
+			sort.Strings(traits)
+
+			parts := make([]string, 0, len(traits))
+			for _, trait := range traits {
+				parts = append(parts, fmt.Sprintf("%s=%d", trait, s.Counts[trait]))
+			}
+			if len(parts) == 0 {
+				parts = append(parts, "no traits")
+			}
+			term.Printf("%s: %s (%d untraited)\n", s.Project, strings.Join(parts, ", "), s.Untraited)
+		}
+		return nil
+	},
+}
+
+func init() {
+	listTraitsCmd.Flags().BoolVar(&listTraitsJSON, "json", true, "Output as JSON")
+	listCmd.AddCommand(listTraitsCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/testfilter"
+)
+
+func TestSummarizeTraits(t *testing.T) {
+	traitMap := testfilter.TraitMap{
+		ClassTraits: map[string][]string{
+			"App.Tests.ApiTests": {"Live"},
+		},
+		MethodTraits: map[string][]string{
+			"App.Tests.ApiTests.Slow":    {"Slow"},
+			"App.Tests.UnitTests.Parses": {"Fast"},
+		},
+	}
+	tests := []string{
+		"App.Tests.ApiTests.Slow",
+		"App.Tests.ApiTests.Get",
+		"App.Tests.UnitTests.Parses(input: \"x\")",
+		"App.Tests.UnitTests.Other",
+	}
+
+	got := summarizeTraits("App.Tests", tests, traitMap)
+	want := traitSummary{
+		Project: "App.Tests",
+		Counts:  map[string]int{"Live": 2, "Slow": 1, "Fast": 1},
+		Tests: map[string][]string{
+			"Live": {"App.Tests.ApiTests.Get", "App.Tests.ApiTests.Slow"},
+			"Slow": {"App.Tests.ApiTests.Slow"},
+			"Fast": {"App.Tests.UnitTests.Parses(input: \"x\")"},
+		},
+		Untraited: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("summarizeTraits = %+v, want %+v", got, want)
+	}
+}
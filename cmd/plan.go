@@ -12,6 +12,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var planCritical bool
+
 var planCmd = &cobra.Command{
 	Use:   "plan",
 	Short: "Show job scheduling plan",
@@ -19,7 +21,12 @@ var planCmd = &cobra.Command{
 
 Displays how projects would be scheduled in parallel waves based on
 their dependency relationships. Useful for understanding build order
-and identifying potential bottlenecks.`,
+and identifying potential bottlenecks.
+
+With --critical, also shows the critical path: the chain of dependent
+projects with the longest combined duration (from recorded run times).
+No amount of parallelism makes a run faster than this chain, so it shows
+which projects are worth splitting up.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		scan, err := scanProjects()
 		if err != nil {
@@ -46,12 +53,14 @@ and identifying potential bottlenecks.`,
 		}
 		defer db.Close()
 
+		argsHash := runner.HashArgs([]string{"test"})
 		changed := FindChangedProjects(FindChangedOpts{
 			Projects:     scan.Projects,
 			ForwardGraph: scan.ForwardGraph,
 			GitRoot:      scan.GitRoot,
 			DB:           db,
-			ArgsHash:     runner.HashArgs([]string{"test"}),
+			ArgsHash:     argsHash,
+			HashMode:     GetConfig().HashMode,
 		})
 
 		affected := project.FindAffectedProjects(changed, scan.Graph, scan.Projects)
@@ -74,17 +83,24 @@ and identifying potential bottlenecks.`,
 		}
 
 		plan := devplan.ComputePlan(planProjects, scan.ForwardGraph)
+		if planCritical {
+			plan.Critical = devplan.ComputeCriticalPath(planProjects, scan.ForwardGraph, db.GetDurations(argsHash))
+		}
 
 		colors := devplan.DefaultColors()
 		if term.IsPlain() {
 			colors = devplan.PlainColors()
 		}
 		plan.Print(os.Stdout, colors)
+		if plan.Critical != nil {
+			plan.Critical.Print(os.Stdout, colors)
+		}
 
 		return nil
 	},
 }
 
 func init() {
+	planCmd.Flags().BoolVar(&planCritical, "critical", false, "Highlight the critical path (longest chain by recorded durations) and estimate the minimal run time")
 	rootCmd.AddCommand(planCmd)
 }
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime/pprof"
+)
+
+// stopProfile stops the CPU profile started by --profile (nil when not profiling).
+var stopProfile func() error
+
+// startProfile starts writing a CPU profile of the whole run to path, for
+// `go tool pprof`. The profile is complete once stopProfile has been called.
+func startProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("starting CPU profile: %w", err)
+	}
+	stopProfile = func() error {
+		pprof.StopCPUProfile()
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing profile: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
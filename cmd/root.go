@@ -4,6 +4,8 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/runar-rkmedia/donotnet/config"
 	"github.com/runar-rkmedia/donotnet/git"
@@ -18,14 +20,27 @@ var (
 	flagColor         string
 	flagDir           string
 	flagCacheDir      string
+	flagCacheMaxSize  string
+	flagCacheLockWait time.Duration
+	flagCacheScope    string
 	flagParallel      int
 	flagLocal         bool
 	flagKeepGoing     bool
+	flagMaxFailures   int
 	flagNoProgress    bool
 	flagNoSuggestions bool
 	flagShowCached    bool
 	flagConfigFile    string
 	flagForce         bool
+	flagVCS           string
+	flagSubmodules    bool
+	flagProfile       string
+	flagHashMode      string
+	flagCacheStats    bool
+	flagCacheStatsLog bool
+
+	flagTestProjectPatterns []string
+	flagCacheEnv            string
 
 	// Loaded configuration
 	cfg *config.Config
@@ -41,6 +56,13 @@ Do not run what you don't need to. Tracks file changes to determine which
 projects need rebuilding/retesting. Uses git-aware caching for speed and
 accuracy across branches and stashes.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Start profiling first, so the path is relative to where we were invoked
+		if flagProfile != "" {
+			if err := startProfile(flagProfile); err != nil {
+				return err
+			}
+		}
+
 		// Handle -C flag (change directory)
 		if flagDir != "" {
 			if err := os.Chdir(flagDir); err != nil {
@@ -85,6 +107,10 @@ accuracy across branches and stashes.`,
 			term.SetColorMode(term.ColorModeAuto)
 		}
 
+		for _, w := range result.Warnings {
+			term.Warnf("config: %s", w)
+		}
+
 		return nil
 	},
 	// Silence usage on errors (we handle our own error messages)
@@ -94,7 +120,13 @@ accuracy across branches and stashes.`,
 
 // Execute runs the root command.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if stopProfile != nil {
+		if profileErr := stopProfile(); err == nil {
+			err = profileErr
+		}
+	}
+	return err
 }
 
 func init() {
@@ -104,14 +136,27 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&flagColor, "color", "", "Color output mode: auto, always, never")
 	rootCmd.PersistentFlags().StringVarP(&flagDir, "dir", "C", "", "Change to directory before running")
 	rootCmd.PersistentFlags().StringVar(&flagCacheDir, "cache-dir", "", "Cache directory path")
+	rootCmd.PersistentFlags().StringVar(&flagCacheMaxSize, "cache-max-size", "", "Evict least recently used cache entries after each run until the cache fits this `size` (e.g. 500MB)")
+	rootCmd.PersistentFlags().DurationVar(&flagCacheLockWait, "cache-lock-timeout", 0, "How long to wait for another donotnet process to release the cache before continuing read-only, or uncached while it writes (default 10s, config: cache_lock_timeout_ms)")
+	rootCmd.PersistentFlags().StringVar(&flagCacheScope, "cache-scope", "", "Use a separate cache database for this scope `key` (e.g. a solution path), so concurrent runs don't share a lock")
 	rootCmd.PersistentFlags().IntVarP(&flagParallel, "parallel", "j", 0, "Number of parallel workers (0 = auto)")
 	rootCmd.PersistentFlags().BoolVar(&flagLocal, "local", false, "Only scan current directory, not entire git repo")
 	rootCmd.PersistentFlags().BoolVarP(&flagKeepGoing, "keep-going", "k", false, "Keep going on errors")
+	rootCmd.PersistentFlags().IntVar(&flagMaxFailures, "max-failures", 0, "With --keep-going, stop once `N` projects have failed (0 = unlimited)")
 	rootCmd.PersistentFlags().BoolVar(&flagNoProgress, "no-progress", false, "Disable progress output")
 	rootCmd.PersistentFlags().BoolVar(&flagNoSuggestions, "no-suggestions", false, "Disable performance suggestions")
 	rootCmd.PersistentFlags().BoolVar(&flagShowCached, "show-cached", false, "Show cached projects in output")
 	rootCmd.PersistentFlags().StringVar(&flagConfigFile, "config", "", "Config file path (overrides auto-discovery)")
 	rootCmd.PersistentFlags().BoolVar(&flagForce, "force", false, "Ignore cache, run all projects")
+	rootCmd.PersistentFlags().BoolVar(&flagSubmodules, "include-submodules", false, "Also discover projects inside git submodules")
+	rootCmd.PersistentFlags().StringVar(&flagVCS, "vcs", "", "VCS used to find changed files: auto, git, jj (default auto: jj when .jj exists)")
+	rootCmd.PersistentFlags().StringVar(&flagHashMode, "hash-mode", "", "How files are fingerprinted for cache keys: content, or mtime (path, size and modification time; faster) (default content)")
+	rootCmd.PersistentFlags().BoolVar(&flagCacheStats, "cache-stats", false, "Print the cache hit rate of the run in the summary")
+	rootCmd.PersistentFlags().BoolVar(&flagCacheStatsLog, "cache-stats-log", false, "Append the cache hit rate of the run to hitrate.log in the cache directory")
+	rootCmd.PersistentFlags().StringVar(&flagProfile, "profile", "", "Write a CPU profile of the run to `path` (for go tool pprof)")
+	rootCmd.PersistentFlags().MarkHidden("profile")
+	rootCmd.PersistentFlags().StringArrayVar(&flagTestProjectPatterns, "test-project-pattern", nil, "Regex on project name marking it as a test project; prefix with ! to opt out (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&flagCacheEnv, "cache-env", "", "Comma-separated environment `variables` whose values are part of the cache key (unset = empty)")
 }
 
 // applyFlagOverrides applies command-line flag values to the config.
@@ -129,6 +174,15 @@ func applyFlagOverrides() {
 	if flagCacheDir != "" {
 		cfg.CacheDir = flagCacheDir
 	}
+	if flagCacheMaxSize != "" {
+		cfg.CacheMaxSize = flagCacheMaxSize
+	}
+	if flagCacheLockWait > 0 {
+		cfg.CacheLockTimeoutMs = int(flagCacheLockWait.Milliseconds())
+	}
+	if flagCacheScope != "" {
+		cfg.CacheScope = flagCacheScope
+	}
 	if flagParallel != 0 {
 		cfg.Parallel = flagParallel
 	}
@@ -138,6 +192,9 @@ func applyFlagOverrides() {
 	if flagKeepGoing {
 		cfg.KeepGoing = true
 	}
+	if flagMaxFailures > 0 {
+		cfg.MaxFailures = flagMaxFailures
+	}
 	if flagNoProgress {
 		cfg.NoProgress = true
 	}
@@ -147,6 +204,27 @@ func applyFlagOverrides() {
 	if flagShowCached {
 		cfg.ShowCached = true
 	}
+	if len(flagTestProjectPatterns) > 0 {
+		cfg.TestProjectPatterns = append(cfg.TestProjectPatterns, flagTestProjectPatterns...)
+	}
+	if flagCacheEnv != "" {
+		cfg.CacheEnv = strings.Split(flagCacheEnv, ",")
+	}
+	if flagVCS != "" {
+		cfg.VCS.Backend = flagVCS
+	}
+	if flagSubmodules {
+		cfg.IncludeSubmodules = true
+	}
+	if flagHashMode != "" {
+		cfg.HashMode = flagHashMode
+	}
+	if flagCacheStats {
+		cfg.CacheStats = true
+	}
+	if flagCacheStatsLog {
+		cfg.CacheStatsLog = true
+	}
 }
 
 // GetConfig returns the loaded configuration.
@@ -155,6 +233,15 @@ func GetConfig() *config.Config {
 	return cfg
 }
 
+// vcsBackend returns the configured VCS backend for the repository at gitRoot.
+func vcsBackend(gitRoot string) (git.Backend, error) {
+	name := ""
+	if cfg != nil {
+		name = cfg.VCS.Backend
+	}
+	return git.NewBackend(gitRoot, name)
+}
+
 // IsForce returns whether the force flag was set.
 func IsForce() bool {
 	return flagForce
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"time"
 
 	"github.com/runar-rkmedia/donotnet/config"
 	"github.com/runar-rkmedia/donotnet/runner"
@@ -21,24 +22,81 @@ type RunOptions struct {
 
 	// Test-specific options
 	Coverage            bool
+	CoverageReport      string
+	CoverageOpen        bool
+	CoverageSummary     bool
+	CoverageSummaryJSON string
+	CoverageMin         float64
+	CoverageDiff        string
+	CoverageDiffJSON    string
 	CoverageBuild       bool
+	CoverageIncremental bool
+	CoverageIsolate     bool
+	Discovery           string
 	Heuristics          string
 	Failed              bool
 	StalenessCheck      string
 	CoverageGranularity string
 	NoReports           bool
+	TestHangTimeout     time.Duration
+	SkipUntested        bool
+	ExcludeTraits       []string
+	FailOnNoTests       bool
+	CoverageAutoRebuild bool
 
 	// Build-specific options
-	FullBuild     bool
-	NoSolution    bool
-	ForceSolution bool
+	FullBuild      bool
+	NoSolution     bool
+	ForceSolution  bool
+	SolutionFilter bool
 
 	// Shared options
-	VcsChanged  bool
-	VcsRef      string
-	Watch       bool
-	PrintOutput bool
-	Force       bool
+	VcsChanged    bool
+	VcsRef        string
+	ChangedFiles  []string
+	VcsRefMode    string
+	Scope         string
+	SDK           string
+	Watch         bool
+	WatchDebounce time.Duration
+	WatchClear    bool
+	OnIdle        string
+	OnIdleAfter   time.Duration
+	PrintOutput   bool
+	// QuietOnSuccess prints only a summary line unless something failed
+	QuietOnSuccess bool
+	Force          bool
+	DryRun         bool
+	Since          string
+	DiffInputs     string
+	CacheKeyDebug  bool
+	Why            string
+	// AffectedGraph is the format to write the affected graph in, to
+	// AffectedGraphOutput (empty = stdout)
+	AffectedGraph       string
+	AffectedGraphOutput string
+
+	// Projects are project names or paths to run regardless of change state
+	Projects []string
+	WithDeps bool
+
+	// OnlyProjects is an allowlist of project names or paths to consider
+	OnlyProjects []string
+
+	// Only are globs; affected projects outside them and their dependents are skipped
+	Only []string
+
+	// ExcludeProjects are glob patterns for projects to skip
+	ExcludeProjects []string
+
+	// ReportMarkdown is a file path to write a Markdown run summary to
+	ReportMarkdown string
+	// Format prints per-test results on stdout after the run (tap)
+	Format string
+
+	// Notify is a webhook URL to POST a run summary to
+	Notify   string
+	NotifyOn string
 
 	// Config from file/env
 	Config *config.Config
@@ -59,9 +117,37 @@ func Run(opts *RunOptions) error {
 	if opts.Coverage {
 		runnerOpts.Coverage = true
 	}
+	// Coverage reports and summaries need coverage collected
+	if opts.CoverageReport != "" || opts.CoverageOpen {
+		runnerOpts.Coverage = true
+		runnerOpts.CoverageReport = opts.CoverageReport
+		runnerOpts.CoverageOpen = opts.CoverageOpen
+	}
+	if opts.CoverageSummary || opts.CoverageSummaryJSON != "" {
+		runnerOpts.Coverage = true
+		runnerOpts.CoverageSummary = opts.CoverageSummary
+		runnerOpts.CoverageSummaryJSON = opts.CoverageSummaryJSON
+	}
+	if opts.CoverageDiff != "" {
+		runnerOpts.Coverage = true
+		runnerOpts.CoverageDiff = opts.CoverageDiff
+		runnerOpts.CoverageDiffJSON = opts.CoverageDiffJSON
+	}
+	if opts.CoverageMin > 0 {
+		runnerOpts.CoverageMin = opts.CoverageMin
+	}
 	if opts.CoverageBuild {
 		runnerOpts.CoverageBuild = true
 	}
+	if opts.CoverageIncremental {
+		runnerOpts.CoverageIncremental = true
+	}
+	if opts.CoverageIsolate {
+		runnerOpts.CoverageIsolate = true
+	}
+	if opts.Discovery != "" {
+		runnerOpts.Discovery = opts.Discovery
+	}
 	if opts.Heuristics != "" {
 		runnerOpts.Heuristics = opts.Heuristics
 	}
@@ -77,6 +163,21 @@ func Run(opts *RunOptions) error {
 	if opts.NoReports {
 		runnerOpts.NoReports = true
 	}
+	if opts.TestHangTimeout > 0 {
+		runnerOpts.TestHangTimeout = opts.TestHangTimeout
+	}
+	if opts.SkipUntested {
+		runnerOpts.SkipUntested = true
+	}
+	if len(opts.ExcludeTraits) > 0 {
+		runnerOpts.ExcludeTraits = append(runnerOpts.ExcludeTraits, opts.ExcludeTraits...)
+	}
+	if opts.FailOnNoTests {
+		runnerOpts.FailOnNoTests = true
+	}
+	if opts.CoverageAutoRebuild {
+		runnerOpts.CoverageAutoRebuild = true
+	}
 
 	// Build options
 	if opts.FullBuild {
@@ -88,6 +189,9 @@ func Run(opts *RunOptions) error {
 	if opts.ForceSolution {
 		runnerOpts.ForceSolution = true
 	}
+	if opts.SolutionFilter {
+		runnerOpts.SolutionFilter = true
+	}
 
 	// Shared options
 	if opts.VcsChanged {
@@ -96,12 +200,85 @@ func Run(opts *RunOptions) error {
 	if opts.VcsRef != "" {
 		runnerOpts.VcsRef = opts.VcsRef
 	}
+	if opts.ChangedFiles != nil {
+		runnerOpts.ChangedFiles = opts.ChangedFiles
+	}
+	if opts.VcsRefMode != "" {
+		runnerOpts.VcsRefMode = opts.VcsRefMode
+	}
+	if opts.Scope != "" {
+		runnerOpts.Scope = opts.Scope
+	}
+	if opts.SDK != "" {
+		runnerOpts.SDK = opts.SDK
+	}
 	if opts.Watch {
 		runnerOpts.Watch = true
 	}
+	if opts.WatchDebounce > 0 {
+		runnerOpts.WatchDebounce = opts.WatchDebounce
+	}
+	if opts.WatchClear {
+		runnerOpts.WatchClear = true
+	}
+	if opts.OnIdle != "" {
+		runnerOpts.OnIdle = opts.OnIdle
+	}
+	if opts.OnIdleAfter > 0 {
+		runnerOpts.OnIdleAfter = opts.OnIdleAfter
+	}
 	if opts.PrintOutput {
 		runnerOpts.PrintOutput = true
 	}
+	if opts.QuietOnSuccess {
+		runnerOpts.QuietOnSuccess = true
+	}
+	if opts.DryRun {
+		runnerOpts.DryRun = true
+	}
+	if opts.Since != "" {
+		runnerOpts.Since = opts.Since
+	}
+	if opts.DiffInputs != "" {
+		runnerOpts.DiffInputs = opts.DiffInputs
+	}
+	if opts.CacheKeyDebug {
+		runnerOpts.CacheKeyDebug = true
+	}
+	if opts.Why != "" {
+		runnerOpts.Why = opts.Why
+	}
+	if opts.AffectedGraph != "" {
+		runnerOpts.AffectedGraph = opts.AffectedGraph
+		runnerOpts.AffectedGraphOutput = opts.AffectedGraphOutput
+	}
+	if len(opts.Projects) > 0 {
+		runnerOpts.Projects = opts.Projects
+	}
+	if opts.WithDeps {
+		runnerOpts.WithDeps = true
+	}
+	if len(opts.OnlyProjects) > 0 {
+		runnerOpts.OnlyProjects = opts.OnlyProjects
+	}
+	if len(opts.Only) > 0 {
+		runnerOpts.Only = opts.Only
+	}
+	if len(opts.ExcludeProjects) > 0 {
+		runnerOpts.ExcludeProjects = opts.ExcludeProjects
+	}
+	if opts.ReportMarkdown != "" {
+		runnerOpts.ReportMarkdown = opts.ReportMarkdown
+	}
+	if opts.Format != "" {
+		runnerOpts.Format = opts.Format
+	}
+	if opts.Notify != "" {
+		runnerOpts.Notify = opts.Notify
+	}
+	if opts.NotifyOn != "" {
+		runnerOpts.NotifyOn = opts.NotifyOn
+	}
 
 	// Create and run
 	r := runner.New(runnerOpts)
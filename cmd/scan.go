@@ -36,10 +36,17 @@ func scanProjects() (*scanResult, error) {
 		scanRoot = cwd
 	}
 
-	projects, solutions, err := project.Discover(scanRoot, gitRoot)
+	projects, solutions, err := project.Discover(scanRoot, gitRoot, cfg != nil && cfg.IncludeSubmodules)
 	if err != nil {
 		return nil, fmt.Errorf("discovering projects: %w", err)
 	}
+	if cfg != nil {
+		testPatterns, err := project.ParseTestProjectPatterns(cfg.TestProjectPatterns)
+		if err != nil {
+			return nil, err
+		}
+		testPatterns.Apply(projects)
+	}
 
 	return &scanResult{
 		GitRoot:      gitRoot,
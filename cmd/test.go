@@ -1,24 +1,68 @@
 package cmd
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Test-specific flags
 	testFlagCoverage            bool
+	testFlagCoverageReport      string
+	testFlagCoverageOpen        bool
+	testFlagCoverageSummary     bool
+	testFlagCoverageSummaryJSON string
+	testFlagCoverageMin         float64
+	testFlagCoverageDiff        string
+	testFlagCoverageDiffJSON    string
 	testFlagHeuristics          string
 	testFlagFailed              bool
 	testFlagStalenessCheck      string
 	testFlagCoverageGranularity string
 	testFlagNoReports           bool
+	testFlagTestHangTimeout     time.Duration
+	testFlagSkipUntested        bool
+	testFlagExcludeTraits       []string
+	testFlagFailOnNoTests       bool
+	testFlagDiscovery           string
+	testFlagCoverageAutoRebuild bool
 	testFlagVcsChanged          bool
+	testFlagChangedFiles        string
 	testFlagVcsRef              string
+	testFlagVcsRefMode          string
+	testFlagScope               string
+	testFlagSDK                 string
 	testFlagWatch               bool
+	testFlagWatchDebounce       time.Duration
+	testFlagWatchClear          bool
+	testFlagOnIdle              string
+	testFlagOnIdleAfter         time.Duration
+	testFlagQuietOnSuccess      bool
 	testFlagPrintOutput         bool
 	testFlagFullBuild           bool
+	testFlagSolutionFilter      bool
 	testFlagNoSolution          bool
 	testFlagSolution            bool
+	testFlagDryRun              bool
+	testFlagSince               string
+	testFlagDiffInputs          string
+	testFlagCacheKeyDebug       bool
+	testFlagWhy                 string
+	testFlagAffectedGraph       string
+	testFlagGraphOut            string
+	testFlagProjects            []string
+	testFlagWithDeps            bool
+	testFlagOnlyProjects        string
+	testFlagExcludeProjects     []string
+	testFlagExclude             []string
+	testFlagOnly                []string
+	testFlagReportMarkdown      string
+	testFlagFormat              string
+	testFlagNotify              string
+	testFlagNotifyOn            string
 
 	// Mapped dotnet flags
 	testFlagFilter        string
@@ -40,34 +84,80 @@ Examples:
   donotnet test                           Run tests on affected projects
   donotnet test path/to/Foo.Tests.csproj  Test a specific project
   donotnet test src/FeatureX/             Test all projects under a directory
+  donotnet test --project=Foo.Tests       Test a project by name
   donotnet test --filter "Name~Foo"       Run with a dotnet test filter
   donotnet test -c Release                Test in Release configuration
   donotnet test -- --no-build             Pass extra args to dotnet
   donotnet test --coverage                Collect code coverage
+  donotnet test --coverage-open           Collect coverage and open an HTML report
   donotnet test --failed                  Rerun only failed tests
   donotnet test --watch                   Watch for changes and rerun
   donotnet test --vcs-changed             Test projects with uncommitted changes
-  donotnet test --vcs-ref=main            Test projects changed vs main branch`,
+  donotnet test --vcs-ref=main            Test projects changed vs main branch
+  donotnet test --report-markdown=out.md  Write a Markdown summary for a PR comment`,
 	RunE: runTest,
 }
 
 func init() {
 	// Test-specific flags
 	testCmd.Flags().BoolVar(&testFlagCoverage, "coverage", false, "Collect code coverage during test runs")
-	testCmd.Flags().StringVar(&testFlagHeuristics, "heuristics", "default", "Test filter heuristics: default, none, or comma-separated names")
+	testCmd.Flags().StringVar(&testFlagCoverageReport, "coverage-report", "", "Write an HTML summary of the merged coverage to `dir` (implies --coverage)")
+	testCmd.Flags().BoolVar(&testFlagCoverageOpen, "coverage-open", false, "Open the HTML coverage report in the browser (implies --coverage; default dir .donotnet/coverage-report)")
+	testCmd.Flags().BoolVar(&testFlagCoverageSummary, "coverage-summary", false, "Print the overall and per-project line coverage after the run (implies --coverage)")
+	testCmd.Flags().StringVar(&testFlagCoverageSummaryJSON, "coverage-summary-json", "", "Write the coverage summary as JSON to `path` (implies --coverage)")
+	testCmd.Flags().StringVar(&testFlagCoverageDiff, "coverage-diff", "", "Report the coverage of lines changed since branching off `ref` (implies --coverage)")
+	testCmd.Flags().StringVar(&testFlagCoverageDiffJSON, "coverage-diff-json", "", "With --coverage-diff, write the changed line coverage and uncovered lines as JSON to `path`")
+	testCmd.Flags().Float64Var(&testFlagCoverageMin, "coverage-min", 0, "Fail a --coverage run whose overall line coverage is below this `percent` (config: test.coverage_min)")
+	testCmd.Flags().StringVar(&testFlagHeuristics, "heuristics", "", "Test filter heuristics: default, none, or comma-separated names (config: test.heuristics, default \"default\")")
 	testCmd.Flags().BoolVar(&testFlagFailed, "failed", false, "Only run previously failed tests")
-	testCmd.Flags().StringVar(&testFlagStalenessCheck, "staleness-check", "git", "Coverage staleness check method: git, mtime, both")
-	testCmd.Flags().StringVar(&testFlagCoverageGranularity, "coverage-granularity", "class", "Coverage granularity: method, class, file")
+	testCmd.Flags().StringVar(&testFlagStalenessCheck, "staleness-check", "", "Coverage staleness check method: git, mtime, both (config: test.staleness_check, default \"git\")")
+	testCmd.Flags().StringVar(&testFlagCoverageGranularity, "coverage-granularity", "", "Coverage granularity: method, class, namespace, file, auto (config: test.coverage_granularity, default \"class\")")
 	testCmd.Flags().BoolVar(&testFlagNoReports, "no-reports", false, "Disable saving test reports (TRX files)")
+	testCmd.Flags().DurationVar(&testFlagTestHangTimeout, "test-hang-timeout", 0, "Abort and report any single test running longer than this (uses dotnet --blame-hang)")
+	testCmd.Flags().BoolVar(&testFlagCoverageAutoRebuild, "coverage-auto-rebuild", false, "In watch mode, rebuild stale per-test coverage of changed projects in the background")
+	testCmd.Flags().BoolVar(&testFlagSkipUntested, "skip-untested", false, "Don't build non-test projects that no test project references")
+	testCmd.Flags().BoolVar(&testFlagSkipUntested, "no-untested-build", false, "Alias for --skip-untested")
+	testCmd.Flags().MarkHidden("no-untested-build")
+	testCmd.Flags().StringArrayVar(&testFlagExcludeTraits, "exclude-trait", nil, "Exclude tests with this category `trait` (e.g. Live) in every project's --filter; repeatable (config: test.exclude_traits)")
+	testCmd.Flags().BoolVar(&testFlagFailOnNoTests, "fail-on-no-tests", false, "Fail test projects that run zero tests (unless your own --filter selected none)")
+	testCmd.Flags().StringVar(&testFlagDiscovery, "discovery", "", "How watch mode lists tests: dotnet, or source to parse test attributes (falls back to dotnet when ambiguous)")
 
 	// Shared test/build flags
 	testCmd.Flags().BoolVar(&testFlagVcsChanged, "vcs-changed", false, "Only test projects with uncommitted changes")
 	testCmd.Flags().StringVar(&testFlagVcsRef, "vcs-ref", "", "Only test projects changed vs specified ref")
+	testCmd.Flags().StringVar(&testFlagChangedFiles, "changed-files", "", "Read changed files (repo-relative, one per line) from `file` or - for stdin, instead of asking git")
+	testCmd.Flags().StringVar(&testFlagVcsRefMode, "vcs-ref-mode", "", "How --vcs-ref is compared: merge-base (changes since branching off it, default) or direct (vs its tip)")
+	testCmd.Flags().StringVar(&testFlagScope, "scope", "", "Only consider changed files under `dir` (uncommitted, or vs --vcs-ref); discovery stays repo-wide")
+	testCmd.Flags().StringVar(&testFlagSDK, "sdk", "", "Require the dotnet on PATH to be this SDK `version` (or prefix, e.g. 8.0); without it, a mismatch with global.json warns")
 	testCmd.Flags().BoolVar(&testFlagWatch, "watch", false, "Watch for file changes and rerun")
+	testCmd.Flags().DurationVar(&testFlagWatchDebounce, "watch-debounce", 0, "How long to wait for more file changes before rerunning in watch mode (default 100ms, config: watch.debounce_ms)")
+	testCmd.Flags().BoolVar(&testFlagWatchClear, "watch-clear", false, "In watch mode, clear the screen and show the changed files before each rerun (config: watch.clear)")
+	testCmd.Flags().StringVar(&testFlagOnIdle, "on-idle", "", "In watch mode, run this shell `command` once idle after a successful run (cancelled by new changes)")
+	testCmd.Flags().DurationVar(&testFlagOnIdleAfter, "on-idle-after", 0, "How long watch mode must be idle before running --on-idle (default 30s)")
 	testCmd.Flags().BoolVar(&testFlagPrintOutput, "print-output", false, "Print stdout from all projects after completion")
+	testCmd.Flags().BoolVar(&testFlagQuietOnSuccess, "quiet-on-success", false, "Hold back all output until done: print only a summary line if everything succeeded, everything if something failed")
 	testCmd.Flags().BoolVar(&testFlagFullBuild, "full-build", false, "Disable auto --no-build detection")
 	testCmd.Flags().BoolVar(&testFlagNoSolution, "no-solution", false, "Disable solution-level builds")
 	testCmd.Flags().BoolVar(&testFlagSolution, "solution", false, "Force solution-level builds")
+	testCmd.Flags().BoolVar(&testFlagSolutionFilter, "solution-filter", false, "Use solution-level builds when 2+ projects in a solution need it, limited to those projects through a generated .slnf")
+	testCmd.Flags().BoolVar(&testFlagDryRun, "dry-run", false, "Print the dotnet commands that would run, without running them or updating the cache")
+	testCmd.Flags().StringVar(&testFlagSince, "since", "", "Also run projects not successfully tested since a duration ago (e.g. 24h) or RFC3339 time")
+	testCmd.Flags().StringVar(&testFlagDiffInputs, "diff-inputs", "", "List input files of `project` changed since its last successful run, then exit")
+	testCmd.Flags().BoolVar(&testFlagCacheKeyDebug, "cache-key-debug", false, "Print the content hash, args hash, cache key and hit/miss verdict of each selected project, then exit")
+	testCmd.Flags().StringVar(&testFlagWhy, "why", "", "Explain why `project` (name, path or glob) would run or be skipped, then exit")
+	testCmd.Flags().StringVar(&testFlagAffectedGraph, "affected-graph", "", "Write the dependency graph in `format` (dot, mermaid), colored by changed/affected/cached, then exit")
+	testCmd.Flags().StringVarP(&testFlagGraphOut, "output", "o", "", "With --affected-graph, write to this `file` instead of stdout")
+	testCmd.Flags().StringArrayVar(&testFlagProjects, "project", nil, "Test this project (`name` or .csproj path) regardless of changes (repeatable)")
+	testCmd.Flags().BoolVar(&testFlagWithDeps, "with-deps", false, "With --project, also test the test projects it references")
+	testCmd.Flags().StringVar(&testFlagOnlyProjects, "only-projects", "", "Comma-separated project names or paths; only these are tested (when not cached), ignoring change detection")
+	testCmd.Flags().StringSliceVar(&testFlagExclude, "exclude", nil, "Never test projects matching `pattern` (name or path glob), even when affected; repeatable or comma-separated")
+	testCmd.Flags().StringSliceVar(&testFlagExcludeProjects, "exclude-projects", nil, "Alias for --exclude")
+	testCmd.Flags().MarkDeprecated("exclude-projects", "use --exclude instead")
+	testCmd.Flags().StringArrayVar(&testFlagOnly, "only", nil, "Only test affected projects matching `pattern` (name or path glob) or depending on one; repeatable")
+	testCmd.Flags().StringVar(&testFlagReportMarkdown, "report-markdown", "", "Write a Markdown summary of the run to `file` (e.g. for PR comments)")
+	testCmd.Flags().StringVar(&testFlagFormat, "format", "", "Print every test result on stdout after the run in `format` (tap)")
+	testCmd.Flags().StringVar(&testFlagNotify, "notify", "", "POST a JSON summary of each run to this webhook `url`")
+	testCmd.Flags().StringVar(&testFlagNotifyOn, "notify-on", "always", "When to send --notify: always, failure")
 
 	// Mapped dotnet flags (no -- needed)
 	testCmd.Flags().StringVar(&testFlagFilter, "filter", "", "Dotnet test filter expression (e.g. \"Name~Foo\")")
@@ -90,12 +180,24 @@ func runTest(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if testFlagCoverageDiffJSON != "" && testFlagCoverageDiff == "" {
+		return fmt.Errorf("--coverage-diff-json requires --coverage-diff")
+	}
+
 	// Resolve path targets
 	targets, err := resolveTargets(paths)
 	if err != nil {
 		return err
 	}
 
+	if err := checkChangedFilesConflict(testFlagChangedFiles, testFlagVcsChanged, testFlagVcsRef); err != nil {
+		return err
+	}
+	changedFiles, err := readChangedFiles(testFlagChangedFiles)
+	if err != nil {
+		return err
+	}
+
 	// Inject mapped flags into dotnet args
 	dotnetArgs = injectMappedFlags(dotnetArgs, testFlagFilter, testFlagConfiguration)
 
@@ -105,18 +207,57 @@ func runTest(cmd *cobra.Command, args []string) error {
 		DotnetArgs:          dotnetArgs,
 		Targets:             targets,
 		Coverage:            testFlagCoverage,
+		CoverageReport:      testFlagCoverageReport,
+		CoverageOpen:        testFlagCoverageOpen,
+		CoverageSummary:     testFlagCoverageSummary,
+		CoverageSummaryJSON: testFlagCoverageSummaryJSON,
+		CoverageMin:         testFlagCoverageMin,
+		CoverageDiff:        testFlagCoverageDiff,
+		CoverageDiffJSON:    testFlagCoverageDiffJSON,
 		Heuristics:          testFlagHeuristics,
 		Failed:              testFlagFailed,
 		StalenessCheck:      testFlagStalenessCheck,
 		CoverageGranularity: testFlagCoverageGranularity,
 		NoReports:           testFlagNoReports,
+		TestHangTimeout:     testFlagTestHangTimeout,
+		SkipUntested:        testFlagSkipUntested,
+		ExcludeTraits:       testFlagExcludeTraits,
+		FailOnNoTests:       testFlagFailOnNoTests,
+		Discovery:           testFlagDiscovery,
+		CoverageAutoRebuild: testFlagCoverageAutoRebuild,
 		VcsChanged:          testFlagVcsChanged,
 		VcsRef:              testFlagVcsRef,
+		ChangedFiles:        changedFiles,
+		VcsRefMode:          testFlagVcsRefMode,
+		Scope:               testFlagScope,
+		SDK:                 testFlagSDK,
 		Watch:               testFlagWatch,
+		WatchDebounce:       testFlagWatchDebounce,
+		WatchClear:          testFlagWatchClear,
+		OnIdle:              testFlagOnIdle,
+		OnIdleAfter:         testFlagOnIdleAfter,
 		PrintOutput:         testFlagPrintOutput,
+		QuietOnSuccess:      testFlagQuietOnSuccess,
 		FullBuild:           testFlagFullBuild,
 		NoSolution:          testFlagNoSolution,
 		ForceSolution:       testFlagSolution,
+		SolutionFilter:      testFlagSolutionFilter,
+		Projects:            testFlagProjects,
+		WithDeps:            testFlagWithDeps,
+		OnlyProjects:        project.ParseExcludePatterns(testFlagOnlyProjects),
+		Only:                testFlagOnly,
+		ExcludeProjects:     append(append([]string{}, testFlagExclude...), testFlagExcludeProjects...),
+		ReportMarkdown:      testFlagReportMarkdown,
+		Format:              testFlagFormat,
+		Notify:              testFlagNotify,
+		NotifyOn:            testFlagNotifyOn,
+		DryRun:              testFlagDryRun,
+		Since:               testFlagSince,
+		DiffInputs:          testFlagDiffInputs,
+		CacheKeyDebug:       testFlagCacheKeyDebug,
+		Why:                 testFlagWhy,
+		AffectedGraph:       testFlagAffectedGraph,
+		AffectedGraphOutput: testFlagGraphOut,
 		Force:               IsForce(),
 		Config:              GetConfig(),
 	}
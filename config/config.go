@@ -14,10 +14,35 @@ type Config struct {
 	ShowCached   bool   `koanf:"show_cached"`
 	Local        bool   `koanf:"local"`
 	KeepGoing    bool   `koanf:"keep_going"`
+	MaxFailures  int    `koanf:"max_failures"` // with keep_going, stop after this many failures (0 = unlimited)
 	Quiet        bool   `koanf:"quiet"`
 	NoProgress   bool   `koanf:"no_progress"`
 	NoSuggestions bool  `koanf:"no_suggestions"`
 	CacheDir     string `koanf:"cache_dir"`
+	CacheMaxSize string `koanf:"cache_max_size"` // e.g. "500MB"; empty = unlimited
+	// CacheLockTimeoutMs is how long to wait for another donotnet process to
+	// release the cache before continuing read-only
+	CacheLockTimeoutMs int `koanf:"cache_lock_timeout_ms"`
+	// CacheScope shards the cache: each scope key (e.g. a solution path) gets
+	// its own database file, so concurrent runs don't contend for one lock
+	CacheScope string `koanf:"cache_scope"`
+	// IncludeSubmodules scans projects inside git submodules too
+	IncludeSubmodules bool `koanf:"include_submodules"`
+	// HashMode is how files are fingerprinted for cache keys: content, mtime
+	HashMode string `koanf:"hash_mode"`
+	// CacheStats prints the cache hit rate after each run
+	CacheStats bool `koanf:"cache_stats"`
+	// CacheStatsLog appends the cache hit rate of each run to
+	// <cache_dir>/hitrate.log
+	CacheStatsLog bool `koanf:"cache_stats_log"`
+
+	// TestProjectPatterns are regexes matched against project names to mark
+	// extra test projects. A "!" prefix opts matching projects out instead.
+	TestProjectPatterns []string `koanf:"test_project_patterns"`
+
+	// CacheEnv names environment variables whose values are part of the
+	// cache key, e.g. ASPNETCORE_ENVIRONMENT (unset hashes as empty)
+	CacheEnv []string `koanf:"cache_env"`
 
 	Test  TestConfig  `koanf:"test"`
 	Build BuildConfig `koanf:"build"`
@@ -29,27 +54,47 @@ type Config struct {
 type TestConfig struct {
 	Heuristics          string `koanf:"heuristics"`           // default, none, or comma-separated
 	Coverage            bool   `koanf:"coverage"`
-	CoverageGranularity string `koanf:"coverage_granularity"` // method, class, file
+	CoverageGranularity string `koanf:"coverage_granularity"` // method, class, namespace, file, auto
 	StalenessCheck      string `koanf:"staleness_check"`      // git, mtime, both
 	Reports             bool   `koanf:"reports"`
 	Failed              bool   `koanf:"failed"`
+	SkipUntested        bool   `koanf:"skip_untested"`
+	ExcludeTraits       []string `koanf:"exclude_traits"` // test categories filtered out of every run
+	Discovery           string `koanf:"discovery"` // dotnet, source
+
+	// CoverageMin is the minimum overall line coverage in percent, enforced
+	// after a --coverage run (0 = disabled)
+	CoverageMin float64 `koanf:"coverage_min"`
+
+	// CoverageThresholds are per-project minimum line coverage percentages,
+	// enforced after a --coverage run. The first matching entry applies.
+	CoverageThresholds []CoverageThreshold `koanf:"coverage_thresholds"`
+}
+
+// CoverageThreshold is a minimum line coverage for projects matching a glob.
+type CoverageThreshold struct {
+	Project string  `koanf:"project"` // glob matched against project name and path
+	Min     float64 `koanf:"min"`     // minimum line coverage in percent
 }
 
 // BuildConfig holds build command settings.
 type BuildConfig struct {
-	Solution  string `koanf:"solution"`   // auto, always, never
+	Solution  string `koanf:"solution"`   // auto, always, filter, never
 	FullBuild bool   `koanf:"full_build"`
 }
 
 // VCSConfig holds version control settings.
 type VCSConfig struct {
+	Backend string `koanf:"backend"` // auto, git, jj
 	Ref     string `koanf:"ref"`
+	RefMode string `koanf:"ref_mode"` // merge-base, direct
 	Changed bool   `koanf:"changed"`
 }
 
 // WatchConfig holds watch mode settings.
 type WatchConfig struct {
-	DebounceMs int `koanf:"debounce_ms"`
+	DebounceMs int  `koanf:"debounce_ms"`
+	Clear      bool `koanf:"clear"` // clear the screen before each rerun
 }
 
 // Default returns the default configuration.
@@ -66,11 +111,15 @@ func Default() *Config {
 		NoSuggestions: false,
 		CacheDir:      "",
 
+		CacheLockTimeoutMs: 10000,
+		HashMode:           "content",
+
 		Test: TestConfig{
 			Heuristics:          "default",
 			Coverage:            false,
 			CoverageGranularity: "class",
 			StalenessCheck:      "git",
+			Discovery:           "dotnet",
 			Reports:             true,
 			Failed:              false,
 		},
@@ -81,7 +130,9 @@ func Default() *Config {
 		},
 
 		VCS: VCSConfig{
+			Backend: "auto",
 			Ref:     "",
+			RefMode: "merge-base",
 			Changed: false,
 		},
 
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -141,3 +142,62 @@ parallel = 8
 		t.Errorf("expected Parallel to be 8, got %d", result.Config.Parallel)
 	}
 }
+
+func TestLoadWithGitRootDotFile(t *testing.T) {
+	tmp := t.TempDir()
+
+	// .donotnet.toml sets defaults; .donotnet/config.toml overrides it
+	os.WriteFile(filepath.Join(tmp, ".donotnet.toml"), []byte(`color = "always"
+parallel = 4
+
+[test]
+heuristics = "default,ExtensionsToBase"
+`), 0644)
+	os.MkdirAll(filepath.Join(tmp, ".donotnet"), 0755)
+	os.WriteFile(filepath.Join(tmp, ".donotnet", "config.toml"), []byte(`parallel = 2
+`), 0644)
+
+	result, err := Load(LoadOptions{
+		CWD:     tmp,
+		GitRoot: tmp,
+		SkipEnv: true,
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if result.Config.Color != "always" {
+		t.Errorf("expected Color to be always from .donotnet.toml, got %q", result.Config.Color)
+	}
+	if result.Config.Test.Heuristics != "default,ExtensionsToBase" {
+		t.Errorf("expected heuristics from .donotnet.toml, got %q", result.Config.Test.Heuristics)
+	}
+	if result.Config.Parallel != 2 {
+		t.Errorf("expected Parallel 2 from .donotnet/config.toml to win, got %d", result.Config.Parallel)
+	}
+}
+
+func TestLoadWarnsOnInvalidFile(t *testing.T) {
+	tmp := t.TempDir()
+
+	// A broken .donotnet.toml is skipped, but .donotnet/config.toml still applies
+	os.WriteFile(filepath.Join(tmp, ".donotnet.toml"), []byte("parallel = \n"), 0644)
+	os.MkdirAll(filepath.Join(tmp, ".donotnet"), 0755)
+	os.WriteFile(filepath.Join(tmp, ".donotnet", "config.toml"), []byte("parallel = 2\n"), 0644)
+
+	result, err := Load(LoadOptions{
+		CWD:     tmp,
+		GitRoot: tmp,
+		SkipEnv: true,
+	})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if result.Config.Parallel != 2 {
+		t.Errorf("expected Parallel to be 2 from .donotnet/config.toml, got %d", result.Config.Parallel)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], ".donotnet.toml") {
+		t.Errorf("expected one warning about .donotnet.toml, got %v", result.Warnings)
+	}
+}
@@ -1,7 +1,6 @@
 package config
 
 import (
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -36,6 +35,10 @@ type LoadOptions struct {
 type LoadResult struct {
 	Config  *Config
 	Sources []string // List of sources that contributed to the config
+	// Warnings describe discovered config files that were skipped because
+	// they failed to parse. The caller should surface them: a broken shared
+	// config silently falling back to defaults makes local and CI runs differ.
+	Warnings []string
 }
 
 // Load loads configuration from all sources and returns the merged result.
@@ -64,11 +67,8 @@ func Load(opts LoadOptions) (*LoadResult, error) {
 		locations := FindLocations(opts.CWD, opts.GitRoot)
 		for _, loc := range ExistingLocations(locations) {
 			if err := loadFile(k, loc.Path); err != nil {
-				// Log but continue - don't fail on config parse errors
-				if opts.Verbose {
-					// Can't use term here to avoid import cycles
-					os.Stderr.WriteString("config: error loading " + loc.Path + ": " + err.Error() + "\n")
-				}
+				// Continue without it - don't fail on config parse errors
+				result.Warnings = append(result.Warnings, "ignoring "+loc.Path+": "+err.Error())
 				continue
 			}
 			result.Sources = append(result.Sources, loc.Source+":"+loc.Path)
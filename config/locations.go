@@ -24,7 +24,9 @@ type Location struct {
 
 // FindLocations returns all potential config file locations in merge order.
 // Later locations override earlier ones.
-// Order: user config → parent directories → git root → current directory
+// Order: user config → parent directories → git root → current directory.
+// At the git root, a single .donotnet.toml (or .json, ...) file is read
+// before .donotnet/config.toml, which overrides it.
 func FindLocations(cwd, gitRoot string) []Location {
 	var locations []Location
 
@@ -55,8 +57,16 @@ func FindLocations(cwd, gitRoot string) []Location {
 		}
 	}
 
-	// 3. Git root directory
+	// 3. Git root directory: .donotnet.<ext>, then .donotnet/config.<ext>
 	if gitRoot != "" {
+		for _, ext := range SupportedExtensions {
+			path := filepath.Join(gitRoot, ConfigDirName+ext)
+			locations = append(locations, Location{
+				Path:   path,
+				Source: "git-root",
+				Exists: fileExists(path),
+			})
+		}
 		for _, ext := range SupportedExtensions {
 			path := filepath.Join(gitRoot, ConfigDirName, ConfigFileName+ext)
 			locations = append(locations, Location{
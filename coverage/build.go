@@ -23,17 +23,23 @@ import (
 type Granularity int
 
 const (
-	GranularityMethod Granularity = iota // Run each test individually (most precise, slowest)
-	GranularityClass                     // Group tests by class name (faster, less precise)
-	GranularityFile                      // Group tests by source file (fastest, file-level precision)
+	GranularityMethod    Granularity = iota // Run each test individually (most precise, slowest)
+	GranularityClass                        // Group tests by class name (faster, less precise)
+	GranularityNamespace                    // Group tests by namespace (fewer groups than class)
+	GranularityFile                         // Group tests by source file (fastest, file-level precision)
+	GranularityAuto                         // Pick one of the above per project (see autoGranularity)
 )
 
 // ParseGranularity parses a coverage granularity from string.
-// Valid values: "method", "class", "file" (defaults to "method").
+// Valid values: "method", "class", "namespace", "file", "auto" (defaults to "method").
 func ParseGranularity(s string) Granularity {
 	switch strings.ToLower(s) {
+	case "auto":
+		return GranularityAuto
 	case "class":
 		return GranularityClass
+	case "namespace":
+		return GranularityNamespace
 	case "file":
 		return GranularityFile
 	default:
@@ -41,6 +47,22 @@ func ParseGranularity(s string) Granularity {
 	}
 }
 
+// String returns the name ParseGranularity accepts for g.
+func (g Granularity) String() string {
+	switch g {
+	case GranularityClass:
+		return "class"
+	case GranularityNamespace:
+		return "namespace"
+	case GranularityFile:
+		return "file"
+	case GranularityAuto:
+		return "auto"
+	default:
+		return "method"
+	}
+}
+
 // testGroup represents a group of tests to run together for coverage.
 type testGroup struct {
 	name   string   // group identifier
@@ -67,6 +89,15 @@ type BuildOptions struct {
 	Granularity  Granularity
 	Ctx          context.Context
 	Cache        TestListCache
+	// Incremental re-runs only the tests an existing map links to files
+	// changed since it was generated, plus tests not in the map yet
+	Incremental bool
+	// Isolate gives each test worker its own copy of a project's build
+	// output, so tests within a project run MaxJobs-wide instead of
+	// sequentially. Projects are then processed one at a time.
+	Isolate bool
+	// Discovery selects how tests are listed on a test list cache miss
+	Discovery Discovery
 }
 
 // BuildPerTestCoverageMaps builds per-test coverage maps for the given test projects.
@@ -120,6 +151,13 @@ func BuildPerTestCoverageMaps(opts BuildOptions) {
 		projectWorkers = len(opts.Projects)
 	}
 
+	// With isolated outputs the parallelism moves into each project instead
+	isolateWorkers := 0
+	if opts.Isolate {
+		isolateWorkers = totalWorkers
+		projectWorkers = 1
+	}
+
 	term.Verbose("Running %d projects in parallel", projectWorkers)
 
 	jobs := make(chan *project.Project, len(opts.Projects))
@@ -130,7 +168,7 @@ func BuildPerTestCoverageMaps(opts BuildOptions) {
 		go func() {
 			defer wg.Done()
 			for p := range jobs {
-				buildSingleProjectCoverage(ctx, opts.GitRoot, p, cacheDir, opts.Granularity, opts.ForwardGraph, opts.Cache)
+				buildSingleProjectCoverage(ctx, opts.GitRoot, p, cacheDir, opts.Granularity, opts.ForwardGraph, opts.Cache, opts.Incremental, isolateWorkers, opts.Discovery)
 			}
 		}()
 	}
@@ -143,7 +181,7 @@ func BuildPerTestCoverageMaps(opts BuildOptions) {
 }
 
 // buildSingleProjectCoverage builds coverage map for a single test project.
-func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.Project, cacheDir string, granularity Granularity, forwardGraph map[string][]string, testCache TestListCache) {
+func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.Project, cacheDir string, granularity Granularity, forwardGraph map[string][]string, testCache TestListCache, incremental bool, isolateWorkers int, discovery Discovery) {
 	absProjectPath := filepath.Join(gitRoot, p.Path)
 	projectDir := filepath.Dir(absProjectPath)
 	mapFile := filepath.Join(cacheDir, p.Name+".testcoverage.json")
@@ -163,7 +201,7 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 	}
 
 	if tests == nil {
-		tests, err = listTests(ctx, gitRoot, absProjectPath)
+		tests, err = listTests(ctx, gitRoot, absProjectPath, discovery)
 		if err != nil {
 			term.Errorf("  failed to list tests: %v", err)
 			return
@@ -192,8 +230,12 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 
 	// Load existing map for resume support
 	existingMap, _ := testfilter.LoadTestCoverageMap(mapFile)
+	if incremental && existingMap == nil {
+		term.Printf("  No existing coverage map, building from scratch\n")
+		incremental = false
+	}
 	processedTests := make(map[string]bool)
-	if existingMap != nil {
+	if existingMap != nil && !incremental {
 		for testName := range existingMap.TestToFiles {
 			processedTests[testName] = true
 		}
@@ -230,17 +272,46 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 		pendingTests = append(pendingTests, baseName)
 	}
 
+	if incremental {
+		changed := getFilesChangedSince(gitRoot, existingMap.GeneratedAt)
+		changedClasses := changedTestClasses(buildClassToFileMap(projectDir), filepath.Dir(p.Path), changed)
+		pendingTests = selectIncrementalTests(covMap, pendingTests, changed, changedClasses)
+		term.Printf("  Incremental: %d changed files, %d tests to re-run\n", len(changed), len(pendingTests))
+		if len(pendingTests) == 0 {
+			covMap.GeneratedAt = time.Now()
+			if err := testfilter.SaveTestCoverageMap(mapFile, covMap); err != nil {
+				term.Errorf("  failed to save coverage map: %v", err)
+				return
+			}
+			term.Success("  Coverage map up to date (%d tests)", covMap.ProcessedTests)
+			return
+		}
+	}
+
 	if len(pendingTests) == 0 {
 		term.Success("  All %d tests already processed", len(tests))
 		return
 	}
 
+	if granularity == GranularityAuto {
+		// Decide on all tests, not just pending ones, so resumed builds agree
+		var all []string
+		for t := range uniqueTests {
+			all = append(all, t)
+		}
+		granularity = autoGranularity(all, projectDir)
+		term.Printf("  Auto granularity: %s\n", granularity)
+	}
+
 	// Group tests based on granularity
 	var groups []testGroup
 	switch granularity {
 	case GranularityClass:
 		groups = groupTestsByClass(pendingTests)
 		term.Printf("  Grouped into %d classes\n", len(groups))
+	case GranularityNamespace:
+		groups = groupTestsByNamespace(pendingTests)
+		term.Printf("  Grouped into %d namespaces\n", len(groups))
 	case GranularityFile:
 		classToFile := buildClassToFileMap(projectDir)
 		groups = groupTestsByFile(pendingTests, classToFile)
@@ -259,8 +330,21 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 	term.Printf("  Running %d groups with coverage...\n", len(groups))
 
 	// Tests within each project must be sequential: coverlet instruments DLLs
-	// which causes file locking if multiple tests run simultaneously.
+	// which causes file locking if multiple tests run simultaneously. Isolated
+	// workers each get their own copy of the build output instead.
 	numWorkers := 1
+	var workerAssemblies []string
+	if isolateWorkers > 1 && len(groups) > 1 {
+		term.Printf("  Copying build output for %d workers...\n", min(isolateWorkers, len(groups)))
+		assemblies, cleanup, err := isolateTestOutput(ctx, gitRoot, absProjectPath, min(isolateWorkers, len(groups)))
+		if err != nil {
+			term.Warn("  could not isolate workers, running sequentially: %v", err)
+		} else {
+			defer cleanup()
+			workerAssemblies = assemblies
+			numWorkers = len(assemblies)
+		}
+	}
 
 	type groupResult struct {
 		group testGroup
@@ -280,11 +364,17 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 			for group := range jobs {
 				os.RemoveAll(workerDir)
 
-				testCmd := exec.CommandContext(ctx, "dotnet", "test", absProjectPath,
+				args := []string{"test", absProjectPath,
 					"--filter", group.filter,
 					"--collect", "XPlat Code Coverage",
 					"--results-directory", workerDir,
-					"--no-build")
+					"--no-build"}
+				if workerAssemblies != nil {
+					// Run the worker's own copy; there is nothing to build
+					args[1] = workerAssemblies[workerID]
+					args = args[:len(args)-1]
+				}
+				testCmd := exec.CommandContext(ctx, "dotnet", args...)
 				testCmd.Dir = gitRoot
 				var stdout, stderr bytes.Buffer
 				testCmd.Stdout = &stdout
@@ -368,8 +458,8 @@ func buildSingleProjectCoverage(ctx context.Context, gitRoot string, p *project.
 }
 
 // listTests is the internal (non-caching) version used within this package.
-func listTests(ctx context.Context, gitRoot, absProjectPath string) ([]string, error) {
-	return ListTests(ctx, gitRoot, absProjectPath)
+func listTests(ctx context.Context, gitRoot, absProjectPath string, discovery Discovery) ([]string, error) {
+	return DiscoverTests(ctx, gitRoot, absProjectPath, discovery)
 }
 
 // ListTests runs dotnet test --list-tests and returns the test names.
@@ -449,6 +539,51 @@ func groupTestsByClass(tests []string) []testGroup {
 	return groups
 }
 
+// getTestNamespace extracts the namespace from a fully qualified test name
+// (everything before the class name). Tests without a namespace fall back to
+// their class name.
+func getTestNamespace(testName string) string {
+	className := getTestClassName(testName)
+	if idx := strings.LastIndex(className, "."); idx > 0 {
+		return className[:idx]
+	}
+	return className
+}
+
+// groupTestsByNamespace groups tests by their containing namespace. The filter
+// lists the group's classes rather than the namespace itself, so tests in
+// nested namespaces are not pulled into the run.
+func groupTestsByNamespace(tests []string) []testGroup {
+	nsToTests := make(map[string][]string)
+	nsToClasses := make(map[string]map[string]bool)
+	for _, t := range tests {
+		className := getTestClassName(t)
+		ns := getTestNamespace(t)
+		nsToTests[ns] = append(nsToTests[ns], t)
+		if nsToClasses[ns] == nil {
+			nsToClasses[ns] = make(map[string]bool)
+		}
+		nsToClasses[ns][className] = true
+	}
+
+	var groups []testGroup
+	for ns, nsTests := range nsToTests {
+		var filterParts []string
+		for className := range nsToClasses[ns] {
+			filterParts = append(filterParts, fmt.Sprintf("FullyQualifiedName~%s.", className))
+		}
+		sort.Strings(filterParts)
+
+		groups = append(groups, testGroup{
+			name:   ns,
+			tests:  nsTests,
+			filter: strings.Join(filterParts, " | "),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
 // testFileInfo contains information about a test file.
 type testFileInfo struct {
 	path      string
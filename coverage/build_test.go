@@ -0,0 +1,110 @@
+package coverage
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseGranularity(t *testing.T) {
+	tests := map[string]Granularity{
+		"method":    GranularityMethod,
+		"class":     GranularityClass,
+		"Namespace": GranularityNamespace,
+		"file":      GranularityFile,
+		"auto":      GranularityAuto,
+		"":          GranularityMethod,
+	}
+	for in, want := range tests {
+		if got := ParseGranularity(in); got != want {
+			t.Errorf("ParseGranularity(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestGroupTestsByNamespace(t *testing.T) {
+	groups := groupTestsByNamespace([]string{
+		"App.Tests.Orders.OrderTests.Creates",
+		"App.Tests.Orders.OrderTests.Cancels(id: 1)",
+		"App.Tests.Orders.RefundTests.Refunds",
+		"App.Tests.Orders.Sub.DeepTests.Works",
+		"App.Tests.SmokeTests.Starts",
+		"GlobalTests.Runs",
+	})
+
+	type summary struct {
+		name   string
+		tests  int
+		filter string
+	}
+	var got []summary
+	for _, g := range groups {
+		got = append(got, summary{g.name, len(g.tests), g.filter})
+	}
+
+	want := []summary{
+		{"App.Tests", 1, "FullyQualifiedName~App.Tests.SmokeTests."},
+		{"App.Tests.Orders", 3, "FullyQualifiedName~App.Tests.Orders.OrderTests. | FullyQualifiedName~App.Tests.Orders.RefundTests."},
+		{"App.Tests.Orders.Sub", 1, "FullyQualifiedName~App.Tests.Orders.Sub.DeepTests."},
+		{"GlobalTests", 1, "FullyQualifiedName~GlobalTests."},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupTestsByNamespace() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestBestGranularity(t *testing.T) {
+	if got := bestGranularity(10, 10, 10); got != "class" {
+		t.Errorf("tie: got %q, want class", got)
+	}
+	if got := bestGranularity(10, 4, 6); got != "namespace" {
+		t.Errorf("got %q, want namespace", got)
+	}
+	if got := bestGranularity(10, 4, 3); got != "file" {
+		t.Errorf("got %q, want file", got)
+	}
+}
+
+func TestRecommendGranularity(t *testing.T) {
+	tests := []struct {
+		class, namespace, file float64
+		want                   Granularity
+	}{
+		{4, 4, 4, GranularityClass},
+		{4, 4.5, 4.5, GranularityClass},
+		{2, 12, 12, GranularityNamespace},
+		{2, 2, 6, GranularityFile},
+		{2, 4, 12, GranularityFile},
+	}
+	for _, tt := range tests {
+		if got := recommendGranularity(tt.class, tt.namespace, tt.file); got != tt.want {
+			t.Errorf("recommendGranularity(%v, %v, %v) = %v, want %v", tt.class, tt.namespace, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestAutoGranularity(t *testing.T) {
+	dir := t.TempDir()
+	testNames := func(classes []string, perClass int) []string {
+		var names []string
+		for _, c := range classes {
+			for i := 0; i < perClass; i++ {
+				names = append(names, fmt.Sprintf("%s.Test%d", c, i))
+			}
+		}
+		return names
+	}
+
+	// Tiny projects run test by test
+	if got := autoGranularity(testNames([]string{"App.Tests.A"}, 5), dir); got != GranularityMethod {
+		t.Errorf("tiny project: got %v, want method", got)
+	}
+	// One class per namespace: coarser levels gain nothing
+	if got := autoGranularity(testNames([]string{"App.A.Tests", "App.B.Tests", "App.C.Tests"}, 4), dir); got != GranularityClass {
+		t.Errorf("one class per namespace: got %v, want class", got)
+	}
+	// Many small classes in one namespace
+	if got := autoGranularity(testNames([]string{"App.Tests.A", "App.Tests.B", "App.Tests.C", "App.Tests.D", "App.Tests.E", "App.Tests.F"}, 2), dir); got != GranularityNamespace {
+		t.Errorf("many classes in one namespace: got %v, want namespace", got)
+	}
+}
@@ -20,13 +20,26 @@ type Report struct {
 	CoveredFiles map[string]struct{}
 	// AllFiles includes all files mentioned in coverage, whether covered or not
 	AllFiles map[string]struct{}
+	// Lines maps each filename (as in CoveredFiles) to its coverable line
+	// numbers and whether the line was hit
+	Lines map[string]map[int]bool
+	// LineRate is the overall line coverage ratio (0-1) from the root element
+	LineRate float64
+	// LinesCovered and LinesValid are the number of covered and coverable
+	// lines, from the root element or, if it lacks them, counted from the
+	// line elements
+	LinesCovered int
+	LinesValid   int
 }
 
 // coberturaXML represents the Cobertura XML structure (only fields we need)
 type coberturaXML struct {
-	XMLName  xml.Name           `xml:"coverage"`
-	Sources  coberturaSource    `xml:"sources"`
-	Packages []coberturaPackage `xml:"packages>package"`
+	XMLName      xml.Name           `xml:"coverage"`
+	LineRate     float64            `xml:"line-rate,attr"`
+	LinesCovered int                `xml:"lines-covered,attr"`
+	LinesValid   int                `xml:"lines-valid,attr"`
+	Sources      coberturaSource    `xml:"sources"`
+	Packages     []coberturaPackage `xml:"packages>package"`
 }
 
 type coberturaSource struct {
@@ -65,7 +78,12 @@ func ParseFile(path string) (*Report, error) {
 		SourceDirs:   cov.Sources.Sources,
 		CoveredFiles: make(map[string]struct{}),
 		AllFiles:     make(map[string]struct{}),
+		Lines:        make(map[string]map[int]bool),
+		LineRate:     cov.LineRate,
+		LinesCovered: cov.LinesCovered,
+		LinesValid:   cov.LinesValid,
 	}
+	countLines := cov.LinesValid == 0
 
 	// Process each package and class
 	for _, pkg := range cov.Packages {
@@ -77,14 +95,26 @@ func ParseFile(path string) (*Report, error) {
 			// Normalize path separators (backslashes from Windows XML)
 			filename := strings.ReplaceAll(class.Filename, "\\", "/")
 			report.AllFiles[filename] = struct{}{}
+			fileLines := report.Lines[filename]
+			if fileLines == nil {
+				fileLines = make(map[int]bool)
+				report.Lines[filename] = fileLines
+			}
 
 			// Check if any line has hits > 0
 			hasCoverage := false
 			for _, line := range class.Lines {
 				hits, err := strconv.ParseInt(line.Hits, 10, 64)
-				if err == nil && hits > 0 {
+				covered := err == nil && hits > 0
+				if covered {
 					hasCoverage = true
-					break
+				}
+				fileLines[line.Number] = fileLines[line.Number] || covered
+				if countLines {
+					report.LinesValid++
+					if covered {
+						report.LinesCovered++
+					}
 				}
 			}
 
@@ -163,6 +193,37 @@ func FindCoverageFile(testProjectDir string) string {
 	return FindCoverageFileIn(testResultsDir)
 }
 
+// RunResultsDir returns the directory "donotnet test --coverage" collects the
+// coverage of the project at projectPath (relative to the git root) into,
+// below reportsDir. It is keyed by the project's path, so projects with the
+// same name in different folders don't share one.
+func RunResultsDir(reportsDir, projectPath string) string {
+	return filepath.Join(reportsDir, "coverage", filepath.FromSlash(projectPath))
+}
+
+// FindProjectCoverageFile finds the most recent coverage.cobertura.xml of a
+// test project, in its TestResults directory under projectDir or in
+// resultsDir (see RunResultsDir; empty = not searched).
+// Returns empty string if no coverage file is found.
+func FindProjectCoverageFile(projectDir, resultsDir string) string {
+	newest := FindCoverageFile(projectDir)
+	if resultsDir == "" {
+		return newest
+	}
+	found := FindCoverageFileIn(resultsDir)
+	if found == "" {
+		return newest
+	}
+	if newest != "" {
+		a, errA := os.Stat(found)
+		b, errB := os.Stat(newest)
+		if errA != nil || (errB == nil && !a.ModTime().After(b.ModTime())) {
+			return newest
+		}
+	}
+	return found
+}
+
 // FindCoverageFileIn finds the most recent coverage.cobertura.xml in the given directory.
 // Returns empty string if no coverage file is found.
 func FindCoverageFileIn(dir string) string {
@@ -251,6 +312,9 @@ func IsCoverageFresh(coverageFile string, coveredDirs []string, maxAge int64) bo
 type TestProject struct {
 	Path string // Relative path to .csproj from gitRoot
 	Dir  string // Directory containing the project (relative to gitRoot)
+	// ResultsDir is where donotnet collected the project's coverage (see
+	// RunResultsDir), searched along with TestResults (empty = not searched)
+	ResultsDir string
 }
 
 // BuildMap builds a coverage map from a list of test projects.
@@ -261,7 +325,7 @@ func BuildMap(gitRoot string, testProjects []TestProject) *Map {
 
 	for _, tp := range testProjects {
 		projectDir := filepath.Join(gitRoot, tp.Dir)
-		coverageFile := FindCoverageFile(projectDir)
+		coverageFile := FindProjectCoverageFile(projectDir, tp.ResultsDir)
 
 		if coverageFile == "" {
 			m.MissingTestProjects = append(m.MissingTestProjects, tp.Path)
@@ -3,7 +3,9 @@ package coverage
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParseFile(t *testing.T) {
@@ -61,6 +63,40 @@ func TestParseFile(t *testing.T) {
 			t.Errorf("expected %s to NOT be covered", f)
 		}
 	}
+
+	if report.LinesCovered != 300 || report.LinesValid != 400 {
+		t.Errorf("lines = %d/%d, want 300/400 from the root element", report.LinesCovered, report.LinesValid)
+	}
+}
+
+func TestParseFile_CountsLinesWithoutRootTotals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "coverage.cobertura.xml")
+	err := os.WriteFile(path, []byte(`<coverage line-rate="0.5">
+  <packages><package name="App"><classes>
+    <class name="App.Foo" filename="Foo.cs"><lines>
+      <line number="1" hits="3" />
+      <line number="2" hits="0" />
+    </lines></class>
+    <class name="App.Bar" filename="Bar.cs"><lines>
+      <line number="1" hits="1" />
+      <line number="2" hits="0" />
+    </lines></class>
+  </classes></package></packages>
+</coverage>`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ParseFile(path)
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	if report.LinesCovered != 2 || report.LinesValid != 4 {
+		t.Errorf("lines = %d/%d, want 2/4", report.LinesCovered, report.LinesValid)
+	}
+	if want := map[int]bool{1: true, 2: false}; !reflect.DeepEqual(report.Lines["Foo.cs"], want) {
+		t.Errorf("Lines[Foo.cs] = %v, want %v", report.Lines["Foo.cs"], want)
+	}
 }
 
 func TestParseFile_NotFound(t *testing.T) {
@@ -271,6 +307,38 @@ func TestFindCoverageFile(t *testing.T) {
 	}
 }
 
+func TestFindProjectCoverageFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(path string, mtime time.Time) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("<coverage/>"), 0644)
+		os.Chtimes(path, mtime, mtime)
+	}
+	projectDir := filepath.Join(tmpDir, "tests", "App.Tests")
+	reportsDir := filepath.Join(tmpDir, ".donotnet", "reports")
+	resultsDir := RunResultsDir(reportsDir, "tests/App.Tests/App.Tests.csproj")
+
+	// Projects with the same name in different folders get their own directory
+	if other := RunResultsDir(reportsDir, "legacy/App.Tests/App.Tests.csproj"); other == resultsDir {
+		t.Errorf("RunResultsDir() = %s for both projects", other)
+	}
+
+	old := filepath.Join(projectDir, "TestResults", "a", "coverage.cobertura.xml")
+	write(old, time.Now().Add(-time.Hour))
+	if got := FindProjectCoverageFile(projectDir, resultsDir); got != old {
+		t.Errorf("FindProjectCoverageFile() = %q, want TestResults file %q", got, old)
+	}
+
+	fresh := filepath.Join(resultsDir, "b", "coverage.cobertura.xml")
+	write(fresh, time.Now())
+	if got := FindProjectCoverageFile(projectDir, resultsDir); got != fresh {
+		t.Errorf("FindProjectCoverageFile() = %q, want newer results dir file %q", got, fresh)
+	}
+	if got := FindProjectCoverageFile(projectDir, ""); got != old {
+		t.Errorf("FindProjectCoverageFile() without results dir = %q, want %q", got, old)
+	}
+}
+
 func TestFindCoverageFile_NotFound(t *testing.T) {
 	tmpDir := t.TempDir()
 	found := FindCoverageFile(tmpDir)
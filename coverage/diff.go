@@ -0,0 +1,68 @@
+package coverage
+
+import "sort"
+
+// DiffCoverage is the line coverage of changed lines, e.g. the lines a pull
+// request adds or modifies.
+type DiffCoverage struct {
+	// Covered and Total count the changed lines that are coverable, covered if
+	// any report hit them. Changed lines no report knows (comments, blank
+	// lines, files without coverage) are not counted.
+	Covered int `json:"covered"`
+	Total   int `json:"total"`
+	// Uncovered are the coverable changed lines no report hit, sorted by path
+	// and line
+	Uncovered []LineRef `json:"uncovered"`
+}
+
+// LineRef is one line of a source file, relative to the git root.
+type LineRef struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// Percent returns the changed line coverage in percent (0-100).
+func (d *DiffCoverage) Percent() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return 100 * float64(d.Covered) / float64(d.Total)
+}
+
+// ChangedLineCoverage intersects changed (path relative to gitRoot -> line
+// numbers, see git.ChangedLines) with the merged line hits of the Cobertura
+// reports.
+func ChangedLineCoverage(gitRoot string, reports []string, changed map[string][]int) (*DiffCoverage, error) {
+	lines, err := mergeLines(gitRoot, reports)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &DiffCoverage{Uncovered: []LineRef{}}
+	for path, numbers := range changed {
+		hits, ok := lines[path]
+		if !ok {
+			continue
+		}
+		for _, n := range numbers {
+			hit, coverable := hits[n]
+			if !coverable {
+				continue
+			}
+			d.Total++
+			if hit {
+				d.Covered++
+			} else {
+				d.Uncovered = append(d.Uncovered, LineRef{Path: path, Line: n})
+			}
+		}
+	}
+	sort.Slice(d.Uncovered, func(i, j int) bool {
+		a, b := d.Uncovered[i], d.Uncovered[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return d, nil
+}
@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChangedLineCoverage(t *testing.T) {
+	gitRoot := t.TempDir()
+	report := filepath.Join(t.TempDir(), "coverage.cobertura.xml")
+	os.WriteFile(report, []byte(`<?xml version="1.0"?>
+<coverage>
+  <sources><source>`+gitRoot+`/</source></sources>
+  <packages><package name="App"><classes>
+    <class name="App.Foo" filename="src/Foo.cs"><lines>
+      <line number="10" hits="2"/><line number="11" hits="0"/><line number="12" hits="0"/><line number="13" hits="1"/>
+    </lines></class>
+  </classes></package></packages>
+</coverage>`), 0644)
+
+	d, err := ChangedLineCoverage(gitRoot, []string{report}, map[string][]int{
+		// 9 and 14 are not coverable (e.g. a comment and a brace)
+		"src/Foo.cs": {9, 10, 12, 11, 14},
+		// no coverage data at all
+		"README.md": {1, 2},
+	})
+	if err != nil {
+		t.Fatalf("ChangedLineCoverage failed: %v", err)
+	}
+	if d.Covered != 1 || d.Total != 3 {
+		t.Errorf("covered %d/%d, want 1/3", d.Covered, d.Total)
+	}
+	want := []LineRef{{Path: "src/Foo.cs", Line: 11}, {Path: "src/Foo.cs", Line: 12}}
+	if !reflect.DeepEqual(d.Uncovered, want) {
+		t.Errorf("Uncovered = %+v, want %+v", d.Uncovered, want)
+	}
+	if got := d.Percent(); got < 33.3 || got > 33.4 {
+		t.Errorf("Percent() = %v, want 33.3", got)
+	}
+}
@@ -0,0 +1,40 @@
+package coverage
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testfilter"
+)
+
+// Discovery specifies how test names are listed.
+type Discovery int
+
+const (
+	DiscoveryDotnet Discovery = iota // dotnet test --list-tests (exact, starts a test host)
+	DiscoverySource                  // Parse test attributes from source, falling back to dotnet
+)
+
+// ParseDiscovery parses a test discovery mode from string.
+// Valid values: "dotnet", "source" (defaults to "dotnet").
+func ParseDiscovery(s string) Discovery {
+	if strings.ToLower(s) == "source" {
+		return DiscoverySource
+	}
+	return DiscoveryDotnet
+}
+
+// DiscoverTests lists the tests of a project using the given discovery mode.
+// Source discovery falls back to ListTests when the project's sources can't
+// be parsed unambiguously (see testfilter.ListTestsFromSource).
+func DiscoverTests(ctx context.Context, gitRoot, absProjectPath string, d Discovery) ([]string, error) {
+	if d == DiscoverySource {
+		if tests, ok := testfilter.ListTestsFromSource(filepath.Dir(absProjectPath)); ok {
+			return tests, nil
+		}
+		term.Verbose("  source discovery ambiguous for %s, using dotnet test --list-tests", filepath.Base(absProjectPath))
+	}
+	return ListTests(ctx, gitRoot, absProjectPath)
+}
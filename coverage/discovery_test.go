@@ -0,0 +1,46 @@
+package coverage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseDiscovery(t *testing.T) {
+	tests := map[string]Discovery{
+		"":       DiscoveryDotnet,
+		"dotnet": DiscoveryDotnet,
+		"source": DiscoverySource,
+		"Source": DiscoverySource,
+		"bogus":  DiscoveryDotnet,
+	}
+	for in, want := range tests {
+		if got := ParseDiscovery(in); got != want {
+			t.Errorf("ParseDiscovery(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestDiscoverTestsFromSource(t *testing.T) {
+	dir := t.TempDir()
+	projectPath := filepath.Join(dir, "App.Tests.csproj")
+	os.WriteFile(projectPath, []byte(`<Project Sdk="Microsoft.NET.Sdk" />`), 0644)
+	os.WriteFile(filepath.Join(dir, "FooTests.cs"), []byte(`namespace App.Tests;
+public class FooTests
+{
+    [Fact]
+    public void Works() { }
+}
+`), 0644)
+
+	// Unambiguous sources never reach dotnet
+	tests, err := DiscoverTests(context.Background(), dir, projectPath, DiscoverySource)
+	if err != nil {
+		t.Fatalf("DiscoverTests() failed: %v", err)
+	}
+	if want := []string{"App.Tests.FooTests.Works"}; !reflect.DeepEqual(tests, want) {
+		t.Errorf("DiscoverTests() = %v, want %v", tests, want)
+	}
+}
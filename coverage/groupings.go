@@ -12,18 +12,20 @@ import (
 
 // GroupingStats holds statistics for a single project's coverage groupings.
 type GroupingStats struct {
-	Name           string
-	TotalTests     int
-	UniqueTests    int
-	MethodGroups   int
-	ClassGroups    int
-	FileGroups     int
-	ClassReduction float64
-	FileReduction  float64
+	Name               string
+	TotalTests         int
+	UniqueTests        int
+	MethodGroups       int
+	ClassGroups        int
+	NamespaceGroups    int
+	FileGroups         int
+	ClassReduction     float64
+	NamespaceReduction float64
+	FileReduction      float64
 }
 
 // ListGroupings lists how tests would be grouped for each granularity level.
-func ListGroupings(ctx context.Context, gitRoot string, projects []*project.Project) {
+func ListGroupings(ctx context.Context, gitRoot string, projects []*project.Project, discovery Discovery) {
 	var allStats []GroupingStats
 
 	for _, p := range projects {
@@ -36,7 +38,7 @@ func ListGroupings(ctx context.Context, gitRoot string, projects []*project.Proj
 
 		term.Info("%s", p.Name)
 
-		tests, err := listTests(ctx, gitRoot, absProjectPath)
+		tests, err := listTests(ctx, gitRoot, absProjectPath, discovery)
 		if err != nil {
 			term.Errorf("  failed to list tests: %v", err)
 			continue
@@ -94,6 +96,24 @@ func ListGroupings(ctx context.Context, gitRoot string, projects []*project.Proj
 			}
 		}
 
+		// Namespace granularity
+		namespaceGroups := groupTestsByNamespace(uniqueTests)
+		namespaceReduction := float64(len(uniqueTests)) / float64(len(namespaceGroups))
+		term.Printf("  %snamespace%s: %d groups (%.1fx reduction)\n",
+			term.Color(term.ColorGreen), term.Color(term.ColorReset), len(namespaceGroups), namespaceReduction)
+
+		for _, g := range namespaceGroups {
+			if len(g.tests) > 1 {
+				groupTraits := collectGroupTraits(g.tests, traitMap)
+				traitSuffix := ""
+				if len(groupTraits) > 0 {
+					traitSuffix = " " + term.Color(term.ColorYellow) + "[" + strings.Join(groupTraits, ", ") + "]" + term.Color(term.ColorReset)
+				}
+				term.Printf("    %s%s%s: %d tests%s\n",
+					term.Color(term.ColorDim), g.name, term.Color(term.ColorReset), len(g.tests), traitSuffix)
+			}
+		}
+
 		// File granularity
 		classToFile := buildClassToFileMap(projectDir)
 		fileGroups := groupTestsByFile(uniqueTests, classToFile)
@@ -116,14 +136,16 @@ func ListGroupings(ctx context.Context, gitRoot string, projects []*project.Proj
 		term.Println()
 
 		allStats = append(allStats, GroupingStats{
-			Name:           p.Name,
-			TotalTests:     len(tests),
-			UniqueTests:    len(uniqueTests),
-			MethodGroups:   len(uniqueTests),
-			ClassGroups:    len(classGroups),
-			FileGroups:     len(fileGroups),
-			ClassReduction: classReduction,
-			FileReduction:  fileReduction,
+			Name:               p.Name,
+			TotalTests:         len(tests),
+			UniqueTests:        len(uniqueTests),
+			MethodGroups:       len(uniqueTests),
+			ClassGroups:        len(classGroups),
+			NamespaceGroups:    len(namespaceGroups),
+			FileGroups:         len(fileGroups),
+			ClassReduction:     classReduction,
+			NamespaceReduction: namespaceReduction,
+			FileReduction:      fileReduction,
 		})
 	}
 
@@ -138,16 +160,18 @@ func printGroupingSummary(stats []GroupingStats) {
 	term.Info("Summary")
 	term.Println()
 
-	var totalTests, totalUnique, totalMethod, totalClass, totalFile int
+	var totalTests, totalUnique, totalMethod, totalClass, totalNamespace, totalFile int
 	for _, s := range stats {
 		totalTests += s.TotalTests
 		totalUnique += s.UniqueTests
 		totalMethod += s.MethodGroups
 		totalClass += s.ClassGroups
+		totalNamespace += s.NamespaceGroups
 		totalFile += s.FileGroups
 	}
 
 	classReduction := float64(totalMethod) / float64(totalClass)
+	namespaceReduction := float64(totalMethod) / float64(totalNamespace)
 	fileReduction := float64(totalMethod) / float64(totalFile)
 
 	nameWidth := 10
@@ -158,96 +182,145 @@ func printGroupingSummary(stats []GroupingStats) {
 	}
 
 	if term.IsPlain() {
-		term.Printf("  %-*s  %8s  %8s  %8s  %8s  %8s\n",
-			nameWidth, "Project", "Tests", "Method", "Class", "File", "Best")
-		term.Printf("  %s  %s  %s  %s  %s  %s\n",
+		term.Printf("  %-*s  %8s  %8s  %8s  %9s  %8s  %9s\n",
+			nameWidth, "Project", "Tests", "Method", "Class", "Namespace", "File", "Best")
+		term.Printf("  %s  %s  %s  %s  %s  %s  %s\n",
 			strings.Repeat("-", nameWidth),
 			strings.Repeat("-", 8),
 			strings.Repeat("-", 8),
 			strings.Repeat("-", 8),
+			strings.Repeat("-", 9),
 			strings.Repeat("-", 8),
-			strings.Repeat("-", 8))
+			strings.Repeat("-", 9))
 	} else {
-		term.Printf("  %s%-*s  %8s  %8s  %8s  %8s  %8s%s\n",
-			term.Color(term.ColorBold), nameWidth, "Project", "Tests", "Method", "Class", "File", "Best", term.Color(term.ColorReset))
+		term.Printf("  %s%-*s  %8s  %8s  %8s  %9s  %8s  %9s%s\n",
+			term.Color(term.ColorBold), nameWidth, "Project", "Tests", "Method", "Class", "Namespace", "File", "Best", term.Color(term.ColorReset))
 	}
 
 	for _, s := range stats {
-		best := "class"
-		classColor := term.ColorGreen
-		fileColor := term.ColorGreen
-		if s.FileGroups < s.ClassGroups {
-			best = "file"
-			classColor = term.ColorYellow
-		} else if s.ClassGroups < s.FileGroups {
-			fileColor = term.ColorYellow
-		}
+		best := bestGranularity(s.ClassGroups, s.NamespaceGroups, s.FileGroups)
+		classColor := granularityColor(best, "class")
+		namespaceColor := granularityColor(best, "namespace")
+		fileColor := granularityColor(best, "file")
 
 		if term.IsPlain() {
-			term.Printf("  %-*s  %8d  %8d  %8d  %8d  %8s\n",
-				nameWidth, s.Name, s.UniqueTests, s.MethodGroups, s.ClassGroups, s.FileGroups, best)
+			term.Printf("  %-*s  %8d  %8d  %8d  %9d  %8d  %9s\n",
+				nameWidth, s.Name, s.UniqueTests, s.MethodGroups, s.ClassGroups, s.NamespaceGroups, s.FileGroups, best)
 		} else {
-			term.Printf("  %-*s  %8d  %s%8d%s  %s%8d%s  %s%8d%s  %s%s%s\n",
+			term.Printf("  %-*s  %8d  %s%8d%s  %s%8d%s  %s%9d%s  %s%8d%s  %s%9s%s\n",
 				nameWidth, s.Name, s.UniqueTests,
 				term.Color(term.ColorRed), s.MethodGroups, term.Color(term.ColorReset),
 				term.Color(classColor), s.ClassGroups, term.Color(term.ColorReset),
+				term.Color(namespaceColor), s.NamespaceGroups, term.Color(term.ColorReset),
 				term.Color(fileColor), s.FileGroups, term.Color(term.ColorReset),
 				term.Color(term.ColorGreen), best, term.Color(term.ColorReset))
 		}
 	}
 
 	// Totals
-	totalClassColor := term.ColorGreen
-	totalFileColor := term.ColorGreen
-	if totalFile < totalClass {
-		totalClassColor = term.ColorYellow
-	} else if totalClass < totalFile {
-		totalFileColor = term.ColorYellow
-	}
+	totalBest := bestGranularity(totalClass, totalNamespace, totalFile)
+	totalClassColor := granularityColor(totalBest, "class")
+	totalNamespaceColor := granularityColor(totalBest, "namespace")
+	totalFileColor := granularityColor(totalBest, "file")
 
 	if term.IsPlain() {
-		term.Printf("  %s  %s  %s  %s  %s  %s\n",
+		term.Printf("  %s  %s  %s  %s  %s  %s  %s\n",
 			strings.Repeat("-", nameWidth),
 			strings.Repeat("-", 8),
 			strings.Repeat("-", 8),
 			strings.Repeat("-", 8),
+			strings.Repeat("-", 9),
 			strings.Repeat("-", 8),
-			strings.Repeat("-", 8))
-		term.Printf("  %-*s  %8d  %8d  %8d  %8d\n",
-			nameWidth, "TOTAL", totalUnique, totalMethod, totalClass, totalFile)
-		term.Printf("  %-*s  %8s  %8s  %7.1fx  %7.1fx\n",
+			strings.Repeat("-", 9))
+		term.Printf("  %-*s  %8d  %8d  %8d  %9d  %8d\n",
+			nameWidth, "TOTAL", totalUnique, totalMethod, totalClass, totalNamespace, totalFile)
+		term.Printf("  %-*s  %8s  %8s  %7.1fx  %8.1fx  %7.1fx\n",
 			nameWidth, "Reduction", "", "1.0x",
-			classReduction, fileReduction)
+			classReduction, namespaceReduction, fileReduction)
 	} else {
 		term.Printf("  %s%s%s\n", term.Color(term.ColorDim),
-			strings.Repeat("─", nameWidth+2+9+9+9+9+9), term.Color(term.ColorReset))
-		term.Printf("  %s%-*s%s  %8d  %s%8d%s  %s%8d%s  %s%8d%s\n",
+			strings.Repeat("─", nameWidth+2+9+9+9+10+9+10), term.Color(term.ColorReset))
+		term.Printf("  %s%-*s%s  %8d  %s%8d%s  %s%8d%s  %s%9d%s  %s%8d%s\n",
 			term.Color(term.ColorBold), nameWidth, "TOTAL", term.Color(term.ColorReset),
 			totalUnique,
 			term.Color(term.ColorRed), totalMethod, term.Color(term.ColorReset),
 			term.Color(totalClassColor), totalClass, term.Color(term.ColorReset),
+			term.Color(totalNamespaceColor), totalNamespace, term.Color(term.ColorReset),
 			term.Color(totalFileColor), totalFile, term.Color(term.ColorReset))
-		term.Printf("  %s%-*s%s  %8s  %s%8s%s  %s%7.1fx%s  %s%7.1fx%s\n",
+		term.Printf("  %s%-*s%s  %8s  %s%8s%s  %s%7.1fx%s  %s%8.1fx%s  %s%7.1fx%s\n",
 			term.Color(term.ColorBold), nameWidth, "Reduction", term.Color(term.ColorReset),
 			"",
 			term.Color(term.ColorRed), "1.0x", term.Color(term.ColorReset),
 			term.Color(totalClassColor), classReduction, term.Color(term.ColorReset),
+			term.Color(totalNamespaceColor), namespaceReduction, term.Color(term.ColorReset),
 			term.Color(totalFileColor), fileReduction, term.Color(term.ColorReset))
 	}
 
 	term.Println()
 
-	// Recommendation
-	recommended := "class"
-	if fileReduction > classReduction*1.2 {
-		recommended = "file"
-	}
+	recommended := recommendGranularity(classReduction, namespaceReduction, fileReduction)
 	term.Printf("  %sRecommendation:%s Use %s--granularity=%s%s for best balance\n",
 		term.Color(term.ColorDim), term.Color(term.ColorReset),
 		term.Color(term.ColorGreen), recommended, term.Color(term.ColorReset))
 	term.Println()
 }
 
+// recommendGranularity picks class granularity, only moving to a coarser
+// level when it cuts runs noticeably (by more than 1.2x) beyond the previous
+// one. Reductions are tests per group at each level.
+func recommendGranularity(classReduction, namespaceReduction, fileReduction float64) Granularity {
+	recommended := GranularityClass
+	bestReduction := classReduction
+	if namespaceReduction > bestReduction*1.2 {
+		recommended = GranularityNamespace
+		bestReduction = namespaceReduction
+	}
+	if fileReduction > bestReduction*1.2 {
+		recommended = GranularityFile
+	}
+	return recommended
+}
+
+// AutoMethodMaxTests is the most unique tests a project can have for auto
+// granularity to run them one by one: grouping saves little there, while
+// method-level maps are the most precise.
+const AutoMethodMaxTests = 10
+
+// autoGranularity resolves GranularityAuto for a project's unique tests:
+// method for tiny projects, else recommendGranularity.
+func autoGranularity(uniqueTests []string, projectDir string) Granularity {
+	if len(uniqueTests) <= AutoMethodMaxTests {
+		return GranularityMethod
+	}
+	n := float64(len(uniqueTests))
+	return recommendGranularity(
+		n/float64(len(groupTestsByClass(uniqueTests))),
+		n/float64(len(groupTestsByNamespace(uniqueTests))),
+		n/float64(len(groupTestsByFile(uniqueTests, buildClassToFileMap(projectDir)))),
+	)
+}
+
+// bestGranularity returns the level with the fewest groups, preferring the
+// finer level on ties.
+func bestGranularity(classGroups, namespaceGroups, fileGroups int) string {
+	best, fewest := "class", classGroups
+	if namespaceGroups < fewest {
+		best, fewest = "namespace", namespaceGroups
+	}
+	if fileGroups < fewest {
+		best = "file"
+	}
+	return best
+}
+
+// granularityColor highlights the best level in green and the others in yellow.
+func granularityColor(best, level string) string {
+	if best == level {
+		return term.ColorGreen
+	}
+	return term.ColorYellow
+}
+
 // collectGroupTraits collects unique traits from all tests in a group.
 func collectGroupTraits(tests []string, traitMap testfilter.TraitMap) []string {
 	seen := make(map[string]bool)
@@ -0,0 +1,146 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// FileCoverage is the line coverage of one source file.
+type FileCoverage struct {
+	// Path is relative to the git root when it can be resolved, otherwise the
+	// filename from the Cobertura report
+	Path    string
+	Covered int
+	Total   int
+}
+
+// Percent returns the line coverage in percent (0-100).
+func (f FileCoverage) Percent() float64 {
+	if f.Total == 0 {
+		return 0
+	}
+	return 100 * float64(f.Covered) / float64(f.Total)
+}
+
+// MergeLineCoverage merges the line hits of several Cobertura reports (e.g.
+// one per test project) into per-file coverage sorted by path. A line is
+// covered if any report hit it.
+func MergeLineCoverage(gitRoot string, reports []string) ([]FileCoverage, error) {
+	lines, err := mergeLines(gitRoot, reports)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]FileCoverage, 0, len(lines))
+	for path, hits := range lines {
+		f := FileCoverage{Path: path, Total: len(hits)}
+		for _, hit := range hits {
+			if hit {
+				f.Covered++
+			}
+		}
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// mergeLines merges the line hits of several Cobertura reports into
+// path -> line number -> hit, with paths relative to gitRoot when they can
+// be resolved.
+func mergeLines(gitRoot string, reports []string) (map[string]map[int]bool, error) {
+	lines := make(map[string]map[int]bool)
+	for _, path := range reports {
+		if err := mergeLineHits(gitRoot, path, lines); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	return lines, nil
+}
+
+// mergeLineHits adds the line hits of the Cobertura file at path to lines.
+func mergeLineHits(gitRoot, path string, lines map[string]map[int]bool) error {
+	report, err := ParseFile(path)
+	if err != nil {
+		return err
+	}
+	for filename, hits := range report.Lines {
+		if resolved := report.ResolveToGitRoot(filename, gitRoot); resolved != "" {
+			filename = resolved
+		}
+		fileLines := lines[filename]
+		if fileLines == nil {
+			fileLines = make(map[int]bool)
+			lines[filename] = fileLines
+		}
+		for n, hit := range hits {
+			fileLines[n] = fileLines[n] || hit
+		}
+	}
+	return nil
+}
+
+// htmlReportTemplate renders a self-contained coverage summary (no external
+// assets), so the report directory can be archived or opened anywhere.
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(p float64) string { return strconv.FormatFloat(p, 'f', 1, 64) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Coverage report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; }
+th, td { padding: 0.3em 0.6em; border-bottom: 1px solid #ddd; text-align: left; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.bar { width: 10em; height: 0.8em; background: #e66; }
+.bar div { height: 100%; background: #5b5; }
+.meta { color: #777; }
+</style>
+</head>
+<body>
+<h1>Line coverage: {{percent .Total.Percent}}%</h1>
+<p class="meta">{{.Total.Covered}} of {{.Total.Total}} lines in {{len .Files}} files, generated {{.Generated}}</p>
+<table>
+<tr><th>File</th><th></th><th>Coverage</th><th>Covered</th><th>Lines</th></tr>
+{{range .Files}}<tr><td>{{.Path}}</td><td><div class="bar"><div style="width: {{percent .Percent}}%"></div></div></td><td class="num">{{percent .Percent}}%</td><td class="num">{{.Covered}}</td><td class="num">{{.Total}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTMLReport writes an index.html summarizing files into dir (created if
+// needed) and returns its path.
+func WriteHTMLReport(dir string, files []FileCoverage) (string, error) {
+	total := FileCoverage{}
+	for _, f := range files {
+		total.Covered += f.Covered
+		total.Total += f.Total
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "index.html")
+	out, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	err = htmlReportTemplate.Execute(out, struct {
+		Files     []FileCoverage
+		Total     FileCoverage
+		Generated string
+	}{files, total, time.Now().Format("2006-01-02 15:04")})
+	if err != nil {
+		return "", err
+	}
+	return path, out.Close()
+}
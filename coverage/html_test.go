@@ -0,0 +1,50 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeLineCoverage(t *testing.T) {
+	gitRoot := t.TempDir()
+	write := func(name, lines string) string {
+		path := filepath.Join(t.TempDir(), name)
+		os.WriteFile(path, []byte(`<?xml version="1.0"?>
+<coverage line-rate="0.5">
+  <sources><source>`+filepath.Join(gitRoot, "src", "App")+`/</source></sources>
+  <packages><package name="App"><classes>
+    <class name="App.Foo" filename="Foo.cs"><lines>`+lines+`</lines></class>
+    <class name="App.Bar" filename="Bar.cs"><lines><line number="1" hits="0"/></lines></class>
+  </classes></package></packages>
+</coverage>`), 0644)
+		return path
+	}
+	a := write("a.xml", `<line number="1" hits="3"/><line number="2" hits="0"/><line number="3" hits="0"/>`)
+	b := write("b.xml", `<line number="2" hits="1"/><line number="3" hits="0"/><line number="4" hits="0"/>`)
+
+	files, err := MergeLineCoverage(gitRoot, []string{a, b})
+	if err != nil {
+		t.Fatalf("MergeLineCoverage failed: %v", err)
+	}
+	want := []FileCoverage{
+		{Path: "src/App/Bar.cs", Covered: 0, Total: 1},
+		{Path: "src/App/Foo.cs", Covered: 2, Total: 4},
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("MergeLineCoverage = %+v, want %+v", files, want)
+	}
+
+	path, err := WriteHTMLReport(filepath.Join(t.TempDir(), "report"), files)
+	if err != nil {
+		t.Fatalf("WriteHTMLReport failed: %v", err)
+	}
+	html, _ := os.ReadFile(path)
+	for _, s := range []string{"Line coverage: 40.0%", "2 of 5 lines in 2 files", "src/App/Foo.cs", "50.0%"} {
+		if !strings.Contains(string(html), s) {
+			t.Errorf("expected %q in report\n%s", s, html)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package coverage
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/runar-rkmedia/donotnet/testfilter"
+)
+
+// selectIncrementalTests picks the tests an incremental build must re-run:
+// tests the existing map links to one of changedFiles, tests of
+// changedClasses (whose own source changed), and tests that are not in the
+// map yet. Their old entries are removed from m so fresh results replace
+// them, as are entries for tests that no longer exist. tests are unique base
+// names; the result keeps their order.
+func selectIncrementalTests(m *testfilter.TestCoverageMap, tests []string, changedFiles []string, changedClasses map[string]bool) []string {
+	current := make(map[string]bool, len(tests))
+	for _, t := range tests {
+		current[t] = true
+	}
+
+	stale := make(map[string]bool)
+	for _, f := range changedFiles {
+		for _, t := range m.FileToTests[f] {
+			stale[t] = true
+		}
+	}
+	for t := range m.TestToFiles {
+		if changedClasses[getTestClassName(t)] {
+			stale[t] = true
+		}
+	}
+
+	var removed []string
+	for t := range m.TestToFiles {
+		if stale[t] || !current[t] {
+			removed = append(removed, t)
+		}
+	}
+	sort.Strings(removed)
+	for _, t := range removed {
+		removeTest(m, t)
+	}
+
+	var pending []string
+	for _, t := range tests {
+		if _, ok := m.TestToFiles[t]; !ok {
+			pending = append(pending, t)
+		}
+	}
+	m.ProcessedTests = len(m.TestToFiles)
+	return pending
+}
+
+// removeTest drops a test from both directions of the map.
+func removeTest(m *testfilter.TestCoverageMap, test string) {
+	for _, f := range m.TestToFiles[test] {
+		remaining := m.FileToTests[f][:0]
+		for _, t := range m.FileToTests[f] {
+			if t != test {
+				remaining = append(remaining, t)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(m.FileToTests, f)
+		} else {
+			m.FileToTests[f] = remaining
+		}
+	}
+	delete(m.TestToFiles, test)
+}
+
+// changedTestClasses returns the fully qualified test classes declared in
+// changedFiles (relative to the git root). A test project's own files are
+// never linked in FileToTests, so without this a test whose body changed
+// would keep its old mapping. classToFile maps classes to their file relative
+// to the test project directory projectRel (see buildClassToFileMap).
+func changedTestClasses(classToFile map[string]string, projectRel string, changedFiles []string) map[string]bool {
+	changed := make(map[string]bool, len(changedFiles))
+	for _, f := range changedFiles {
+		changed[filepath.ToSlash(f)] = true
+	}
+	classes := make(map[string]bool)
+	for class, file := range classToFile {
+		if changed[path.Join(filepath.ToSlash(projectRel), filepath.ToSlash(file))] {
+			classes[class] = true
+		}
+	}
+	return classes
+}
@@ -0,0 +1,93 @@
+package coverage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/testfilter"
+)
+
+func TestSelectIncrementalTests(t *testing.T) {
+	m := &testfilter.TestCoverageMap{
+		FileToTests: map[string][]string{
+			"src/A.cs":      {"T.A1", "T.Shared"},
+			"src/B.cs":      {"T.B1", "T.Shared"},
+			"src/Gone.cs":   {"T.Removed"},
+			"src/Unused.cs": {"T.B1"},
+		},
+		TestToFiles: map[string][]string{
+			"T.A1":      {"src/A.cs"},
+			"T.B1":      {"src/B.cs", "src/Unused.cs"},
+			"T.Shared":  {"src/A.cs", "src/B.cs"},
+			"T.Removed": {"src/Gone.cs"},
+		},
+		ProcessedTests: 4,
+	}
+
+	tests := []string{"T.A1", "T.B1", "T.Shared", "T.New"}
+	got := selectIncrementalTests(m, tests, []string{"src/A.cs", "README.md"}, nil)
+
+	if want := []string{"T.A1", "T.Shared", "T.New"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pending = %v, want %v", got, want)
+	}
+
+	wantTestToFiles := map[string][]string{
+		"T.B1": {"src/B.cs", "src/Unused.cs"},
+	}
+	if !reflect.DeepEqual(m.TestToFiles, wantTestToFiles) {
+		t.Errorf("TestToFiles = %v, want %v", m.TestToFiles, wantTestToFiles)
+	}
+	wantFileToTests := map[string][]string{
+		"src/B.cs":      {"T.B1"},
+		"src/Unused.cs": {"T.B1"},
+	}
+	if !reflect.DeepEqual(m.FileToTests, wantFileToTests) {
+		t.Errorf("FileToTests = %v, want %v", m.FileToTests, wantFileToTests)
+	}
+	if m.ProcessedTests != 1 {
+		t.Errorf("ProcessedTests = %d, want 1", m.ProcessedTests)
+	}
+}
+
+func TestSelectIncrementalTestsNothingChanged(t *testing.T) {
+	m := &testfilter.TestCoverageMap{
+		FileToTests: map[string][]string{"src/A.cs": {"T.A1"}},
+		TestToFiles: map[string][]string{"T.A1": {"src/A.cs"}},
+	}
+	if got := selectIncrementalTests(m, []string{"T.A1"}, nil, nil); len(got) != 0 {
+		t.Errorf("pending = %v, want none", got)
+	}
+	if m.ProcessedTests != 1 {
+		t.Errorf("ProcessedTests = %d, want 1", m.ProcessedTests)
+	}
+}
+
+func TestSelectIncrementalTestsChangedTestSource(t *testing.T) {
+	m := &testfilter.TestCoverageMap{
+		FileToTests: map[string][]string{"src/A.cs": {"Tests.ATests.Adds", "Tests.BTests.Reads"}},
+		TestToFiles: map[string][]string{
+			"Tests.ATests.Adds":  {"src/A.cs"},
+			"Tests.BTests.Reads": {"src/A.cs"},
+		},
+	}
+	classToFile := map[string]string{
+		"Tests.ATests": "ATests.cs",
+		"Tests.BTests": filepath.Join("Sub", "BTests.cs"),
+	}
+
+	// Only the test's own file changed, which FileToTests never links
+	changed := []string{"tests/Tests/ATests.cs"}
+	classes := changedTestClasses(classToFile, filepath.Join("tests", "Tests"), changed)
+	if want := map[string]bool{"Tests.ATests": true}; !reflect.DeepEqual(classes, want) {
+		t.Fatalf("changedTestClasses() = %v, want %v", classes, want)
+	}
+
+	got := selectIncrementalTests(m, []string{"Tests.ATests.Adds", "Tests.BTests.Reads"}, changed, classes)
+	if want := []string{"Tests.ATests.Adds"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pending = %v, want %v", got, want)
+	}
+	if want := []string{"Tests.BTests.Reads"}; !reflect.DeepEqual(m.FileToTests["src/A.cs"], want) {
+		t.Errorf("FileToTests[src/A.cs] = %v, want %v", m.FileToTests["src/A.cs"], want)
+	}
+}
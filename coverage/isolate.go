@@ -0,0 +1,107 @@
+package coverage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// isolatedOutputDir is where per-worker copies of a test project's build
+// output live, relative to the project directory.
+const isolatedOutputDir = "obj/donotnet-isolate"
+
+// isolateTestOutput builds the test project once and copies its output
+// directory for each of n workers, so coverlet can instrument every copy
+// without the workers locking each other's DLLs. It returns the test
+// assembly path of each copy and a cleanup func that removes the copies.
+func isolateTestOutput(ctx context.Context, gitRoot, absProjectPath string, n int) ([]string, func(), error) {
+	buildCmd := exec.CommandContext(ctx, "dotnet", "build", absProjectPath, "--property:WarningLevel=0", "-clp:ErrorsOnly")
+	buildCmd.Dir = gitRoot
+	if out, err := buildCmd.CombinedOutput(); err != nil {
+		return nil, nil, fmt.Errorf("dotnet build failed: %w\n%s", err, out)
+	}
+
+	projectDir := filepath.Dir(absProjectPath)
+	assembly := findTestAssembly(projectDir, strings.TrimSuffix(filepath.Base(absProjectPath), ".csproj"))
+	if assembly == "" {
+		return nil, nil, fmt.Errorf("no test assembly found under %s", filepath.Join(projectDir, "bin"))
+	}
+
+	root := filepath.Join(projectDir, filepath.FromSlash(isolatedOutputDir))
+	cleanup := func() { os.RemoveAll(root) }
+	os.RemoveAll(root)
+
+	var assemblies []string
+	for i := 0; i < n; i++ {
+		workerDir := filepath.Join(root, fmt.Sprintf("worker%d", i))
+		if err := copyDir(filepath.Dir(assembly), workerDir); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("copying build output: %w", err)
+		}
+		assemblies = append(assemblies, filepath.Join(workerDir, filepath.Base(assembly)))
+	}
+	return assemblies, cleanup, nil
+}
+
+// findTestAssembly returns the most recently built <name>.dll under the
+// project's bin directory, or "" if there is none.
+func findTestAssembly(projectDir, name string) string {
+	var newest string
+	var newestTime int64
+	filepath.WalkDir(filepath.Join(projectDir, "bin"), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(d.Name(), name+".dll") {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().UnixNano() > newestTime {
+			newest = path
+			newestTime = info.ModTime().UnixNano()
+		}
+		return nil
+	})
+	return newest
+}
+
+// copyDir recursively copies src to dst, preserving file modes.
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+func copyFile(src, dst string, perm fs.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,56 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFindTestAssembly(t *testing.T) {
+	projectDir := t.TempDir()
+	debug := filepath.Join(projectDir, "bin", "Debug", "net8.0")
+	release := filepath.Join(projectDir, "bin", "Release", "net8.0")
+	os.MkdirAll(debug, 0755)
+	os.MkdirAll(release, 0755)
+
+	if got := findTestAssembly(projectDir, "App.Tests"); got != "" {
+		t.Errorf("expected no assembly before a build, got %q", got)
+	}
+
+	old := filepath.Join(debug, "App.Tests.dll")
+	newer := filepath.Join(release, "App.Tests.dll")
+	os.WriteFile(old, []byte("MZ"), 0644)
+	os.WriteFile(newer, []byte("MZ"), 0644)
+	os.WriteFile(filepath.Join(release, "App.dll"), []byte("MZ"), 0644)
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(old, past, past)
+
+	if got := findTestAssembly(projectDir, "App.Tests"); got != newer {
+		t.Errorf("findTestAssembly() = %q, want %q", got, newer)
+	}
+}
+
+func TestCopyDir(t *testing.T) {
+	src := t.TempDir()
+	os.MkdirAll(filepath.Join(src, "runtimes", "linux-x64"), 0755)
+	os.WriteFile(filepath.Join(src, "App.Tests.dll"), []byte("assembly"), 0644)
+	os.WriteFile(filepath.Join(src, "runtimes", "linux-x64", "native.so"), []byte("native"), 0755)
+
+	dst := filepath.Join(t.TempDir(), "worker0")
+	if err := copyDir(src, dst); err != nil {
+		t.Fatalf("copyDir() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dst, "App.Tests.dll"))
+	if err != nil || string(data) != "assembly" {
+		t.Errorf("top-level file not copied: %q, %v", data, err)
+	}
+	info, err := os.Stat(filepath.Join(dst, "runtimes", "linux-x64", "native.so"))
+	if err != nil {
+		t.Fatalf("nested file not copied: %v", err)
+	}
+	if info.Mode().Perm()&0100 == 0 {
+		t.Errorf("file mode not preserved: %v", info.Mode())
+	}
+}
@@ -0,0 +1,86 @@
+package coverage
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// Threshold is a minimum line coverage percentage for projects matching Pattern.
+type Threshold struct {
+	// Pattern is a glob matched against the project name and path (see project.MatchGlob)
+	Pattern string
+	// Min is the minimum line coverage in percent (0-100)
+	Min float64
+}
+
+// ThresholdResult is the outcome of checking one project against its threshold.
+type ThresholdResult struct {
+	Project *project.Project
+	Pattern string
+	Min     float64
+	// Actual is the measured line coverage in percent (0-100)
+	Actual float64
+	// Err is set when the coverage file could not be found or parsed
+	Err error
+}
+
+// Passed returns true if the project met its threshold.
+func (r ThresholdResult) Passed() bool {
+	return r.Err == nil && r.Actual >= r.Min
+}
+
+// String describes the result, e.g. "MyApp.Tests: line coverage 42.0% is below threshold 60.0% (src/**)".
+func (r ThresholdResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s: %v (threshold %.1f%%, %s)", r.Project.Name, r.Err, r.Min, r.Pattern)
+	}
+	verdict := "meets"
+	if !r.Passed() {
+		verdict = "is below"
+	}
+	return fmt.Sprintf("%s: line coverage %.1f%% %s threshold %.1f%% (%s)", r.Project.Name, r.Actual, verdict, r.Min, r.Pattern)
+}
+
+// FindThreshold returns the first threshold whose pattern matches p.
+func FindThreshold(p *project.Project, thresholds []Threshold) (Threshold, bool) {
+	relPath := filepath.ToSlash(p.Path)
+	for _, t := range thresholds {
+		pattern := filepath.ToSlash(t.Pattern)
+		if project.MatchGlob(pattern, p.Name) || project.MatchGlob(pattern, relPath) {
+			return t, true
+		}
+	}
+	return Threshold{}, false
+}
+
+// CheckThresholds compares each project's most recent Cobertura report against
+// the first matching threshold. Projects without a matching threshold are exempt
+// and not included in the results. coverageFiles maps project paths to a known
+// report (e.g. from a per-project --results-directory); projects not in it fall
+// back to their TestResults directory.
+func CheckThresholds(gitRoot string, projects []*project.Project, thresholds []Threshold, coverageFiles map[string]string) []ThresholdResult {
+	var results []ThresholdResult
+	for _, p := range projects {
+		t, ok := FindThreshold(p, thresholds)
+		if !ok {
+			continue
+		}
+
+		res := ThresholdResult{Project: p, Pattern: t.Pattern, Min: t.Min}
+		covFile := coverageFiles[p.Path]
+		if covFile == "" {
+			covFile = FindCoverageFile(filepath.Join(gitRoot, p.Dir))
+		}
+		if covFile == "" {
+			res.Err = fmt.Errorf("no coverage file found")
+		} else if report, err := ParseFile(covFile); err != nil {
+			res.Err = fmt.Errorf("parsing %s: %w", covFile, err)
+		} else {
+			res.Actual = report.LineRate * 100
+		}
+		results = append(results, res)
+	}
+	return results
+}
@@ -0,0 +1,90 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func writeCoverage(t *testing.T, gitRoot, projectDir, lineRate string) {
+	t.Helper()
+	dir := filepath.Join(gitRoot, projectDir, "TestResults", "guid-123")
+	os.MkdirAll(dir, 0755)
+	xml := `<?xml version="1.0"?><coverage line-rate="` + lineRate + `"><packages/></coverage>`
+	if err := os.WriteFile(filepath.Join(dir, "coverage.cobertura.xml"), []byte(xml), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	newProj := &project.Project{Name: "New.Tests", Path: "tests/New.Tests/New.Tests.csproj", Dir: "tests/New.Tests"}
+	legacyProj := &project.Project{Name: "Legacy.Tests", Path: "legacy/Legacy.Tests/Legacy.Tests.csproj", Dir: "legacy/Legacy.Tests"}
+	exemptProj := &project.Project{Name: "Other.Tests", Path: "other/Other.Tests/Other.Tests.csproj", Dir: "other/Other.Tests"}
+
+	// Both projects have 60% coverage, but are held to different standards
+	writeCoverage(t, tmpDir, newProj.Dir, "0.6")
+	writeCoverage(t, tmpDir, legacyProj.Dir, "0.6")
+	writeCoverage(t, tmpDir, exemptProj.Dir, "0.1")
+
+	thresholds := []Threshold{
+		{Pattern: "legacy/**", Min: 40},
+		{Pattern: "*.Tests", Min: 80},
+		{Pattern: "Other.*", Min: 10}, // shadowed by *.Tests above
+	}
+
+	results := CheckThresholds(tmpDir, []*project.Project{newProj, legacyProj, exemptProj}, thresholds, nil)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if results[0].Project != newProj || results[0].Passed() {
+		t.Errorf("expected New.Tests to fail its 80%% threshold: %s", results[0])
+	}
+	if !strings.Contains(results[0].String(), "60.0% is below threshold 80.0%") {
+		t.Errorf("unexpected message: %s", results[0])
+	}
+	if results[1].Project != legacyProj || !results[1].Passed() {
+		t.Errorf("expected Legacy.Tests to meet its 40%% threshold: %s", results[1])
+	}
+	// The first matching pattern wins, so Other.Tests is held to 80%, not 10%
+	if results[2].Project != exemptProj || results[2].Passed() {
+		t.Errorf("expected Other.Tests to fail the 80%% threshold: %s", results[2])
+	}
+	if !strings.Contains(results[2].String(), "10.0% is below threshold 80.0%") {
+		t.Errorf("unexpected message: %s", results[2])
+	}
+
+	// Projects without a configured threshold are exempt
+	results = CheckThresholds(tmpDir, []*project.Project{exemptProj}, []Threshold{{Pattern: "legacy/**", Min: 40}}, nil)
+	if len(results) != 0 {
+		t.Errorf("expected exempt project to be skipped, got %v", results)
+	}
+}
+
+func TestCheckThresholds_MissingCoverage(t *testing.T) {
+	p := &project.Project{Name: "App.Tests", Path: "App.Tests/App.Tests.csproj", Dir: "App.Tests"}
+	results := CheckThresholds(t.TempDir(), []*project.Project{p}, []Threshold{{Pattern: "**", Min: 50}}, nil)
+	if len(results) != 1 || results[0].Passed() || results[0].Err == nil {
+		t.Errorf("expected missing coverage to fail, got %v", results)
+	}
+}
+
+func TestCheckThresholds_CoverageFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	p := &project.Project{Name: "App.Tests", Path: "App.Tests/App.Tests.csproj", Dir: "App.Tests"}
+
+	// A stale report in TestResults is ignored in favour of the run's own
+	writeCoverage(t, tmpDir, p.Dir, "0.1")
+	writeCoverage(t, tmpDir, "reports/App.Tests", "0.9")
+	covFile := filepath.Join(tmpDir, "reports", "App.Tests", "TestResults", "guid-123", "coverage.cobertura.xml")
+
+	results := CheckThresholds(tmpDir, []*project.Project{p}, []Threshold{{Pattern: "**", Min: 50}}, map[string]string{p.Path: covFile})
+	if len(results) != 1 || !results[0].Passed() {
+		t.Errorf("expected the given coverage file to be used, got %v", results)
+	}
+}
@@ -0,0 +1,126 @@
+package devplan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CriticalPath is the chain of dependent projects with the longest combined
+// duration. Even with unlimited workers a run can't finish sooner than Total.
+type CriticalPath struct {
+	// Projects along the chain, dependencies first
+	Projects []CriticalProject
+	// Total is the summed duration of the chain
+	Total time.Duration
+	// Sequential is the summed duration of all projects, i.e. a run on one worker
+	Sequential time.Duration
+	// Unknown lists projects without a recorded duration; they count as zero
+	Unknown []string
+}
+
+// CriticalProject is a project on the critical path.
+type CriticalProject struct {
+	Name     string
+	Duration time.Duration
+}
+
+// ComputeCriticalPath finds the longest dependency chain among projects,
+// weighting each project by its duration (keyed by project path). Dependencies
+// outside the project set are ignored, and cycles are broken arbitrarily.
+func ComputeCriticalPath(projects []*Project, forwardGraph map[string][]string, durations map[string]time.Duration) *CriticalPath {
+	cp := &CriticalPath{}
+	projectByPath := make(map[string]*Project)
+	for _, p := range projects {
+		projectByPath[p.Path] = p
+		if d, ok := durations[p.Path]; ok {
+			cp.Sequential += d
+		} else {
+			cp.Unknown = append(cp.Unknown, p.Name)
+		}
+	}
+
+	// finish[p] is the earliest p can complete: its own duration after the
+	// slowest chain of its dependencies
+	finish := make(map[string]time.Duration)
+	next := make(map[string]string) // slowest dependency of each project
+	visiting := make(map[string]bool)
+	var visit func(path string) time.Duration
+	visit = func(path string) time.Duration {
+		if d, ok := finish[path]; ok {
+			return d
+		}
+		if visiting[path] {
+			return 0
+		}
+		visiting[path] = true
+		var longest time.Duration
+		for _, dep := range forwardGraph[path] {
+			if projectByPath[dep] == nil {
+				continue
+			}
+			if d := visit(dep); d > longest || next[path] == "" {
+				longest = d
+				next[path] = dep
+			}
+		}
+		visiting[path] = false
+		finish[path] = longest + durations[path]
+		return finish[path]
+	}
+
+	var end string
+	for _, p := range projects {
+		if d := visit(p.Path); end == "" || d > finish[end] {
+			end = p.Path
+		}
+	}
+	if end == "" {
+		return cp
+	}
+	cp.Total = finish[end]
+
+	// Walk back from the last project to the start of the chain
+	seen := make(map[string]bool)
+	for path := end; path != "" && !seen[path]; path = next[path] {
+		seen[path] = true
+		cp.Projects = append([]CriticalProject{{Name: projectByPath[path].Name, Duration: durations[path]}}, cp.Projects...)
+	}
+	return cp
+}
+
+// Contains returns true if the named project is on the critical path.
+func (cp *CriticalPath) Contains(name string) bool {
+	for _, p := range cp.Projects {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Print outputs the critical path and the time estimates it implies.
+func (cp *CriticalPath) Print(w io.Writer, c Colors) {
+	fmt.Fprintf(w, "%s%sCritical Path%s (%d projects)\n", c.Bold, c.Red, c.Reset, len(cp.Projects))
+	fmt.Fprintln(w, strings.Repeat("─", 50))
+
+	nameWidth := 0
+	for _, p := range cp.Projects {
+		nameWidth = max(nameWidth, len(p.Name))
+	}
+	for i, p := range cp.Projects {
+		arrow := " "
+		if i > 0 {
+			arrow = "→"
+		}
+		fmt.Fprintf(w, "  %s%s%s %-*s %s%8s%s\n", c.Red, arrow, c.Reset, nameWidth, p.Name, c.Dim, p.Duration.Round(time.Millisecond), c.Reset)
+	}
+
+	fmt.Fprintf(w, "\nMinimal time with unlimited parallelism: %s%s%s\n", c.Bold, cp.Total.Round(time.Millisecond), c.Reset)
+	fmt.Fprintf(w, "%sSequential time (one worker): %s%s\n", c.Dim, cp.Sequential.Round(time.Millisecond), c.Reset)
+	if len(cp.Unknown) > 0 {
+		fmt.Fprintf(w, "%sNo recorded duration (counted as 0): %s%s\n", c.Yellow, strings.Join(cp.Unknown, ", "), c.Reset)
+	}
+	fmt.Fprintln(w)
+}
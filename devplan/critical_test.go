@@ -0,0 +1,96 @@
+package devplan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeCriticalPath(t *testing.T) {
+	// Core <- Api <- Api.Tests and Core <- Web.Tests; the Api chain is slower
+	projects := []*Project{
+		{Path: "core/core.csproj", Name: "Core"},
+		{Path: "api/api.csproj", Name: "Api"},
+		{Path: "api.tests/api.tests.csproj", Name: "Api.Tests"},
+		{Path: "web.tests/web.tests.csproj", Name: "Web.Tests"},
+	}
+	forwardGraph := map[string][]string{
+		"api/api.csproj":             {"core/core.csproj"},
+		"api.tests/api.tests.csproj": {"api/api.csproj"},
+		"web.tests/web.tests.csproj": {"core/core.csproj", "external/ext.csproj"},
+	}
+	durations := map[string]time.Duration{
+		"core/core.csproj":           10 * time.Second,
+		"api/api.csproj":             5 * time.Second,
+		"api.tests/api.tests.csproj": 25 * time.Second,
+		"web.tests/web.tests.csproj": 20 * time.Second,
+	}
+
+	cp := ComputeCriticalPath(projects, forwardGraph, durations)
+
+	var names []string
+	for _, p := range cp.Projects {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, " -> "); got != "Core -> Api -> Api.Tests" {
+		t.Errorf("critical path = %s, want Core -> Api -> Api.Tests", got)
+	}
+	if cp.Total != 40*time.Second {
+		t.Errorf("Total = %s, want 40s", cp.Total)
+	}
+	if cp.Sequential != 60*time.Second {
+		t.Errorf("Sequential = %s, want 60s", cp.Sequential)
+	}
+	if !cp.Contains("Api") || cp.Contains("Web.Tests") {
+		t.Error("Contains() disagrees with the computed path")
+	}
+}
+
+func TestComputeCriticalPath_UnknownDurationsAndCycles(t *testing.T) {
+	projects := []*Project{
+		{Path: "a/a.csproj", Name: "A"},
+		{Path: "b/b.csproj", Name: "B"},
+	}
+	forwardGraph := map[string][]string{
+		"a/a.csproj": {"b/b.csproj"},
+		"b/b.csproj": {"a/a.csproj"},
+	}
+
+	cp := ComputeCriticalPath(projects, forwardGraph, map[string]time.Duration{"a/a.csproj": time.Second})
+	if cp.Total != time.Second || len(cp.Projects) != 2 {
+		t.Errorf("unexpected path through a cycle: %+v", cp)
+	}
+	if len(cp.Unknown) != 1 || cp.Unknown[0] != "B" {
+		t.Errorf("Unknown = %v, want [B]", cp.Unknown)
+	}
+}
+
+func TestPlan_PrintCritical(t *testing.T) {
+	projects := []*Project{
+		{Path: "a/a.csproj", Name: "A"},
+		{Path: "b/b.csproj", Name: "B"},
+		{Path: "c/c.csproj", Name: "C"},
+	}
+	forwardGraph := map[string][]string{"b/b.csproj": {"a/a.csproj"}}
+	durations := map[string]time.Duration{
+		"a/a.csproj": 2 * time.Second,
+		"b/b.csproj": 3 * time.Second,
+		"c/c.csproj": 1 * time.Second,
+	}
+
+	plan := ComputePlan(projects, forwardGraph)
+	plan.Critical = ComputeCriticalPath(projects, forwardGraph, durations)
+
+	var buf bytes.Buffer
+	plan.Print(&buf, PlainColors())
+	plan.Critical.Print(&buf, PlainColors())
+	output := buf.String()
+
+	if !strings.Contains(output, "◆ B (critical path)") || strings.Contains(output, "◆ C") {
+		t.Errorf("expected only A and B highlighted:\n%s", output)
+	}
+	if !strings.Contains(output, "Minimal time with unlimited parallelism: 5s") {
+		t.Errorf("expected the 5s estimate:\n%s", output)
+	}
+}
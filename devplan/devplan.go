@@ -33,6 +33,8 @@ type Plan struct {
 	TotalProjects  int
 	HasCycleError  bool
 	StuckProjects  []StuckProject
+	// Critical, when set, marks the projects on the critical path in Print
+	Critical *CriticalPath
 }
 
 // StuckProject represents a project stuck in a circular dependency
@@ -180,6 +182,10 @@ func (p *Plan) Print(w io.Writer, c Colors) {
 			c.Bold, c.Green, wave.Number, c.Reset, c.Dim, len(wave.Projects), c.Reset)
 
 		for _, proj := range wave.Projects {
+			if p.Critical != nil && p.Critical.Contains(proj.Name) {
+				fmt.Fprintf(w, "  %s%s◆ %s%s %s(critical path)%s\n", c.Bold, c.Red, proj.Name, c.Reset, c.Dim, c.Reset)
+				continue
+			}
 			if len(proj.Dependencies) == 0 {
 				fmt.Fprintf(w, "  %s•%s %s\n", c.Green, c.Reset, proj.Name)
 			} else {
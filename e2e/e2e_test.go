@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -238,6 +239,20 @@ func TestDirFlag(t *testing.T) {
 	assertContains(t, r, "Core.Tests")
 }
 
+func TestProjectFlag(t *testing.T) {
+	t.Parallel()
+	dir := setupFixtureWithGit(t)
+
+	r := runCLI(t, binaryPath, dir, "build", "--project=Core.Tests", "--dry-run", "--full-build")
+	assertExit(t, r, 0)
+	assertContains(t, r, "Core.Tests.csproj")
+	assertNotContains(t, r, "Core.csproj")
+
+	r = runCLI(t, binaryPath, dir, "build", "--project=Nope", "--dry-run")
+	assertExit(t, r, 1)
+	assertContains(t, r, `--project "Nope" did not match any discovered project`)
+}
+
 // --- Build ---
 
 func TestBasicBuild(t *testing.T) {
@@ -340,6 +355,56 @@ func TestCoverageBuild(t *testing.T) {
 	assertExit(t, r, 0)
 }
 
+func TestCoverageBuildIsolate(t *testing.T) {
+	t.Parallel()
+	needsDotnet(t)
+	dir := setupFixtureWithGit(t)
+
+	readMaps := func() map[string]string {
+		maps := make(map[string]string)
+		files, _ := filepath.Glob(filepath.Join(dir, ".donotnet", "*.testcoverage.json"))
+		for _, f := range files {
+			var m struct {
+				TestToFiles map[string][]string `json:"test_to_files"`
+			}
+			data, _ := os.ReadFile(f)
+			json.Unmarshal(data, &m)
+			normalized, _ := json.Marshal(m.TestToFiles)
+			maps[filepath.Base(f)] = string(normalized)
+			os.Remove(f)
+		}
+		return maps
+	}
+
+	start := time.Now()
+	r := runCLI(t, binaryPath, dir, "coverage", "build", "--granularity=method")
+	assertExit(t, r, 0)
+	sequential := time.Since(start)
+	sequentialMaps := readMaps()
+
+	start = time.Now()
+	r = runCLI(t, binaryPath, dir, "coverage", "build", "--granularity=method", "--isolate", "-j", "4")
+	assertExit(t, r, 0)
+	isolated := time.Since(start)
+	isolatedMaps := readMaps()
+
+	t.Logf("sequential: %s, isolated: %s", sequential.Round(time.Millisecond), isolated.Round(time.Millisecond))
+
+	if len(isolatedMaps) == 0 || len(isolatedMaps) != len(sequentialMaps) {
+		t.Fatalf("expected the same coverage maps, got %d sequential and %d isolated", len(sequentialMaps), len(isolatedMaps))
+	}
+	for name, m := range sequentialMaps {
+		if isolatedMaps[name] != m {
+			t.Errorf("%s differs:\nsequential: %s\nisolated:   %s", name, m, isolatedMaps[name])
+		}
+	}
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "*", "obj", "donotnet-isolate"))
+	if len(leftovers) > 0 {
+		t.Errorf("isolated output copies were not cleaned up: %v", leftovers)
+	}
+}
+
 // --- Did-you-mean for misspelled flags ---
 
 func TestFlagSuggestion(t *testing.T) {
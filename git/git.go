@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -18,9 +19,16 @@ func FindRoot() (string, error) {
 	return FindRootFrom(dir)
 }
 
-// FindRootFrom finds the root of the git repository by walking up from the given directory.
+// FindRootFrom finds the root of the git repository containing the given directory.
+// In a linked worktree this is the worktree, not the main checkout. It asks git
+// first, and walks up looking for .git (a directory, or a file in worktrees and
+// submodules) when git is unavailable.
 func FindRootFrom(dir string) (string, error) {
 	dir = filepath.Clean(dir)
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if root := strings.TrimSpace(string(out)); err == nil && root != "" {
+		return filepath.Clean(filepath.FromSlash(root)), nil
+	}
 	for {
 		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
 			return dir, nil
@@ -43,36 +51,182 @@ func GetCommit(gitRoot string) string {
 	return strings.TrimSpace(string(out))
 }
 
+// GetBranch returns the name of the checked out branch, or "" when HEAD is
+// detached or gitRoot is not a git checkout.
+func GetBranch(gitRoot string) string {
+	cmd := exec.Command("git", "-C", gitRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// ListBranches returns the names of the local branches.
+func ListBranches(gitRoot string) ([]string, error) {
+	cmd := exec.Command("git", "-C", gitRoot, "branch", "--list", "--format=%(refname:short)")
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			branches = append(branches, line)
+		}
+	}
+	return branches, nil
+}
+
+// ChangeStatus is the kind of change git reports for a file.
+type ChangeStatus byte
+
+const (
+	ChangeAdded    ChangeStatus = 'A'
+	ChangeModified ChangeStatus = 'M'
+	ChangeDeleted  ChangeStatus = 'D'
+	ChangeRenamed  ChangeStatus = 'R'
+)
+
+// FileChange is a changed file relative to git root. OldPath is set for
+// renames only.
+type FileChange struct {
+	Status  ChangeStatus
+	Path    string
+	OldPath string
+}
+
+// ChangedPaths returns the current path of each change.
+func ChangedPaths(changes []FileChange) []string {
+	var files []string
+	for _, c := range changes {
+		files = append(files, c.Path)
+	}
+	return files
+}
+
 // GetDirtyFiles returns a list of dirty (uncommitted) files relative to git root.
+// Renamed files are listed under their new path.
 func GetDirtyFiles(gitRoot string) []string {
+	return ChangedPaths(GetDirtyFileChanges(gitRoot))
+}
+
+// GetDirtyFileChanges returns the dirty (uncommitted) files relative to git
+// root along with how each was changed.
+func GetDirtyFileChanges(gitRoot string) []FileChange {
 	cmd := exec.Command("git", "-C", gitRoot, "status", "--porcelain")
 	out, err := cmd.Output()
 	if err != nil {
 		return nil
 	}
 
-	var files []string
-	for _, line := range strings.Split(string(out), "\n") {
+	var changes []FileChange
+	for _, c := range parsePorcelain(string(out)) {
+		if c.Status == ChangeAdded && strings.HasSuffix(c.Path, "/") {
+			changes = append(changes, untrackedDirChanges(gitRoot, c)...)
+			continue
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// untrackedDirChanges drops nested checkouts (e.g. worktrees created inside
+// this one) from an untracked directory entry: their changes are not ours.
+// git reports them as an untracked directory, or hides them inside one when
+// it collapses a parent, which is then listed file by file instead.
+func untrackedDirChanges(gitRoot string, dir FileChange) []FileChange {
+	if IsCheckout(filepath.Join(gitRoot, dir.Path)) {
+		return nil
+	}
+	out, err := exec.Command("git", "-C", gitRoot, "status", "--porcelain", "--untracked-files=all", "--", dir.Path).Output()
+	if err != nil {
+		return []FileChange{dir}
+	}
+	var changes []FileChange
+	nested := false
+	for _, c := range parsePorcelain(string(out)) {
+		if strings.HasSuffix(c.Path, "/") && IsCheckout(filepath.Join(gitRoot, c.Path)) {
+			nested = true
+			continue
+		}
+		changes = append(changes, c)
+	}
+	if !nested {
+		return []FileChange{dir}
+	}
+	return changes
+}
+
+// IsCheckout reports whether dir is the root of a git checkout: a repository,
+// linked worktree or submodule.
+func IsCheckout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// parsePorcelain parses `git status --porcelain` output.
+func parsePorcelain(out string) []FileChange {
+	var changes []FileChange
+	for _, line := range strings.Split(out, "\n") {
 		if len(line) < 3 {
 			continue
 		}
 		// Format: XY filename or XY orig -> renamed
-		file := strings.TrimSpace(line[3:])
+		xy := line[:2]
+		c := FileChange{Status: ChangeModified, Path: strings.TrimSpace(line[3:])}
 		// Handle renames: "old -> new"
-		if idx := strings.Index(file, " -> "); idx >= 0 {
-			file = file[idx+4:]
+		if idx := strings.Index(c.Path, " -> "); idx >= 0 {
+			c.Status = ChangeRenamed
+			c.OldPath = c.Path[:idx]
+			c.Path = c.Path[idx+4:]
+		} else if strings.ContainsRune(xy, 'D') {
+			c.Status = ChangeDeleted
+		} else if xy == "??" || strings.ContainsRune(xy, 'A') {
+			c.Status = ChangeAdded
 		}
-		if file != "" {
-			files = append(files, file)
+		if c.Path != "" {
+			changes = append(changes, c)
 		}
 	}
-	return files
+	return changes
 }
 
 // GetChangedFiles returns files changed compared to a ref (e.g., "main", "HEAD~3").
+// Renamed files are listed under their new path.
 // Returns an error if the ref is invalid.
 func GetChangedFiles(gitRoot, ref string) ([]string, error) {
-	cmd := exec.Command("git", "-C", gitRoot, "diff", "--name-only", ref)
+	changes, err := GetFileChanges(gitRoot, ref)
+	if err != nil {
+		return nil, err
+	}
+	return ChangedPaths(changes), nil
+}
+
+// GetMergeBase returns the merge base of HEAD and ref (e.g. "main").
+// Returns an error if the ref is invalid or has no history in common with HEAD.
+func GetMergeBase(gitRoot, ref string) (string, error) {
+	out, err := exec.Command("git", "-C", gitRoot, "merge-base", "HEAD", ref).Output()
+	if err != nil {
+		// Check if ref exists
+		checkCmd := exec.Command("git", "-C", gitRoot, "rev-parse", "--verify", ref)
+		if checkErr := checkCmd.Run(); checkErr != nil {
+			return "", fmt.Errorf("unknown git ref: %s", ref)
+		}
+		return "", fmt.Errorf("no merge base between HEAD and %s", ref)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetFileChanges returns the files changed compared to a ref along with how
+// each was changed, detecting renames.
+// Returns an error if the ref is invalid.
+func GetFileChanges(gitRoot, ref string) ([]FileChange, error) {
+	cmd := exec.Command("git", "-C", gitRoot, "diff", "--name-status", "-M", "-z", ref)
 	out, err := cmd.Output()
 	if err != nil {
 		// Check if ref exists
@@ -82,13 +236,101 @@ func GetChangedFiles(gitRoot, ref string) ([]string, error) {
 		}
 		return nil, err
 	}
+	return parseNameStatus(string(out)), nil
+}
 
-	var files []string
-	for _, line := range strings.Split(string(out), "\n") {
-		file := strings.TrimSpace(line)
-		if file != "" {
-			files = append(files, file)
+// ChangedLines returns the line numbers added or modified in each file
+// compared to a ref, as paths relative to gitRoot. Working tree changes are
+// included; deleted files and removed lines are not.
+// Returns an error if the ref is invalid.
+func ChangedLines(gitRoot, ref string) (map[string][]int, error) {
+	cmd := exec.Command("git", "-C", gitRoot, "-c", "core.quotePath=false",
+		"diff", "--unified=0", "--no-color", "--no-ext-diff", "-M", ref)
+	out, err := cmd.Output()
+	if err != nil {
+		checkCmd := exec.Command("git", "-C", gitRoot, "rev-parse", "--verify", ref)
+		if checkErr := checkCmd.Run(); checkErr != nil {
+			return nil, fmt.Errorf("unknown git ref: %s", ref)
+		}
+		return nil, err
+	}
+	return parseDiffLines(string(out)), nil
+}
+
+// parseDiffLines parses `git diff --unified=0` output into the new-side line
+// numbers of each hunk, keyed by the file's new path.
+func parseDiffLines(out string) map[string][]int {
+	changed := make(map[string][]int)
+	path := ""
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			path = strings.TrimPrefix(line, "+++ ")
+			if unquoted, err := strconv.Unquote(path); err == nil {
+				path = unquoted
+			}
+			if path == "/dev/null" {
+				path = ""
+			} else {
+				path = strings.TrimPrefix(path, "b/")
+			}
+		case strings.HasPrefix(line, "@@ ") && path != "":
+			// @@ -old[,n] +new[,n] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				continue
+			}
+			start, count := fields[2][1:], "1"
+			if i := strings.IndexByte(start, ','); i >= 0 {
+				start, count = start[:i], start[i+1:]
+			}
+			first, err1 := strconv.Atoi(start)
+			n, err2 := strconv.Atoi(count)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			for l := first; l < first+n; l++ {
+				changed[path] = append(changed[path], l)
+			}
+		}
+	}
+	return changed
+}
+
+// parseNameStatus parses `git diff --name-status -z` output: a status
+// (e.g. "M", "R087") followed by one path, or two for renames and copies,
+// all NUL-separated. Copies are reported as additions of the new path and
+// type changes as modifications.
+func parseNameStatus(out string) []FileChange {
+	fields := strings.Split(out, "\x00")
+	var changes []FileChange
+	for i := 0; i+1 < len(fields); i += 2 {
+		status := fields[i]
+		if status == "" {
+			continue
+		}
+		c := FileChange{Status: ChangeModified, Path: fields[i+1]}
+		switch status[0] {
+		case 'R', 'C':
+			if i+2 >= len(fields) {
+				return changes
+			}
+			c.Path = fields[i+2]
+			if status[0] == 'R' {
+				c.Status = ChangeRenamed
+				c.OldPath = fields[i+1]
+			} else {
+				c.Status = ChangeAdded
+			}
+			i++
+		case 'A':
+			c.Status = ChangeAdded
+		case 'D':
+			c.Status = ChangeDeleted
+		}
+		if c.Path != "" {
+			changes = append(changes, c)
 		}
 	}
-	return files, nil
+	return changes
 }
@@ -2,7 +2,9 @@ package git
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -39,6 +41,29 @@ func TestGetCommit(t *testing.T) {
 	}
 }
 
+func TestGetBranch(t *testing.T) {
+	root, err := FindRoot()
+	if err != nil {
+		t.Skip("Not in a git repository")
+	}
+
+	branch := GetBranch(root)
+	if branch == "" {
+		t.Skip("HEAD is detached")
+	}
+	branches, err := ListBranches(root)
+	if err != nil {
+		t.Fatalf("ListBranches() failed: %v", err)
+	}
+	found := false
+	for _, b := range branches {
+		found = found || b == branch
+	}
+	if !found {
+		t.Errorf("ListBranches() = %v, want it to contain the current branch %q", branches, branch)
+	}
+}
+
 func TestGetDirtyFiles(t *testing.T) {
 	root, err := FindRoot()
 	if err != nil {
@@ -71,3 +96,220 @@ func TestGetChangedFiles(t *testing.T) {
 		t.Error("GetChangedFiles(invalid-ref) should have failed")
 	}
 }
+
+func TestParseDiffLines(t *testing.T) {
+	out := `diff --git a/src/Foo.cs b/src/Foo.cs
+index 1111111..2222222 100644
+--- a/src/Foo.cs
++++ b/src/Foo.cs
+@@ -3 +3 @@ namespace App
+-old
++new
+@@ -10,0 +11,3 @@ class Foo
++a
++b
++c
+@@ -20,2 +23,0 @@ class Foo
+-gone
+-gone
+diff --git a/Old.cs b/Old.cs
+deleted file mode 100644
+--- a/Old.cs
++++ /dev/null
+@@ -1,2 +0,0 @@
+-x
+-y
+diff --git a/New File.cs b/New File.cs
+new file mode 100644
+--- /dev/null
++++ "b/New\tFile.cs"
+@@ -0,0 +1,2 @@
++x
++y
+`
+	want := map[string][]int{
+		"src/Foo.cs":   {3, 11, 12, 13},
+		"New\tFile.cs": {1, 2},
+	}
+	if got := parseDiffLines(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseDiffLines() = %v, want %v", got, want)
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := "M\x00src/App/Program.cs\x00" +
+		"A\x00src/App/New.cs\x00" +
+		"D\x00src/Old/Old.csproj\x00" +
+		"R087\x00src/Lib/Name.cs\x00src/Other/Name.cs\x00" +
+		"C100\x00src/Lib/A.cs\x00src/Lib/B.cs\x00" +
+		"T\x00src/App/link\x00"
+
+	want := []FileChange{
+		{Status: ChangeModified, Path: "src/App/Program.cs"},
+		{Status: ChangeAdded, Path: "src/App/New.cs"},
+		{Status: ChangeDeleted, Path: "src/Old/Old.csproj"},
+		{Status: ChangeRenamed, Path: "src/Other/Name.cs", OldPath: "src/Lib/Name.cs"},
+		{Status: ChangeAdded, Path: "src/Lib/B.cs"},
+		{Status: ChangeModified, Path: "src/App/link"},
+	}
+	if got := parseNameStatus(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNameStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParsePorcelain(t *testing.T) {
+	out := " M src/App/Program.cs\n" +
+		"?? src/App/New.cs\n" +
+		"A  src/App/Staged.cs\n" +
+		" D src/Old/Old.cs\n" +
+		"R  src/Lib/Name.cs -> src/Other/Name.cs\n"
+
+	want := []FileChange{
+		{Status: ChangeModified, Path: "src/App/Program.cs"},
+		{Status: ChangeAdded, Path: "src/App/New.cs"},
+		{Status: ChangeAdded, Path: "src/App/Staged.cs"},
+		{Status: ChangeDeleted, Path: "src/Old/Old.cs"},
+		{Status: ChangeRenamed, Path: "src/Other/Name.cs", OldPath: "src/Lib/Name.cs"},
+	}
+	if got := parsePorcelain(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePorcelain() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseJJSummary(t *testing.T) {
+	out := "M src/App/Program.cs\n" +
+		"A src/App/New.cs\n" +
+		"D src/Old/Old.csproj\n" +
+		"R src/{Lib => Other}/Name.cs\n" +
+		"R src/App/{Old.cs => Renamed.cs}\n" +
+		"R {src => }/Moved.cs\n" +
+		"C src/Lib/{A.cs => B.cs}\n"
+
+	want := []FileChange{
+		{Status: ChangeModified, Path: "src/App/Program.cs"},
+		{Status: ChangeAdded, Path: "src/App/New.cs"},
+		{Status: ChangeDeleted, Path: "src/Old/Old.csproj"},
+		{Status: ChangeRenamed, Path: "src/Other/Name.cs", OldPath: "src/Lib/Name.cs"},
+		{Status: ChangeRenamed, Path: "src/App/Renamed.cs", OldPath: "src/App/Old.cs"},
+		{Status: ChangeRenamed, Path: "Moved.cs", OldPath: "src/Moved.cs"},
+		{Status: ChangeAdded, Path: "src/Lib/B.cs"},
+	}
+	if got := parseJJSummary(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseJJSummary() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewBackend(t *testing.T) {
+	root := t.TempDir()
+
+	for name, want := range map[string]string{"": BackendGit, BackendAuto: BackendGit, BackendGit: BackendGit, BackendJJ: BackendJJ} {
+		b, err := NewBackend(root, name)
+		if err != nil {
+			t.Fatalf("NewBackend(%q) failed: %v", name, err)
+		}
+		if b.Name() != want {
+			t.Errorf("NewBackend(%q) = %s, want %s", name, b.Name(), want)
+		}
+	}
+	if _, err := NewBackend(root, "svn"); err == nil {
+		t.Error("NewBackend(svn) should fail")
+	}
+}
+
+// runGit runs git in dir for test fixtures.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func TestWorktree(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	main, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, main, "init", "-q")
+	os.WriteFile(filepath.Join(main, "App.cs"), []byte("class App {}"), 0644)
+	runGit(t, main, "add", ".")
+	runGit(t, main, "commit", "-q", "-m", "init")
+
+	// A linked worktree nested inside the main checkout, where .git is a file
+	worktree := filepath.Join(main, "wt", "feature")
+	runGit(t, main, "worktree", "add", "-q", "-b", "feature", worktree)
+	os.MkdirAll(filepath.Join(worktree, "src"), 0755)
+
+	root, err := FindRootFrom(filepath.Join(worktree, "src"))
+	if err != nil {
+		t.Fatalf("FindRootFrom() failed: %v", err)
+	}
+	if root != worktree {
+		t.Errorf("FindRootFrom() = %q, want the worktree %q", root, worktree)
+	}
+
+	os.WriteFile(filepath.Join(worktree, "App.cs"), []byte("class App { int x; }"), 0644)
+	if files := GetDirtyFiles(worktree); !reflect.DeepEqual(files, []string{"App.cs"}) {
+		t.Errorf("GetDirtyFiles(worktree) = %v, want [App.cs]", files)
+	}
+	os.WriteFile(filepath.Join(main, "wt", "Notes.cs"), []byte("class Notes {}"), 0644)
+	if files := GetDirtyFiles(main); !reflect.DeepEqual(files, []string{"wt/Notes.cs"}) {
+		t.Errorf("GetDirtyFiles(main) = %v, want [wt/Notes.cs]: the nested worktree isn't part of the main checkout", files)
+	}
+	if files, err := GetChangedFiles(worktree, "HEAD"); err != nil || !reflect.DeepEqual(files, []string{"App.cs"}) {
+		t.Errorf("GetChangedFiles(worktree, HEAD) = %v, %v, want [App.cs]", files, err)
+	}
+}
+
+func TestRefChanges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	root := t.TempDir()
+	runGit(t, root, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(root, "App.cs"), []byte("class App {}"), 0644)
+	runGit(t, root, "add", ".")
+	runGit(t, root, "commit", "-q", "-m", "init")
+
+	runGit(t, root, "checkout", "-q", "-b", "feature")
+	os.WriteFile(filepath.Join(root, "Feature.cs"), []byte("class Feature {}"), 0644)
+	runGit(t, root, "add", ".")
+	runGit(t, root, "commit", "-q", "-m", "feature")
+
+	// main moves on after the branch forked
+	runGit(t, root, "checkout", "-q", "main")
+	os.WriteFile(filepath.Join(root, "Upstream.cs"), []byte("class Upstream {}"), 0644)
+	runGit(t, root, "add", ".")
+	runGit(t, root, "commit", "-q", "-m", "upstream")
+	runGit(t, root, "checkout", "-q", "feature")
+
+	changes, _, err := RefChanges(Git{}, root, "main", RefModeMergeBase)
+	if err != nil {
+		t.Fatalf("RefChanges(merge-base) failed: %v", err)
+	}
+	if files := ChangedPaths(changes); !reflect.DeepEqual(files, []string{"Feature.cs"}) {
+		t.Errorf("RefChanges(merge-base) = %v, want [Feature.cs]", files)
+	}
+
+	changes, base, err := RefChanges(Git{}, root, "main", RefModeDirect)
+	if err != nil {
+		t.Fatalf("RefChanges(direct) failed: %v", err)
+	}
+	if base != "main" {
+		t.Errorf("RefChanges(direct) compared against %q, want main", base)
+	}
+	if files := ChangedPaths(changes); !reflect.DeepEqual(files, []string{"Feature.cs", "Upstream.cs"}) {
+		t.Errorf("RefChanges(direct) = %v, want [Feature.cs Upstream.cs]", files)
+	}
+
+	if _, _, err := RefChanges(Git{}, root, "nonexistent-ref", RefModeMergeBase); err == nil || err.Error() != "unknown git ref: nonexistent-ref" {
+		t.Errorf("RefChanges(unknown ref) error = %v, want unknown git ref", err)
+	}
+	if _, _, err := RefChanges(Git{}, root, "main", "three-dot"); err == nil {
+		t.Error("RefChanges() with an unknown mode should fail")
+	}
+}
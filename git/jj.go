@@ -0,0 +1,109 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// JJ is the Backend for Jujutsu repositories colocated with git. jj snapshots
+// the working copy into the @ commit whenever it runs, so "dirty" files are
+// the changes in @.
+type JJ struct{}
+
+// Name implements Backend.
+func (JJ) Name() string { return BackendJJ }
+
+// DirtyFileChanges implements Backend: the changes in the working-copy commit.
+func (JJ) DirtyFileChanges(root string) []FileChange {
+	out, err := jjCommand(root, "diff", "--summary", "-r", "@").Output()
+	if err != nil {
+		return nil
+	}
+	return parseJJSummary(string(out))
+}
+
+// FileChanges implements Backend: the changes between revision ref and the
+// working copy. Returns an error if the revision is invalid.
+func (JJ) FileChanges(root, ref string) ([]FileChange, error) {
+	out, err := jjCommand(root, "diff", "--summary", "--from", ref, "--to", "@").Output()
+	if err != nil {
+		// Check if the revision exists
+		if checkErr := jjCommand(root, "log", "--no-graph", "-r", ref, "-T", `""`).Run(); checkErr != nil {
+			return nil, fmt.Errorf("unknown jj revision: %s", ref)
+		}
+		return nil, err
+	}
+	return parseJJSummary(string(out)), nil
+}
+
+// MergeBase implements Backend: the latest common ancestor of the
+// working-copy commit and revision ref.
+func (JJ) MergeBase(root, ref string) (string, error) {
+	out, err := jjCommand(root, "log", "--no-graph", "-r", "heads(::@ & ::("+ref+"))", "-T", `commit_id ++ "\n"`).Output()
+	if err != nil {
+		return "", fmt.Errorf("unknown jj revision: %s", ref)
+	}
+	base, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if base == "" {
+		return "", fmt.Errorf("no merge base between @ and %s", ref)
+	}
+	return base, nil
+}
+
+// jjCommand runs jj in root, where it prints paths relative to root.
+func jjCommand(root string, args ...string) *exec.Cmd {
+	cmd := exec.Command("jj", append([]string{"--no-pager", "--color=never"}, args...)...)
+	cmd.Dir = root
+	return cmd
+}
+
+// parseJJSummary parses `jj diff --summary` output: a status letter and a
+// path per line. Renames and copies are written as "dir/{old => new}.cs".
+// Copies are reported as additions of the new path.
+func parseJJSummary(out string) []FileChange {
+	var changes []FileChange
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if len(line) < 3 || line[1] != ' ' {
+			continue
+		}
+		path := filepath.ToSlash(line[2:])
+		c := FileChange{Status: ChangeModified, Path: path}
+		switch line[0] {
+		case 'A':
+			c.Status = ChangeAdded
+		case 'D':
+			c.Status = ChangeDeleted
+		case 'R', 'C':
+			oldPath, newPath := splitJJRename(path)
+			c.Path = newPath
+			if line[0] == 'R' {
+				c.Status = ChangeRenamed
+				c.OldPath = oldPath
+			} else {
+				c.Status = ChangeAdded
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// splitJJRename expands "src/{a => b}/X.cs" into "src/a/X.cs" and
+// "src/b/X.cs". Either side of the arrow may be empty.
+func splitJJRename(path string) (oldPath, newPath string) {
+	open := strings.Index(path, "{")
+	end := strings.LastIndex(path, "}")
+	arrow := strings.Index(path, " => ")
+	if open < 0 || end < open || arrow < open || arrow > end {
+		return path, path
+	}
+	prefix, suffix := path[:open], path[end+1:]
+	join := func(middle string) string {
+		p := strings.ReplaceAll(prefix+middle+suffix, "//", "/")
+		return strings.TrimPrefix(p, "/")
+	}
+	return join(path[open+1 : arrow]), join(path[arrow+4 : end])
+}
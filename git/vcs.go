@@ -0,0 +1,96 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Backend names accepted by NewBackend.
+const (
+	BackendAuto = "auto" // jj when the repository has a .jj directory, else git
+	BackendGit  = "git"
+	BackendJJ   = "jj"
+)
+
+// Backend lists the files changed in a working copy. Content hashing and the
+// cache don't depend on the VCS, so this is all that varies between them.
+type Backend interface {
+	// Name returns the backend name, e.g. "git".
+	Name() string
+	// DirtyFileChanges returns the uncommitted changes relative to root.
+	DirtyFileChanges(root string) []FileChange
+	// FileChanges returns the changes of the working copy compared to ref.
+	FileChanges(root, ref string) ([]FileChange, error)
+	// MergeBase returns the best common ancestor of the current commit and
+	// ref, as a revision FileChanges accepts.
+	MergeBase(root, ref string) (string, error)
+}
+
+// Ref modes accepted by RefChanges.
+const (
+	RefModeMergeBase = "merge-base" // changes since the branch forked from ref, like git diff ref...
+	RefModeDirect    = "direct"     // changes vs the tip of ref, including ones ref gained since
+)
+
+// RefChanges returns the changes of the working copy compared to ref: with
+// RefModeMergeBase (the default for an empty mode), to the merge base of the
+// current commit and ref, so commits ref gained since the branch forked don't
+// count; with RefModeDirect, to ref itself. Also returns the revision diffed
+// against.
+func RefChanges(b Backend, root, ref, mode string) ([]FileChange, string, error) {
+	base := ref
+	switch mode {
+	case "", RefModeMergeBase:
+		var err error
+		if base, err = b.MergeBase(root, ref); err != nil {
+			return nil, "", err
+		}
+	case RefModeDirect:
+	default:
+		return nil, "", fmt.Errorf("unknown ref mode %q (expected merge-base or direct)", mode)
+	}
+	changes, err := b.FileChanges(root, base)
+	return changes, base, err
+}
+
+// NewBackend returns the backend named name for the repository at root.
+// An empty name means BackendAuto.
+func NewBackend(root, name string) (Backend, error) {
+	switch name {
+	case "", BackendAuto:
+		if _, err := os.Stat(filepath.Join(root, ".jj")); err == nil {
+			if _, err := exec.LookPath("jj"); err == nil {
+				return JJ{}, nil
+			}
+		}
+		return Git{}, nil
+	case BackendGit:
+		return Git{}, nil
+	case BackendJJ:
+		return JJ{}, nil
+	}
+	return nil, fmt.Errorf("unknown VCS %q (expected auto, git or jj)", name)
+}
+
+// Git is the git Backend.
+type Git struct{}
+
+// Name implements Backend.
+func (Git) Name() string { return BackendGit }
+
+// DirtyFileChanges implements Backend using GetDirtyFileChanges.
+func (Git) DirtyFileChanges(root string) []FileChange {
+	return GetDirtyFileChanges(root)
+}
+
+// FileChanges implements Backend using GetFileChanges.
+func (Git) FileChanges(root, ref string) ([]FileChange, error) {
+	return GetFileChanges(root, ref)
+}
+
+// MergeBase implements Backend using GetMergeBase.
+func (Git) MergeBase(root, ref string) (string, error) {
+	return GetMergeBase(root, ref)
+}
@@ -0,0 +1,79 @@
+package project
+
+import "sort"
+
+// FindCycles returns the dependency cycles in a forward dependency graph,
+// using Tarjan's strongly connected components algorithm. Each cycle lists
+// the project paths in the loop, sorted; a project referencing itself is a
+// cycle of one. Cycles are ordered by their first path, so output is stable.
+func FindCycles(forwardGraph map[string][]string) [][]string {
+	// Visit nodes in a stable order
+	nodes := make([]string, 0, len(forwardGraph))
+	for n := range forwardGraph {
+		nodes = append(nodes, n)
+	}
+	sort.Strings(nodes)
+
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var cycles [][]string
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		indices[v] = index
+		lowlink[v] = index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range forwardGraph[v] {
+			if _, seen := indices[w]; !seen {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], indices[w])
+			}
+		}
+
+		if lowlink[v] != indices[v] {
+			return
+		}
+
+		// v is the root of a component: pop it off the stack
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || referencesSelf(v, forwardGraph) {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := indices[n]; !seen {
+			strongConnect(n)
+		}
+	}
+
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+func referencesSelf(path string, forwardGraph map[string][]string) bool {
+	for _, dep := range forwardGraph[path] {
+		if dep == path {
+			return true
+		}
+	}
+	return false
+}
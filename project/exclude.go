@@ -0,0 +1,99 @@
+package project
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ExcludeFileName is the name of the file inside the .donotnet config
+// directory listing project exclusion patterns, one per line.
+const ExcludeFileName = "exclude"
+
+// ParseExcludePatterns splits a comma-separated list of glob patterns.
+func ParseExcludePatterns(s string) []string {
+	var patterns []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			patterns = append(patterns, part)
+		}
+	}
+	return patterns
+}
+
+// LoadExcludeFile reads exclusion patterns from path.
+// Blank lines and lines starting with # are ignored.
+// A missing file is not an error and yields no patterns.
+func LoadExcludeFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, scanner.Err()
+}
+
+// IsExcluded returns true if p matches any of the glob patterns.
+// Patterns are matched against the project name and against its path
+// relative to the git root (using forward slashes). "**" matches any
+// number of path segments, so "legacy/**" excludes everything below legacy/.
+func IsExcluded(p *Project, patterns []string) bool {
+	return MatchesAny(p, patterns)
+}
+
+// MatchesAny reports whether p's name or slash-separated relative path
+// matches any of the glob patterns (see IsExcluded).
+func MatchesAny(p *Project, patterns []string) bool {
+	relPath := filepath.ToSlash(p.Path)
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		if MatchGlob(pattern, p.Name) || MatchGlob(pattern, relPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether name matches the slash-separated glob pattern.
+// Each segment is matched with path.Match; a "**" segment matches zero or
+// more segments.
+func MatchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(rest, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
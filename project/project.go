@@ -8,8 +8,16 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/term"
+	ignore "github.com/sabhiram/go-gitignore"
 )
 
+// IgnoreFileName is the gitignore-syntax file at the git root listing
+// .csproj paths that should never be discovered.
+const IgnoreFileName = ".donotnetignore"
+
 // Project represents a parsed .csproj file.
 type Project struct {
 	Path              string   // relative path from git root
@@ -28,14 +36,27 @@ type Solution struct {
 }
 
 var projectRefRegex = regexp.MustCompile(`<ProjectReference\s+Include="([^"]+)"`)
+var isTestProjectRegex = regexp.MustCompile(`(?i)<IsTestProject>\s*(true|false)\s*</IsTestProject>`)
+var importRegex = regexp.MustCompile(`<Import\b[^>]*\bProject="([^"]+)"`)
 var packageRefRegex = regexp.MustCompile(`<PackageReference\s+Include="([^"]+)"`)
 var slnProjectRegex = regexp.MustCompile(`Project\("[^"]+"\)\s*=\s*"[^"]+",\s*"([^"]+\.csproj)"`)
 
 // Discover walks scanRoot once to find all .csproj and .sln files.
-func Discover(scanRoot, gitRoot string) ([]*Project, []*Solution, error) {
+// Git submodules (see LoadSubmodules) are skipped unless includeSubmodules
+// is set, since their projects have their own lifecycle.
+func Discover(scanRoot, gitRoot string, includeSubmodules bool) ([]*Project, []*Solution, error) {
 	var projects []*Project
 	var solutions []*Solution
 
+	var ignored *ignore.GitIgnore
+	if gi, err := ignore.CompileIgnoreFile(filepath.Join(gitRoot, IgnoreFileName)); err == nil {
+		ignored = gi
+	}
+	skipped := 0
+
+	submodules := SubmoduleDirs(gitRoot)
+	skippedSubmodules := 0
+
 	err := filepath.WalkDir(scanRoot, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // skip errors
@@ -45,10 +66,23 @@ func Discover(scanRoot, gitRoot string) ([]*Project, []*Solution, error) {
 			if name == ".git" || name == "node_modules" || name == "bin" || name == "obj" || name == ".vs" {
 				return filepath.SkipDir
 			}
+			if submodules[path] {
+				if !includeSubmodules {
+					skippedSubmodules++
+					return filepath.SkipDir
+				}
+			} else if path != scanRoot && git.IsCheckout(path) {
+				// Another checkout nested in this one, e.g. a linked worktree
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if strings.HasSuffix(path, ".csproj") {
 			relPath, _ := filepath.Rel(gitRoot, path)
+			if ignored != nil && ignored.MatchesPath(relPath) {
+				skipped++
+				return nil
+			}
 			p, err := Parse(path, relPath)
 			if err != nil {
 				return nil
@@ -83,6 +117,13 @@ func Discover(scanRoot, gitRoot string) ([]*Project, []*Solution, error) {
 		return nil
 	})
 
+	if skipped > 0 {
+		term.Verbose("Skipped %d project(s) matched by %s", skipped, IgnoreFileName)
+	}
+	if skippedSubmodules > 0 {
+		term.Verbose("Skipped %d git submodule(s) (use --include-submodules to scan them)", skippedSubmodules)
+	}
+
 	return projects, solutions, err
 }
 
@@ -96,11 +137,14 @@ func Parse(path, relPath string) (*Project, error) {
 	dir := filepath.Dir(path)
 	name := strings.TrimSuffix(filepath.Base(path), ".csproj")
 
-	// Check if it's a test project
+	// Check if it's a test project. An explicit <IsTestProject> overrides the
+	// name, e.g. for test helper libraries named SomethingTests.
 	isTest := strings.HasSuffix(name, ".Tests") ||
 		strings.HasSuffix(name, ".Test") ||
-		strings.HasSuffix(name, "Tests") ||
-		strings.Contains(string(content), "<IsTestProject>true</IsTestProject>")
+		strings.HasSuffix(name, "Tests")
+	if m := isTestProjectRegex.FindStringSubmatch(string(content)); m != nil {
+		isTest = strings.EqualFold(m[1], "true")
+	}
 
 	// Find project references
 	matches := projectRefRegex.FindAllStringSubmatch(string(content), -1)
@@ -109,6 +153,10 @@ func Parse(path, relPath string) (*Project, error) {
 		refPath := m[1]
 		// Convert Windows path separators
 		refPath = strings.ReplaceAll(refPath, "\\", "/")
+		if strings.ContainsAny(refPath, "*?[") {
+			refs = append(refs, expandReferenceGlob(dir, refPath)...)
+			continue
+		}
 		// Resolve relative path
 		absRef := filepath.Clean(filepath.Join(dir, refPath))
 		refs = append(refs, absRef)
@@ -131,6 +179,69 @@ func Parse(path, relPath string) (*Project, error) {
 	}, nil
 }
 
+// expandReferenceGlob resolves a wildcard ProjectReference include such as
+// ../Plugins/**/*.csproj (slash-separated, relative to dir) to the absolute
+// paths of the matching .csproj files. Only the directory below the last
+// segment without wildcards is walked.
+func expandReferenceGlob(dir, pattern string) []string {
+	segments := strings.Split(pattern, "/")
+	static := 0
+	for static < len(segments) && !strings.ContainsAny(segments[static], "*?[") {
+		static++
+	}
+	base := filepath.Clean(filepath.Join(dir, filepath.FromSlash(strings.Join(segments[:static], "/"))))
+	rest := strings.Join(segments[static:], "/")
+
+	var refs []string
+	filepath.WalkDir(base, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip errors
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != base && (name == ".git" || name == "node_modules" || name == "bin" || name == "obj" || name == ".vs") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".csproj") {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err == nil && MatchGlob(rest, filepath.ToSlash(rel)) {
+			refs = append(refs, path)
+		}
+		return nil
+	})
+	return refs
+}
+
+// ParseImports returns the absolute paths of the existing files that the
+// MSBuild file at path (a .csproj, .props or .targets with the given content)
+// pulls in with <Import Project="...">. Paths are resolved relative to the
+// file; $(MSBuildThisFileDirectory) is expanded, while imports using other
+// properties or wildcards (e.g. SDK imports) can't be resolved and are skipped.
+func ParseImports(path string, content []byte) []string {
+	dir := filepath.Dir(path)
+	var imports []string
+	for _, m := range importRegex.FindAllStringSubmatch(string(content), -1) {
+		imp := strings.ReplaceAll(m[1], "$(MSBuildThisFileDirectory)", dir+"/")
+		imp = strings.ReplaceAll(imp, "\\", "/")
+		if strings.Contains(imp, "$(") || strings.ContainsAny(imp, "*?") {
+			continue
+		}
+		imp = filepath.FromSlash(imp)
+		if !filepath.IsAbs(imp) {
+			imp = filepath.Join(dir, imp)
+		}
+		imp = filepath.Clean(imp)
+		if info, err := os.Stat(imp); err == nil && !info.IsDir() {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
 // buildAbsToRel maps absolute project paths to their relative paths.
 // Uses gitRoot to resolve relative project paths, since they are relative to the git root,
 // not the current working directory.
@@ -268,9 +379,32 @@ func FilterFilesToProject(files []string, relevantDirs []string) []string {
 	return result
 }
 
+// FindProjectsForFiles returns the projects whose relevant directories (see
+// GetRelevantDirs) contain any of files. Files are slash-separated paths
+// relative to the git root and need not exist.
+func FindProjectsForFiles(files []string, projects []*Project, forwardGraph map[string][]string) map[string]bool {
+	matched := make(map[string]bool)
+	for _, p := range projects {
+		if len(FilterFilesToProject(files, GetRelevantDirs(p, forwardGraph))) > 0 {
+			matched[p.Path] = true
+		}
+	}
+	return matched
+}
+
 // FindAffectedProjects finds all projects affected by changes using the dependency graph.
 func FindAffectedProjects(changed map[string]bool, graph map[string][]string, projects []*Project) map[string]bool {
+	affected, _ := FindAffectedProjectsVia(changed, graph)
+	return affected
+}
+
+// FindAffectedProjectsVia is FindAffectedProjects that also returns, for each
+// project affected only through a dependency, the dependency the BFS reached
+// it from. Changed projects are visited in path order, so the parents (and
+// thus AffectedVia chains) are stable and shortest.
+func FindAffectedProjectsVia(changed map[string]bool, graph map[string][]string) (map[string]bool, map[string]string) {
 	affected := make(map[string]bool)
+	parents := make(map[string]string)
 
 	// Copy changed to affected
 	for p := range changed {
@@ -282,6 +416,7 @@ func FindAffectedProjects(changed map[string]bool, graph map[string][]string, pr
 	for p := range changed {
 		queue = append(queue, p)
 	}
+	sort.Strings(queue)
 
 	for len(queue) > 0 {
 		current := queue[0]
@@ -290,12 +425,24 @@ func FindAffectedProjects(changed map[string]bool, graph map[string][]string, pr
 		for _, dep := range graph[current] {
 			if !affected[dep] {
 				affected[dep] = true
+				parents[dep] = current
 				queue = append(queue, dep)
 			}
 		}
 	}
 
-	return affected
+	return affected, parents
+}
+
+// AffectedVia returns the dependency chain through which path was affected,
+// from the changed project down to path's direct dependency. It is empty for
+// changed (or unaffected) projects.
+func AffectedVia(parents map[string]string, path string) []string {
+	var chain []string
+	for p, ok := parents[path]; ok; p, ok = parents[p] {
+		chain = append([]string{p}, chain...)
+	}
+	return chain
 }
 
 // buildProjectByAbsPath maps absolute project paths to their Project pointers.
@@ -3,6 +3,7 @@ package project
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -58,6 +59,70 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseGlobReference(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, rel := range []string{
+		"Plugins/Alpha/Alpha.csproj",
+		"Plugins/Nested/Beta/Beta.csproj",
+		"Plugins/Alpha/bin/Debug/Copy.csproj",
+		"Core/Core.csproj",
+	} {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`), 0644)
+	}
+
+	projPath := filepath.Join(tmpDir, "Host", "Host.csproj")
+	os.MkdirAll(filepath.Dir(projPath), 0755)
+	os.WriteFile(projPath, []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <ProjectReference Include="..\Plugins\**\*.csproj" />
+    <ProjectReference Include="..\Core\Core.csproj" />
+  </ItemGroup>
+</Project>`), 0644)
+
+	p, err := Parse(projPath, "Host/Host.csproj")
+	if err != nil {
+		t.Fatalf("Parse() failed: %v", err)
+	}
+
+	want := []string{
+		filepath.Join(tmpDir, "Plugins", "Alpha", "Alpha.csproj"),
+		filepath.Join(tmpDir, "Plugins", "Nested", "Beta", "Beta.csproj"),
+		filepath.Join(tmpDir, "Core", "Core.csproj"),
+	}
+	if !reflect.DeepEqual(p.References, want) {
+		t.Errorf("References = %v, want %v", p.References, want)
+	}
+}
+
+func TestParseImports(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, rel := range []string{"Common.targets", "build/Shared.props"} {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(`<Project></Project>`), 0644)
+	}
+
+	projPath := filepath.Join(tmpDir, "App", "App.csproj")
+	content := []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <Import Project="..\Common.targets" />
+  <Import Condition="Exists('x')" Project="$(MSBuildThisFileDirectory)../build/Shared.props" />
+  <Import Project="$(MSBuildExtensionsPath)/Foo.targets" />
+  <Import Project="../Missing.targets" />
+  <Import Project="../build/*.props" />
+</Project>`)
+
+	got := ParseImports(projPath, content)
+	want := []string{
+		filepath.Join(tmpDir, "Common.targets"),
+		filepath.Join(tmpDir, "build", "Shared.props"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseImports() = %v, want %v", got, want)
+	}
+}
+
 func TestParseTestProject(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -89,6 +154,12 @@ func TestParseTestProject(t *testing.T) {
 			content:  `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><IsTestProject>true</IsTestProject></PropertyGroup></Project>`,
 			wantTest: true,
 		},
+		{
+			name:     "IsTestProject false overrides name",
+			projName: "XyzTests",
+			content:  `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><IsTestProject>false</IsTestProject></PropertyGroup></Project>`,
+			wantTest: false,
+		},
 		{
 			name:     "regular project",
 			projName: "MyApp",
@@ -120,6 +191,107 @@ func TestParseTestProject(t *testing.T) {
 	}
 }
 
+func TestDiscoverIgnoreFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`)
+	for _, rel := range []string{
+		"src/App/App.csproj",
+		"third_party/Vendored/Vendored.csproj",
+		"third_party/Other/Other.csproj",
+	} {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, content, 0644)
+	}
+	os.WriteFile(filepath.Join(tmpDir, IgnoreFileName), []byte("# vendored code\nthird_party/\n"), 0644)
+
+	projects, _, err := Discover(tmpDir, tmpDir, false)
+	if err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "App" {
+		var names []string
+		for _, p := range projects {
+			names = append(names, p.Name)
+		}
+		t.Errorf("expected only App, got %v", names)
+	}
+}
+
+func TestTestProjectPatterns(t *testing.T) {
+	projects := []*Project{
+		{Name: "MyApp"},
+		{Name: "MyApp.Specs"},
+		{Name: "MyApp.Tests", IsTest: true},
+		{Name: "Shared.TestUtils", IsTest: true},
+	}
+
+	tp, err := ParseTestProjectPatterns([]string{`\.Specs$`, `!TestUtils$`, `!`})
+	if err != nil {
+		t.Fatalf("ParseTestProjectPatterns() failed: %v", err)
+	}
+	tp.Apply(projects)
+
+	want := map[string]bool{
+		"MyApp":            false,
+		"MyApp.Specs":      true,
+		"MyApp.Tests":      true,
+		"Shared.TestUtils": false,
+	}
+	for _, p := range projects {
+		if p.IsTest != want[p.Name] {
+			t.Errorf("%s: IsTest = %v, want %v", p.Name, p.IsTest, want[p.Name])
+		}
+	}
+
+	if _, err := ParseTestProjectPatterns([]string{"("}); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func TestIsExcluded(t *testing.T) {
+	patterns := ParseExcludePatterns("legacy/**, *.Experimental.Tests,,src/*/Old.csproj")
+
+	tests := []struct {
+		path string
+		name string
+		want bool
+	}{
+		{"legacy/Foo/Foo.csproj", "Foo", true},
+		{"legacy/Foo.csproj", "Foo", true},
+		{"src/App.Experimental.Tests/App.Experimental.Tests.csproj", "App.Experimental.Tests", true},
+		{"src/Old/Old.csproj", "Old", true},
+		{"src/a/b/Old.csproj", "Old", false},
+		{"src/App/App.csproj", "App", false},
+		{"notlegacy/Foo/Foo.csproj", "Foo", false},
+	}
+	for _, tt := range tests {
+		p := &Project{Path: tt.path, Name: tt.name}
+		if got := IsExcluded(p, patterns); got != tt.want {
+			t.Errorf("IsExcluded(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadExcludeFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, ExcludeFileName)
+	os.WriteFile(path, []byte("# comment\nlegacy/**\n\n  *.Tests  \n"), 0644)
+
+	patterns, err := LoadExcludeFile(path)
+	if err != nil {
+		t.Fatalf("LoadExcludeFile() failed: %v", err)
+	}
+	if len(patterns) != 2 || patterns[0] != "legacy/**" || patterns[1] != "*.Tests" {
+		t.Errorf("patterns = %v", patterns)
+	}
+
+	patterns, err = LoadExcludeFile(filepath.Join(tmpDir, "missing"))
+	if err != nil || patterns != nil {
+		t.Errorf("missing file: patterns = %v, err = %v", patterns, err)
+	}
+}
+
 func TestBuildDependencyGraphs(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "graph-test-*")
 	if err != nil {
@@ -248,6 +420,38 @@ func TestFindAffectedProjects(t *testing.T) {
 	}
 }
 
+func TestFindProjectsForFiles(t *testing.T) {
+	core := &Project{Name: "Core", Path: "src/Core/Core.csproj", Dir: "src/Core"}
+	app := &Project{Name: "App", Path: "src/App/App.csproj", Dir: "src/App"}
+	tests := &Project{Name: "App.Tests", Path: "tests/App.Tests/App.Tests.csproj", Dir: "tests/App.Tests", IsTest: true}
+	other := &Project{Name: "Other", Path: "src/Other/Other.csproj", Dir: "src/Other"}
+	projects := []*Project{core, app, tests, other}
+
+	forward := map[string][]string{
+		app.Path:   {core.Path},
+		tests.Path: {app.Path},
+	}
+	reverse := map[string][]string{
+		core.Path: {app.Path},
+		app.Path:  {tests.Path},
+	}
+
+	changed := FindProjectsForFiles([]string{"src/Core/Thing.cs"}, projects, forward)
+	affected := FindAffectedProjects(changed, reverse, projects)
+	for _, p := range []*Project{core, app, tests} {
+		if !affected[p.Path] {
+			t.Errorf("%s should be affected by src/Core/Thing.cs", p.Name)
+		}
+	}
+	if affected[other.Path] {
+		t.Error("Other should not be affected by src/Core/Thing.cs")
+	}
+
+	if changed := FindProjectsForFiles([]string{"docs/README.md"}, projects, forward); len(changed) != 0 {
+		t.Errorf("expected no projects for a file outside all projects, got %v", changed)
+	}
+}
+
 func TestGetTransitiveDependencies(t *testing.T) {
 	// A depends on B, B depends on C
 	graph := map[string][]string{
@@ -274,6 +478,44 @@ func TestGetTransitiveDependencies(t *testing.T) {
 	}
 }
 
+func TestFindCycles(t *testing.T) {
+	graph := map[string][]string{
+		// A -> B -> C -> A
+		"A/A.csproj": {"B/B.csproj"},
+		"B/B.csproj": {"C/C.csproj"},
+		"C/C.csproj": {"A/A.csproj", "D/D.csproj"},
+		// D is a plain dependency, not part of any cycle
+		"D/D.csproj": {},
+		// E references itself
+		"E/E.csproj": {"E/E.csproj"},
+	}
+
+	cycles := FindCycles(graph)
+	if len(cycles) != 2 {
+		t.Fatalf("FindCycles returned %d cycles, want 2: %v", len(cycles), cycles)
+	}
+	want := []string{"A/A.csproj", "B/B.csproj", "C/C.csproj"}
+	if len(cycles[0]) != len(want) {
+		t.Fatalf("cycles[0] = %v, want %v", cycles[0], want)
+	}
+	for i := range want {
+		if cycles[0][i] != want[i] {
+			t.Errorf("cycles[0] = %v, want %v", cycles[0], want)
+			break
+		}
+	}
+	if len(cycles[1]) != 1 || cycles[1][0] != "E/E.csproj" {
+		t.Errorf("cycles[1] = %v, want [E/E.csproj]", cycles[1])
+	}
+
+	// An acyclic graph has no cycles
+	delete(graph, "E/E.csproj")
+	graph["C/C.csproj"] = []string{"D/D.csproj"}
+	if cycles := FindCycles(graph); len(cycles) != 0 {
+		t.Errorf("expected no cycles, got %v", cycles)
+	}
+}
+
 func TestGetRelevantDirs(t *testing.T) {
 	project := &Project{
 		Path: "App/App.csproj",
@@ -491,3 +733,21 @@ func TestFindCommonSolutionNone(t *testing.T) {
 		t.Error("FindCommonSolution should return nil when no solution contains all projects")
 	}
 }
+
+func TestFindAffectedProjectsVia(t *testing.T) {
+	reverse := map[string][]string{
+		"Core":   {"Api", "Worker"},
+		"Api":    {"Api.Tests"},
+		"Worker": {"Worker.Tests"},
+	}
+	affected, parents := FindAffectedProjectsVia(map[string]bool{"Core": true}, reverse)
+	if len(affected) != 5 {
+		t.Errorf("expected 5 affected projects, got %v", affected)
+	}
+	if via := AffectedVia(parents, "Api.Tests"); !reflect.DeepEqual(via, []string{"Core", "Api"}) {
+		t.Errorf("AffectedVia(Api.Tests) = %v, want [Core Api]", via)
+	}
+	if via := AffectedVia(parents, "Core"); len(via) != 0 {
+		t.Errorf("AffectedVia(Core) = %v, want none for a changed project", via)
+	}
+}
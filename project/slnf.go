@@ -0,0 +1,49 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// solutionFilter is the JSON layout of a .slnf solution filter file.
+type solutionFilter struct {
+	Solution struct {
+		Path     string   `json:"path"`
+		Projects []string `json:"projects"`
+	} `json:"solution"`
+}
+
+// WriteSolutionFilter writes a .slnf file at path that limits sln to the
+// given projects (absolute paths), so `dotnet build`/`dotnet test` on it
+// builds just those projects while MSBuild still coordinates their shared
+// dependencies. Paths in the file are relative, as dotnet expects: the
+// solution to the filter's directory, the projects to the solution's.
+func WriteSolutionFilter(path string, sln *Solution, projects []string) error {
+	slnPath, err := filepath.Rel(filepath.Dir(path), sln.Path)
+	if err != nil {
+		return err
+	}
+
+	var filter solutionFilter
+	filter.Solution.Path = slnPath
+	slnDir := filepath.Dir(sln.Path)
+	for _, p := range projects {
+		rel, err := filepath.Rel(slnDir, p)
+		if err != nil {
+			return err
+		}
+		filter.Solution.Projects = append(filter.Solution.Projects, rel)
+	}
+	sort.Strings(filter.Solution.Projects)
+
+	data, err := json.MarshalIndent(filter, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
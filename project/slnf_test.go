@@ -0,0 +1,47 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteSolutionFilter(t *testing.T) {
+	root := t.TempDir()
+	sln := &Solution{
+		Path:    filepath.Join(root, "App.sln"),
+		RelPath: "App.sln",
+		Projects: map[string]bool{
+			filepath.Join(root, "src", "Core", "Core.csproj"): true,
+			filepath.Join(root, "src", "Api", "Api.csproj"):   true,
+			filepath.Join(root, "src", "Web", "Web.csproj"):   true,
+		},
+	}
+
+	path := filepath.Join(root, ".donotnet", "slnf", "App.slnf")
+	err := WriteSolutionFilter(path, sln, []string{
+		filepath.Join(root, "src", "Api", "Api.csproj"),
+		filepath.Join(root, "src", "Core", "Core.csproj"),
+	})
+	if err != nil {
+		t.Fatalf("WriteSolutionFilter() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got solutionFilter
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid solution filter: %v\n%s", err, data)
+	}
+	if want := filepath.Join("..", "..", "App.sln"); got.Solution.Path != want {
+		t.Errorf("solution path = %q, want %q", got.Solution.Path, want)
+	}
+	want := []string{filepath.Join("src", "Api", "Api.csproj"), filepath.Join("src", "Core", "Core.csproj")}
+	if !reflect.DeepEqual(got.Solution.Projects, want) {
+		t.Errorf("projects = %v, want %v", got.Solution.Projects, want)
+	}
+}
@@ -0,0 +1,51 @@
+package project
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SubmodulesFileName is the file at the git root declaring git submodules.
+const SubmodulesFileName = ".gitmodules"
+
+// LoadSubmodules returns the submodule paths declared in gitRoot's
+// .gitmodules, slash-separated and relative to gitRoot. Returns nil when the
+// file doesn't exist or declares none.
+func LoadSubmodules(gitRoot string) []string {
+	f, err := os.Open(filepath.Join(gitRoot, SubmodulesFileName))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok || strings.TrimSpace(key) != "path" {
+			continue
+		}
+		path := strings.Trim(strings.TrimSpace(value), `"`)
+		path = strings.TrimSuffix(filepath.ToSlash(path), "/")
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// SubmoduleDirs returns the absolute directories of gitRoot's submodules as
+// a set, for skipping them in walks.
+func SubmoduleDirs(gitRoot string) map[string]bool {
+	paths := LoadSubmodules(gitRoot)
+	if len(paths) == 0 {
+		return nil
+	}
+	dirs := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		dirs[filepath.Join(gitRoot, filepath.FromSlash(p))] = true
+	}
+	return dirs
+}
@@ -0,0 +1,89 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const fakeGitmodules = `[submodule "Vendored"]
+	path = third_party/Vendored
+	url = https://example.com/vendored.git
+[submodule "Tools"]
+	path = "tools/external/"
+	url = ../tools.git
+`
+
+func TestLoadSubmodules(t *testing.T) {
+	tmpDir := t.TempDir()
+	if got := LoadSubmodules(tmpDir); got != nil {
+		t.Errorf("LoadSubmodules() without .gitmodules = %v, want nil", got)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, SubmodulesFileName), []byte(fakeGitmodules), 0644)
+	want := []string{"third_party/Vendored", "tools/external"}
+	if got := LoadSubmodules(tmpDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadSubmodules() = %v, want %v", got, want)
+	}
+}
+
+func TestDiscoverSkipsSubmodules(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`)
+	for _, rel := range []string{
+		"src/App/App.csproj",
+		"third_party/Vendored/src/Vendored/Vendored.csproj",
+		"third_party/Vendored/tests/Vendored.Tests/Vendored.Tests.csproj",
+	} {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, content, 0644)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "third_party", "Vendored", "Vendored.sln"),
+		[]byte(`Project("{FAE04EC0}") = "Vendored", "src\Vendored\Vendored.csproj", "{1}"`), 0644)
+	os.WriteFile(filepath.Join(tmpDir, SubmodulesFileName), []byte(fakeGitmodules), 0644)
+
+	projects, solutions, err := Discover(tmpDir, tmpDir, false)
+	if err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Name != "App" {
+		var names []string
+		for _, p := range projects {
+			names = append(names, p.Name)
+		}
+		t.Errorf("expected only App, got %v", names)
+	}
+	if len(solutions) != 0 {
+		t.Errorf("expected the submodule's solution to be skipped, got %d solution(s)", len(solutions))
+	}
+
+	projects, solutions, err = Discover(tmpDir, tmpDir, true)
+	if err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if len(projects) != 3 || len(solutions) != 1 {
+		t.Errorf("with includeSubmodules, got %d project(s) and %d solution(s), want 3 and 1", len(projects), len(solutions))
+	}
+}
+
+func TestDiscoverSkipsNestedCheckouts(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte(`<Project Sdk="Microsoft.NET.Sdk"></Project>`)
+	for _, rel := range []string{"src/App/App.csproj", ".worktrees/feature/src/App/App.csproj"} {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, content, 0644)
+	}
+	// A linked worktree's .git is a file pointing at the real gitdir
+	os.WriteFile(filepath.Join(tmpDir, ".worktrees", "feature", ".git"), []byte("gitdir: ../../.git/worktrees/feature\n"), 0644)
+
+	projects, _, err := Discover(tmpDir, tmpDir, true)
+	if err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if len(projects) != 1 || projects[0].Path != filepath.Join("src", "App", "App.csproj") {
+		t.Errorf("expected only src/App/App.csproj, got %d project(s)", len(projects))
+	}
+}
@@ -0,0 +1,66 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TestProjectPatterns augments the built-in test project detection
+// (name suffixes and <IsTestProject>) with user-supplied regexes matched
+// against the project name.
+type TestProjectPatterns struct {
+	Include []*regexp.Regexp // names matching these are test projects
+	Exclude []*regexp.Regexp // names matching these are never test projects
+}
+
+// ParseTestProjectPatterns compiles test project patterns.
+// A pattern prefixed with "!" is an opt-out: matching projects are treated as
+// non-test projects, even if the built-in rules would classify them as tests.
+func ParseTestProjectPatterns(specs []string) (*TestProjectPatterns, error) {
+	tp := &TestProjectPatterns{}
+	for _, spec := range specs {
+		exclude := strings.HasPrefix(spec, "!")
+		pattern := strings.TrimPrefix(spec, "!")
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test project pattern %q: %w", spec, err)
+		}
+		if exclude {
+			tp.Exclude = append(tp.Exclude, re)
+		} else {
+			tp.Include = append(tp.Include, re)
+		}
+	}
+	return tp, nil
+}
+
+// Apply reclassifies projects according to the patterns.
+// Opt-out patterns take precedence over both built-in and include patterns.
+func (tp *TestProjectPatterns) Apply(projects []*Project) {
+	if tp == nil {
+		return
+	}
+	for _, p := range projects {
+		if matchesAny(tp.Exclude, p.Name) {
+			p.IsTest = false
+			continue
+		}
+		if matchesAny(tp.Include, p.Name) {
+			p.IsTest = true
+		}
+	}
+}
+
+// matchesAny returns true if name matches any of the regexes.
+func matchesAny(patterns []*regexp.Regexp, name string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,9 @@ package runner
 import (
 	"strings"
 
+	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testfilter"
 )
 
 // shouldAutoQuiet returns true if the dotnet args indicate an informational command
@@ -72,6 +74,20 @@ func combineFilter(args []string, extra string) []string {
 	return append(append([]string{}, args...), "--filter", extra)
 }
 
+// withExcludedTraits combines the --exclude-trait categories into the --filter
+// of test args for the given projects. A solution run shares one filter, so the
+// dialect is picked from all its projects' packages.
+func (r *Runner) withExcludedTraits(args []string, projects ...*project.Project) []string {
+	if len(r.opts.ExcludeTraits) == 0 {
+		return args
+	}
+	var packages []string
+	for _, p := range projects {
+		packages = append(packages, p.PackageReferences...)
+	}
+	return combineFilter(args, testfilter.ExcludeTraitsFilter(testfilter.TraitFilterProperty(packages), r.opts.ExcludeTraits))
+}
+
 // removeCategoryFromFilter strips all Category-related clauses from a dotnet
 // test filter string. This is used when an interactive trait override replaces
 // the category filter so that contradictions like
@@ -3,6 +3,8 @@ package runner
 import (
 	"strings"
 	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
 )
 
 func TestCombineFilter(t *testing.T) {
@@ -215,3 +217,25 @@ func TestRemoveFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestWithExcludedTraits(t *testing.T) {
+	r := &Runner{opts: &Options{ExcludeTraits: []string{"Live"}}}
+	xunit := &project.Project{Name: "A.Tests", PackageReferences: []string{"xunit"}}
+	mstest := &project.Project{Name: "B.Tests", PackageReferences: []string{"MSTest.TestFramework"}}
+
+	got := r.withExcludedTraits([]string{"--filter", "Name~Foo"}, xunit)
+	want := []string{"--filter", "(Name~Foo)&(Category!=Live)"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got = r.withExcludedTraits(nil, mstest)
+	if extractFilter(got) != "TestCategory!=Live" {
+		t.Errorf("expected MSTest dialect, got %v", got)
+	}
+
+	r.opts.ExcludeTraits = nil
+	if got := r.withExcludedTraits([]string{"--no-build"}, xunit); len(got) != 1 {
+		t.Errorf("expected args unchanged without traits, got %v", got)
+	}
+}
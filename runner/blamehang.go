@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// blameHangArgs returns the dotnet test arguments that make the blame data
+// collector dump and abort any single test running longer than timeout.
+func blameHangArgs(timeout time.Duration) []string {
+	return []string{"--blame-hang", "--blame-hang-timeout", fmt.Sprintf("%dms", timeout.Milliseconds())}
+}
+
+// hangReport describes a test hang detected by the blame data collector.
+type hangReport struct {
+	Tests []string // Tests still running when the hang dump was taken
+	Dumps []string // Hang dump files
+}
+
+// String formats the report for appending to a project's failure output.
+func (h *hangReport) String() string {
+	var sb strings.Builder
+	if len(h.Tests) > 0 {
+		sb.WriteString("Hung test(s):\n")
+		for _, t := range h.Tests {
+			sb.WriteString("  " + t + "\n")
+		}
+	} else {
+		sb.WriteString("A test hung, but the blame sequence did not say which one\n")
+	}
+	for _, d := range h.Dumps {
+		sb.WriteString("Hang dump: " + d + "\n")
+	}
+	return sb.String()
+}
+
+// findProjectsHangReport combines the hang reports of projects run together,
+// e.g. through a solution. Returns "" if none of them hung.
+func findProjectsHangReport(gitRoot string, projects []*project.Project, since time.Time) string {
+	var sb strings.Builder
+	for _, p := range projects {
+		if hang := findHangReport(filepath.Join(gitRoot, p.Dir, "TestResults"), since); hang != nil {
+			sb.WriteString(p.Name + ": " + hang.String())
+		}
+	}
+	return sb.String()
+}
+
+// blameSequence is the Sequence_*.xml file written by the blame data collector.
+type blameSequence struct {
+	Tests []struct {
+		Name      string `xml:"Name,attr"`
+		Completed string `xml:"Completed,attr"`
+	} `xml:"Test"`
+}
+
+// findHangReport looks in resultsDir for hang dumps written at or after since
+// and, using the blame sequence files next to them, works out which tests hung.
+// Returns nil if no hang dump was found.
+func findHangReport(resultsDir string, since time.Time) *hangReport {
+	since = since.Truncate(time.Second)
+	var report hangReport
+	var sequences []string
+
+	filepath.WalkDir(resultsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.ModTime().Before(since) {
+			return nil
+		}
+		name := d.Name()
+		switch {
+		case strings.HasSuffix(name, "_hangdump.dmp"):
+			report.Dumps = append(report.Dumps, path)
+		case strings.HasPrefix(name, "Sequence_") && strings.HasSuffix(name, ".xml"):
+			sequences = append(sequences, path)
+		}
+		return nil
+	})
+
+	if len(report.Dumps) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, path := range sequences {
+		for _, t := range hungTestsFromSequence(path) {
+			if !seen[t] {
+				seen[t] = true
+				report.Tests = append(report.Tests, t)
+			}
+		}
+	}
+	sort.Strings(report.Dumps)
+	return &report
+}
+
+// hungTestsFromSequence returns the tests in a blame sequence file that never
+// completed. Older test platforms don't record completion; there the last
+// test in the sequence is the one that was running.
+func hungTestsFromSequence(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var seq blameSequence
+	if err := xml.Unmarshal(data, &seq); err != nil || len(seq.Tests) == 0 {
+		return nil
+	}
+
+	var hung []string
+	hasCompletion := false
+	for _, t := range seq.Tests {
+		if t.Completed != "" {
+			hasCompletion = true
+		}
+		if strings.EqualFold(t.Completed, "false") {
+			hung = append(hung, t.Name)
+		}
+	}
+	if !hasCompletion {
+		return []string{seq.Tests[len(seq.Tests)-1].Name}
+	}
+	return hung
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestRunSingleProjectTestHangTimeout(t *testing.T) {
+	gitRoot := t.TempDir()
+	p := &project.Project{Name: "App.Tests", Path: "tests/App.Tests/App.Tests.csproj", Dir: "tests/App.Tests"}
+
+	r := New(&Options{
+		Command:         "test",
+		FullBuild:       true,
+		DryRun:          true,
+		NoReports:       true,
+		TestHangTimeout: 90 * time.Second,
+	})
+	r.gitRoot = gitRoot
+
+	res := r.runSingleProject(context.Background(), p, "", "", "", "", nil, nil, func() {})
+	if !strings.HasSuffix(res.output, " --blame-hang --blame-hang-timeout 90000ms") {
+		t.Errorf("expected blame-hang args to be appended, got %q", res.output)
+	}
+}
+
+func TestFindHangReport(t *testing.T) {
+	resultsDir := t.TempDir()
+	runDir := filepath.Join(resultsDir, "0c4f1d2e-guid")
+	os.MkdirAll(runDir, 0755)
+
+	start := time.Now()
+
+	// No dump: nothing hung
+	if report := findHangReport(resultsDir, start); report != nil {
+		t.Fatalf("expected no report without a dump, got %+v", report)
+	}
+
+	sequence := `<?xml version="1.0" encoding="utf-8"?>
+<TestSequence>
+  <Test Name="App.Tests.FastTests.Adds" Source="App.Tests.dll" Completed="True" />
+  <Test Name="App.Tests.SlowTests.WaitsForever" Source="App.Tests.dll" Completed="False" />
+</TestSequence>`
+	os.WriteFile(filepath.Join(runDir, "Sequence_abc123.xml"), []byte(sequence), 0644)
+	dump := filepath.Join(runDir, "testhost_1234_20240101T000000_hangdump.dmp")
+	os.WriteFile(dump, []byte("MDMP"), 0644)
+
+	report := findHangReport(resultsDir, start)
+	if report == nil {
+		t.Fatal("expected a hang report when a dump is present")
+	}
+	if len(report.Tests) != 1 || report.Tests[0] != "App.Tests.SlowTests.WaitsForever" {
+		t.Errorf("Tests = %v, want [App.Tests.SlowTests.WaitsForever]", report.Tests)
+	}
+	if len(report.Dumps) != 1 || report.Dumps[0] != dump {
+		t.Errorf("Dumps = %v, want [%s]", report.Dumps, dump)
+	}
+	if !strings.Contains(report.String(), "Hung test(s):\n  App.Tests.SlowTests.WaitsForever\n") {
+		t.Errorf("unexpected report: %q", report.String())
+	}
+
+	// Dumps from earlier runs are ignored
+	old := start.Add(-time.Hour)
+	os.Chtimes(dump, old, old)
+	if report := findHangReport(resultsDir, start); report != nil {
+		t.Errorf("expected stale dump to be ignored, got %+v", report)
+	}
+}
+
+func TestHungTestsFromSequenceWithoutCompletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Sequence_old.xml")
+	os.WriteFile(path, []byte(`<TestSequence><Test Name="A.First" /><Test Name="A.Second" /></TestSequence>`), 0644)
+
+	got := hungTestsFromSequence(path)
+	if len(got) != 1 || got[0] != "A.Second" {
+		t.Errorf("hungTestsFromSequence() = %v, want [A.Second]", got)
+	}
+}
@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// cacheKeyDebug describes how a project's cache key was derived and whether
+// it hit, for --cache-key-debug.
+type cacheKeyDebug struct {
+	name        string
+	contentHash string
+	argsHash    string
+	key         string
+	verdict     string
+}
+
+// debugCacheKeys runs the cache lookup for each project. A miss is explained
+// as "content new" when the project has cached runs with these arguments,
+// just none for its current content, and as "absent" when it has none at all.
+func (r *Runner) debugCacheKeys(projects []*project.Project, argsHash string, vcsChanges []git.FileChange, useVcsFilter bool) []cacheKeyDebug {
+	cachedPaths := make(map[string]bool)
+	r.db.View(func(key string, _ cache.Entry) error {
+		if _, keyArgsHash, keyPath := cache.ParseKey(key); keyArgsHash == argsHash {
+			cachedPaths[keyPath] = true
+		}
+		return nil
+	})
+	vcsChangedFiles := vcsChangePaths(vcsChanges)
+
+	var entries []cacheKeyDebug
+	for _, p := range projects {
+		c := r.checkCache(p, argsHash)
+		d := cacheKeyDebug{name: p.Name, contentHash: c.contentHash, argsHash: argsHash, key: c.key}
+		switch {
+		case useVcsFilter && !r.targetPaths[p.Path] &&
+			len(project.FilterFilesToProject(vcsChangedFiles, project.GetRelevantDirs(p, r.forwardGraph))) == 0:
+			d.verdict = "skipped (no VCS changes)"
+		case c.hit:
+			d.verdict = "hit"
+		case c.forced:
+			d.verdict = "miss (forced)"
+		case c.noOutput:
+			d.verdict = "miss (no output)"
+		case r.db.LookupAny(c.key) != nil:
+			d.verdict = "miss (last run failed)"
+		case cachedPaths[p.Path]:
+			d.verdict = "miss (content new)"
+		default:
+			d.verdict = "miss (absent)"
+		}
+		entries = append(entries, d)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+// formatCacheKeyDebug renders one block per project.
+func formatCacheKeyDebug(entries []cacheKeyDebug) string {
+	var sb strings.Builder
+	for _, d := range entries {
+		fmt.Fprintf(&sb, "%s: %s\n", d.name, d.verdict)
+		fmt.Fprintf(&sb, "  content hash: %s\n", d.contentHash)
+		fmt.Fprintf(&sb, "  args hash:    %s\n", d.argsHash)
+		fmt.Fprintf(&sb, "  key:          %s\n", d.key)
+	}
+	return sb.String()
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestDebugCacheKeys(t *testing.T) {
+	gitRoot := t.TempDir()
+	db, err := cache.Open(filepath.Join(gitRoot, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	var projects []*project.Project
+	for _, name := range []string{"Hit", "Edited", "New", "Broken"} {
+		p := &project.Project{Name: name, Path: name + "/" + name + ".csproj", Dir: name}
+		os.MkdirAll(filepath.Join(gitRoot, p.Dir), 0755)
+		os.WriteFile(filepath.Join(gitRoot, p.Dir, "Class.cs"), []byte("class "+name+" {}"), 0644)
+		projects = append(projects, p)
+	}
+	graph := map[string][]string{}
+	argsHash := HashArgs([]string{"build"})
+	now := time.Now()
+	db.Mark(ProjectCacheKey(projects[0], gitRoot, graph, argsHash), now, true, nil, "build")
+	db.Mark(cache.MakeKey("stale", argsHash, projects[1].Path), now, true, nil, "build")
+	db.Mark(ProjectCacheKey(projects[3], gitRoot, graph, argsHash), now, false, nil, "build")
+
+	r := New(&Options{Command: "build", CacheKeyDebug: true})
+	r.gitRoot = gitRoot
+	r.db = db
+	r.projects = projects
+	r.forwardGraph = graph
+
+	out := formatCacheKeyDebug(r.debugCacheKeys(projects, argsHash, nil, false))
+	want := map[string]string{
+		"Hit":    "hit",
+		"Edited": "miss (content new)",
+		"New":    "miss (absent)",
+		"Broken": "miss (last run failed)",
+	}
+	for _, p := range projects {
+		if !strings.Contains(out, p.Name+": "+want[p.Name]+"\n") {
+			t.Errorf("output missing %s verdict %q:\n%s", p.Name, want[p.Name], out)
+		}
+		key := ProjectCacheKey(p, gitRoot, graph, argsHash)
+		if !strings.Contains(out, "  key:          "+key+"\n") {
+			t.Errorf("output missing %s key %s:\n%s", p.Name, key, out)
+		}
+	}
+	if n := strings.Count(out, "  args hash:    "+argsHash+"\n"); n != len(projects) {
+		t.Errorf("args hash listed %d times, want %d", n, len(projects))
+	}
+}
@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// maintainCacheSize evicts least-recently-used entries from the cache at path
+// until it fits in maxBytes, compacting it when that leaves much free space.
+// The cache must be closed. Failures are only warned about, since the run
+// itself already completed.
+func maintainCacheSize(path string, maxBytes int64) {
+	res, err := cache.MaintainSize(path, maxBytes)
+	if err != nil {
+		term.Warnf("cache maintenance failed: %v", err)
+		return
+	}
+	if res.Deleted > 0 {
+		term.Dim("Cache over %s: evicted %d least recently used entries (%s)",
+			cache.FormatSize(maxBytes), res.Deleted, cache.FormatSize(res.Freed))
+	}
+	if res.Used > maxBytes {
+		term.Warnf("cache still uses %s after evicting all run results, over --cache-max-size %s",
+			cache.FormatSize(res.Used), cache.FormatSize(maxBytes))
+	}
+	if res.Compacted {
+		term.Dim("Compacted cache: %s -> %s", cache.FormatSize(res.Before), cache.FormatSize(res.After))
+	}
+}
+
+// pruneDeletedProjects removes cache entries of projects whose .csproj no
+// longer exists, so deleted or moved projects don't linger in the cache.
+// Skipped for read-only and dry runs, when the scan found no projects (e.g.
+// a checkout in progress), and when the cache lives outside the repository,
+// since it may then be shared with other checkouts.
+func (r *Runner) pruneDeletedProjects() {
+	if r.db.ReadOnly() || r.opts.DryRun || len(r.projects) == 0 {
+		return
+	}
+	if !cacheInRepo(r.gitRoot, r.cacheDir) {
+		return
+	}
+	deleted, err := r.db.DeleteProjectEntries(func(projectPath string) bool {
+		_, err := os.Stat(filepath.Join(r.gitRoot, projectPath))
+		return !os.IsNotExist(err)
+	})
+	if err != nil {
+		term.Warnf("failed to prune cache entries of deleted projects: %v", err)
+		return
+	}
+	if deleted > 0 {
+		term.Dim("Removed %d cache entries of deleted projects", deleted)
+	}
+}
+
+// cacheInRepo reports whether cacheDir is inside the repository at gitRoot.
+func cacheInRepo(gitRoot, cacheDir string) bool {
+	rel, err := filepath.Rel(gitRoot, cacheDir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
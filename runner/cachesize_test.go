@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestPruneDeletedProjects(t *testing.T) {
+	gitRoot := t.TempDir()
+	cacheDir := filepath.Join(gitRoot, ".donotnet")
+	os.MkdirAll(filepath.Join(gitRoot, "Kept"), 0755)
+	os.WriteFile(filepath.Join(gitRoot, "Kept", "Kept.csproj"), []byte("<Project/>"), 0644)
+	os.MkdirAll(cacheDir, 0755)
+
+	db, err := cache.Open(filepath.Join(cacheDir, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	kept := cache.MakeKey("hash", "args", "Kept/Kept.csproj")
+	gone := cache.MakeKey("hash", "args", "Gone/Gone.csproj")
+	db.MarkWithDuration(kept, time.Now(), time.Second, true, nil, "test")
+	db.MarkWithDuration(gone, time.Now(), time.Second, true, nil, "test")
+
+	newRunner := func(opts *Options, cacheDir string, projects []*project.Project) *Runner {
+		r := New(opts)
+		r.gitRoot = gitRoot
+		r.cacheDir = cacheDir
+		r.db = db
+		r.projects = projects
+		return r
+	}
+	scanned := []*project.Project{{Name: "Kept", Path: "Kept/Kept.csproj", Dir: "Kept"}}
+
+	// Guarded runs leave the entries of deleted projects alone
+	newRunner(&Options{Command: "test", DryRun: true}, cacheDir, scanned).pruneDeletedProjects()
+	newRunner(&Options{Command: "test"}, cacheDir, nil).pruneDeletedProjects()
+	newRunner(&Options{Command: "test"}, t.TempDir(), scanned).pruneDeletedProjects()
+	if db.LookupAny(gone) == nil {
+		t.Fatal("entry of deleted project should survive dry runs, empty scans and a shared cache_dir")
+	}
+
+	newRunner(&Options{Command: "test"}, cacheDir, scanned).pruneDeletedProjects()
+	if db.LookupAny(gone) != nil {
+		t.Error("entry of deleted project should be pruned")
+	}
+	if db.LookupAny(kept) == nil {
+		t.Error("entry of existing project should be kept")
+	}
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// HitRateLogFileName is the file in the cache directory that --cache-stats-log
+// appends one line per run to, for trending cache effectiveness (e.g. in CI).
+const HitRateLogFileName = "hitrate.log"
+
+// cacheStats counts how the projects of a run were resolved against the cache.
+type cacheStats struct {
+	Hits   int // up to date, not run
+	Misses int // run because they (or a dependency) changed
+	Forced int // run because of --force
+}
+
+// newCacheStats classifies the projects selected for a run.
+func newCacheStats(targets, cached []*project.Project, force bool) cacheStats {
+	s := cacheStats{Hits: len(cached)}
+	if force {
+		s.Forced = len(targets)
+	} else {
+		s.Misses = len(targets)
+	}
+	return s
+}
+
+// Total is the number of projects considered.
+func (s cacheStats) Total() int {
+	return s.Hits + s.Misses + s.Forced
+}
+
+// HitRate is the percentage of projects that were cache hits (0 when there
+// were none).
+func (s cacheStats) HitRate() float64 {
+	if s.Total() == 0 {
+		return 0
+	}
+	return 100 * float64(s.Hits) / float64(s.Total())
+}
+
+// String renders e.g. "cache hit rate: 75% (3/4)".
+func (s cacheStats) String() string {
+	str := fmt.Sprintf("cache hit rate: %.0f%% (%d/%d)", s.HitRate(), s.Hits, s.Total())
+	if s.Forced > 0 {
+		str += fmt.Sprintf(", %d forced", s.Forced)
+	}
+	return str
+}
+
+// logLine renders one tab-separated line of the hit rate log:
+// time, command, hits, misses, forced, total, hit rate.
+func (s cacheStats) logLine(command string, at time.Time) string {
+	return fmt.Sprintf("%s\t%s\t%d\t%d\t%d\t%d\t%.1f\n",
+		at.UTC().Format(time.RFC3339), command, s.Hits, s.Misses, s.Forced, s.Total(), s.HitRate())
+}
+
+// reportCacheStats prints the hit rate of a run (--cache-stats) and appends it
+// to the hit rate log (--cache-stats-log). Dry runs are not logged.
+func (r *Runner) reportCacheStats(s cacheStats) {
+	if r.opts.CacheStats && !r.opts.Quiet {
+		term.Info("%s", s)
+	}
+	if !r.opts.CacheStatsLog || r.opts.DryRun {
+		return
+	}
+	path := filepath.Join(r.cacheDir, HitRateLogFileName)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		term.Warnf("failed to open %s: %v", path, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.WriteString(s.logLine(r.opts.Command, time.Now())); err != nil {
+		term.Warnf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestCacheStats(t *testing.T) {
+	targets := []*project.Project{{Name: "A"}}
+	cached := []*project.Project{{Name: "B"}, {Name: "C"}, {Name: "D"}}
+
+	s := newCacheStats(targets, cached, false)
+	if got, want := s.String(), "cache hit rate: 75% (3/4)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got, want := s.logLine("test", at), "2024-01-02T03:04:05Z\ttest\t3\t1\t0\t4\t75.0\n"; got != want {
+		t.Errorf("logLine() = %q, want %q", got, want)
+	}
+
+	forced := newCacheStats(targets, nil, true)
+	if forced.Misses != 0 || forced.Forced != 1 {
+		t.Errorf("--force should count targets as forced, got %+v", forced)
+	}
+	if got, want := forced.String(), "cache hit rate: 0% (0/1), 1 forced"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if rate := (cacheStats{}).HitRate(); rate != 0 {
+		t.Errorf("empty run should have hit rate 0, got %v", rate)
+	}
+
+	md := renderMarkdownReport(markdownReport{Command: "test", Cached: cached, CacheStats: &s})
+	if !strings.Contains(md, "_cache hit rate: 75% (3/4)_") {
+		t.Errorf("expected hit rate in report\n\n%s", md)
+	}
+}
@@ -0,0 +1,107 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/coverage"
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// coverageDiffOutput is the changed line coverage written by
+// --coverage-diff-json. Uncovered lines can be turned into PR annotations.
+type coverageDiffOutput struct {
+	Ref     string  `json:"ref"`
+	Base    string  `json:"base"`
+	Percent float64 `json:"percent"`
+	*coverage.DiffCoverage
+}
+
+// writeCoverageDiff reports how many of the lines changed since branching off
+// CoverageDiff the test projects that ran cover (--coverage-diff), and
+// writes the uncovered ones as JSON with --coverage-diff-json.
+func (r *Runner) writeCoverageDiff(targets []*project.Project) {
+	ref := r.opts.CoverageDiff
+	if ref == "" || !r.opts.Coverage || r.opts.DryRun || r.opts.Command != "test" {
+		return
+	}
+
+	var reports []string
+	for _, f := range r.testCoverageFiles(targets) {
+		reports = append(reports, f.path)
+	}
+	if len(reports) == 0 {
+		term.Warnf("no coverage files found, no changed line coverage")
+		return
+	}
+
+	base, err := git.GetMergeBase(r.gitRoot, ref)
+	if err != nil {
+		term.Warnf("changed line coverage: %v", err)
+		return
+	}
+	changed, err := git.ChangedLines(r.gitRoot, base)
+	if err != nil {
+		term.Warnf("changed line coverage: %v", err)
+		return
+	}
+	diff, err := coverage.ChangedLineCoverage(r.gitRoot, reports, changed)
+	if err != nil {
+		term.Warnf("failed to compute changed line coverage: %v", err)
+		return
+	}
+
+	if !r.opts.Quiet {
+		if diff.Total == 0 {
+			term.Info("No coverable lines changed since %s", ref)
+		} else {
+			term.Info("Changed line coverage: %.1f%% (%d/%d lines since %s)", diff.Percent(), diff.Covered, diff.Total, ref)
+			for _, line := range uncoveredByFile(diff.Uncovered) {
+				term.Printf("  %s\n", line)
+			}
+		}
+	}
+
+	if path := r.opts.CoverageDiffJSON; path != "" {
+		out := coverageDiffOutput{Ref: ref, Base: base, Percent: diff.Percent(), DiffCoverage: diff}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err == nil {
+			if dir := filepath.Dir(path); dir != "." {
+				os.MkdirAll(dir, 0755)
+			}
+			err = os.WriteFile(path, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			term.Warnf("failed to write changed line coverage: %v", err)
+		}
+	}
+}
+
+// uncoveredByFile formats uncovered lines (sorted by path and line) as one
+// "path: 3-5, 9" entry per file.
+func uncoveredByFile(lines []coverage.LineRef) []string {
+	var out []string
+	for i := 0; i < len(lines); {
+		path := lines[i].Path
+		var ranges []string
+		for i < len(lines) && lines[i].Path == path {
+			start := lines[i].Line
+			end := start
+			for i++; i < len(lines) && lines[i].Path == path && lines[i].Line == end+1; i++ {
+				end++
+			}
+			if start == end {
+				ranges = append(ranges, fmt.Sprint(start))
+			} else {
+				ranges = append(ranges, fmt.Sprintf("%d-%d", start, end))
+			}
+		}
+		out = append(out, path+": "+strings.Join(ranges, ", "))
+	}
+	return out
+}
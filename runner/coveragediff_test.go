@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/coverage"
+)
+
+func TestUncoveredByFile(t *testing.T) {
+	lines := []coverage.LineRef{
+		{Path: "src/Bar.cs", Line: 7},
+		{Path: "src/Foo.cs", Line: 3},
+		{Path: "src/Foo.cs", Line: 4},
+		{Path: "src/Foo.cs", Line: 5},
+		{Path: "src/Foo.cs", Line: 9},
+	}
+	want := []string{"src/Bar.cs: 7", "src/Foo.cs: 3-5, 9"}
+	if got := uncoveredByFile(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("uncoveredByFile() = %q, want %q", got, want)
+	}
+}
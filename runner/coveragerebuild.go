@@ -0,0 +1,202 @@
+package runner
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/coverage"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// coverageRebuildDelay is how long watch mode waits after the last test run
+// that left coverage stale before rebuilding it in the background.
+const coverageRebuildDelay = 5 * time.Second
+
+// coverageRebuilder rebuilds per-test coverage maps for individual projects in
+// the background during watch mode. Requests are debounced and coalesced, at
+// most one rebuild runs at a time, and a rebuild never starts while busy
+// reports that a watch run is in progress. A watch run about to start calls
+// interrupt, so the two never build the same project at once.
+type coverageRebuilder struct {
+	ctx   context.Context
+	delay time.Duration
+	busy  func() bool
+	build func(ctx context.Context, projects []*project.Project)
+	done  func()
+
+	mu       sync.Mutex
+	queued   map[string]*project.Project
+	running  bool
+	cancel   context.CancelFunc // cancels the running rebuild
+	finished chan struct{}      // closed once the running rebuild returned
+	timer    *time.Timer
+	stopped  bool
+	inflight sync.WaitGroup
+}
+
+func newCoverageRebuilder(ctx context.Context, delay time.Duration, busy func() bool, build func(context.Context, []*project.Project), done func()) *coverageRebuilder {
+	return &coverageRebuilder{
+		ctx:    ctx,
+		delay:  delay,
+		busy:   busy,
+		build:  build,
+		done:   done,
+		queued: make(map[string]*project.Project),
+	}
+}
+
+// schedule queues projects for a rebuild and restarts the debounce timer.
+func (c *coverageRebuilder) schedule(projects []*project.Project) {
+	if len(projects) == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return
+	}
+	for _, p := range projects {
+		c.queued[p.Path] = p
+	}
+	c.armLocked()
+}
+
+func (c *coverageRebuilder) armLocked() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(c.delay, c.fire)
+}
+
+// fire starts a rebuild of all queued projects, unless one is already running
+// or a watch run is in progress, in which case it tries again later.
+func (c *coverageRebuilder) fire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped || len(c.queued) == 0 {
+		return
+	}
+	if c.running || (c.busy != nil && c.busy()) {
+		c.armLocked()
+		return
+	}
+
+	projects := make([]*project.Project, 0, len(c.queued))
+	for _, p := range c.queued {
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Path < projects[j].Path })
+	c.queued = make(map[string]*project.Project)
+	ctx, cancel := context.WithCancel(c.ctx)
+	finished := make(chan struct{})
+	c.running, c.cancel, c.finished = true, cancel, finished
+	c.inflight.Add(1)
+
+	go func() {
+		defer c.inflight.Done()
+		defer close(finished)
+		c.build(ctx, projects)
+		if c.done != nil {
+			c.done()
+		}
+
+		c.mu.Lock()
+		c.running = false
+		cancel()
+		// An interrupted rebuild is retried once things are quiet again
+		if ctx.Err() != nil && c.ctx.Err() == nil {
+			for _, p := range projects {
+				if _, ok := c.queued[p.Path]; !ok {
+					c.queued[p.Path] = p
+				}
+			}
+		}
+		if !c.stopped && len(c.queued) > 0 {
+			c.armLocked()
+		}
+		c.mu.Unlock()
+	}()
+}
+
+// interrupt cancels a running rebuild and waits for it to return; its
+// projects are queued again. Callers mark themselves busy first, so no new
+// rebuild starts until they are done.
+func (c *coverageRebuilder) interrupt() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	c.cancel()
+	finished := c.finished
+	c.mu.Unlock()
+	<-finished
+}
+
+// stop cancels pending rebuilds and waits for a running one to finish.
+func (c *coverageRebuilder) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mu.Unlock()
+	c.inflight.Wait()
+}
+
+// staleCoverageProjects returns the test projects among targets that have a
+// per-test coverage map and whose relevant directories contain one of
+// changedFiles, provided the coverage staleness check reports stale coverage.
+func (r *Runner) staleCoverageProjects(targets []*project.Project, changedFiles []string, haveMap func(*project.Project) bool) []*project.Project {
+	var relevant []string
+	for _, f := range changedFiles {
+		if project.IsRelevantForCoverage(f) {
+			relevant = append(relevant, f)
+		}
+	}
+	if len(relevant) == 0 {
+		return nil
+	}
+
+	status := coverage.CheckStaleness(r.gitRoot, coverage.ParseStalenessMethod(r.opts.StalenessCheck))
+	if status.Staleness != coverage.Stale {
+		return nil
+	}
+
+	var stale []*project.Project
+	for _, p := range targets {
+		if !p.IsTest || !haveMap(p) {
+			continue
+		}
+		if len(project.FilterFilesToProject(relevant, project.GetRelevantDirs(p, r.forwardGraph))) > 0 {
+			stale = append(stale, p)
+		}
+	}
+	return stale
+}
+
+// rebuildCoverage rebuilds the per-test coverage maps of projects.
+func (r *Runner) rebuildCoverage(ctx context.Context, projects []*project.Project) {
+	var names []string
+	for _, p := range projects {
+		names = append(names, p.Name)
+	}
+	term.Dim("Rebuilding stale coverage in the background: %s", strings.Join(names, ", "))
+
+	coverage.BuildPerTestCoverageMaps(coverage.BuildOptions{
+		GitRoot:      r.gitRoot,
+		Projects:     projects,
+		ForwardGraph: r.forwardGraph,
+		MaxJobs:      1,
+		Granularity:  coverage.ParseGranularity(r.opts.CoverageGranularity),
+		Ctx:          ctx,
+		Cache:        NewTestListCache(r.db, r.gitRoot, r.forwardGraph),
+		Incremental:  true,
+		Discovery:    coverage.ParseDiscovery(r.opts.Discovery),
+	})
+	term.Dim("Coverage rebuilt: %s", strings.Join(names, ", "))
+}
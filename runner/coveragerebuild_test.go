@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestCoverageRebuilderDebounces(t *testing.T) {
+	a := &project.Project{Name: "A.Tests", Path: "A.Tests/A.Tests.csproj"}
+	b := &project.Project{Name: "B.Tests", Path: "B.Tests/B.Tests.csproj"}
+
+	var mu sync.Mutex
+	var builds [][]string
+	done := make(chan struct{}, 10)
+	var busy atomic.Bool
+
+	c := newCoverageRebuilder(context.Background(), 20*time.Millisecond, busy.Load, func(_ context.Context, projects []*project.Project) {
+		mu.Lock()
+		defer mu.Unlock()
+		var names []string
+		for _, p := range projects {
+			names = append(names, p.Name)
+		}
+		builds = append(builds, names)
+	}, func() { done <- struct{}{} })
+	defer c.stop()
+
+	// A watch run is in progress: nothing may start yet
+	busy.Store(true)
+	c.schedule([]*project.Project{a})
+	c.schedule([]*project.Project{b, a})
+	time.Sleep(60 * time.Millisecond)
+	mu.Lock()
+	if len(builds) != 0 {
+		t.Fatalf("expected no rebuild while busy, got %v", builds)
+	}
+	mu.Unlock()
+
+	busy.Store(false)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("rebuild did not run")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(builds) != 1 || len(builds[0]) != 2 || builds[0][0] != "A.Tests" || builds[0][1] != "B.Tests" {
+		t.Errorf("expected one coalesced rebuild of A.Tests and B.Tests, got %v", builds)
+	}
+}
+
+func TestCoverageRebuilderStop(t *testing.T) {
+	var calls atomic.Int32
+	c := newCoverageRebuilder(context.Background(), 10*time.Millisecond, nil, func(context.Context, []*project.Project) { calls.Add(1) }, nil)
+	c.schedule([]*project.Project{{Name: "A.Tests", Path: "A.Tests/A.Tests.csproj"}})
+	c.stop()
+	time.Sleep(30 * time.Millisecond)
+	if calls.Load() != 0 {
+		t.Errorf("expected no rebuild after stop, got %d", calls.Load())
+	}
+}
+
+func TestCoverageRebuilderInterrupt(t *testing.T) {
+	var busy atomic.Bool
+	var builds atomic.Int32
+	started := make(chan struct{}, 10)
+	var interrupted atomic.Bool
+	done := make(chan struct{}, 10)
+
+	c := newCoverageRebuilder(context.Background(), 10*time.Millisecond, busy.Load, func(ctx context.Context, _ []*project.Project) {
+		if builds.Add(1) == 1 {
+			started <- struct{}{}
+			<-ctx.Done()
+			interrupted.Store(true)
+		}
+	}, func() { done <- struct{}{} })
+	defer c.stop()
+
+	c.schedule([]*project.Project{{Name: "A.Tests", Path: "A.Tests/A.Tests.csproj"}})
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("rebuild did not start")
+	}
+
+	// A watch run starts: the rebuild is cancelled and has returned once
+	// interrupt does
+	busy.Store(true)
+	c.interrupt()
+	if !interrupted.Load() {
+		t.Fatal("interrupt returned before the rebuild stopped")
+	}
+	<-done
+
+	// ...and it is retried after the run
+	busy.Store(false)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("interrupted rebuild was not retried")
+	}
+	if n := builds.Load(); n != 2 {
+		t.Errorf("builds = %d, want 2", n)
+	}
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"path/filepath"
+
+	"github.com/runar-rkmedia/donotnet/coverage"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// writeCoverageReport merges the Cobertura reports of the test projects that
+// ran into an HTML summary in r.opts.CoverageReport (--coverage-report), and
+// opens it with --coverage-open (which defaults the directory to
+// coverage-report in the cache directory).
+func (r *Runner) writeCoverageReport(targets []*project.Project) {
+	dir := r.opts.CoverageReport
+	if dir == "" && r.opts.CoverageOpen {
+		dir = filepath.Join(r.cacheDir, "coverage-report")
+	}
+	if dir == "" || !r.opts.Coverage || r.opts.DryRun || r.opts.Command != "test" {
+		return
+	}
+
+	var reports []string
+	for _, f := range r.testCoverageFiles(targets) {
+		reports = append(reports, f.path)
+	}
+	if len(reports) == 0 {
+		term.Warnf("no coverage files found, not writing coverage report")
+		return
+	}
+
+	files, err := coverage.MergeLineCoverage(r.gitRoot, reports)
+	if err != nil {
+		term.Warnf("failed to merge coverage: %v", err)
+		return
+	}
+	path, err := coverage.WriteHTMLReport(dir, files)
+	if err != nil {
+		term.Warnf("failed to write coverage report: %v", err)
+		return
+	}
+	term.Info("Wrote coverage report of %d file(s) to %s", len(files), path)
+
+	if r.opts.CoverageOpen {
+		if err := openCommand(path).Start(); err != nil {
+			term.Warnf("failed to open %s: %v", path, err)
+		}
+	}
+}
+
+// projectCoverageFile is the Cobertura report of one test project.
+type projectCoverageFile struct {
+	project *project.Project
+	path    string
+}
+
+// testCoverageFiles returns the Cobertura reports of the test projects among
+// targets, in target order: the report a run recorded, or else the most recent
+// one in the project's TestResults directory. Projects without one are left out.
+func (r *Runner) testCoverageFiles(targets []*project.Project) []projectCoverageFile {
+	recorded := make(map[string]string)
+	for _, res := range r.results {
+		if res.coverageFile != "" {
+			recorded[res.project.Path] = res.coverageFile
+		}
+	}
+	var files []projectCoverageFile
+	for _, p := range targets {
+		if !p.IsTest || r.opts.BuildOnlyProjects[p.Path] {
+			continue
+		}
+		covFile := recorded[p.Path]
+		if covFile == "" {
+			covFile = coverage.FindProjectCoverageFile(filepath.Join(r.gitRoot, p.Dir), coverage.RunResultsDir(r.reportsDir, p.Path))
+		}
+		if covFile != "" {
+			files = append(files, projectCoverageFile{project: p, path: covFile})
+		}
+	}
+	return files
+}
@@ -0,0 +1,144 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runar-rkmedia/donotnet/coverage"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// coverageSummary is the line coverage of a --coverage run, printed with
+// --coverage-summary and written by --coverage-summary-json.
+type coverageSummary struct {
+	// Covered and Total count each source line once, covered if any test
+	// project hit it
+	Covered  int               `json:"covered"`
+	Total    int               `json:"total"`
+	Percent  float64           `json:"percent"`
+	Projects []projectCoverage `json:"projects"`
+}
+
+// projectCoverage is the line coverage measured by one test project.
+type projectCoverage struct {
+	Name    string  `json:"name"`
+	Path    string  `json:"path"`
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// linePercent returns covered/total in percent (0 when there are no lines).
+func linePercent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+// buildCoverageSummary parses the Cobertura reports of files into a summary.
+func buildCoverageSummary(gitRoot string, files []projectCoverageFile) (*coverageSummary, error) {
+	summary := &coverageSummary{Projects: []projectCoverage{}}
+	var reports []string
+	for _, f := range files {
+		report, err := coverage.ParseFile(f.path)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", f.path, err)
+		}
+		summary.Projects = append(summary.Projects, projectCoverage{
+			Name:    f.project.Name,
+			Path:    f.project.Path,
+			Covered: report.LinesCovered,
+			Total:   report.LinesValid,
+			Percent: linePercent(report.LinesCovered, report.LinesValid),
+		})
+		reports = append(reports, f.path)
+	}
+
+	merged, err := coverage.MergeLineCoverage(gitRoot, reports)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range merged {
+		summary.Covered += f.Covered
+		summary.Total += f.Total
+	}
+	summary.Percent = linePercent(summary.Covered, summary.Total)
+	return summary, nil
+}
+
+// checkCoverageMin fails a --coverage test run whose overall line coverage is
+// below CoverageMin. Runs that produced no coverage reports (e.g. everything
+// was cached) are not checked.
+func (r *Runner) checkCoverageMin(targets []*project.Project) error {
+	if r.opts.CoverageMin <= 0 || !r.opts.Coverage || r.opts.DryRun || r.opts.Command != "test" {
+		return nil
+	}
+
+	files := r.testCoverageFiles(targets)
+	if len(files) == 0 {
+		return nil
+	}
+	summary, err := buildCoverageSummary(r.gitRoot, files)
+	if err != nil {
+		return fmt.Errorf("checking coverage minimum: %w", err)
+	}
+	return checkCoverageMin(summary, r.opts.CoverageMin)
+}
+
+// checkCoverageMin returns an error if summary's overall line coverage is
+// below minPercent.
+func checkCoverageMin(summary *coverageSummary, minPercent float64) error {
+	if summary.Total == 0 || summary.Percent >= minPercent {
+		return nil
+	}
+	return fmt.Errorf("line coverage %.1f%% (%d/%d lines) is below the minimum of %.1f%%",
+		summary.Percent, summary.Covered, summary.Total, minPercent)
+}
+
+// writeCoverageSummary prints the overall and per-project line coverage of a
+// --coverage test run (--coverage-summary) and/or writes it as JSON
+// (--coverage-summary-json).
+func (r *Runner) writeCoverageSummary(targets []*project.Project) {
+	if (!r.opts.CoverageSummary && r.opts.CoverageSummaryJSON == "") || !r.opts.Coverage || r.opts.DryRun || r.opts.Command != "test" {
+		return
+	}
+
+	files := r.testCoverageFiles(targets)
+	if len(files) == 0 {
+		term.Warnf("no coverage files found, no coverage summary")
+		return
+	}
+	summary, err := buildCoverageSummary(r.gitRoot, files)
+	if err != nil {
+		term.Warnf("failed to summarize coverage: %v", err)
+		return
+	}
+
+	if r.opts.CoverageSummary && !r.opts.Quiet {
+		term.Info("Line coverage: %.1f%% (%d/%d lines)", summary.Percent, summary.Covered, summary.Total)
+		nameWidth := 0
+		for _, p := range summary.Projects {
+			nameWidth = max(nameWidth, len(p.Name))
+		}
+		for _, p := range summary.Projects {
+			term.Printf("  %-*s  %5.1f%%  %d/%d\n", nameWidth, p.Name, p.Percent, p.Covered, p.Total)
+		}
+	}
+
+	if path := r.opts.CoverageSummaryJSON; path != "" {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err == nil {
+			if dir := filepath.Dir(path); dir != "." {
+				os.MkdirAll(dir, 0755)
+			}
+			err = os.WriteFile(path, append(data, '\n'), 0644)
+		}
+		if err != nil {
+			term.Warnf("failed to write coverage summary: %v", err)
+		}
+	}
+}
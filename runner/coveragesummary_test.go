@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestBuildCoverageSummary(t *testing.T) {
+	gitRoot := t.TempDir()
+	write := func(name, lines string) string {
+		path := filepath.Join(gitRoot, name)
+		content := `<coverage><sources><source>` + gitRoot + `</source></sources><packages><package name="App"><classes>
+<class name="App.Foo" filename="src/Foo.cs"><lines>` + lines + `</lines></class>
+</classes></package></packages></coverage>`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	// Each project covers a different line of the same 4-line file
+	a := write("a.xml", `<line number="1" hits="1"/><line number="2" hits="0"/><line number="3" hits="0"/><line number="4" hits="0"/>`)
+	b := write("b.xml", `<line number="1" hits="0"/><line number="2" hits="5"/><line number="3" hits="0"/><line number="4" hits="0"/>`)
+
+	summary, err := buildCoverageSummary(gitRoot, []projectCoverageFile{
+		{project: &project.Project{Name: "A.Tests", Path: "A.Tests/A.Tests.csproj"}, path: a},
+		{project: &project.Project{Name: "B.Tests", Path: "B.Tests/B.Tests.csproj"}, path: b},
+	})
+	if err != nil {
+		t.Fatalf("buildCoverageSummary() failed: %v", err)
+	}
+
+	if summary.Covered != 2 || summary.Total != 4 || summary.Percent != 50 {
+		t.Errorf("overall = %d/%d (%.1f%%), want 2/4 (50%%)", summary.Covered, summary.Total, summary.Percent)
+	}
+	if len(summary.Projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(summary.Projects))
+	}
+	for _, p := range summary.Projects {
+		if p.Covered != 1 || p.Total != 4 || p.Percent != 25 {
+			t.Errorf("%s = %d/%d (%.1f%%), want 1/4 (25%%)", p.Name, p.Covered, p.Total, p.Percent)
+		}
+	}
+}
+
+func TestCheckCoverageMin(t *testing.T) {
+	summary := &coverageSummary{Covered: 3, Total: 4, Percent: 75}
+	if err := checkCoverageMin(summary, 75); err != nil {
+		t.Errorf("75%% against 75%%: unexpected error %v", err)
+	}
+	err := checkCoverageMin(summary, 80)
+	if err == nil {
+		t.Fatal("75% against 80%: expected an error")
+	}
+	if want := "line coverage 75.0% (3/4 lines) is below the minimum of 80.0%"; err.Error() != want {
+		t.Errorf("error = %q, want %q", err, want)
+	}
+	if err := checkCoverageMin(&coverageSummary{}, 80); err != nil {
+		t.Errorf("no lines: unexpected error %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// changedInputsSince returns files in relevantDirs that differ between commit
+// and the current working tree (including uncommitted and untracked files).
+func changedInputsSince(gitRoot, commit string, relevantDirs []string) ([]string, error) {
+	changed, err := git.GetChangedFiles(gitRoot, commit)
+	if err != nil {
+		return nil, err
+	}
+	changed = append(changed, git.GetDirtyFiles(gitRoot)...)
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, f := range project.FilterFilesToProject(changed, relevantDirs) {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// findProjectByName resolves a project by name or relative .csproj path.
+func (r *Runner) findProjectByName(query string) *project.Project {
+	for _, p := range r.projects {
+		if p.Name == query || filepath.ToSlash(p.Path) == filepath.ToSlash(query) {
+			return p
+		}
+	}
+	return nil
+}
+
+// printDiffInputs lists the inputs of a project that changed since its last
+// successful cached run, explaining why it is running (or failing) now.
+func (r *Runner) printDiffInputs(query, argsHash string) error {
+	p := r.findProjectByName(query)
+	if p == nil {
+		return fmt.Errorf("unknown project: %s", query)
+	}
+
+	entry := r.db.LastSuccessEntry(argsHash, p.Path)
+	if entry == nil {
+		term.Info("%s has no successful cached run for these arguments", p.Name)
+		return nil
+	}
+	lastRun := time.Unix(entry.LastRun, 0).Format(time.RFC3339)
+	if entry.Commit == "" {
+		term.Info("%s last succeeded at %s, but no commit was recorded for that run", p.Name, lastRun)
+		return nil
+	}
+
+	relevantDirs := project.GetRelevantDirs(p, r.forwardGraph)
+	files, err := changedInputsSince(r.gitRoot, entry.Commit, relevantDirs)
+	if err != nil {
+		return fmt.Errorf("diffing against %s: %w", entry.Commit, err)
+	}
+
+	term.Info("%s last succeeded at %s (commit %s)", p.Name, lastRun, entry.Commit)
+	if len(files) == 0 {
+		term.Dim("No input files changed since then")
+		return nil
+	}
+	term.Info("%d input file(s) changed since then:", len(files))
+	for _, f := range files {
+		term.Printf("  %s\n", f)
+	}
+	return nil
+}
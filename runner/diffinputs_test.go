@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChangedInputsSince(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	gitRoot := t.TempDir()
+	runGit := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", gitRoot, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(gitRoot, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// State 1: last successful run
+	runGit("init", "-q")
+	write("src/App/App.cs", "class App {}")
+	write("src/App/Util.cs", "class Util {}")
+	write("src/Other/Other.cs", "class Other {}")
+	runGit("add", "-A")
+	runGit("commit", "-q", "-m", "initial")
+	commit := runGit("rev-parse", "--short", "HEAD")
+
+	relevantDirs := []string{"src/App"}
+
+	files, err := changedInputsSince(gitRoot, commit, relevantDirs)
+	if err != nil {
+		t.Fatalf("changedInputsSince() failed: %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no changes at the recorded commit, got %v", files)
+	}
+
+	// State 2: a committed change, an uncommitted change, a new file, and a
+	// change outside the project's relevant dirs
+	write("src/App/App.cs", "class App { int x; }")
+	runGit("commit", "-q", "-am", "change app")
+	write("src/App/Util.cs", "class Util { int y; }")
+	write("src/App/New.cs", "class New {}")
+	write("src/Other/Other.cs", "class Other { int z; }")
+
+	files, err = changedInputsSince(gitRoot, commit, relevantDirs)
+	if err != nil {
+		t.Fatalf("changedInputsSince() failed: %v", err)
+	}
+	want := []string{"src/App/App.cs", "src/App/New.cs", "src/App/Util.cs"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("changedInputsSince() = %v, want %v", files, want)
+	}
+}
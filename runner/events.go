@@ -0,0 +1,111 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// EventSink receives structured progress events when the runner is embedded
+// as a library, alongside the usual terminal output (set Options.Quiet to
+// render a UI of your own). Publish is called from worker goroutines, so
+// implementations must be safe for concurrent use and should not block.
+type EventSink interface {
+	Publish(Event)
+}
+
+// EventFunc adapts a function to an EventSink.
+type EventFunc func(Event)
+
+// Publish calls f(e).
+func (f EventFunc) Publish(e Event) { f(e) }
+
+// Event is one of ProjectStarted, ProjectLine, ProjectFinished or SummaryReady.
+type Event interface {
+	isEvent()
+}
+
+// ProjectStarted is published when a worker starts building or testing a project.
+type ProjectStarted struct {
+	Project *project.Project
+}
+
+// ProjectLine is published for each non-empty line of dotnet output.
+type ProjectLine struct {
+	Project *project.Project
+	Line    string
+}
+
+// ProjectFinished is published when a project's run completes. Solution-level
+// runs publish only this event, for a pseudo-project named after the .sln.
+type ProjectFinished struct {
+	Result ProjectResult
+}
+
+// SummaryReady is published once all projects of a run (or of a watch-mode
+// batch) have finished.
+type SummaryReady struct {
+	Results  []ProjectResult
+	Cached   int
+	Duration time.Duration
+	Success  bool
+}
+
+func (ProjectStarted) isEvent()  {}
+func (ProjectLine) isEvent()     {}
+func (ProjectFinished) isEvent() {}
+func (SummaryReady) isEvent()    {}
+
+// ProjectResult is the outcome of running one project.
+type ProjectResult struct {
+	Project  *project.Project
+	Success  bool
+	Output   string
+	Duration time.Duration
+	// BuildOnly is set for non-test projects that were only built during a test run
+	BuildOnly bool
+	// SkippedByFilter is set when the user's filter excluded every test
+	SkippedByFilter bool
+	// DryRun is set when the command was only printed (Output holds it)
+	DryRun bool
+}
+
+func (res runResult) public() ProjectResult {
+	return ProjectResult{
+		Project:         res.project,
+		Success:         res.success,
+		Output:          res.output,
+		Duration:        res.duration,
+		BuildOnly:       res.buildOnly,
+		SkippedByFilter: res.skippedByFilter,
+		DryRun:          res.dryRun,
+	}
+}
+
+// publish sends e to the configured EventSink, if any.
+func (r *Runner) publish(e Event) {
+	if r.opts.EventSink != nil {
+		r.opts.EventSink.Publish(e)
+	}
+}
+
+// addResult records a completed result for reporting and publishes it.
+func (r *Runner) addResult(res runResult) {
+	r.results = append(r.results, res)
+	r.publish(ProjectFinished{Result: res.public()})
+}
+
+// publishSummary publishes SummaryReady for results.
+func (r *Runner) publishSummary(results []runResult, cached int, duration time.Duration) {
+	if r.opts.EventSink == nil {
+		return
+	}
+	s := SummaryReady{Cached: cached, Duration: duration, Success: true}
+	for _, res := range results {
+		s.Results = append(s.Results, res.public())
+		if !res.success {
+			s.Success = false
+		}
+	}
+	r.publish(s)
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestStatusLineWriterPublishesLines(t *testing.T) {
+	var lines []string
+	w := &statusLineWriter{
+		project: &project.Project{Name: "App"},
+		status:  make(chan statusUpdate, 10),
+		buffer:  &bytes.Buffer{},
+		onLine:  func(line string) { lines = append(lines, line) },
+	}
+
+	w.Write([]byte("Restoring...\n  Build succ"))
+	w.Write([]byte("eeded.\n\n"))
+	// Lines keep flowing once the writer switches to direct mode on failure
+	w.Write([]byte("Build FAILED\nerror CS0103: x\n"))
+
+	want := []string{"Restoring...", "Build succeeded.", "Build FAILED", "error CS0103: x"}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("published lines = %q, want %q", lines, want)
+	}
+}
+
+func TestRunnerPublishesResults(t *testing.T) {
+	var events []Event
+	r := New(&Options{EventSink: EventFunc(func(e Event) { events = append(events, e) })})
+
+	app := &project.Project{Name: "App"}
+	lib := &project.Project{Name: "Lib"}
+	r.addResult(runResult{project: app, success: true, duration: time.Second})
+	r.addResult(runResult{project: lib, success: false, output: "Build FAILED"})
+	r.publishSummary(r.results, 3, 2*time.Second)
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %#v", len(events), events)
+	}
+	finished, ok := events[1].(ProjectFinished)
+	if !ok || finished.Result.Project != lib || finished.Result.Success || finished.Result.Output != "Build FAILED" {
+		t.Errorf("events[1] = %#v, want failed ProjectFinished for Lib", events[1])
+	}
+	summary, ok := events[2].(SummaryReady)
+	if !ok {
+		t.Fatalf("events[2] = %#v, want SummaryReady", events[2])
+	}
+	if summary.Success || summary.Cached != 3 || summary.Duration != 2*time.Second || len(summary.Results) != 2 {
+		t.Errorf("unexpected summary: %#v", summary)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/runar-rkmedia/donotnet/term"
 	"github.com/runar-rkmedia/donotnet/testresults"
 )
@@ -58,3 +61,47 @@ func getFailedTestFilter(cachedOutput []byte, reportsDir, projectName string) st
 
 	return testresults.BuildFilterString(failedTests)
 }
+
+// recordTestOutcomes stores the per-test outcomes of a test run in the cache.
+// Parameterized tests share a fully qualified name and count as failed if any
+// case failed; tests that did not execute keep their previous outcome.
+func (r *Runner) recordTestOutcomes(res runResult) {
+	if len(res.testResults) == 0 {
+		return
+	}
+	now := time.Now()
+	byName := make(map[string]cache.TestOutcome)
+	for _, t := range res.testResults {
+		if t.Outcome != testresults.OutcomePassed && t.Outcome != testresults.OutcomeFailed {
+			continue
+		}
+		o := byName[t.FullyQualifiedName]
+		o.Name = t.FullyQualifiedName
+		o.Failed = o.Failed || t.Outcome == testresults.OutcomeFailed
+		o.At = now
+		byName[t.FullyQualifiedName] = o
+	}
+	outcomes := make([]cache.TestOutcome, 0, len(byName))
+	for _, o := range byName {
+		outcomes = append(outcomes, o)
+	}
+	if err := r.db.RecordTestOutcomes(res.project.Path, outcomes); err != nil {
+		term.Verbose("  [%s] could not record test outcomes: %v", res.project.Name, err)
+	}
+}
+
+// failedTestFilter returns the --failed filter for p. Tests whose last
+// recorded outcome is a failure are preferred, so tests that passed on a
+// narrowed rerun drop out; without a history it falls back to the TRX file
+// and cached output (see getFailedTestFilter).
+func (r *Runner) failedTestFilter(p *project.Project, cachedOutput []byte) string {
+	if failing := r.db.GetFailingTests(p.Path); len(failing) > 0 {
+		term.Verbose("  [%s] %d tests still failing in test history", p.Name, len(failing))
+		tests := make([]testresults.FailedTest, len(failing))
+		for i, name := range failing {
+			tests[i] = testresults.FailedTest{FullyQualifiedName: name}
+		}
+		return testresults.BuildFilterString(tests)
+	}
+	return getFailedTestFilter(cachedOutput, r.reportsDir, p.Name)
+}
@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// --affected-graph formats.
+const (
+	AffectedGraphDot     = "dot"     // Graphviz DOT
+	AffectedGraphMermaid = "mermaid" // Mermaid flowchart, for embedding in Markdown
+)
+
+// Node colors in the affected graph.
+const (
+	graphColorChanged  = "#f4a6a6" // changed directly
+	graphColorAffected = "#f7e08a" // affected through a dependency
+	graphColorCached   = "#a8dba8" // not affected
+)
+
+// writeAffectedGraph writes the dependency graph in format to path, or
+// stdout when path is empty. Edges point from a dependency to its dependents
+// (graph is the reverse dependency graph); nodes are colored by whether the
+// project changed, is affected through a dependency, or is unaffected, and
+// test projects are drawn with rounded shapes.
+func writeAffectedGraph(path, format string, projects []*project.Project, graph map[string][]string, changed, affected map[string]bool) error {
+	write := writeAffectedGraphDot
+	if format == AffectedGraphMermaid {
+		write = writeAffectedGraphMermaid
+	}
+	if path == "" {
+		return write(os.Stdout, projects, graph, changed, affected)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, projects, graph, changed, affected); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// graphNodeState returns the color class of p: changed, affected or cached.
+func graphNodeState(p *project.Project, changed, affected map[string]bool) string {
+	if changed[p.Path] {
+		return "changed"
+	} else if affected[p.Path] {
+		return "affected"
+	}
+	return "cached"
+}
+
+// graphStateColors maps graphNodeState to its fill color.
+var graphStateColors = map[string]string{
+	"changed":  graphColorChanged,
+	"affected": graphColorAffected,
+	"cached":   graphColorCached,
+}
+
+// writeAffectedGraphDot writes the DOT document described by writeAffectedGraph to w.
+func writeAffectedGraphDot(w io.Writer, projects []*project.Project, graph map[string][]string, changed, affected map[string]bool) error {
+	sorted := append([]*project.Project{}, projects...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	id := func(path string) string { return strconv.Quote(filepath.ToSlash(path)) }
+
+	fmt.Fprintln(w, "digraph affected {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box, style=filled];")
+	for _, p := range sorted {
+		color := graphStateColors[graphNodeState(p, changed, affected)]
+		shape := ""
+		if p.IsTest {
+			shape = ", shape=ellipse"
+		}
+		fmt.Fprintf(w, "  %s [label=%s, fillcolor=%q%s];\n", id(p.Path), strconv.Quote(p.Name), color, shape)
+	}
+	for _, p := range sorted {
+		dependents := append([]string{}, graph[p.Path]...)
+		sort.Strings(dependents)
+		for _, dep := range dependents {
+			fmt.Fprintf(w, "  %s -> %s;\n", id(p.Path), id(dep))
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// writeAffectedGraphMermaid writes the graph described by writeAffectedGraph
+// to w as a Mermaid flowchart. Node ids are generated, since Mermaid ids
+// can't hold paths.
+func writeAffectedGraphMermaid(w io.Writer, projects []*project.Project, graph map[string][]string, changed, affected map[string]bool) error {
+	sorted := append([]*project.Project{}, projects...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+	ids := make(map[string]string, len(sorted))
+	for i, p := range sorted {
+		ids[p.Path] = "p" + strconv.Itoa(i)
+	}
+	label := func(name string) string { return `"` + strings.ReplaceAll(name, `"`, "#quot;") + `"` }
+
+	fmt.Fprintln(w, "flowchart LR")
+	for _, p := range sorted {
+		node := "[" + label(p.Name) + "]"
+		if p.IsTest {
+			node = "([" + label(p.Name) + "])"
+		}
+		fmt.Fprintf(w, "  %s%s:::%s\n", ids[p.Path], node, graphNodeState(p, changed, affected))
+	}
+	for _, p := range sorted {
+		dependents := append([]string{}, graph[p.Path]...)
+		sort.Strings(dependents)
+		for _, dep := range dependents {
+			if id, ok := ids[dep]; ok {
+				fmt.Fprintf(w, "  %s --> %s\n", ids[p.Path], id)
+			}
+		}
+	}
+	for _, state := range []string{"changed", "affected", "cached"} {
+		fmt.Fprintf(w, "  classDef %s fill:%s\n", state, graphStateColors[state])
+	}
+	return nil
+}
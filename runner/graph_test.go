@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestWriteAffectedGraphDot(t *testing.T) {
+	core := &project.Project{Name: "Core", Path: "src/Core/Core.csproj"}
+	api := &project.Project{Name: "Api", Path: "src/Api/Api.csproj"}
+	apiTests := &project.Project{Name: "Api.Tests", Path: "tests/Api.Tests/Api.Tests.csproj", IsTest: true}
+	tool := &project.Project{Name: "Tool", Path: "tools/Tool/Tool.csproj"}
+
+	graph := map[string][]string{
+		core.Path: {api.Path},
+		api.Path:  {apiTests.Path},
+	}
+	changed := map[string]bool{api.Path: true}
+	affected := map[string]bool{api.Path: true, apiTests.Path: true}
+
+	var buf bytes.Buffer
+	if err := writeAffectedGraphDot(&buf, []*project.Project{tool, apiTests, core, api}, graph, changed, affected); err != nil {
+		t.Fatalf("writeAffectedGraphDot() failed: %v", err)
+	}
+
+	want := `digraph affected {
+  rankdir=LR;
+  node [shape=box, style=filled];
+  "src/Api/Api.csproj" [label="Api", fillcolor="#f4a6a6"];
+  "src/Core/Core.csproj" [label="Core", fillcolor="#a8dba8"];
+  "tests/Api.Tests/Api.Tests.csproj" [label="Api.Tests", fillcolor="#f7e08a", shape=ellipse];
+  "tools/Tool/Tool.csproj" [label="Tool", fillcolor="#a8dba8"];
+  "src/Api/Api.csproj" -> "tests/Api.Tests/Api.Tests.csproj";
+  "src/Core/Core.csproj" -> "src/Api/Api.csproj";
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeAffectedGraphDot() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteAffectedGraphMermaid(t *testing.T) {
+	core := &project.Project{Name: "Core", Path: "src/Core/Core.csproj"}
+	api := &project.Project{Name: "Api", Path: "src/Api/Api.csproj"}
+	apiTests := &project.Project{Name: "Api.Tests", Path: "tests/Api.Tests/Api.Tests.csproj", IsTest: true}
+
+	graph := map[string][]string{
+		core.Path: {api.Path},
+		api.Path:  {apiTests.Path},
+	}
+	changed := map[string]bool{api.Path: true}
+	affected := map[string]bool{api.Path: true, apiTests.Path: true}
+
+	var buf bytes.Buffer
+	if err := writeAffectedGraphMermaid(&buf, []*project.Project{apiTests, core, api}, graph, changed, affected); err != nil {
+		t.Fatalf("writeAffectedGraphMermaid() failed: %v", err)
+	}
+
+	want := `flowchart LR
+  p0["Api"]:::changed
+  p1["Core"]:::cached
+  p2(["Api.Tests"]):::affected
+  p0 --> p2
+  p1 --> p0
+  classDef changed fill:#f4a6a6
+  classDef affected fill:#f7e08a
+  classDef cached fill:#a8dba8
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeAffectedGraphMermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
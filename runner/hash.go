@@ -2,25 +2,72 @@ package runner
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
-	ignore "github.com/sabhiram/go-gitignore"
 	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/config"
+	"github.com/runar-rkmedia/donotnet/git"
 	"github.com/runar-rkmedia/donotnet/project"
+	ignore "github.com/sabhiram/go-gitignore"
 )
 
+// HashIgnoreFileName is the gitignore-syntax file inside the .donotnet config
+// directory that replaces DefaultHashIgnorePatterns when present.
+const HashIgnoreFileName = "hashignore"
+
+// DefaultHashIgnorePatterns match generated files that are excluded from
+// content hashing, so regenerating them does not invalidate the cache.
+var DefaultHashIgnorePatterns = []string{
+	"*.g.cs",
+	"*.Designer.cs",
+	"*.generated.cs",
+	"Generated/",
+}
+
+// loadHashIgnore returns the hash ignore matcher for root, using
+// .donotnet/hashignore if it exists and the defaults otherwise.
+func loadHashIgnore(root string) *ignore.GitIgnore {
+	path := filepath.Join(root, config.ConfigDirName, HashIgnoreFileName)
+	if gi, err := ignore.CompileIgnoreFile(path); err == nil {
+		return gi
+	}
+	return ignore.CompileIgnoreLines(DefaultHashIgnorePatterns...)
+}
+
+// CacheVersionFileName is the optional file inside the .donotnet config
+// directory whose content salts every args hash. Committing a change to it
+// (e.g. bumping a number) invalidates all cached results repo-wide, without
+// deleting the cache.
+const CacheVersionFileName = "cache-version"
+
+// cacheVersionHashArgs returns the hash input for root's cache version file,
+// or nil when it is absent or empty, so cache keys stay as they were.
+func cacheVersionHashArgs(root string) []string {
+	content, err := os.ReadFile(filepath.Join(root, config.ConfigDirName, CacheVersionFileName))
+	if err != nil {
+		return nil
+	}
+	version := strings.TrimSpace(string(content))
+	if version == "" {
+		return nil
+	}
+	return []string{"--cache-version=" + version}
+}
+
 // ProjectCacheKey computes the cache key for a project by hashing its
 // relevant source files and combining with the args hash.
 func ProjectCacheKey(p *project.Project, gitRoot string, forwardGraph map[string][]string, argsHash string) string {
-	relevantDirs := project.GetRelevantDirs(p, forwardGraph)
-	contentHash := ComputeContentHash(gitRoot, relevantDirs)
-	return cache.MakeKey(contentHash, argsHash, p.Path)
+	return NewContentHasher(gitRoot, HashModeContent).ProjectCacheKey(p, forwardGraph, argsHash)
 }
 
 // HashArgs creates a hash of command arguments for cache keys.
@@ -32,6 +79,25 @@ func HashArgs(args []string) string {
 	return fmt.Sprintf("%x", h[:8])
 }
 
+// envHashArgs returns one hash input per environment variable in names
+// (sorted, duplicates dropped) with its current value, so runs under different
+// values of e.g. ASPNETCORE_ENVIRONMENT get separate cache entries. Unset
+// variables hash the same as empty ones.
+func envHashArgs(names []string) []string {
+	seen := make(map[string]bool)
+	var args []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		args = append(args, "--cache-env="+name+"="+os.Getenv(name))
+	}
+	sort.Strings(args)
+	return args
+}
+
 // isNonBuildFile returns true for files that don't affect the build.
 func isNonBuildFile(name string) bool {
 	lower := strings.ToLower(name)
@@ -53,54 +119,82 @@ func isNonBuildFile(name string) bool {
 	return false
 }
 
+// Hash modes select how ContentHasher fingerprints a file.
+const (
+	// HashModeContent hashes file contents (the default). Correct across
+	// branch switches and checkouts, which rewrite files with new mtimes.
+	HashModeContent = "content"
+	// HashModeMtime hashes path, size and modification time without reading
+	// files. Much faster, but only as trustworthy as the mtimes.
+	HashModeMtime = "mtime"
+)
+
 // ComputeContentHash computes a hash of all source files in the given directories.
 func ComputeContentHash(root string, dirs []string) string {
-	h := sha256.New()
+	return NewContentHasher(root, HashModeContent).Hash(dirs)
+}
+
+// ContentHasher computes content hashes like ComputeContentHash, but walks and
+// hashes each directory's files only once, so a library shared by many
+// projects is read once per run instead of once per dependent. Use a new
+// ContentHasher whenever files may have changed (e.g. for each watch rerun).
+// It is safe for concurrent use.
+type ContentHasher struct {
+	root       string
+	mode       string
+	gitIgnore  *ignore.GitIgnore
+	hashIgnore *ignore.GitIgnore
+	submodules map[string]bool
+
+	mu   sync.Mutex
+	dirs map[string]*dirDigest
+}
+
+// dirDigest holds the digests of the source files below one directory.
+type dirDigest struct {
+	once  sync.Once
+	files []fileDigest
+}
+
+// fileDigest is what one source file contributes to a content hash: the
+// SHA256 of its content, or of its size and modification time with
+// HashModeMtime. Unreadable files keep the zero sum.
+type fileDigest struct {
+	path string
+	sum  [sha256.Size]byte
+}
 
+// NewContentHasher returns a ContentHasher for the repository at root, using
+// the given hash mode ("" = HashModeContent).
+func NewContentHasher(root, mode string) *ContentHasher {
+	if mode == "" {
+		mode = HashModeContent
+	}
+	c := &ContentHasher{
+		root:       root,
+		mode:       mode,
+		hashIgnore: loadHashIgnore(root),
+		submodules: project.SubmoduleDirs(root),
+		dirs:       make(map[string]*dirDigest),
+	}
 	// Try to load .gitignore from root
-	var gitIgnore *ignore.GitIgnore
-	gitignorePath := filepath.Join(root, ".gitignore")
-	if gi, err := ignore.CompileIgnoreFile(gitignorePath); err == nil {
-		gitIgnore = gi
+	if gi, err := ignore.CompileIgnoreFile(filepath.Join(root, ".gitignore")); err == nil {
+		c.gitIgnore = gi
 	}
+	return c
+}
 
-	// Collect all source files
-	var files []string
+// Hash returns the content hash of all source files in dirs: a hash over the
+// path and content digest of each file, in path order. Returns "" when there
+// are no files.
+func (c *ContentHasher) Hash(dirs []string) string {
+	var files []fileDigest
 	for _, dir := range dirs {
 		absDir := dir
 		if !filepath.IsAbs(absDir) {
-			absDir = filepath.Join(root, absDir)
+			absDir = filepath.Join(c.root, absDir)
 		}
-		filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return nil
-			}
-
-			name := d.Name()
-
-			if d.IsDir() {
-				if project.ShouldSkipDir(name) {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-
-			// Check gitignore
-			if gitIgnore != nil {
-				relPath, err := filepath.Rel(root, path)
-				if err == nil && gitIgnore.MatchesPath(relPath) {
-					return nil
-				}
-			}
-
-			// Skip non-build files
-			if isNonBuildFile(name) {
-				return nil
-			}
-
-			files = append(files, path)
-			return nil
-		})
+		files = append(files, c.dir(absDir)...)
 	}
 
 	if len(files) == 0 {
@@ -108,22 +202,160 @@ func ComputeContentHash(root string, dirs []string) string {
 	}
 
 	// Sort for deterministic ordering
-	sort.Strings(files)
+	sort.SliceStable(files, func(i, j int) bool { return files[i].path < files[j].path })
 
+	h := sha256.New()
+	// Fold the mode into the hash so switching modes never hits entries
+	// cached under the other one
+	if c.mode != HashModeContent {
+		h.Write([]byte(c.mode))
+		h.Write([]byte{0})
+	}
 	for _, f := range files {
-		h.Write([]byte(f))
+		h.Write([]byte(f.path))
 		h.Write([]byte{0})
+		h.Write(f.sum[:])
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+}
 
-		content, err := os.ReadFile(f)
+// ProjectCacheKey is like the package-level ProjectCacheKey, but reuses the
+// digests of directories this hasher has already seen.
+func (c *ContentHasher) ProjectCacheKey(p *project.Project, forwardGraph map[string][]string, argsHash string) string {
+	contentHash := c.Hash(project.GetRelevantDirs(p, forwardGraph))
+	return cache.MakeKey(contentHash, argsHash, p.Path)
+}
+
+// dir returns the digests of the source files below absDir, hashing them on
+// first use.
+func (c *ContentHasher) dir(absDir string) []fileDigest {
+	c.mu.Lock()
+	d, ok := c.dirs[absDir]
+	if !ok {
+		d = &dirDigest{}
+		c.dirs[absDir] = d
+	}
+	c.mu.Unlock()
+
+	d.once.Do(func() {
+		for _, path := range c.sourceFiles(absDir) {
+			d.files = append(d.files, c.digest(path))
+		}
+		d.files = append(d.files, c.importDigests(absDir, d.files)...)
+	})
+	return d.files
+}
+
+// digest fingerprints the file at path according to the hash mode.
+func (c *ContentHasher) digest(path string) fileDigest {
+	f := fileDigest{path: path}
+	if c.mode == HashModeMtime {
+		if info, err := os.Stat(path); err == nil {
+			var buf [16]byte
+			binary.LittleEndian.PutUint64(buf[0:8], uint64(info.Size()))
+			binary.LittleEndian.PutUint64(buf[8:16], uint64(info.ModTime().UnixNano()))
+			f.sum = sha256.Sum256(buf[:])
+		}
+	} else if file, err := os.Open(path); err == nil {
+		h := sha256.New()
+		if _, err := io.Copy(h, file); err == nil {
+			h.Sum(f.sum[:0])
+		}
+		file.Close()
+	}
+	return f
+}
+
+// importDigests returns the digests of files outside absDir that the MSBuild
+// files among files pull in with <Import Project>, following imports of
+// imports, so that editing e.g. a shared ../Common.targets invalidates the
+// projects importing it (and their dependents).
+func (c *ContentHasher) importDigests(absDir string, files []fileDigest) []fileDigest {
+	seen := make(map[string]bool)
+	var queue []string
+	for _, f := range files {
+		if isMSBuildFile(f.path) {
+			seen[f.path] = true
+			queue = append(queue, f.path)
+		}
+	}
+
+	var digests []fileDigest
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		content, err := os.ReadFile(path)
 		if err != nil {
-			h.Write([]byte{})
-		} else {
-			h.Write(content)
+			continue
+		}
+		for _, imp := range project.ParseImports(path, content) {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			queue = append(queue, imp)
+			// Files below absDir are hashed by the walk already
+			if rel, err := filepath.Rel(absDir, imp); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+			digests = append(digests, c.digest(imp))
 		}
-		h.Write([]byte{0})
 	}
+	return digests
+}
 
-	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+// isMSBuildFile reports whether path is an MSBuild file that can import others.
+func isMSBuildFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csproj", ".props", ".targets":
+		return true
+	}
+	return false
+}
+
+// sourceFiles lists the files below absDir that affect builds.
+func (c *ContentHasher) sourceFiles(absDir string) []string {
+	var files []string
+	filepath.WalkDir(absDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		name := d.Name()
+
+		if d.IsDir() {
+			if project.ShouldSkipDir(name) {
+				return filepath.SkipDir
+			}
+			// Submodules and nested worktrees have their own lifecycle; a
+			// walk that starts inside one (a project in an included
+			// submodule) still works
+			if path != absDir && (c.submodules[path] || git.IsCheckout(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Check gitignore and generated files
+		if relPath, err := filepath.Rel(c.root, path); err == nil {
+			if c.gitIgnore != nil && c.gitIgnore.MatchesPath(relPath) {
+				return nil
+			}
+			if c.hashIgnore.MatchesPath(relPath) {
+				return nil
+			}
+		}
+
+		// Skip non-build files
+		if isNonBuildFile(name) {
+			return nil
+		}
+
+		files = append(files, path)
+		return nil
+	})
+	return files
 }
 
 // restoreRelevantExts lists file extensions that can affect NuGet restore.
@@ -217,12 +449,40 @@ func anyFileNewer(dir string, names []string, than time.Time) bool {
 	return false
 }
 
+var (
+	assemblyNameRegex = regexp.MustCompile(`<AssemblyName>\s*([^<]+?)\s*</AssemblyName>`)
+	outputTypeRegex   = regexp.MustCompile(`<OutputType>\s*([^<]+?)\s*</OutputType>`)
+)
+
+// buildOutputNames returns the file names a build of the project may produce.
+// The assembly name comes from <AssemblyName> when set to a literal value,
+// otherwise the project file name. Exe and WinExe projects may also produce
+// an .exe or an extensionless apphost instead of (or next to) the .dll.
+func buildOutputNames(projectPath string) []string {
+	assemblyName := strings.TrimSuffix(filepath.Base(projectPath), ".csproj")
+	var outputType string
+	if content, err := os.ReadFile(projectPath); err == nil {
+		if m := assemblyNameRegex.FindSubmatch(content); m != nil && !strings.Contains(string(m[1]), "$(") {
+			assemblyName = string(m[1])
+		}
+		if m := outputTypeRegex.FindSubmatch(content); m != nil {
+			outputType = string(m[1])
+		}
+	}
+
+	names := []string{assemblyName + ".dll"}
+	if strings.EqualFold(outputType, "Exe") || strings.EqualFold(outputType, "WinExe") {
+		names = append(names, assemblyName+".exe", assemblyName)
+	}
+	return names
+}
+
 // canSkipBuild checks if --no-build can be safely used.
-// Returns true if output DLL exists and is newer than all source files
+// Returns true if the build output exists and is newer than all source files
 // in the project AND all its transitive dependencies.
 func canSkipBuild(projectPath string, relevantDirs []string, gitRoot string) bool {
 	projectDir := filepath.Dir(projectPath)
-	projectName := strings.TrimSuffix(filepath.Base(projectPath), ".csproj")
+	outputNames := buildOutputNames(projectPath)
 
 	// Find the output DLL - check common locations
 	var dllInfo os.FileInfo
@@ -233,13 +493,17 @@ func canSkipBuild(projectPath string, relevantDirs []string, gitRoot string) boo
 		if err != nil || d.IsDir() {
 			return nil
 		}
-		if strings.EqualFold(d.Name(), projectName+".dll") {
+		for _, name := range outputNames {
+			if !strings.EqualFold(d.Name(), name) {
+				continue
+			}
 			info, err := d.Info()
 			if err == nil {
 				if dllInfo == nil || info.ModTime().After(dllInfo.ModTime()) {
 					dllInfo = info
 				}
 			}
+			break
 		}
 		return nil
 	})
@@ -249,16 +513,25 @@ func canSkipBuild(projectPath string, relevantDirs []string, gitRoot string) boo
 	}
 
 	// Check if any source file in any relevant directory is newer than the DLL
-	newerSourceFound := false
+	newer, _ := newerSource(relevantDirs, gitRoot, dllInfo.ModTime())
+	return newer == ""
+}
+
+// newerSource returns the first file in relevantDirs (skipping build output
+// and non-build files) modified after t, with its modification time, or ""
+// if every file is older.
+func newerSource(relevantDirs []string, gitRoot string, t time.Time) (string, time.Time) {
+	var found string
+	var foundTime time.Time
 	for _, dir := range relevantDirs {
-		if newerSourceFound {
+		if found != "" {
 			break
 		}
 		if !filepath.IsAbs(dir) {
 			dir = filepath.Join(gitRoot, dir)
 		}
 		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
-			if err != nil || newerSourceFound {
+			if err != nil || found != "" {
 				return filepath.SkipAll
 			}
 			if d.IsDir() {
@@ -277,15 +550,15 @@ func canSkipBuild(projectPath string, relevantDirs []string, gitRoot string) boo
 				return nil
 			}
 
-			if info.ModTime().After(dllInfo.ModTime()) {
-				newerSourceFound = true
+			if info.ModTime().After(t) {
+				found, foundTime = path, info.ModTime()
 				return filepath.SkipAll
 			}
 			return nil
 		})
 	}
 
-	return !newerSourceFound
+	return found, foundTime
 }
 
 // binHasSpacedDirs checks if any directory under the project's bin/ directory
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// defaultIdleDelay is how long watch mode must be idle after a green run
+// before the --on-idle command fires.
+const defaultIdleDelay = 30 * time.Second
+
+// idleRunner fires a command once watch mode has been idle for a while after
+// a successful run. Any new activity cancels the pending timer, or the
+// command itself if it is already running. It fires at most once per green
+// run.
+type idleRunner struct {
+	delay time.Duration
+	run   func(ctx context.Context)
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	gen      int // invalidates timers that fire after being cancelled
+	cancel   context.CancelFunc
+	stopped  bool
+	inflight sync.WaitGroup
+}
+
+func newIdleRunner(delay time.Duration, run func(ctx context.Context)) *idleRunner {
+	return &idleRunner{delay: delay, run: run}
+}
+
+// green records a successful run and (re)starts the idle timer.
+func (i *idleRunner) green() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.stopped {
+		return
+	}
+	i.cancelLocked()
+	gen := i.gen
+	i.timer = time.AfterFunc(i.delay, func() { i.fire(gen) })
+}
+
+// activity records a file change or a new run, cancelling a pending or
+// running idle command.
+func (i *idleRunner) activity() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.cancelLocked()
+}
+
+func (i *idleRunner) cancelLocked() {
+	i.gen++
+	if i.timer != nil {
+		i.timer.Stop()
+		i.timer = nil
+	}
+	if i.cancel != nil {
+		i.cancel()
+		i.cancel = nil
+	}
+}
+
+func (i *idleRunner) fire(gen int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.stopped || gen != i.gen {
+		return
+	}
+	i.timer = nil
+
+	ctx, cancel := context.WithCancel(context.Background())
+	i.cancel = cancel
+	i.inflight.Add(1)
+	go func() {
+		defer i.inflight.Done()
+		defer cancel()
+		i.run(ctx)
+	}()
+}
+
+// stop cancels any pending or running command and waits for it to exit.
+func (i *idleRunner) stop() {
+	i.mu.Lock()
+	i.stopped = true
+	i.cancelLocked()
+	i.mu.Unlock()
+	i.inflight.Wait()
+}
+
+// runIdleCommand runs the --on-idle command from the git root. Failures are
+// reported but never affect watch mode.
+func (r *Runner) runIdleCommand(ctx context.Context) {
+	term.Dim("Idle: running %s", r.opts.OnIdle)
+	cmd := shellCommand(ctx, r.opts.OnIdle)
+	setupProcessGroup(cmd)
+	cmd.Dir = r.gitRoot
+	cmd.Env = os.Environ()
+
+	out, err := cmd.CombinedOutput()
+	switch {
+	case ctx.Err() != nil:
+		term.Dim("Idle command cancelled by new changes")
+	case err != nil:
+		term.Warnf("idle command failed: %v", err)
+		term.Verbose("%s", out)
+	default:
+		term.Dim("Idle command finished")
+		term.Verbose("%s", out)
+	}
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdleRunnerFiresAfterIdlePeriod(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	i := newIdleRunner(30*time.Millisecond, func(ctx context.Context) { fired <- struct{}{} })
+	defer i.stop()
+
+	i.green()
+	select {
+	case <-fired:
+		t.Fatal("idle command fired before the idle period")
+	case <-time.After(10 * time.Millisecond):
+	}
+	select {
+	case <-fired:
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle command did not fire after the idle period")
+	}
+
+	// It fires only once per green run
+	select {
+	case <-fired:
+		t.Fatal("idle command fired twice")
+	case <-time.After(60 * time.Millisecond):
+	}
+}
+
+func TestIdleRunnerActivityCancelsPendingCommand(t *testing.T) {
+	var calls atomic.Int32
+	i := newIdleRunner(30*time.Millisecond, func(ctx context.Context) { calls.Add(1) })
+	defer i.stop()
+
+	i.green()
+	time.Sleep(10 * time.Millisecond)
+	i.activity()
+	time.Sleep(60 * time.Millisecond)
+	if n := calls.Load(); n != 0 {
+		t.Errorf("expected a new event to cancel the pending command, got %d call(s)", n)
+	}
+}
+
+func TestIdleRunnerActivityCancelsRunningCommand(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	i := newIdleRunner(10*time.Millisecond, func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+	})
+	defer i.stop()
+
+	i.green()
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle command did not start")
+	}
+
+	i.activity()
+	select {
+	case <-cancelled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("new event did not cancel the running command")
+	}
+}
+
+func TestRunIdleCommand(t *testing.T) {
+	dir := t.TempDir()
+	r := New(&Options{OnIdle: "echo done > idle.txt"})
+	r.gitRoot = dir
+
+	r.runIdleCommand(context.Background())
+
+	if _, err := os.Stat(filepath.Join(dir, "idle.txt")); err != nil {
+		t.Errorf("expected the command to run in the git root: %v", err)
+	}
+}
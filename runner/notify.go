@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// notifyTimeout bounds how long a webhook POST may take.
+const notifyTimeout = 10 * time.Second
+
+// Values for Options.NotifyOn.
+const (
+	NotifyAlways  = "always"
+	NotifyFailure = "failure"
+)
+
+// notifySummary is the JSON body POSTed to the --notify webhook. Text is a
+// one-line summary so chat webhooks (e.g. Slack) render something readable.
+type notifySummary struct {
+	Text           string   `json:"text"`
+	Command        string   `json:"command"`
+	Success        bool     `json:"success"`
+	Total          int      `json:"total"`
+	Succeeded      int      `json:"succeeded"`
+	Failed         int      `json:"failed"`
+	Cached         int      `json:"cached"`
+	DurationMs     int64    `json:"duration_ms"`
+	FailedProjects []string `json:"failed_projects"`
+}
+
+// buildNotifySummary summarizes the results of one run.
+func buildNotifySummary(command string, results []runResult, cached int, duration time.Duration) notifySummary {
+	s := notifySummary{
+		Command:        command,
+		Cached:         cached,
+		DurationMs:     duration.Milliseconds(),
+		FailedProjects: []string{},
+	}
+	for _, res := range results {
+		if res.success {
+			s.Succeeded++
+		} else {
+			s.Failed++
+			s.FailedProjects = append(s.FailedProjects, res.project.Name)
+		}
+	}
+	s.Total = s.Succeeded + s.Failed + s.Cached
+	s.Success = s.Failed == 0
+
+	if s.Success {
+		s.Text = fmt.Sprintf("donotnet %s succeeded: %d succeeded, %d cached (%s)",
+			command, s.Succeeded, s.Cached, duration.Round(time.Second))
+	} else {
+		s.Text = fmt.Sprintf("donotnet %s failed: %d failed (%s), %d succeeded, %d cached (%s)",
+			command, s.Failed, strings.Join(s.FailedProjects, ", "), s.Succeeded, s.Cached, duration.Round(time.Second))
+	}
+	return s
+}
+
+// shouldNotify reports whether a run with summary s triggers a notification
+// under the --notify-on selector.
+func shouldNotify(notifyOn string, s notifySummary) bool {
+	return notifyOn != NotifyFailure || !s.Success
+}
+
+// notify POSTs a summary of results to the --notify webhook in the
+// background. Errors are only logged in verbose mode; Run waits for pending
+// notifications before returning.
+func (r *Runner) notify(results []runResult, cached int, duration time.Duration) {
+	if r.opts.Notify == "" || r.opts.DryRun {
+		return
+	}
+	s := buildNotifySummary(r.opts.Command, results, cached, duration)
+	if !shouldNotify(r.opts.NotifyOn, s) {
+		return
+	}
+
+	r.notifyWG.Add(1)
+	go func() {
+		defer r.notifyWG.Done()
+		if err := postNotification(r.opts.Notify, s); err != nil {
+			term.Verbose("notify: %v", err)
+		}
+	}()
+}
+
+func postNotification(url string, s notifySummary) error {
+	body, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestBuildNotifySummary(t *testing.T) {
+	results := []runResult{
+		{project: &project.Project{Name: "App.Tests"}, success: true},
+		{project: &project.Project{Name: "Api.Tests"}, success: false},
+		{project: &project.Project{Name: "Web.Tests"}, success: false},
+	}
+	s := buildNotifySummary("test", results, 4, 90*time.Second)
+
+	if s.Success || s.Total != 7 || s.Succeeded != 1 || s.Failed != 2 || s.Cached != 4 || s.DurationMs != 90000 {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if len(s.FailedProjects) != 2 || s.FailedProjects[0] != "Api.Tests" || s.FailedProjects[1] != "Web.Tests" {
+		t.Errorf("FailedProjects = %v", s.FailedProjects)
+	}
+	want := "donotnet test failed: 2 failed (Api.Tests, Web.Tests), 1 succeeded, 4 cached (1m30s)"
+	if s.Text != want {
+		t.Errorf("Text = %q, want %q", s.Text, want)
+	}
+}
+
+func TestShouldNotify(t *testing.T) {
+	ok := notifySummary{Success: true}
+	failed := notifySummary{Success: false}
+
+	if !shouldNotify(NotifyAlways, ok) || !shouldNotify("", ok) || !shouldNotify(NotifyAlways, failed) {
+		t.Error("expected always to notify on every run")
+	}
+	if shouldNotify(NotifyFailure, ok) || !shouldNotify(NotifyFailure, failed) {
+		t.Error("expected failure to notify only on failed runs")
+	}
+}
+
+func TestNotifyPostsSummary(t *testing.T) {
+	received := make(chan notifySummary, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request: %s %s", req.Method, req.Header.Get("Content-Type"))
+		}
+		var s notifySummary
+		json.NewDecoder(req.Body).Decode(&s)
+		received <- s
+	}))
+	defer srv.Close()
+
+	r := New(&Options{Command: "build", Notify: srv.URL})
+	r.notify([]runResult{{project: &project.Project{Name: "App"}, success: true}}, 0, time.Second)
+	r.notifyWG.Wait()
+
+	select {
+	case s := <-received:
+		if !s.Success || s.Succeeded != 1 || s.Command != "build" {
+			t.Errorf("unexpected summary: %+v", s)
+		}
+	default:
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotifyToleratesWebhookErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if err := postNotification(srv.URL, notifySummary{}); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+
+	// notify only logs the error
+	r := New(&Options{Command: "test", Notify: srv.URL})
+	r.notify(nil, 0, 0)
+	r.notifyWG.Wait()
+}
@@ -2,7 +2,10 @@
 package runner
 
 import (
+	"time"
+
 	"github.com/runar-rkmedia/donotnet/config"
+	"github.com/runar-rkmedia/donotnet/coverage"
 	"github.com/runar-rkmedia/donotnet/testfilter"
 )
 
@@ -26,24 +29,143 @@ type Options struct {
 
 	// --- Test-specific options ---
 	Coverage            bool
-	CoverageBuild       bool // Per-test coverage map build (donotnet coverage build)
+	CoverageBuild       bool   // Per-test coverage map build (donotnet coverage build)
+	CoverageIncremental bool   // Only re-run tests affected by changes since the map was built
+	CoverageIsolate     bool   // Run tests within a project in parallel against copied build outputs
+	Discovery           string // How tests are listed: dotnet, source
 	Heuristics          string
 	Failed              bool
 	StalenessCheck      string
 	CoverageGranularity string
 	NoReports           bool
+	CoverageThresholds  []coverage.Threshold
+	// CoverageMin fails a --coverage run whose overall line coverage is below
+	// this percentage (0 = disabled)
+	CoverageMin float64
+	// CoverageReport is a directory to write a merged HTML coverage report to
+	// after a --coverage run (empty = disabled)
+	CoverageReport string
+	// CoverageOpen opens the HTML coverage report in the browser
+	CoverageOpen bool
+	// CoverageSummary prints the overall and per-project line coverage after
+	// a --coverage run
+	CoverageSummary bool
+	// CoverageSummaryJSON is a file to write the coverage summary to as JSON
+	// (empty = disabled)
+	CoverageSummaryJSON string
+	// CoverageDiff is a ref; after a --coverage run, report the coverage of
+	// the lines changed since branching off it (empty = disabled).
+	// CoverageDiffJSON is a file to write it to, with the uncovered lines.
+	CoverageDiff     string
+	CoverageDiffJSON string
+	// CoverageAutoRebuild rebuilds stale per-test coverage maps of changed
+	// projects in the background during watch mode
+	CoverageAutoRebuild bool
+	// ExcludeTraits are test categories (e.g. "Live") excluded from every test
+	// project's --filter, in the project's framework dialect
+	ExcludeTraits []string
+	// SkipUntested leaves non-test projects without tests out of test runs
+	// instead of building them
+	SkipUntested bool
+	// FailOnNoTests fails test projects whose run executed zero tests,
+	// unless the user's own --filter (or --failed) narrowed the run
+	FailOnNoTests bool
+	// TestHangTimeout aborts and dumps any single test running longer than
+	// this, via dotnet's blame data collector (0 = disabled)
+	TestHangTimeout time.Duration
 
 	// --- Build-specific options ---
 	FullBuild     bool
 	NoSolution    bool
 	ForceSolution bool
+	// SolutionFilter uses a solution when 2+ of its projects need building,
+	// like ForceSolution, but limits it to those projects and their
+	// dependencies through a generated .slnf
+	SolutionFilter bool
 
 	// --- Shared options ---
-	VcsChanged  bool
-	VcsRef      string
-	Watch       bool
+	// VCS is the backend used to find changed files (see git.NewBackend)
+	VCS        string
+	VcsChanged bool
+	VcsRef     string
+	// VcsRefMode is how VcsRef is compared (see git.RefChanges)
+	VcsRefMode string
+	// SDK is the .NET SDK version (or version prefix, e.g. "8.0") the dotnet on
+	// PATH must report. When empty, the nearest global.json is checked and a
+	// mismatch only warns.
+	SDK string
+	// Scope restricts change detection to changed files under this directory
+	// (absolute, or relative to the working directory). Without VcsRef it
+	// uses uncommitted changes, like VcsChanged.
+	Scope string
+	// ChangedFiles replaces VCS change detection (and VcsChanged/VcsRef) with
+	// this list of changed paths relative to the git root. nil = not given;
+	// an empty list means nothing changed.
+	ChangedFiles []string
+	Watch        bool
+	// WatchDebounce is how long watch mode waits for more file events before
+	// running. Events within the window are coalesced into one run, so larger
+	// values batch more aggressively but react later (default 100ms).
+	WatchDebounce time.Duration
+	// WatchClear clears the screen before each rerun triggered by file
+	// changes (not the initial run) and prints the changed files
+	WatchClear bool
+	// OnIdle is a shell command watch mode runs once it has been idle for
+	// OnIdleAfter after a successful run (empty = disabled)
+	OnIdle      string
+	OnIdleAfter time.Duration
 	PrintOutput bool
 	Force       bool
+	DryRun      bool // Print resolved dotnet commands without running them or touching the cache
+	// QuietOnSuccess holds back all output of a run; only a summary line is
+	// printed if everything succeeded, everything if something failed
+	QuietOnSuccess bool
+	// Since forces a run of projects without a successful run since this
+	// duration ago or RFC3339 time (empty = disabled)
+	Since string
+	// DiffInputs is a project name; list its inputs changed since its last
+	// successful run instead of running anything (empty = disabled)
+	DiffInputs string
+	// CacheKeyDebug prints how each selected project's cache key was derived
+	// and whether it hit, instead of running anything
+	CacheKeyDebug bool
+	// Why is a project name; explain why it runs or is skipped (cache
+	// lookup, newest input, dependency chain) instead of running anything
+	Why string
+	// AffectedGraph is a format (AffectedGraphDot, AffectedGraphMermaid) to write the dependency
+	// graph in, colored by change state, instead of running anything
+	// (empty = disabled). AffectedGraphOutput is the file (empty = stdout).
+	AffectedGraph       string
+	AffectedGraphOutput string
+
+	// Projects selects projects by name or .csproj path to run regardless of
+	// change state, like explicit Targets. WithDeps adds their transitive
+	// project references.
+	Projects []string
+	WithDeps bool
+	// OnlyProjects is an allowlist of project names or .csproj paths. When
+	// set, only these projects run, each when its own cache entry is stale
+	// (or with Force), bypassing change propagation and VCS filters.
+	OnlyProjects []string
+	// Only are name or path globs; affected projects run only if they match
+	// or depend (transitively) on a match. Cache and ordering still apply.
+	Only []string
+
+	// ExcludeProjects are glob patterns for projects that are never built or tested
+	ExcludeProjects []string
+
+	// ReportMarkdown is a file path to write a Markdown run summary to (empty = disabled)
+	ReportMarkdown string
+	// Format prints per-test results on stdout after the run: FormatTAP (empty = disabled)
+	Format string
+
+	// EventSink, when set, receives structured progress events (see Event)
+	EventSink EventSink
+
+	// Notify is a webhook URL that receives a JSON summary after each run (empty = disabled)
+	Notify string
+	// NotifyOn is NotifyAlways (default) or NotifyFailure
+	NotifyOn string
 
 	// --- Global options ---
 	Verbose       bool
@@ -51,10 +173,33 @@ type Options struct {
 	Parallel      int
 	Local         bool
 	KeepGoing     bool
+	MaxFailures   int // with KeepGoing, stop once this many projects have failed (0 = unlimited)
 	ShowCached    bool
 	NoProgress    bool
 	NoSuggestions bool
 	CacheDir      string
+	CacheMaxSize  string
+	// CacheLockTimeout is how long to wait for a cache locked by another
+	// process before continuing read-only (0 = cache.DefaultLockWait)
+	CacheLockTimeout time.Duration
+	// CacheScope selects a separate cache database per scope key (see
+	// cache.FileName; empty = the shared cache.db)
+	CacheScope string
+	// IncludeSubmodules discovers projects inside git submodules too
+	IncludeSubmodules bool
+	// HashMode is how file contents are fingerprinted for cache keys:
+	// HashModeContent (default) or HashModeMtime
+	HashMode string
+	// CacheStats prints the cache hit rate of the run in the summary
+	CacheStats bool
+	// CacheStatsLog appends the cache hit rate of each run to HitRateLogFileName
+	// in the cache directory
+	CacheStatsLog bool
+
+	// TestProjectPatterns override test project detection (see project.ParseTestProjectPatterns)
+	TestProjectPatterns []string
+	// CacheEnv names environment variables folded into the args hash
+	CacheEnv []string
 
 	// Config from file/env (used for defaults)
 	Config *config.Config
@@ -81,10 +226,20 @@ func NewOptions(cfg *config.Config) *Options {
 		opts.Parallel = cfg.Parallel
 		opts.Local = cfg.Local
 		opts.KeepGoing = cfg.KeepGoing
+		opts.MaxFailures = cfg.MaxFailures
 		opts.ShowCached = cfg.ShowCached
 		opts.NoProgress = cfg.NoProgress
 		opts.NoSuggestions = cfg.NoSuggestions
 		opts.CacheDir = cfg.CacheDir
+		opts.CacheMaxSize = cfg.CacheMaxSize
+		opts.CacheLockTimeout = time.Duration(cfg.CacheLockTimeoutMs) * time.Millisecond
+		opts.CacheScope = cfg.CacheScope
+		opts.IncludeSubmodules = cfg.IncludeSubmodules
+		opts.HashMode = cfg.HashMode
+		opts.CacheStats = cfg.CacheStats
+		opts.CacheStatsLog = cfg.CacheStatsLog
+		opts.TestProjectPatterns = cfg.TestProjectPatterns
+		opts.CacheEnv = cfg.CacheEnv
 
 		// Test defaults
 		opts.Heuristics = cfg.Test.Heuristics
@@ -93,6 +248,13 @@ func NewOptions(cfg *config.Config) *Options {
 		opts.StalenessCheck = cfg.Test.StalenessCheck
 		opts.NoReports = !cfg.Test.Reports
 		opts.Failed = cfg.Test.Failed
+		opts.SkipUntested = cfg.Test.SkipUntested
+		opts.ExcludeTraits = cfg.Test.ExcludeTraits
+		opts.Discovery = cfg.Test.Discovery
+		opts.CoverageMin = cfg.Test.CoverageMin
+		for _, t := range cfg.Test.CoverageThresholds {
+			opts.CoverageThresholds = append(opts.CoverageThresholds, coverage.Threshold{Pattern: t.Project, Min: t.Min})
+		}
 
 		// Build defaults
 		opts.FullBuild = cfg.Build.FullBuild
@@ -100,11 +262,19 @@ func NewOptions(cfg *config.Config) *Options {
 			opts.NoSolution = true
 		} else if cfg.Build.Solution == "always" {
 			opts.ForceSolution = true
+		} else if cfg.Build.Solution == "filter" {
+			opts.SolutionFilter = true
 		}
 
 		// VCS defaults
+		opts.VCS = cfg.VCS.Backend
 		opts.VcsRef = cfg.VCS.Ref
+		opts.VcsRefMode = cfg.VCS.RefMode
 		opts.VcsChanged = cfg.VCS.Changed
+
+		// Watch defaults
+		opts.WatchDebounce = time.Duration(cfg.Watch.DebounceMs) * time.Millisecond
+		opts.WatchClear = cfg.Watch.Clear
 	} else {
 		// Sensible defaults without config
 		opts.Heuristics = "default"
@@ -115,6 +285,12 @@ func NewOptions(cfg *config.Config) *Options {
 	return opts
 }
 
+// FailureBudgetSpent returns true if a keep-going run with this many failures
+// has reached MaxFailures and should stop.
+func (o *Options) FailureBudgetSpent(failures int) bool {
+	return o.KeepGoing && o.MaxFailures > 0 && failures >= o.MaxFailures
+}
+
 // EffectiveParallel returns the parallelism to use.
 func (o *Options) EffectiveParallel() int {
 	if o.Parallel <= 0 {
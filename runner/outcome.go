@@ -0,0 +1,88 @@
+package runner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// runOutcome is the result of a dotnet test run as reported in its output,
+// normalized across test frameworks and test platforms.
+type runOutcome struct {
+	// testCounts are the reported counts; Total is the number of tests that ran
+	testCounts
+	// Counted is true if the output contained a summary with test counts
+	Counted bool
+	// NoTestsMatched is true if the test case filter selected no tests
+	NoTestsMatched bool
+	// NoTestsAvailable is true if the test assembly contains no tests any
+	// loaded adapter recognizes
+	NoTestsAvailable bool
+	// FilterRejected is true if the test case filter could not be parsed
+	FilterRejected bool
+}
+
+// noTestsRan reports whether the run executed no tests: a zero total, no test
+// matching the filter, or no tests found in the assembly at all.
+func (o runOutcome) noTestsRan() bool {
+	return (o.Counted && o.Total == 0) || o.NoTestsMatched || o.NoTestsAvailable
+}
+
+var (
+	// "Total tests: N" followed by "Passed: N" etc. lines (older VSTest consoles)
+	legacySummaryRegex = regexp.MustCompile(`(?m)^\s*Total tests:\s*\d+\s*$`)
+	// "Test run summary: Passed! - ..." followed by "total: N" etc. lines
+	// (Microsoft.Testing.Platform, used by MSTest, NUnit and xUnit v3 runners)
+	platformSummaryRegex = regexp.MustCompile(`(?m)^\s*Test run summary:.*$`)
+	countLineRegex       = regexp.MustCompile(`^([A-Za-z ]+):\s*(\d+)$`)
+)
+
+// parseRunOutcome extracts the outcome of a dotnet test run from its output.
+// It understands the VSTest one-line summary (testStatsRegex), the older
+// multi-line VSTest summary and the Microsoft.Testing.Platform summary, plus
+// the messages MSTest, NUnit and xUnit adapters print when no tests ran.
+func parseRunOutcome(output string) runOutcome {
+	var o runOutcome
+	if counts, ok := parseTestCounts(output); ok {
+		o.testCounts, o.Counted = counts, true
+	} else if loc := legacySummaryRegex.FindStringIndex(output); loc != nil {
+		o.testCounts, o.Counted = parseCountLines(output[loc[0]:]), true
+	} else if loc := platformSummaryRegex.FindStringIndex(output); loc != nil {
+		o.testCounts, o.Counted = parseCountLines(output[loc[1]:]), true
+	}
+
+	o.NoTestsMatched = strings.Contains(output, "No test matches the given testcase filter")
+	o.NoTestsAvailable = strings.Contains(output, "No test is available in")
+	o.FilterRejected = strings.Contains(output, "Incorrect format for TestCaseFilter")
+	return o
+}
+
+// parseCountLines reads consecutive "Name: N" lines from the start of s,
+// skipping leading blank lines and stopping at the first other line.
+func parseCountLines(s string) testCounts {
+	var c testCounts
+	started := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" && !started {
+			continue
+		}
+		m := countLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			break
+		}
+		started = true
+		n, _ := strconv.Atoi(m[2])
+		switch strings.ToLower(m[1]) {
+		case "total", "total tests":
+			c.Total = n
+		case "passed", "succeeded":
+			c.Passed = n
+		case "failed":
+			c.Failed = n
+		case "skipped":
+			c.Skipped = n
+		}
+	}
+	return c
+}
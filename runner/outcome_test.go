@@ -0,0 +1,69 @@
+package runner
+
+import "testing"
+
+func TestParseRunOutcome(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   runOutcome
+	}{
+		{
+			name:   "vstest summary",
+			output: "Failed!  - Failed:     2, Passed:    10, Skipped:     1, Total:    13, Duration: 40 ms - App.Tests.dll (net8.0)",
+			want:   runOutcome{testCounts: testCounts{Failed: 2, Passed: 10, Skipped: 1, Total: 13}, Counted: true},
+		},
+		{
+			name: "legacy vstest summary",
+			output: `Test Run Failed.
+Total tests: 5
+     Passed: 3
+     Failed: 1
+    Skipped: 1
+ Total time: 1.2 Seconds`,
+			want: runOutcome{testCounts: testCounts{Failed: 1, Passed: 3, Skipped: 1, Total: 5}, Counted: true},
+		},
+		{
+			name: "testing platform summary",
+			output: `Test run summary: Passed! - bin/Debug/net8.0/App.Tests.dll (net8.0|x64)
+  total: 12
+  failed: 0
+  succeeded: 11
+  skipped: 1
+  duration: 318ms`,
+			want: runOutcome{testCounts: testCounts{Passed: 11, Skipped: 1, Total: 12}, Counted: true},
+		},
+		{
+			name: "testing platform zero tests",
+			output: `Test run summary: Zero tests ran - bin/Debug/net8.0/App.Tests.dll (net8.0|x64)
+  total: 0
+  failed: 0
+  succeeded: 0
+  skipped: 0
+  duration: 41ms`,
+			want: runOutcome{Counted: true},
+		},
+		{
+			name:   "filter matched nothing",
+			output: "No test matches the given testcase filter `FullyQualifiedName~Foo` in /src/App.Tests.dll",
+			want:   runOutcome{NoTestsMatched: true},
+		},
+		{
+			name:   "filter rejected",
+			output: "Incorrect format for TestCaseFilter Missing Operator '|' or '&'. Specify the correct format and try again.",
+			want:   runOutcome{FilterRejected: true},
+		},
+		{
+			name:   "no output",
+			output: "Build succeeded.",
+			want:   runOutcome{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRunOutcome(tt.output); got != tt.want {
+				t.Errorf("parseRunOutcome() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testresults"
 )
 
 // runResult holds the result of running a dotnet command on a project.
@@ -21,11 +22,14 @@ type runResult struct {
 	duration        time.Duration
 	skippedBuild    bool
 	skippedRestore  bool
-	filteredTests   bool     // true if only specific tests were run
-	testClasses     []string // test classes that were run (if filtered)
-	buildOnly       bool     // true if this was a build-only job (no tests)
-	viaSolution     bool     // true if this was run as part of a solution build
-	skippedByFilter bool     // true if all tests were excluded by user's category filter
+	filteredTests   bool                     // true if only specific tests were run
+	testClasses     []string                 // test classes that were run (if filtered)
+	buildOnly       bool                     // true if this was a build-only job (no tests)
+	viaSolution     bool                     // true if this was run as part of a solution build
+	skippedByFilter bool                     // true if all tests were excluded by user's category filter
+	dryRun          bool                     // true if the command was only printed (output holds the command line)
+	coverageFile    string                   // Cobertura report from this run's results directory (-coverage)
+	testResults     []testresults.TestResult // per-test results from this run's TRX (nil without one)
 }
 
 // statusUpdate is sent from workers to update the status line.
@@ -44,6 +48,10 @@ type statusLineWriter struct {
 	killProcess func() // Called to kill the current process (for fail-fast)
 	directMode  bool
 	mu          sync.Mutex
+	// onLine, when set, receives every non-empty output line, also in
+	// direct mode (see Options.EventSink)
+	onLine   func(line string)
+	eventBuf []byte
 }
 
 var failurePatterns = []string{
@@ -106,10 +114,51 @@ func formatTestStats(failed, passed, skipped, total string) string {
 	return fmt.Sprintf("%s  %s  %s  %s", failedStr, passedStr, skippedStr, totalStr)
 }
 
+// failureSummary formats a compact list of failed projects for the end of a
+// run, with the number of failed tests when the output reports it, e.g.
+// "Failed: Api.Tests (3 tests), Web (build)". Returns "" without failures.
+func failureSummary(failures []runResult) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	entries := make([]string, 0, len(failures))
+	for _, f := range failures {
+		entry := f.project.Name
+		if f.buildOnly {
+			entry += " (build)"
+		} else if o := parseRunOutcome(f.output); o.Counted && o.Failed > 0 {
+			entry += fmt.Sprintf(" (%d tests)", o.Failed)
+		}
+		entries = append(entries, entry)
+	}
+	return "Failed: " + strings.Join(entries, ", ")
+}
+
+// printFailureSummary prints failureSummary below the run summary.
+func printFailureSummary(failures []runResult) {
+	if summary := failureSummary(failures); summary != "" {
+		term.Printf("%s%s%s\n", term.Color(term.ColorRed), summary, term.Color(term.ColorReset))
+	}
+}
+
 func (w *statusLineWriter) Write(p []byte) (n int, err error) {
 	n = len(p)
 	w.buffer.Write(p)
 
+	if w.onLine != nil {
+		w.eventBuf = append(w.eventBuf, p...)
+		for {
+			idx := bytes.IndexByte(w.eventBuf, '\n')
+			if idx < 0 {
+				break
+			}
+			if line := strings.TrimSpace(string(w.eventBuf[:idx])); line != "" {
+				w.onLine(line)
+			}
+			w.eventBuf = w.eventBuf[idx+1:]
+		}
+	}
+
 	w.mu.Lock()
 	directMode := w.directMode
 	w.mu.Unlock()
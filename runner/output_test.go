@@ -0,0 +1,25 @@
+package runner
+
+import "testing"
+
+func TestNoTestsRan(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   bool
+	}{
+		{"zero total", "Passed!  - Failed:     0, Passed:     0, Skipped:     0, Total:     0, Duration: 1 ms", true},
+		{"tests ran", "Passed!  - Failed:     0, Passed:    12, Skipped:     1, Total:    13, Duration: 40 ms", false},
+		{"filter matched nothing", "No test matches the given testcase filter `FullyQualifiedName~Foo` in /src/App.Tests.dll", true},
+		{"empty assembly", "No test is available in /src/App.Tests/bin/Debug/net8.0/App.Tests.dll.", true},
+		{"testing platform zero total", "Test run summary: Zero tests ran - App.Tests.dll (net8.0|x64)\n  total: 0\n  failed: 0\n  succeeded: 0\n  skipped: 0", true},
+		{"no summary", "Build succeeded.", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRunOutcome(tt.output).noTestsRan(); got != tt.want {
+				t.Errorf("noTestsRan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,9 @@
 package runner
 
 import (
+	"context"
 	"os/exec"
+	"runtime"
 	"syscall"
 )
 
@@ -15,3 +17,16 @@ func setupProcessGroup(cmd *exec.Cmd) {
 		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
 	}
 }
+
+// shellCommand runs command through the user's shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// openCommand opens path with the desktop's default application.
+func openCommand(path string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", path)
+	}
+	return exec.Command("xdg-open", path)
+}
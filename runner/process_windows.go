@@ -3,6 +3,7 @@
 package runner
 
 import (
+	"context"
 	"os/exec"
 )
 
@@ -13,3 +14,13 @@ func setupProcessGroup(cmd *exec.Cmd) {
 		return cmd.Process.Kill()
 	}
 }
+
+// shellCommand runs command through cmd.exe.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
+
+// openCommand opens path with the desktop's default application.
+func openCommand(path string) *exec.Cmd {
+	return exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+}
@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"context"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// runProjectsQuietOnSuccess runs runProjects with all terminal output held
+// back (--quiet-on-success). A successful run prints only its summary line;
+// a failed one prints everything, failures and summary included, once done.
+func (r *Runner) runProjectsQuietOnSuccess(ctx context.Context, targets, cached []*project.Project, argsHash string) bool {
+	start := time.Now()
+	return quietOnSuccess(func() bool {
+		return r.runProjects(ctx, r.contentHasher(), targets, cached, argsHash)
+	}, func() {
+		term.Summary(len(targets), len(targets), len(cached), time.Since(start).Round(time.Millisecond), true)
+	})
+}
+
+// quietOnSuccess calls run with all terminal output held back. If it
+// succeeds, only summary prints; otherwise the held back output is replayed.
+func quietOnSuccess(run func() bool, summary func()) bool {
+	term.StartBuffer()
+	success := run()
+	output := term.StopBuffer()
+
+	if !success {
+		term.Write(output)
+		return false
+	}
+	summary()
+	return true
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+func TestQuietOnSuccess(t *testing.T) {
+	var out bytes.Buffer
+	defer func(prev *term.Terminal) { term.Default = prev }(term.Default)
+	term.Default = term.NewWriter(&out)
+	summary := func() { term.Summary(1, 1, 0, time.Second, true) }
+
+	// A green run prints only the summary line
+	ok := quietOnSuccess(func() bool {
+		term.Printf("building App...\n")
+		term.ResultLine(true, "", "App", "1s", "", "")
+		return true
+	}, summary)
+	if !ok {
+		t.Error("quietOnSuccess() = false for a green run")
+	}
+	if got := strings.TrimSpace(out.String()); got != "1/1 succeeded (1s)" {
+		t.Errorf("green run printed %q, want only the summary line", got)
+	}
+
+	// A red run replays everything that was held back, and no extra summary
+	out.Reset()
+	ok = quietOnSuccess(func() bool {
+		term.Printf("building App...\n")
+		term.Printf("App.cs(3,1): error CS1002: ; expected\n")
+		return false
+	}, summary)
+	if ok {
+		t.Error("quietOnSuccess() = true for a red run")
+	}
+	if got := out.String(); got != "building App...\nApp.cs(3,1): error CS1002: ; expected\n" {
+		t.Errorf("red run printed %q, want the buffered output", got)
+	}
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// maxReportOutputLines caps how much failure output is embedded per project.
+// GitHub rejects comments over 65536 characters, so huge logs are trimmed to
+// their tail (where dotnet prints the failing assertions and the summary).
+const maxReportOutputLines = 150
+
+// maxReportOutputBytes caps the embedded failure output per project in bytes.
+const maxReportOutputBytes = 16 * 1024
+
+// testCounts holds the parsed "Failed: X, Passed: Y, Skipped: Z, Total: N" line.
+type testCounts struct {
+	Failed, Passed, Skipped, Total int
+}
+
+// parseTestCounts extracts test counts from dotnet test output.
+// Returns false if the output contains no summary line.
+func parseTestCounts(output string) (testCounts, bool) {
+	match := testStatsRegex.FindStringSubmatch(output)
+	if match == nil {
+		return testCounts{}, false
+	}
+	var c testCounts
+	c.Failed, _ = strconv.Atoi(match[1])
+	c.Passed, _ = strconv.Atoi(match[2])
+	c.Skipped, _ = strconv.Atoi(match[3])
+	c.Total, _ = strconv.Atoi(match[4])
+	return c, true
+}
+
+// markdownReport holds the data needed to render a Markdown run summary.
+type markdownReport struct {
+	Command  string
+	Results  []runResult
+	Cached   []*project.Project
+	Duration time.Duration
+	// LogDir is the reports directory (relative to git root) where full logs
+	// are saved. Empty when reports are disabled.
+	LogDir string
+	// CacheStats is the cache hit rate of the run (nil = not reported)
+	CacheStats *cacheStats
+}
+
+// renderMarkdownReport renders a run summary as Markdown, suitable for
+// posting as a pull request comment.
+func renderMarkdownReport(rep markdownReport) string {
+	var sb strings.Builder
+
+	succeeded := 0
+	var failures []runResult
+	for _, res := range rep.Results {
+		if res.success {
+			succeeded++
+		} else {
+			failures = append(failures, res)
+		}
+	}
+
+	icon := "✅"
+	if len(failures) > 0 {
+		icon = "❌"
+	}
+	fmt.Fprintf(&sb, "## %s donotnet %s: %d/%d succeeded", icon, rep.Command, succeeded, len(rep.Results))
+	if len(rep.Cached) > 0 {
+		fmt.Fprintf(&sb, ", %d cached", len(rep.Cached))
+	}
+	fmt.Fprintf(&sb, " (%s)\n\n", rep.Duration.Round(time.Millisecond))
+	if rep.CacheStats != nil {
+		fmt.Fprintf(&sb, "_%s_\n\n", rep.CacheStats)
+	}
+
+	if len(rep.Results) == 0 {
+		sb.WriteString("No affected projects.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| | Project | Duration | Passed | Failed | Skipped | Total |\n")
+	sb.WriteString("|---|---|---:|---:|---:|---:|---:|\n")
+	for _, res := range rep.Results {
+		status := "✅"
+		if !res.success {
+			status = "❌"
+		}
+		name := res.project.Name
+		switch {
+		case res.buildOnly:
+			name += " _(no tests)_"
+		case res.viaSolution:
+			name += " _(solution)_"
+		}
+		duration := res.duration.Round(time.Millisecond).String()
+
+		if o := parseRunOutcome(res.output); o.Counted {
+			counts := o.testCounts
+			fmt.Fprintf(&sb, "| %s | %s | %s | %d | %d | %d | %d |\n",
+				status, name, duration, counts.Passed, counts.Failed, counts.Skipped, counts.Total)
+		} else {
+			fmt.Fprintf(&sb, "| %s | %s | %s | - | - | - | - |\n", status, name, duration)
+		}
+	}
+
+	if len(failures) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n### Failures\n")
+	for _, f := range failures {
+		output, omitted := truncateReportOutput(term.StripAnsi(f.output))
+		fence := "```"
+		if strings.Contains(output, fence) {
+			fence = "````"
+		}
+
+		fmt.Fprintf(&sb, "\n<details>\n<summary>❌ %s</summary>\n\n", f.project.Name)
+		if omitted > 0 {
+			fmt.Fprintf(&sb, "_Output truncated, %d lines omitted.", omitted)
+			if rep.LogDir != "" {
+				fmt.Fprintf(&sb, " Full log: `%s`", filepath.ToSlash(filepath.Join(rep.LogDir, f.project.Name+".log")))
+			}
+			sb.WriteString("_\n\n")
+		}
+		fmt.Fprintf(&sb, "%s\n%s\n%s\n\n</details>\n", fence, strings.TrimRight(output, "\n"), fence)
+	}
+
+	return sb.String()
+}
+
+// truncateReportOutput keeps the tail of output within the report size limits.
+// Returns the (possibly) truncated output and the number of lines omitted.
+func truncateReportOutput(output string) (string, int) {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	omitted := 0
+	if len(lines) > maxReportOutputLines {
+		omitted = len(lines) - maxReportOutputLines
+		lines = lines[omitted:]
+	}
+	for len(lines) > 1 && len(strings.Join(lines, "\n")) > maxReportOutputBytes {
+		lines = lines[1:]
+		omitted++
+	}
+	return strings.Join(lines, "\n"), omitted
+}
+
+// writeMarkdownReport writes the Markdown summary for this run to r.opts.ReportMarkdown.
+// stats is included when non-nil.
+func (r *Runner) writeMarkdownReport(cached []*project.Project, duration time.Duration, stats *cacheStats) {
+	if r.opts.ReportMarkdown == "" {
+		return
+	}
+
+	var logDir string
+	if !r.opts.NoReports {
+		logDir, _ = filepath.Rel(r.gitRoot, r.reportsDir)
+	}
+
+	content := renderMarkdownReport(markdownReport{
+		Command:    r.opts.Command,
+		Results:    r.results,
+		Cached:     cached,
+		Duration:   duration,
+		LogDir:     logDir,
+		CacheStats: stats,
+	})
+
+	if err := os.WriteFile(r.opts.ReportMarkdown, []byte(content), 0644); err != nil {
+		term.Warnf("failed to write markdown report: %v", err)
+		return
+	}
+	term.Verbose("Wrote markdown report to %s", r.opts.ReportMarkdown)
+}
@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestRenderMarkdownReport(t *testing.T) {
+	rep := markdownReport{
+		Command: "test",
+		Results: []runResult{
+			{
+				project:  &project.Project{Name: "Foo.Tests"},
+				success:  true,
+				output:   "Passed!  - Failed:     0, Passed:    21, Skipped:     4, Total:    25",
+				duration: 1600 * time.Millisecond,
+			},
+			{
+				project:  &project.Project{Name: "Bar.Tests"},
+				success:  false,
+				output:   "  Failed Bar.Tests.CalcTests.Adds [12ms]\n  Error Message:\n   Assert.Equal() Failure\nFailed!  - Failed:     1, Passed:     3, Skipped:     0, Total:     4",
+				duration: 800 * time.Millisecond,
+			},
+			{
+				project:   &project.Project{Name: "LibA"},
+				success:   true,
+				duration:  400 * time.Millisecond,
+				buildOnly: true,
+			},
+		},
+		Cached:   []*project.Project{{Name: "Cached.Tests"}},
+		Duration: 2 * time.Second,
+		LogDir:   ".donotnet/reports",
+	}
+
+	md := renderMarkdownReport(rep)
+
+	wantContains := []string{
+		"## ❌ donotnet test: 2/3 succeeded, 1 cached (2s)",
+		"| | Project | Duration | Passed | Failed | Skipped | Total |",
+		"| ✅ | Foo.Tests | 1.6s | 21 | 0 | 4 | 25 |",
+		"| ❌ | Bar.Tests | 800ms | 3 | 1 | 0 | 4 |",
+		"| ✅ | LibA _(no tests)_ | 400ms | - | - | - | - |",
+		"### Failures",
+		"<details>\n<summary>❌ Bar.Tests</summary>",
+		"Assert.Equal() Failure",
+		"</details>",
+	}
+	for _, want := range wantContains {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q\n\n%s", want, md)
+		}
+	}
+
+	// Passing projects should not get a failure details block
+	if strings.Contains(md, "<summary>❌ Foo.Tests</summary>") {
+		t.Errorf("passing project should not have failure details\n\n%s", md)
+	}
+}
+
+func TestRenderMarkdownReportAllCached(t *testing.T) {
+	md := renderMarkdownReport(markdownReport{
+		Command: "build",
+		Cached:  []*project.Project{{Name: "Foo"}, {Name: "Bar"}},
+	})
+
+	if !strings.Contains(md, "## ✅ donotnet build: 0/0 succeeded, 2 cached") {
+		t.Errorf("unexpected header\n\n%s", md)
+	}
+	if !strings.Contains(md, "No affected projects.") {
+		t.Errorf("expected no-affected note\n\n%s", md)
+	}
+}
+
+func TestRenderMarkdownReportTruncatesOutput(t *testing.T) {
+	var lines []string
+	for i := 0; i < maxReportOutputLines+50; i++ {
+		lines = append(lines, "line")
+	}
+	lines = append(lines, "Failed!  - Failed:     1, Passed:     0, Skipped:     0, Total:     1")
+
+	md := renderMarkdownReport(markdownReport{
+		Command: "test",
+		Results: []runResult{{
+			project: &project.Project{Name: "Big.Tests"},
+			output:  strings.Join(lines, "\n"),
+		}},
+		LogDir: ".donotnet/reports",
+	})
+
+	if !strings.Contains(md, "_Output truncated, 51 lines omitted. Full log: `.donotnet/reports/Big.Tests.log`_") {
+		t.Errorf("expected truncation note\n\n%s", md)
+	}
+	// The tail (with the summary line) must be kept
+	if !strings.Contains(md, "Failed!  - Failed:     1") {
+		t.Errorf("expected output tail to be kept\n\n%s", md)
+	}
+}
@@ -15,12 +15,14 @@ import (
 	"time"
 
 	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/config"
 	"github.com/runar-rkmedia/donotnet/coverage"
 	"github.com/runar-rkmedia/donotnet/git"
 	"github.com/runar-rkmedia/donotnet/project"
 	"github.com/runar-rkmedia/donotnet/suggestions"
 	"github.com/runar-rkmedia/donotnet/term"
 	"github.com/runar-rkmedia/donotnet/testfilter"
+	"github.com/runar-rkmedia/donotnet/testresults"
 )
 
 // Runner executes test and build commands.
@@ -38,10 +40,46 @@ type Runner struct {
 	forwardGraph   map[string][]string
 	projectsByPath map[string]*project.Project
 	db             *cache.DB
+	vcs            git.Backend
 
 	// targetPaths is the set of project relative paths matched by explicit targets.
 	// When non-nil, only these projects are executed (and they bypass cache).
 	targetPaths map[string]bool
+
+	// onlyPaths is the --only-projects allowlist. When non-nil, only these
+	// projects are considered, each checked against its own cache entry
+	// instead of the changed/affected propagation.
+	onlyPaths map[string]bool
+	// onlyScope is the --only set: the matching projects plus their
+	// transitive dependents. When non-nil, affected projects outside it are
+	// left out (they are neither run nor cached).
+	onlyScope map[string]bool
+	// changedFiles maps each project with VCS changes in its relevant dirs to
+	// those files, as recorded by findChangedProjects under a VCS filter.
+	changedFiles map[string][]string
+
+	// hasher memoizes content hashes of project directories for the initial
+	// run (see contentHasher). Watch batches use a fresh hasher of their own,
+	// passed to runProjects, so this is never replaced once the run started.
+	hasher *ContentHasher
+
+	// excludePatterns are glob patterns for projects that are never built or tested.
+	excludePatterns []string
+
+	// heuristicsResolver holds the per-directory rules of the heuristics file
+	// (nil without one); files matching no rule use --heuristics.
+	heuristicsResolver *testfilter.HeuristicsResolver
+
+	// scope is the --scope directory relative to the git root. When set, only
+	// changed files inside it count towards change detection.
+	scope string
+
+	// results collects the completed project results of the current run (or
+	// watch batch) for reporting.
+	results []runResult
+
+	// notifyWG tracks --notify webhook requests still in flight.
+	notifyWG sync.WaitGroup
 }
 
 // New creates a new Runner with the given options.
@@ -51,6 +89,34 @@ func New(opts *Options) *Runner {
 
 // Run executes the command.
 func (r *Runner) Run(ctx context.Context) error {
+	if r.opts.DryRun && r.opts.Watch {
+		return fmt.Errorf("--dry-run cannot be combined with --watch")
+	}
+	if r.opts.ChangedFiles != nil && r.opts.Watch {
+		return fmt.Errorf("--changed-files cannot be combined with --watch")
+	}
+	if r.opts.OnIdle != "" && !r.opts.Watch {
+		return fmt.Errorf("--on-idle requires --watch")
+	}
+	if r.opts.Format != "" {
+		if r.opts.Format != FormatTAP {
+			return fmt.Errorf("invalid --format %q (expected %s)", r.opts.Format, FormatTAP)
+		}
+		if r.opts.Watch {
+			return fmt.Errorf("--format cannot be combined with --watch")
+		}
+		if r.opts.NoReports {
+			return fmt.Errorf("--format=%s reads the TRX reports and cannot be combined with --no-reports", r.opts.Format)
+		}
+	}
+	if r.opts.HashMode != "" && r.opts.HashMode != HashModeContent && r.opts.HashMode != HashModeMtime {
+		return fmt.Errorf("invalid --hash-mode %q: expected %s or %s", r.opts.HashMode, HashModeContent, HashModeMtime)
+	}
+	if r.opts.NotifyOn != "" && r.opts.NotifyOn != NotifyAlways && r.opts.NotifyOn != NotifyFailure {
+		return fmt.Errorf("invalid --notify-on %q: expected %s or %s", r.opts.NotifyOn, NotifyAlways, NotifyFailure)
+	}
+	defer r.notifyWG.Wait()
+
 	// Setup terminal
 	term.SetVerbose(r.opts.Verbose)
 	term.SetQuiet(r.opts.Quiet)
@@ -74,6 +140,21 @@ func (r *Runner) Run(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("finding git root: %w", err)
 	}
+	r.hasher = NewContentHasher(r.gitRoot, r.opts.HashMode)
+	r.vcs, err = git.NewBackend(r.gitRoot, r.opts.VCS)
+	if err != nil {
+		return err
+	}
+	if r.vcs.Name() != git.BackendGit {
+		term.Verbose("VCS: %s", r.vcs.Name())
+	}
+
+	if r.opts.Scope != "" {
+		r.scope, err = resolveScope(r.gitRoot, cwd, r.opts.Scope)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Scan root: current dir if local, otherwise git root
 	r.scanRoot = r.gitRoot
@@ -83,10 +164,15 @@ func (r *Runner) Run(ctx context.Context) error {
 
 	// Discover projects and solutions before creating any cache artifacts,
 	// so we can bail out early in non-.NET repos without side effects.
-	r.projects, r.solutions, err = project.Discover(r.scanRoot, r.gitRoot)
+	r.projects, r.solutions, err = project.Discover(r.scanRoot, r.gitRoot, r.opts.IncludeSubmodules)
 	if err != nil {
 		return fmt.Errorf("discovering projects: %w", err)
 	}
+	testPatterns, err := project.ParseTestProjectPatterns(r.opts.TestProjectPatterns)
+	if err != nil {
+		return err
+	}
+	testPatterns.Apply(r.projects)
 
 	if len(r.projects) == 0 && len(r.solutions) == 0 {
 		term.Verbose("No .NET projects or solutions found, nothing to do")
@@ -94,6 +180,15 @@ func (r *Runner) Run(ctx context.Context) error {
 	}
 	term.Verbose("Found %d projects, %d solutions", len(r.projects), len(r.solutions))
 
+	// Catch builds that would use a different SDK than global.json (or --sdk) asks for
+	warning, err := r.checkSDK(ctx, cwd)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		term.Warnf("%s", warning)
+	}
+
 	// Filter projects by explicit targets (if specified)
 	if len(r.opts.Targets) > 0 {
 		matched, err := r.resolveTargetProjects()
@@ -109,36 +204,140 @@ func (r *Runner) Run(ctx context.Context) error {
 	if r.cacheDir == "" {
 		r.cacheDir = filepath.Join(r.gitRoot, ".donotnet")
 	}
-	os.MkdirAll(r.cacheDir, 0755)
+	if !r.opts.DryRun {
+		os.MkdirAll(r.cacheDir, 0755)
+	}
 	r.reportsDir = filepath.Join(r.cacheDir, "reports")
 
-	cachePath := filepath.Join(r.cacheDir, "cache.db")
-	r.db, err = cache.Open(cachePath)
+	cachePath := filepath.Join(r.cacheDir, cache.FileName(r.opts.CacheScope))
+	if r.opts.CacheMaxSize != "" && !r.opts.DryRun {
+		maxSize, err := cache.ParseSize(r.opts.CacheMaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid --cache-max-size: %w", err)
+		}
+		// Registered before the Close below so it runs once the cache is closed
+		defer func() {
+			if r.db != nil && !r.db.ReadOnly() {
+				maintainCacheSize(cachePath, maxSize)
+			}
+		}()
+	}
+	lockWait := r.opts.CacheLockTimeout
+	if lockWait <= 0 {
+		lockWait = cache.DefaultLockWait
+	}
+	// A dry run only reads the cache: it is neither created nor locked
+	if r.opts.DryRun {
+		r.db, err = cache.OpenReadOnly(cachePath)
+	} else {
+		r.db, err = cache.OpenShared(cachePath, lockWait)
+	}
 	if err != nil {
 		return fmt.Errorf("opening cache: %w", err)
 	}
 	defer r.db.Close()
+	switch {
+	case r.opts.DryRun:
+		// Read-only by design; nothing is written anyway
+	case r.db.Detached():
+		term.Warnf("cache is in use by another donotnet process; continuing without it, so nothing is skipped as cached and results of this run won't be cached")
+	case r.db.ReadOnly():
+		term.Warnf("cache is in use by another donotnet process; continuing read-only, so results of this run won't be cached")
+	}
+	r.db.SetCommit(git.GetCommit(r.gitRoot))
+	r.db.SetBranch(git.GetBranch(r.gitRoot))
+	r.pruneDeletedProjects()
+
+	// Load user-defined test heuristics (.donotnet/heuristics.json)
+	if r.opts.Command == "test" {
+		heuristicsPath := filepath.Join(r.gitRoot, config.ConfigDirName, testfilter.HeuristicsFileName)
+		if err := testfilter.LoadCustomHeuristics(heuristicsPath); err != nil {
+			return fmt.Errorf("loading custom heuristics: %w", err)
+		}
+		// Per-directory heuristics (.donotnet-heuristics), which may use the
+		// custom heuristics loaded above
+		r.heuristicsResolver, err = testfilter.LoadHeuristicsRules(filepath.Join(r.gitRoot, testfilter.ScopedHeuristicsFileName))
+		if err != nil {
+			return fmt.Errorf("loading heuristics file: %w", err)
+		}
+	}
+
+	// Load project exclusions (--exclude and .donotnet/exclude)
+	excludePath := filepath.Join(r.gitRoot, config.ConfigDirName, project.ExcludeFileName)
+	filePatterns, err := project.LoadExcludeFile(excludePath)
+	if err != nil {
+		return fmt.Errorf("loading exclude file: %w", err)
+	}
+	r.excludePatterns = append(append([]string{}, r.opts.ExcludeProjects...), filePatterns...)
+	if len(r.excludePatterns) > 0 {
+		term.Verbose("Excluding projects matching: %s", strings.Join(r.excludePatterns, ", "))
+	}
 
 	// Build dependency graphs
 	r.graph = project.BuildDependencyGraph(r.projects, r.gitRoot)
 	r.forwardGraph = project.BuildForwardDependencyGraph(r.projects, r.gitRoot)
 
+	// Warn about reference cycles: scheduling assumes a DAG and the graph walks
+	// would otherwise hide them
+	for _, cycle := range project.FindCycles(r.forwardGraph) {
+		term.Warnf("dependency cycle between %s (run 'donotnet check-cycles')", strings.Join(cycle, ", "))
+	}
+
 	// Build project lookup
 	r.projectsByPath = make(map[string]*project.Project)
 	for _, p := range r.projects {
 		r.projectsByPath[p.Path] = p
 	}
 
+	// Add projects selected by name (--project); like explicit targets they bypass cache
+	if len(r.opts.Projects) > 0 {
+		matched, err := r.resolveNamedProjects(cwd)
+		if err != nil {
+			return err
+		}
+		if r.targetPaths == nil {
+			r.targetPaths = make(map[string]bool)
+		}
+		for path := range matched {
+			r.targetPaths[path] = true
+		}
+		term.Verbose("--project: %d projects matched", len(matched))
+	}
+	if len(r.opts.OnlyProjects) > 0 {
+		r.onlyPaths, err = r.resolveOnlyProjects(cwd)
+		if err != nil {
+			return err
+		}
+	}
+	if len(r.opts.Only) > 0 {
+		r.onlyScope, err = r.resolveOnlyScope()
+		if err != nil {
+			return err
+		}
+		term.Verbose("--only: %d projects in scope", len(r.onlyScope))
+	}
+
 	// Handle per-test coverage build (separate flow from normal test/build)
 	if r.opts.CoverageBuild {
-		var testProjects []*project.Project
-		for _, p := range r.projects {
-			if p.IsTest {
-				testProjects = append(testProjects, p)
+		var changes []git.FileChange
+		useVcsFilter := r.opts.VcsChanged || r.opts.VcsRef != "" || r.opts.ChangedFiles != nil
+		if r.opts.ChangedFiles != nil {
+			changes = suppliedChanges(r.gitRoot, r.opts.ChangedFiles)
+		} else if r.opts.VcsRef != "" {
+			changes, _, err = git.RefChanges(r.vcs, r.gitRoot, r.opts.VcsRef, r.opts.VcsRefMode)
+			if err != nil {
+				return err
 			}
+		} else if r.opts.VcsChanged {
+			changes = r.vcs.DirtyFileChanges(r.gitRoot)
 		}
+		testProjects := r.coverageBuildProjects(vcsChangePaths(changes), useVcsFilter)
 		if len(testProjects) == 0 {
-			term.Dim("No test projects found")
+			if useVcsFilter {
+				term.Dim("No test projects affected by changes")
+			} else {
+				term.Dim("No test projects found")
+			}
 			return nil
 		}
 		coverage.BuildPerTestCoverageMaps(coverage.BuildOptions{
@@ -148,39 +347,79 @@ func (r *Runner) Run(ctx context.Context) error {
 			MaxJobs:      r.opts.EffectiveParallel(),
 			Granularity:  coverage.ParseGranularity(r.opts.CoverageGranularity),
 			Ctx:          ctx,
-			Cache:        newTestListCache(r.db, r.gitRoot, r.forwardGraph),
+			Cache:        NewTestListCache(r.db, r.gitRoot, r.forwardGraph),
+			Incremental:  r.opts.CoverageIncremental,
+			Isolate:      r.opts.CoverageIsolate,
+			Discovery:    coverage.ParseDiscovery(r.opts.Discovery),
 		})
 		return nil
 	}
 
-	// Always fetch dirty files (used for test filtering even without VCS mode)
-	dirtyFiles := git.GetDirtyFiles(r.gitRoot)
+	// Always fetch dirty files (used for test filtering even without VCS mode);
+	// a --changed-files list stands in for them
+	var dirtyChanges []git.FileChange
+	if r.opts.ChangedFiles != nil {
+		dirtyChanges = suppliedChanges(r.gitRoot, r.opts.ChangedFiles)
+	} else {
+		dirtyChanges = r.vcs.DirtyFileChanges(r.gitRoot)
+	}
+	dirtyFiles := git.ChangedPaths(dirtyChanges)
 	if len(dirtyFiles) > 0 {
 		term.Verbose("Dirty files: %d", len(dirtyFiles))
 	}
 
 	// Get VCS state
-	var vcsChangedFiles []string
-	useVcsFilter := r.opts.VcsChanged || r.opts.VcsRef != ""
+	// (--only-projects replaces change detection, VCS filters included)
+	var vcsChanges []git.FileChange
+	useVcsFilter := (r.opts.VcsChanged || r.opts.VcsRef != "" || r.scope != "" || r.opts.ChangedFiles != nil) && r.onlyPaths == nil
 
 	if useVcsFilter {
-		if r.opts.VcsRef != "" {
-			vcsChangedFiles, err = git.GetChangedFiles(r.gitRoot, r.opts.VcsRef)
+		if r.opts.ChangedFiles != nil {
+			vcsChanges = dirtyChanges
+			if r.scope != "" {
+				vcsChanges = filterChangesToScope(vcsChanges, r.scope)
+			}
+			if len(vcsChanges) == 0 {
+				term.Dim("No changed files given")
+				return nil
+			}
+			term.Verbose("VCS filter: --changed-files (%d files)", len(vcsChanges))
+		} else if r.opts.VcsRef != "" {
+			var base string
+			vcsChanges, base, err = git.RefChanges(r.vcs, r.gitRoot, r.opts.VcsRef, r.opts.VcsRefMode)
 			if err != nil {
 				return err
 			}
-			if len(vcsChangedFiles) == 0 {
-				term.Dim("No changes vs %s", r.opts.VcsRef)
+			if r.scope != "" {
+				vcsChanges = filterChangesToScope(vcsChanges, r.scope)
+			}
+			if len(vcsChanges) == 0 {
+				if r.scope != "" {
+					term.Dim("No changes vs %s under %s", r.opts.VcsRef, r.scope)
+				} else {
+					term.Dim("No changes vs %s", r.opts.VcsRef)
+				}
 				return nil
 			}
-			term.Verbose("VCS filter: changes vs %s (%d files)", r.opts.VcsRef, len(vcsChangedFiles))
+			if base != r.opts.VcsRef {
+				term.Verbose("VCS filter: changes vs %s (merge base %s, %d files)", r.opts.VcsRef, base, len(vcsChanges))
+			} else {
+				term.Verbose("VCS filter: changes vs %s (%d files)", r.opts.VcsRef, len(vcsChanges))
+			}
 		} else {
-			vcsChangedFiles = dirtyFiles
-			if len(vcsChangedFiles) == 0 {
-				term.Dim("No uncommitted changes")
+			vcsChanges = dirtyChanges
+			if r.scope != "" {
+				vcsChanges = filterChangesToScope(vcsChanges, r.scope)
+			}
+			if len(vcsChanges) == 0 {
+				if r.scope != "" {
+					term.Dim("No uncommitted changes under %s", r.scope)
+				} else {
+					term.Dim("No uncommitted changes")
+				}
 				return nil
 			}
-			term.Verbose("VCS filter: uncommitted changes (%d files)", len(vcsChangedFiles))
+			term.Verbose("VCS filter: uncommitted changes (%d files)", len(vcsChanges))
 		}
 	}
 
@@ -189,10 +428,48 @@ func (r *Runner) Run(ctx context.Context) error {
 	if r.opts.Coverage {
 		hashInput = append(hashInput, "--coverage")
 	}
+	if r.opts.Command == "test" {
+		for _, trait := range r.opts.ExcludeTraits {
+			hashInput = append(hashInput, "--exclude-trait="+trait)
+		}
+	}
+	hashInput = append(hashInput, r.keyHashArgs()...)
 	argsHash := HashArgs(hashInput)
 
+	if r.opts.DiffInputs != "" {
+		return r.printDiffInputs(r.opts.DiffInputs, argsHash)
+	}
+	if r.opts.AffectedGraph != "" && r.opts.AffectedGraph != AffectedGraphDot && r.opts.AffectedGraph != AffectedGraphMermaid {
+		return fmt.Errorf("invalid --affected-graph %q (expected %s or %s)", r.opts.AffectedGraph, AffectedGraphDot, AffectedGraphMermaid)
+	}
+
 	// Find changed projects
-	changed := r.findChangedProjects(argsHash, vcsChangedFiles, useVcsFilter)
+	var changed map[string]bool
+	if r.onlyPaths != nil {
+		changed = r.findChangedOnlyProjects(argsHash)
+	} else {
+		changed = r.findChangedProjects(argsHash, vcsChanges, useVcsFilter)
+	}
+
+	// --since: also run projects without a successful run since the cutoff
+	if r.opts.Since != "" {
+		cutoff, err := parseSince(r.opts.Since, time.Now())
+		if err != nil {
+			return err
+		}
+		var candidates []*project.Project
+		for _, p := range r.projects {
+			if r.opts.Command == "test" && !p.IsTest {
+				continue
+			}
+			candidates = append(candidates, p)
+		}
+		stale := staleSince(candidates, r.db.GetLastSuccess(argsHash), cutoff)
+		for _, p := range stale {
+			changed[p.Path] = true
+		}
+		term.Verbose("Since %s: %d project(s) without a successful run", cutoff.Format(time.RFC3339), len(stale))
+	}
 
 	// When explicit targets are specified, force-mark them as changed
 	if r.targetPaths != nil {
@@ -205,26 +482,35 @@ func (r *Runner) Run(ctx context.Context) error {
 	// Find affected projects
 	affected := project.FindAffectedProjects(changed, r.graph, r.projects)
 
+	if r.opts.AffectedGraph != "" {
+		return writeAffectedGraph(r.opts.AffectedGraphOutput, r.opts.AffectedGraph, r.projects, r.graph, changed, affected)
+	}
+
 	// Filter to target projects
-	var targetProjects []*project.Project
-	var cachedProjects []*project.Project
+	targetProjects, cachedProjects, excludedProjects := r.selectTargets(affected)
 
-	for _, p := range r.projects {
-		// Filter by type for test command
-		if r.opts.Command == "test" && !p.IsTest {
-			continue
+	if r.opts.CacheKeyDebug {
+		term.Printf("%s", formatCacheKeyDebug(r.debugCacheKeys(append(targetProjects, cachedProjects...), argsHash, vcsChanges, useVcsFilter)))
+		return nil
+	}
+	if r.opts.Why != "" {
+		projects := r.findWhyProjects(r.opts.Why)
+		if len(projects) == 0 {
+			return fmt.Errorf("unknown project: %s", r.opts.Why)
 		}
-
-		// When explicit targets are specified, only include matching projects
-		if r.targetPaths != nil && !r.targetPaths[p.Path] {
-			continue
+		for i, p := range projects {
+			if i > 0 {
+				term.Println()
+			}
+			term.Printf("%s", formatWhy(r.explainProject(p, argsHash, vcsChanges, useVcsFilter, changed, affected, targetProjects, cachedProjects)))
 		}
+		return nil
+	}
 
-		if !affected[p.Path] {
-			cachedProjects = append(cachedProjects, p)
-			continue
+	if !r.opts.Quiet {
+		for _, p := range excludedProjects {
+			term.ExcludedLine(p.Name)
 		}
-		targetProjects = append(targetProjects, p)
 	}
 
 	// Handle --failed: filter to only previously-failed projects with per-test filters
@@ -240,7 +526,7 @@ func (r *Runner) Run(ctx context.Context) error {
 			for _, entry := range failedEntries {
 				failedPaths[entry.ProjectPath] = true
 				if p, ok := r.projectsByPath[entry.ProjectPath]; ok {
-					filter := getFailedTestFilter(entry.Output, r.reportsDir, p.Name)
+					filter := r.failedTestFilter(p, entry.Output)
 					if filter != "" {
 						r.opts.FailedTestFilters[entry.ProjectPath] = filter
 						term.Verbose("  %s: filtering to %d failed tests", p.Name, strings.Count(filter, "|")+1)
@@ -277,29 +563,13 @@ func (r *Runner) Run(ctx context.Context) error {
 	// Find untested projects (non-test projects with no test project referencing them)
 	// and add them as build-only targets so we at least verify compilation.
 	if r.opts.Command == "test" {
-		untestedProjects := project.FindUntestedProjects(r.projects, r.forwardGraph)
-		if len(untestedProjects) > 0 {
-			buildArgsHash := HashArgs(append([]string{"build"}, filterBuildArgs(r.opts.DotnetArgs)...))
-			r.opts.BuildOnlyProjects = make(map[string]bool)
-			var untestedNames []string
-			for _, p := range untestedProjects {
-				if !affected[p.Path] {
-					continue
-				}
-				// Re-check cache with build-specific hash
-				key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, buildArgsHash)
-				if !r.opts.Force && r.db.Lookup(key) != nil {
-					cachedProjects = append(cachedProjects, p)
-					continue
-				}
-				r.opts.BuildOnlyProjects[p.Path] = true
-				targetProjects = append(targetProjects, p)
-				untestedNames = append(untestedNames, p.Name)
-			}
-			if len(untestedNames) > 0 {
-				term.Warnf("%d project(s) have no tests, will build instead: %s", len(untestedNames), strings.Join(untestedNames, ", "))
-			}
-		}
+		targetProjects, cachedProjects = r.addUntestedBuildTargets(affected, targetProjects, cachedProjects)
+	}
+
+	var stats *cacheStats
+	if r.opts.CacheStats || r.opts.CacheStatsLog {
+		s := newCacheStats(targetProjects, cachedProjects, r.opts.Force)
+		stats = &s
 	}
 
 	// Set up test filter for non-watch mode (same filtering as watch mode).
@@ -312,6 +582,7 @@ func (r *Runner) Run(ctx context.Context) error {
 		tf := testfilter.NewTestFilter()
 		tf.SetCoverageMaps(testCovMaps)
 		tf.SetHeuristics(testfilter.ParseHeuristics(r.opts.Heuristics))
+		tf.SetHeuristicsResolver(r.heuristicsResolver)
 
 		// Map dirty files to their owning projects
 		for _, f := range dirtyFiles {
@@ -336,7 +607,10 @@ func (r *Runner) Run(ctx context.Context) error {
 	// Watch mode: run initial build/test if needed, then start watching
 	if r.opts.Watch {
 		if len(targetProjects) > 0 {
-			r.runProjects(ctx, targetProjects, cachedProjects, argsHash)
+			runStart := time.Now()
+			r.runProjects(ctx, r.contentHasher(), targetProjects, cachedProjects, argsHash)
+			r.notify(r.results, len(cachedProjects), time.Since(runStart))
+			r.publishSummary(r.results, len(cachedProjects), time.Since(runStart))
 		} else if !r.opts.Quiet {
 			term.Dim("No affected projects to %s (%d cached)%s", r.opts.Command, len(cachedProjects), formatExtraArgs(r.opts.DotnetArgs))
 		}
@@ -344,13 +618,22 @@ func (r *Runner) Run(ctx context.Context) error {
 	}
 
 	if len(targetProjects) == 0 {
+		r.writeMarkdownReport(cachedProjects, 0, stats)
+		r.writeTAP(time.Now())
+		r.publishSummary(nil, len(cachedProjects), 0)
+
 		if !r.opts.Quiet {
-			term.Dim("No affected projects to %s (%d cached)%s", r.opts.Command, len(cachedProjects), formatExtraArgs(r.opts.DotnetArgs))
-			for _, p := range cachedProjects {
-				term.CachedLine(p.Name)
+			if !r.opts.QuietOnSuccess {
+				term.Dim("No affected projects to %s (%d cached)%s", r.opts.Command, len(cachedProjects), formatExtraArgs(r.opts.DotnetArgs))
+				for _, p := range cachedProjects {
+					term.CachedLine(p.Name)
+				}
 			}
 			term.Summary(0, 0, len(cachedProjects), 0, true)
 		}
+		if stats != nil {
+			r.reportCacheStats(*stats)
+		}
 
 		// Print cached outputs if requested
 		if r.opts.PrintOutput && len(cachedProjects) > 0 {
@@ -361,7 +644,7 @@ func (r *Runner) Run(ctx context.Context) error {
 			})
 			term.Println()
 			for _, p := range sorted {
-				key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, argsHash)
+				key := r.contentHasher().ProjectCacheKey(p, r.forwardGraph, argsHash)
 				if result := r.db.Lookup(key); result != nil && len(result.Output) > 0 {
 					term.Printf("=== %s ===\n%s\n", p.Name, string(result.Output))
 				}
@@ -371,20 +654,48 @@ func (r *Runner) Run(ctx context.Context) error {
 		return nil
 	}
 
-	success := r.runProjects(ctx, targetProjects, cachedProjects, argsHash)
+	runStart := time.Now()
+	var success bool
+	if r.opts.QuietOnSuccess {
+		success = r.runProjectsQuietOnSuccess(ctx, targetProjects, cachedProjects, argsHash)
+	} else {
+		success = r.runProjects(ctx, r.contentHasher(), targetProjects, cachedProjects, argsHash)
+	}
+	r.writeMarkdownReport(cachedProjects, time.Since(runStart), stats)
+	r.writeCoverageReport(targetProjects)
+	r.writeCoverageSummary(targetProjects)
+	r.writeCoverageDiff(targetProjects)
+	if stats != nil {
+		r.reportCacheStats(*stats)
+	}
+	r.writeTAP(runStart)
+	r.notify(r.results, len(cachedProjects), time.Since(runStart))
+	r.publishSummary(r.results, len(cachedProjects), time.Since(runStart))
 	if !success {
 		return fmt.Errorf("%s failed", r.opts.Command)
 	}
 
+	if err := r.checkCoverageThresholds(targetProjects); err != nil {
+		return err
+	}
+	if err := r.checkCoverageMin(targetProjects); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // findChangedProjects returns projects that have changes.
+// With useVcsFilter, only projects owning one of vcsChanges (see vcsChangePaths) are candidates.
 // Projects are checked concurrently since content hash computation involves filesystem I/O.
-func (r *Runner) findChangedProjects(argsHash string, vcsChangedFiles []string, useVcsFilter bool) map[string]bool {
+func (r *Runner) findChangedProjects(argsHash string, vcsChanges []git.FileChange, useVcsFilter bool) map[string]bool {
 	changed := make(map[string]bool)
+	vcsChangedFiles := vcsChangePaths(vcsChanges)
 	var mu sync.Mutex
 	var wg sync.WaitGroup
+	if useVcsFilter {
+		r.changedFiles = make(map[string][]string)
+	}
 
 	for _, p := range r.projects {
 		wg.Add(1)
@@ -399,6 +710,9 @@ func (r *Runner) findChangedProjects(argsHash string, vcsChangedFiles []string,
 					return
 				}
 				term.Verbose("  vcs candidate: %s (%d files)", p.Name, len(projectVcsFiles))
+				mu.Lock()
+				r.changedFiles[p.Path] = projectVcsFiles
+				mu.Unlock()
 			}
 
 			// Check cache
@@ -414,26 +728,71 @@ func (r *Runner) findChangedProjects(argsHash string, vcsChangedFiles []string,
 	return changed
 }
 
+// findChangedOnlyProjects returns the --only-projects allowlist entries
+// whose cache entry is missing or stale. Unlike findChangedProjects it ignores
+// VCS filters and other projects entirely.
+func (r *Runner) findChangedOnlyProjects(argsHash string) map[string]bool {
+	changed := make(map[string]bool)
+	for path := range r.onlyPaths {
+		if r.projectChanged(r.projectsByPath[path], argsHash) {
+			changed[path] = true
+		}
+	}
+	return changed
+}
+
 // projectChanged checks if a project needs to be rebuilt/retested.
 func (r *Runner) projectChanged(p *project.Project, argsHash string) bool {
-	key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, argsHash)
+	c := r.checkCache(p, argsHash)
+	switch {
+	case c.hit:
+		term.Verbose("  cache hit: %s (key=%s)", p.Name, c.key)
+	case c.forced:
+		term.Verbose("  forced: %s (key=%s)", p.Name, c.key)
+	case c.noOutput:
+		term.Verbose("  cache miss (no output): %s (key=%s)", p.Name, c.key)
+	default:
+		term.Verbose("  cache miss: %s (key=%s)", p.Name, c.key)
+	}
+	return !c.hit
+}
 
-	if r.opts.Force {
-		term.Verbose("  forced: %s (key=%s)", p.Name, key)
-		return true
+// cacheCheck is the outcome of looking up a project's cache key.
+type cacheCheck struct {
+	contentHash string
+	key         string
+	hit         bool
+	forced      bool // missed because of --force
+	noOutput    bool // missed because the cached run has no output to print
+}
+
+// contentHasher returns the content hasher of the current run, which hashes
+// each directory once (see ContentHasher).
+func (r *Runner) contentHasher() *ContentHasher {
+	if r.hasher == nil {
+		r.hasher = NewContentHasher(r.gitRoot, r.opts.HashMode)
 	}
+	return r.hasher
+}
+
+// checkCache derives a project's cache key and looks it up.
+func (r *Runner) checkCache(p *project.Project, argsHash string) cacheCheck {
+	relevantDirs := project.GetRelevantDirs(p, r.forwardGraph)
+	contentHash := r.contentHasher().Hash(relevantDirs)
+	c := cacheCheck{contentHash: contentHash, key: cache.MakeKey(contentHash, argsHash, p.Path)}
 
-	if result := r.db.Lookup(key); result != nil {
+	if r.opts.Force {
+		c.forced = true
+		return c
+	}
+	if result := r.db.Lookup(c.key); result != nil {
 		if r.opts.PrintOutput && p.IsTest && len(result.Output) == 0 {
-			term.Verbose("  cache miss (no output): %s (key=%s)", p.Name, key)
-			return true
+			c.noOutput = true
+			return c
 		}
-		term.Verbose("  cache hit: %s (key=%s)", p.Name, key)
-		return false
+		c.hit = true
 	}
-
-	term.Verbose("  cache miss: %s (key=%s)", p.Name, key)
-	return true
+	return c
 }
 
 // resolveTargetProjects maps absolute target paths (.csproj, .sln, dirs) to
@@ -499,9 +858,262 @@ func (r *Runner) resolveTargetProjects() (map[string]bool, error) {
 	return matched, nil
 }
 
+// resolveNamedProjects maps --project values to project relative paths.
+// With WithDeps, the transitive project references of each match are
+// included too.
+func (r *Runner) resolveNamedProjects(cwd string) (map[string]bool, error) {
+	matched := make(map[string]bool)
+
+	for _, name := range r.opts.Projects {
+		p, err := r.findNamedProject("--project", name, cwd)
+		if err != nil {
+			return nil, err
+		}
+		matched[p.Path] = true
+		if r.opts.WithDeps {
+			for _, dep := range project.GetTransitiveDependencies(p.Path, r.forwardGraph) {
+				matched[dep] = true
+			}
+		}
+	}
+
+	return matched, nil
+}
+
+// resolveOnlyProjects maps --only-projects values to project relative paths.
+func (r *Runner) resolveOnlyProjects(cwd string) (map[string]bool, error) {
+	matched := make(map[string]bool)
+	for _, name := range r.opts.OnlyProjects {
+		p, err := r.findNamedProject("--only-projects", name, cwd)
+		if err != nil {
+			return nil, err
+		}
+		matched[p.Path] = true
+	}
+	return matched, nil
+}
+
+// resolveOnlyScope maps --only patterns to the matching projects plus their
+// transitive dependents. Excluded matches are skipped; a pattern matching
+// nothing, or only excluded projects, is an error.
+func (r *Runner) resolveOnlyScope() (map[string]bool, error) {
+	matched := make(map[string]bool)
+	for _, pattern := range r.opts.Only {
+		found := false
+		var excluded []string
+		for _, p := range r.projects {
+			if !project.MatchesAny(p, []string{pattern}) {
+				continue
+			}
+			if project.IsExcluded(p, r.excludePatterns) {
+				excluded = append(excluded, p.Name)
+				continue
+			}
+			matched[p.Path] = true
+			found = true
+		}
+		switch {
+		case !found && len(excluded) > 0:
+			return nil, fmt.Errorf("--only %q only selects excluded projects (%s; see --exclude and .donotnet/exclude)", pattern, strings.Join(excluded, ", "))
+		case !found:
+			return nil, fmt.Errorf("--only %q did not match any discovered project%s", pattern, projectSuggestions(r.projects, pattern))
+		case len(excluded) > 0:
+			term.Verbose("--only %q: skipping excluded %s", pattern, strings.Join(excluded, ", "))
+		}
+	}
+	return project.FindAffectedProjects(matched, r.graph, r.projects), nil
+}
+
+// findNamedProject returns the project matching name (case-insensitive) or
+// .csproj path, relative to cwd or the git root. flag names the option in
+// errors, which suggest similarly named projects when nothing matches.
+func (r *Runner) findNamedProject(flag, name, cwd string) (*project.Project, error) {
+	var found []*project.Project
+	for _, p := range r.projects {
+		if strings.EqualFold(p.Name, name) || r.isProjectPath(p, cwd, name) {
+			found = append(found, p)
+		}
+	}
+
+	switch {
+	case len(found) == 0:
+		return nil, fmt.Errorf("%s %q did not match any discovered project%s", flag, name, projectSuggestions(r.projects, name))
+	case len(found) > 1:
+		var paths []string
+		for _, p := range found {
+			paths = append(paths, p.Path)
+		}
+		sort.Strings(paths)
+		return nil, fmt.Errorf("%s %q matches %d projects (%s); pass its path instead", flag, name, len(found), strings.Join(paths, ", "))
+	}
+	return found[0], nil
+}
+
+// isProjectPath reports whether path refers to the project's .csproj, either
+// relative to cwd or to the git root.
+func (r *Runner) isProjectPath(p *project.Project, cwd, path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".csproj") {
+		return false
+	}
+	abs := filepath.Join(r.gitRoot, p.Path)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path) == abs
+	}
+	return filepath.Join(cwd, path) == abs || filepath.Join(r.gitRoot, path) == abs
+}
+
+// selectTargets splits the projects this run considers into affected
+// targets, unaffected ones (reported as cached) and affected ones left out by
+// an exclusion pattern.
+func (r *Runner) selectTargets(affected map[string]bool) (targets, cached, excluded []*project.Project) {
+	for _, p := range r.projects {
+		// Filter by type for test command
+		if r.opts.Command == "test" && !p.IsTest {
+			continue
+		}
+
+		// When explicit targets are specified, only include matching projects
+		if r.targetPaths != nil && !r.targetPaths[p.Path] {
+			continue
+		}
+		if r.onlyPaths != nil && !r.onlyPaths[p.Path] {
+			continue
+		}
+		if r.onlyScope != nil && !r.onlyScope[p.Path] {
+			continue
+		}
+
+		// Excluded projects are neither run nor reported as cached; affected
+		// ones are reported as excluded instead
+		if project.IsExcluded(p, r.excludePatterns) {
+			if affected[p.Path] {
+				excluded = append(excluded, p)
+			}
+			continue
+		}
+
+		if !affected[p.Path] {
+			cached = append(cached, p)
+			continue
+		}
+		targets = append(targets, p)
+	}
+	return targets, cached, excluded
+}
+
+// pendingDependencies maps each target to the references it has to wait for:
+// those that are targets themselves. Cached and excluded references are
+// never run, so they don't hold up their dependents.
+func (r *Runner) pendingDependencies(targets []*project.Project) map[string]map[string]bool {
+	targetSet := make(map[string]bool)
+	for _, p := range targets {
+		targetSet[p.Path] = true
+	}
+
+	pendingDeps := make(map[string]map[string]bool)
+	for _, p := range targets {
+		deps := make(map[string]bool)
+		for _, depPath := range r.forwardGraph[p.Path] {
+			if targetSet[depPath] {
+				deps[depPath] = true
+			}
+		}
+		pendingDeps[p.Path] = deps
+	}
+	return pendingDeps
+}
+
+// projectSuggestions formats up to five project names containing name, as a
+// hint for an unmatched --project.
+func projectSuggestions(projects []*project.Project, name string) string {
+	var similar []string
+	lower := strings.ToLower(name)
+	for _, p := range projects {
+		if strings.Contains(strings.ToLower(p.Name), lower) {
+			similar = append(similar, p.Name)
+		}
+	}
+	if len(similar) == 0 {
+		return ""
+	}
+	sort.Strings(similar)
+	if len(similar) > 5 {
+		similar = similar[:5]
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(similar, ", "))
+}
+
+// coverageBuildProjects returns the test projects to build per-test coverage
+// maps for. With useVcsFilter, only test projects whose relevant directories
+// contain one of changedFiles are returned.
+func (r *Runner) coverageBuildProjects(changedFiles []string, useVcsFilter bool) []*project.Project {
+	var affected map[string]bool
+	if useVcsFilter {
+		affected = project.FindProjectsForFiles(changedFiles, r.projects, r.forwardGraph)
+	}
+	var testProjects []*project.Project
+	for _, p := range r.projects {
+		if !p.IsTest || (useVcsFilter && !affected[p.Path]) {
+			continue
+		}
+		testProjects = append(testProjects, p)
+	}
+	return testProjects
+}
+
+// addUntestedBuildTargets adds affected, uncached non-test projects that no
+// test project references to targets as build-only projects, so a test run
+// at least verifies they compile. Returns the updated targets and cached lists.
+// With SkipUntested, such projects are out of scope: neither run nor cached.
+func (r *Runner) addUntestedBuildTargets(affected map[string]bool, targets, cached []*project.Project) ([]*project.Project, []*project.Project) {
+	if r.opts.SkipUntested {
+		return targets, cached
+	}
+	untestedProjects := project.FindUntestedProjects(r.projects, r.forwardGraph)
+	if len(untestedProjects) > 0 {
+		buildArgsHash := r.buildArgsHash(filterBuildArgs(r.opts.DotnetArgs))
+		r.opts.BuildOnlyProjects = make(map[string]bool)
+		var untestedNames []string
+		for _, p := range untestedProjects {
+			if !affected[p.Path] || project.IsExcluded(p, r.excludePatterns) {
+				continue
+			}
+			// Explicit targets, --project and --only-projects limit the run to those projects
+			if (r.targetPaths != nil && !r.targetPaths[p.Path]) || (r.onlyPaths != nil && !r.onlyPaths[p.Path]) || (r.onlyScope != nil && !r.onlyScope[p.Path]) {
+				continue
+			}
+			// Re-check cache with build-specific hash
+			key := r.contentHasher().ProjectCacheKey(p, r.forwardGraph, buildArgsHash)
+			if !r.opts.Force && r.db.Lookup(key) != nil {
+				cached = append(cached, p)
+				continue
+			}
+			r.opts.BuildOnlyProjects[p.Path] = true
+			targets = append(targets, p)
+			untestedNames = append(untestedNames, p.Name)
+		}
+		if len(untestedNames) > 0 {
+			term.Warnf("%d project(s) have no tests, will build instead (--skip-untested to skip them): %s", len(untestedNames), strings.Join(untestedNames, ", "))
+		}
+	}
+	return targets, cached
+}
+
+// keyHashArgs returns the args hash inputs that don't come from the command
+// line: --cache-env values and the cache version file.
+func (r *Runner) keyHashArgs() []string {
+	return append(envHashArgs(r.opts.CacheEnv), cacheVersionHashArgs(r.gitRoot)...)
+}
+
+// buildArgsHash is the args hash of building a build-only project with args.
+func (r *Runner) buildArgsHash(args []string) string {
+	hashInput := append([]string{"build"}, args...)
+	return HashArgs(append(hashInput, r.keyHashArgs()...))
+}
+
 // runProjects runs the command on the given projects using a parallel worker pool
 // with dependency-ordered scheduling.
-func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Project, argsHash string) bool {
+func (r *Runner) runProjects(ctx context.Context, hasher *ContentHasher, targets, cached []*project.Project, argsHash string) bool {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -523,7 +1135,7 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 	var filteredBuildArgs []string
 	if len(r.opts.BuildOnlyProjects) > 0 {
 		filteredBuildArgs = filterBuildArgs(r.opts.DotnetArgs)
-		buildArgsHash = HashArgs(append([]string{"build"}, filteredBuildArgs...))
+		buildArgsHash = r.buildArgsHash(filteredBuildArgs)
 		buildArgsForCache = strings.Join(append([]string{"build"}, filteredBuildArgs...), " ")
 	}
 
@@ -543,14 +1155,14 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 		// Single solution containing all test projects
 		if sln := project.FindCommonSolution(testProjects, r.solutions, r.gitRoot); sln != nil {
 			if len(buildOnlyList) == 0 {
-				return r.runSolutionCommand(ctx, sln, testProjects, cached, argsHash, argsForCache)
+				return r.runSolutionCommand(ctx, hasher, sln, testProjects, cached, argsHash, argsForCache)
 			}
 		}
 
 		// Grouped solution builds
 		var slnGroups map[*project.Solution][]*project.Project
 		var remaining []*project.Project
-		if r.opts.ForceSolution {
+		if r.opts.ForceSolution || r.opts.SolutionFilter {
 			slnGroups, remaining = project.GroupProjectsBySolution(testProjects, r.solutions, r.gitRoot)
 		} else {
 			slnGroups, remaining = project.FindCompleteSolutionMatches(testProjects, r.solutions, r.gitRoot)
@@ -559,7 +1171,7 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 		remaining = append(remaining, buildOnlyList...)
 
 		if len(slnGroups) > 0 {
-			return r.runSolutionGroups(ctx, slnGroups, remaining, cached, argsHash, argsForCache)
+			return r.runSolutionGroups(ctx, hasher, slnGroups, remaining, cached, argsHash, argsForCache)
 		}
 	}
 
@@ -624,6 +1236,7 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 					}
 				}
 
+				r.publish(ProjectStarted{Project: p})
 				result := r.runSingleProject(ctx, p, argsHash, argsForCache, buildArgsHash, buildArgsForCache, filteredBuildArgs, status, signalStop)
 
 				// Send result first (main loop needs it for docstrings/summary)
@@ -633,33 +1246,52 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 	}
 
 	// Build dependency tracking for target set
-	targetSet := make(map[string]bool)
-	for _, p := range targets {
-		targetSet[p.Path] = true
-	}
+	pendingDeps := r.pendingDependencies(targets)
 
-	pendingDeps := make(map[string]map[string]bool)
-	for _, p := range targets {
-		deps := make(map[string]bool)
-		for _, depPath := range r.forwardGraph[p.Path] {
-			if targetSet[depPath] {
-				deps[depPath] = true
-			}
-		}
-		pendingDeps[p.Path] = deps
+	// Historical failures and durations, so ready projects that failed last
+	// time are dispatched first, then the slowest
+	historyArgs := []string{argsHash}
+	if buildArgsHash != "" {
+		historyArgs = append(historyArgs, buildArgsHash)
+	}
+	history := r.db.GetRunHistory(historyArgs...)
+	lastFailed, durations := history.LastFailures, history.Durations
+	dispatchOrder := func(ready []*project.Project) []*project.Project {
+		return sortFailedFirst(sortSlowestFirst(ready, durations), lastFailed)
 	}
 
 	// Send ready jobs (no pending deps)
 	pending := make(map[string]*project.Project)
 	jobsSent := 0
+	var ready []*project.Project
 	for _, p := range targets {
 		if len(pendingDeps[p.Path]) == 0 {
-			jobs <- p
-			jobsSent++
+			ready = append(ready, p)
 		} else {
 			pending[p.Path] = p
 		}
 	}
+	for _, p := range dispatchOrder(ready) {
+		jobs <- p
+		jobsSent++
+	}
+
+	// unblockDependents queues the projects whose last pending dependency
+	// just finished, last failed and slowest first
+	unblockDependents := func(done string) {
+		var ready []*project.Project
+		for path, p := range pending {
+			delete(pendingDeps[path], done)
+			if len(pendingDeps[path]) == 0 {
+				delete(pending, path)
+				ready = append(ready, p)
+			}
+		}
+		for _, p := range dispatchOrder(ready) {
+			jobs <- p
+			jobsSent++
+		}
+	}
 
 	jobsClosed := false
 	closeJobsIfDone := func() {
@@ -702,7 +1334,10 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 	var failures []runResult
 	var allResults []runResult
 	directPrinted := make(map[string]bool)
+	// notRun counts the projects left out once --max-failures is reached (-1 = not reached)
+	notRun := -1
 
+collect:
 	for completed < len(targets) {
 		select {
 		case <-ticker.C:
@@ -725,6 +1360,18 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 		case res := <-results:
 			completed++
 			allResults = append(allResults, res)
+			r.addResult(res)
+
+			// Dry run: print the command, never touch the cache
+			if res.dryRun {
+				clearStatus()
+				term.Printf("%s\n", res.output)
+				succeeded++
+				unblockDependents(res.project.Path)
+				closeJobsIfDone()
+				continue
+			}
+			r.recordTestOutcomes(res)
 
 			if r.opts.Quiet {
 				// Mark cache (unless skipped by filter)
@@ -736,23 +1383,21 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 						cacheArgsHash = buildArgsHash
 						cacheArgsForCache = buildArgsForCache
 					}
-					key := ProjectCacheKey(res.project, r.gitRoot, r.forwardGraph, cacheArgsHash)
-					r.db.Mark(key, now, res.success, []byte(res.output), cacheArgsForCache)
+					key := hasher.ProjectCacheKey(res.project, r.forwardGraph, cacheArgsHash)
+					r.db.MarkWithDuration(key, now, res.duration, res.success, []byte(res.output), cacheArgsForCache)
 				}
 				if res.success {
 					succeeded++
 				} else {
 					failures = append(failures, res)
-				}
-				// Unblock waiting projects
-				for path, p := range pending {
-					delete(pendingDeps[path], res.project.Path)
-					if len(pendingDeps[path]) == 0 {
-						delete(pending, path)
-						jobs <- p
-						jobsSent++
+					if r.opts.FailureBudgetSpent(len(failures)) {
+						notRun = len(targets) - completed
+						cancel()
+						break collect
 					}
 				}
+				// Unblock waiting projects
+				unblockDependents(res.project.Path)
 				closeJobsIfDone()
 				continue
 			}
@@ -763,14 +1408,7 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 			if res.skippedByFilter {
 				succeeded++
 				testSucceeded++
-				for path, p := range pending {
-					delete(pendingDeps[path], res.project.Path)
-					if len(pendingDeps[path]) == 0 {
-						delete(pending, path)
-						jobs <- p
-						jobsSent++
-					}
-				}
+				unblockDependents(res.project.Path)
 				closeJobsIfDone()
 				continue
 			}
@@ -812,13 +1450,13 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 					cacheArgsHash = buildArgsHash
 					cacheArgsForCache = buildArgsForCache
 				}
-				key := ProjectCacheKey(res.project, r.gitRoot, r.forwardGraph, cacheArgsHash)
-				r.db.Mark(key, now, true, []byte(res.output), cacheArgsForCache)
+				key := hasher.ProjectCacheKey(res.project, r.forwardGraph, cacheArgsHash)
+				r.db.MarkWithDuration(key, now, res.duration, true, []byte(res.output), cacheArgsForCache)
 
 				// Mark transitive dependencies
 				for _, depPath := range project.GetTransitiveDependencies(res.project.Path, r.forwardGraph) {
 					if dep, ok := r.projectsByPath[depPath]; ok {
-						depKey := ProjectCacheKey(dep, r.gitRoot, r.forwardGraph, cacheArgsHash)
+						depKey := hasher.ProjectCacheKey(dep, r.forwardGraph, cacheArgsHash)
 						r.db.Mark(depKey, now, true, nil, cacheArgsForCache)
 					}
 				}
@@ -831,8 +1469,8 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 					cacheArgsHash = buildArgsHash
 					cacheArgsForCache = buildArgsForCache
 				}
-				key := ProjectCacheKey(res.project, r.gitRoot, r.forwardGraph, cacheArgsHash)
-				r.db.Mark(key, time.Now(), false, []byte(res.output), cacheArgsForCache)
+				key := hasher.ProjectCacheKey(res.project, r.forwardGraph, cacheArgsHash)
+				r.db.MarkWithDuration(key, time.Now(), res.duration, false, []byte(res.output), cacheArgsForCache)
 
 				alreadyPrinted := false
 				select {
@@ -857,17 +1495,15 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 					term.Summary(succeeded, len(targets), len(cached), totalDuration, false)
 					return false
 				}
+				if r.opts.FailureBudgetSpent(len(failures)) {
+					notRun = len(targets) - completed
+					cancel()
+					break collect
+				}
 			}
 
 			// Unblock waiting projects
-			for path, p := range pending {
-				delete(pendingDeps[path], res.project.Path)
-				if len(pendingDeps[path]) == 0 {
-					delete(pending, path)
-					jobs <- p
-					jobsSent++
-				}
-			}
+			unblockDependents(res.project.Path)
 			closeJobsIfDone()
 
 		case <-ctx.Done():
@@ -960,6 +1596,10 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 	} else {
 		term.Summary(succeeded, len(targets), len(cached), totalDuration, len(failures) == 0)
 	}
+	printFailureSummary(failures)
+	if notRun >= 0 {
+		term.Errorf("stopped after %d failures (%d projects not run)", len(failures), notRun)
+	}
 
 	// Print all outputs if requested
 	if r.opts.PrintOutput {
@@ -976,7 +1616,7 @@ func (r *Runner) runProjects(ctx context.Context, targets, cached []*project.Pro
 		}
 
 		for _, p := range cached {
-			key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, argsHash)
+			key := hasher.ProjectCacheKey(p, r.forwardGraph, argsHash)
 			if result := r.db.Lookup(key); result != nil && len(result.Output) > 0 {
 				outputs = append(outputs, outputEntry{p.Name, string(result.Output)})
 			}
@@ -1073,6 +1713,12 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 	var filteredTests bool
 	var testClasses []string
 	var argsBeforeOurFilter []string
+	// --exclude-trait acts like part of the user's filter, so the existing
+	// skip for fully excluded test files applies to it as well
+	if projectCommand == "test" {
+		extraArgs = r.withExcludedTraits(extraArgs, p)
+	}
+
 	userFilter := extractFilter(extraArgs)
 	originalExtraArgs := extraArgs // before any filter modifications
 	var skipTestsDueToUserFilter bool
@@ -1107,26 +1753,42 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 	}
 
 	// Apply failed test filters — combine with any existing --filter in extraArgs
+	userFiltered := userFilter != ""
 	if projectCommand == "test" && r.opts.FailedTestFilters != nil {
 		if filter, ok := r.opts.FailedTestFilters[p.Path]; ok && filter != "" {
 			extraArgs = combineFilter(extraArgs, filter)
 			filteredTests = true
 			testClasses = []string{"previously failed"}
+			userFiltered = true
 		}
 	}
 
 	// Add TRX logger if reports enabled
 	var trxPath string
 	if !r.opts.NoReports && projectCommand == "test" {
-		os.MkdirAll(r.reportsDir, 0755)
+		if !r.opts.DryRun {
+			os.MkdirAll(r.reportsDir, 0755)
+		}
 		trxPath = filepath.Join(r.reportsDir, p.Name+".trx")
 		args = append(args, "--logger", "trx;LogFileName="+trxPath)
 	}
-	_ = trxPath // trxPath used for TRX report generation
 
-	// Add coverage collection if enabled
+	// Add coverage collection if enabled. Each project gets its own results
+	// directory, keyed by its path, so projects sharing a directory or a name
+	// don't overwrite each other.
+	var resultsDir string
 	if r.opts.Coverage && projectCommand == "test" {
-		args = append(args, "--collect:XPlat Code Coverage")
+		resultsDir = coverage.RunResultsDir(r.reportsDir, p.Path)
+		if !r.opts.DryRun {
+			os.RemoveAll(resultsDir)
+			os.MkdirAll(resultsDir, 0755)
+		}
+		args = append(args, "--collect:XPlat Code Coverage", "--results-directory", resultsDir)
+	}
+
+	// Let the blame collector abort individual hung tests
+	if r.opts.TestHangTimeout > 0 && projectCommand == "test" {
+		args = append(args, blameHangArgs(r.opts.TestHangTimeout)...)
 	}
 
 	// Use filtered args for build-only projects
@@ -1136,6 +1798,21 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 		args = append(args, extraArgs...)
 	}
 
+	// Dry run: report the fully resolved command instead of running it
+	if r.opts.DryRun {
+		return runResult{
+			project:        p,
+			success:        true,
+			output:         "dotnet " + term.ShellQuoteArgs(args),
+			skippedBuild:   skippedBuild,
+			skippedRestore: skippedRestore,
+			filteredTests:  filteredTests,
+			testClasses:    testClasses,
+			buildOnly:      isBuildOnly,
+			dryRun:         true,
+		}
+	}
+
 	// Create a separate context for this command so we can kill it for fail-fast
 	// without affecting the main context (which is needed for result processing)
 	cmdCtx, cmdCancel := context.WithCancel(ctx)
@@ -1152,6 +1829,11 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 		onFailure:   signalStop,
 		killProcess: cmdCancel, // Kill this specific process on failure
 	}
+	if r.opts.EventSink != nil {
+		lineWriter.onLine = func(line string) {
+			r.publish(ProjectLine{Project: p, Line: line})
+		}
+	}
 	cmd.Stdout = lineWriter
 	cmd.Stderr = lineWriter
 	cmd.Dir = r.gitRoot
@@ -1197,10 +1879,9 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 	}
 
 	// Retry without test filter if no matches
-	filterError := strings.Contains(outputStr, "No test matches the given testcase filter")
-	filterFormatError := strings.Contains(outputStr, "Incorrect format for TestCaseFilter")
-	if filteredTests && (filterError || filterFormatError) {
-		if filterFormatError {
+	outcome := parseRunOutcome(outputStr)
+	if filteredTests && (outcome.NoTestsMatched || outcome.FilterRejected) {
+		if outcome.FilterRejected {
 			term.Warnf("  [%s] filter format error, retrying without our filter", p.Name)
 		} else {
 			term.Warnf("  [%s] heuristic filter matched 0 tests, retrying without it", p.Name)
@@ -1255,6 +1936,43 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 		testClasses = nil
 	}
 
+	// Report which test hung if the blame collector took a hang dump
+	if err != nil && r.opts.TestHangTimeout > 0 && projectCommand == "test" {
+		hangDir := resultsDir
+		if hangDir == "" {
+			hangDir = filepath.Join(filepath.Dir(projectPath), "TestResults")
+		}
+		if hang := findHangReport(hangDir, projectStart); hang != nil {
+			outputStr += "\n" + hang.String()
+		}
+	}
+
+	// An empty test assembly passes in dotnet; fail it on request. A run the
+	// user narrowed themselves may legitimately select nothing.
+	success := err == nil
+	if success && r.opts.FailOnNoTests && projectCommand == "test" && !isBuildOnly && !userFiltered && parseRunOutcome(outputStr).noTestsRan() {
+		success = false
+		outputStr += "\nNo tests ran in " + p.Name + " (--fail-on-no-tests)\n"
+	}
+
+	var coverageFile string
+	if resultsDir != "" {
+		coverageFile = coverage.FindCoverageFileIn(resultsDir)
+	}
+
+	// Per-test outcomes for --failed; a TRX older than this run is left over
+	// from an earlier one (e.g. the build failed)
+	var testResults []testresults.TestResult
+	if trxPath != "" && !r.opts.DryRun {
+		if info, statErr := os.Stat(trxPath); statErr == nil && !info.ModTime().Before(projectStart) {
+			if results, parseErr := testresults.ParseTRXResultsFile(trxPath); parseErr == nil {
+				testResults = results
+			} else {
+				term.Verbose("  [%s] TRX parse error: %v", p.Name, parseErr)
+			}
+		}
+	}
+
 	// Save console output if reports enabled
 	if !r.opts.NoReports {
 		consolePath := filepath.Join(r.reportsDir, p.Name+".log")
@@ -1263,7 +1981,7 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 
 	return runResult{
 		project:        p,
-		success:        err == nil,
+		success:        success,
 		output:         outputStr,
 		duration:       duration,
 		skippedBuild:   skippedBuild,
@@ -1271,6 +1989,8 @@ func (r *Runner) runSingleProject(ctx context.Context, p *project.Project, argsH
 		filteredTests:  filteredTests,
 		testClasses:    testClasses,
 		buildOnly:      isBuildOnly,
+		coverageFile:   coverageFile,
+		testResults:    testResults,
 	}
 }
 
@@ -1365,3 +2085,38 @@ func (r *Runner) printStartMessage(targets, cached []*project.Project, numWorker
 }
 
 // runWatch is implemented in watch.go
+
+// checkCoverageThresholds enforces per-project coverage thresholds after a
+// --coverage test run. Only projects that ran are checked; projects without
+// a matching threshold are exempt.
+func (r *Runner) checkCoverageThresholds(targets []*project.Project) error {
+	if !r.opts.Coverage || r.opts.DryRun || r.opts.Command != "test" || len(r.opts.CoverageThresholds) == 0 {
+		return nil
+	}
+
+	var testProjects []*project.Project
+	for _, p := range targets {
+		if p.IsTest && !r.opts.BuildOnlyProjects[p.Path] {
+			testProjects = append(testProjects, p)
+		}
+	}
+
+	coverageFiles := make(map[string]string)
+	for _, f := range r.testCoverageFiles(targets) {
+		coverageFiles[f.project.Path] = f.path
+	}
+
+	failed := 0
+	for _, res := range coverage.CheckThresholds(r.gitRoot, testProjects, r.opts.CoverageThresholds, coverageFiles) {
+		if res.Passed() {
+			term.Verbose("  %s", res)
+			continue
+		}
+		term.Errorf("%s", res)
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d project(s) below coverage threshold", failed)
+	}
+	return nil
+}
@@ -1,11 +1,21 @@
 package runner
 
 import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/runar-rkmedia/donotnet/cache"
 	"github.com/runar-rkmedia/donotnet/config"
+	"github.com/runar-rkmedia/donotnet/project"
 )
 
 func TestNewOptions(t *testing.T) {
@@ -34,6 +44,9 @@ func TestNewOptions(t *testing.T) {
 	if !opts.Coverage {
 		t.Error("expected Coverage to be true from config")
 	}
+	if opts.WatchDebounce != 100*time.Millisecond {
+		t.Errorf("expected WatchDebounce to be 100ms from config, got %s", opts.WatchDebounce)
+	}
 }
 
 func TestEffectiveParallel(t *testing.T) {
@@ -51,6 +64,61 @@ func TestEffectiveParallel(t *testing.T) {
 	}
 }
 
+func TestFailureBudgetSpent(t *testing.T) {
+	opts := NewOptions(nil)
+	opts.MaxFailures = 2
+	if opts.FailureBudgetSpent(5) {
+		t.Error("expected --max-failures to be ignored without --keep-going")
+	}
+
+	opts.KeepGoing = true
+	if opts.FailureBudgetSpent(1) || !opts.FailureBudgetSpent(2) {
+		t.Error("expected the budget to be spent at exactly 2 failures")
+	}
+
+	opts.MaxFailures = 0
+	if opts.FailureBudgetSpent(100) {
+		t.Error("expected 0 to mean unlimited")
+	}
+}
+
+func TestEnvHashArgs(t *testing.T) {
+	t.Setenv("DONOTNET_TEST_ENV_A", "Staging")
+	t.Setenv("DONOTNET_TEST_ENV_B", "")
+
+	got := envHashArgs([]string{"DONOTNET_TEST_ENV_B", " DONOTNET_TEST_ENV_A", "DONOTNET_TEST_ENV_B", "DONOTNET_TEST_ENV_UNSET", ""})
+	want := []string{
+		"--cache-env=DONOTNET_TEST_ENV_A=Staging",
+		"--cache-env=DONOTNET_TEST_ENV_B=",
+		"--cache-env=DONOTNET_TEST_ENV_UNSET=",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("envHashArgs() = %v, want %v", got, want)
+	}
+	if got := envHashArgs(nil); got != nil {
+		t.Errorf("envHashArgs(nil) = %v, want nil", got)
+	}
+}
+
+func TestCacheVersionHashArgs(t *testing.T) {
+	root := t.TempDir()
+	if got := cacheVersionHashArgs(root); got != nil {
+		t.Errorf("without a cache version file: got %v, want nil", got)
+	}
+
+	path := filepath.Join(root, config.ConfigDirName, CacheVersionFileName)
+	os.MkdirAll(filepath.Dir(path), 0755)
+	os.WriteFile(path, []byte("  \n"), 0644)
+	if got := cacheVersionHashArgs(root); got != nil {
+		t.Errorf("with an empty cache version file: got %v, want nil", got)
+	}
+
+	os.WriteFile(path, []byte("3\n"), 0644)
+	if got, want := cacheVersionHashArgs(root), []string{"--cache-version=3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("cacheVersionHashArgs() = %v, want %v", got, want)
+	}
+}
+
 func TestHashArgs(t *testing.T) {
 	tests := []struct {
 		args     []string
@@ -115,6 +183,113 @@ func TestComputeContentHashIncludesConfigFiles(t *testing.T) {
 	}
 }
 
+func TestComputeContentHashSkipsGeneratedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "Generated"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "App.cs"), []byte("class App {}"), 0644)
+
+	hash1 := ComputeContentHash(tmpDir, []string{tmpDir})
+
+	// Generated files do not affect the hash
+	os.WriteFile(filepath.Join(tmpDir, "App.g.cs"), []byte("partial class App {}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "Resources.Designer.cs"), []byte("class Resources {}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "Generated", "Client.cs"), []byte("class Client {}"), 0644)
+
+	if hash2 := ComputeContentHash(tmpDir, []string{tmpDir}); hash1 != hash2 {
+		t.Error("Content hash should not change when generated files change")
+	}
+
+	// .donotnet/hashignore replaces the defaults
+	os.MkdirAll(filepath.Join(tmpDir, ".donotnet"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".donotnet", HashIgnoreFileName), []byte("*.g.cs\n"), 0644)
+
+	if hash3 := ComputeContentHash(tmpDir, []string{tmpDir}); hash3 == hash1 {
+		t.Error("Content hash should include Designer and Generated/ files once the defaults are overridden")
+	}
+}
+
+func TestComputeContentHashSkipsSubmodules(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "vendor", "Lib"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, ".gitmodules"), []byte("[submodule \"Lib\"]\n\tpath = vendor/Lib\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "App.cs"), []byte("class App {}"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "vendor", "Lib", "Lib.cs"), []byte("class Lib {}"), 0644)
+
+	rootHash := ComputeContentHash(tmpDir, []string{tmpDir})
+	libHash := ComputeContentHash(tmpDir, []string{"vendor/Lib"})
+	if libHash == "" {
+		t.Fatal("a walk starting inside a submodule should hash its files")
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "vendor", "Lib", "Lib.cs"), []byte("class Lib { int x; }"), 0644)
+	if hash := ComputeContentHash(tmpDir, []string{tmpDir}); hash != rootHash {
+		t.Error("Content hash should not change when files inside a submodule change")
+	}
+	if hash := ComputeContentHash(tmpDir, []string{"vendor/Lib"}); hash == libHash {
+		t.Error("Content hash of a project inside a submodule should change with its files")
+	}
+}
+
+func TestCanSkipBuildExeProject(t *testing.T) {
+	gitRoot := t.TempDir()
+	projectDir := filepath.Join(gitRoot, "src", "Tool")
+	outDir := filepath.Join(projectDir, "bin", "Release", "net8.0", "win-x64")
+	os.MkdirAll(outDir, 0755)
+
+	writeProject := func(props string) string {
+		path := filepath.Join(projectDir, "Tool.csproj")
+		os.WriteFile(path, []byte(`<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup>`+props+`</PropertyGroup></Project>`), 0644)
+		return path
+	}
+	source := filepath.Join(projectDir, "Program.cs")
+	os.WriteFile(source, []byte("class Program {}"), 0644)
+
+	// The build only left an .exe named after <AssemblyName>
+	os.WriteFile(filepath.Join(outDir, "mytool.exe"), []byte("MZ"), 0755)
+	past := time.Now().Add(-time.Hour)
+	for _, f := range []string{source, filepath.Join(projectDir, "Tool.csproj")} {
+		os.Chtimes(f, past, past)
+	}
+
+	tests := []struct {
+		name  string
+		props string
+		want  bool
+	}{
+		{"exe with assembly name", "<OutputType>Exe</OutputType><AssemblyName>mytool</AssemblyName>", true},
+		{"winexe with assembly name", "<OutputType> WinExe </OutputType><AssemblyName>mytool</AssemblyName>", true},
+		{"library does not match exe", "<AssemblyName>mytool</AssemblyName>", false},
+		{"exe without assembly name", "<OutputType>Exe</OutputType>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeProject(tt.props)
+			os.Chtimes(path, past, past)
+			if got := canSkipBuild(path, []string{projectDir}, gitRoot); got != tt.want {
+				t.Errorf("canSkipBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildOutputNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "App.csproj")
+	os.WriteFile(path, []byte(`<Project><PropertyGroup><OutputType>Exe</OutputType><AssemblyName>$(MSBuildProjectName).Cli</AssemblyName></PropertyGroup></Project>`), 0644)
+
+	got := buildOutputNames(path)
+	want := []string{"App.dll", "App.exe", "App"}
+	if len(got) != len(want) {
+		t.Fatalf("buildOutputNames() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("buildOutputNames() = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
 func TestFormatExtraArgs(t *testing.T) {
 	tests := []struct {
 		args []string
@@ -204,7 +379,444 @@ func TestNeedsRestoreRetry(t *testing.T) {
 	}
 }
 
+func TestRunSingleProjectDryRun(t *testing.T) {
+	gitRoot := t.TempDir()
+	p := &project.Project{Name: "App", Path: "src/App/App.csproj", Dir: "src/App"}
+
+	r := New(&Options{
+		Command:    "build",
+		DotnetArgs: []string{"-c", "Release"},
+		FullBuild:  true,
+		DryRun:     true,
+		NoReports:  true,
+	})
+	r.gitRoot = gitRoot
+
+	res := r.runSingleProject(context.Background(), p, "", "", "", "", nil, nil, func() {})
+	if !res.success || !res.dryRun {
+		t.Fatalf("expected synthetic dry-run success, got %+v", res)
+	}
+	want := "dotnet build " + filepath.Join(gitRoot, p.Path) + " '--property:WarningLevel=0' -clp:ErrorsOnly -c Release"
+	if res.output != want {
+		t.Errorf("output = %q, want %q", res.output, want)
+	}
+}
+
+func TestDryRunSolutionComment(t *testing.T) {
+	sln := &project.Solution{RelPath: "App.sln"}
+	projects := []*project.Project{{Name: "App"}, {Name: "App.Tests"}}
+
+	want := "# App.sln batches 2 project(s): App, App.Tests"
+	if got := dryRunSolutionComment(sln, projects); got != want {
+		t.Errorf("dryRunSolutionComment() = %q, want %q", got, want)
+	}
+}
+
+func TestCoverageBuildProjects(t *testing.T) {
+	core := &project.Project{Name: "Core", Path: "Core/Core.csproj", Dir: "Core"}
+	coreTests := &project.Project{Name: "Core.Tests", Path: "Core.Tests/Core.Tests.csproj", Dir: "Core.Tests", IsTest: true}
+	webTests := &project.Project{Name: "Web.Tests", Path: "Web.Tests/Web.Tests.csproj", Dir: "Web.Tests", IsTest: true}
+
+	r := New(&Options{Command: "test", CoverageBuild: true})
+	r.projects = []*project.Project{core, coreTests, webTests}
+	r.forwardGraph = map[string][]string{coreTests.Path: {core.Path}}
+
+	if got := names(r.coverageBuildProjects(nil, false)); len(got) != 2 {
+		t.Errorf("without VCS filter got %v, want both test projects", got)
+	}
+
+	got := names(r.coverageBuildProjects([]string{"Core/Service.cs"}, true))
+	if len(got) != 1 || got[0] != "Core.Tests" {
+		t.Errorf("coverageBuildProjects() = %v, want [Core.Tests]", got)
+	}
+
+	if got := r.coverageBuildProjects(nil, true); len(got) != 0 {
+		t.Errorf("expected no projects without changes, got %v", names(got))
+	}
+}
+
+func TestAddUntestedBuildTargets(t *testing.T) {
+	gitRoot := t.TempDir()
+	db, err := cache.Open(filepath.Join(gitRoot, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	tests := &project.Project{Name: "App.Tests", Path: "App.Tests/App.Tests.csproj", Dir: "App.Tests", IsTest: true}
+	app := &project.Project{Name: "App", Path: "App/App.csproj", Dir: "App"}
+	tool := &project.Project{Name: "Tool", Path: "Tool/Tool.csproj", Dir: "Tool"}
+	affected := map[string]bool{tests.Path: true, app.Path: true, tool.Path: true}
+
+	newRunner := func(skipUntested bool) *Runner {
+		r := New(&Options{Command: "test", SkipUntested: skipUntested})
+		r.gitRoot = gitRoot
+		r.db = db
+		r.projects = []*project.Project{tests, app, tool}
+		r.forwardGraph = map[string][]string{tests.Path: {app.Path}}
+		return r
+	}
+
+	// By default the untested Tool project is built
+	r := newRunner(false)
+	targets, cached := r.addUntestedBuildTargets(affected, []*project.Project{tests}, nil)
+	if len(targets) != 2 || targets[1] != tool || !r.opts.BuildOnlyProjects[tool.Path] {
+		t.Errorf("expected Tool to be added as build-only, got targets %v", names(targets))
+	}
+	if len(cached) != 0 {
+		t.Errorf("expected no cached projects, got %v", names(cached))
+	}
+
+	// With --skip-untested, Tool is neither a target nor counted as cached
+	r = newRunner(true)
+	targets, cached = r.addUntestedBuildTargets(affected, []*project.Project{tests}, nil)
+	if len(targets) != 1 || targets[0] != tests {
+		t.Errorf("expected only App.Tests to run, got targets %v", names(targets))
+	}
+	if len(cached) != 0 || len(r.opts.BuildOnlyProjects) != 0 {
+		t.Errorf("expected Tool to be out of scope, got cached %v, build-only %v", names(cached), r.opts.BuildOnlyProjects)
+	}
+}
+
+func TestResolveNamedProjects(t *testing.T) {
+	gitRoot := t.TempDir()
+	core := &project.Project{Name: "Core", Path: filepath.Join("src", "Core", "Core.csproj"), Dir: filepath.Join("src", "Core")}
+	coreTests := &project.Project{Name: "Core.Tests", Path: filepath.Join("tests", "Core.Tests", "Core.Tests.csproj"), Dir: filepath.Join("tests", "Core.Tests"), IsTest: true}
+	webTests := &project.Project{Name: "Web.Tests", Path: filepath.Join("tests", "Web.Tests", "Web.Tests.csproj"), Dir: filepath.Join("tests", "Web.Tests"), IsTest: true}
+
+	newRunner := func(withDeps bool, projects ...string) *Runner {
+		r := New(&Options{Command: "test", Projects: projects, WithDeps: withDeps})
+		r.gitRoot = gitRoot
+		r.projects = []*project.Project{core, coreTests, webTests}
+		r.forwardGraph = map[string][]string{coreTests.Path: {core.Path}}
+		return r
+	}
+
+	tests := []struct {
+		name     string
+		projects []string
+		withDeps bool
+		cwd      string
+		want     []string
+	}{
+		{"by name", []string{"core.tests"}, false, gitRoot, []string{coreTests.Path}},
+		{"repeated", []string{"Core.Tests", "Web.Tests"}, false, gitRoot, []string{coreTests.Path, webTests.Path}},
+		{"by path from git root", []string{filepath.Join("tests", "Web.Tests", "Web.Tests.csproj")}, false, gitRoot, []string{webTests.Path}},
+		{"by path from cwd", []string{filepath.Join("Web.Tests", "Web.Tests.csproj")}, false, filepath.Join(gitRoot, "tests"), []string{webTests.Path}},
+		{"with deps", []string{"Core.Tests"}, true, gitRoot, []string{coreTests.Path, core.Path}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, err := newRunner(tt.withDeps, tt.projects...).resolveNamedProjects(tt.cwd)
+			if err != nil {
+				t.Fatalf("resolveNamedProjects() failed: %v", err)
+			}
+			if len(matched) != len(tt.want) {
+				t.Errorf("resolveNamedProjects() = %v, want %v", matched, tt.want)
+			}
+			for _, path := range tt.want {
+				if !matched[path] {
+					t.Errorf("resolveNamedProjects() = %v, missing %s", matched, path)
+				}
+			}
+		})
+	}
+
+	_, err := newRunner(false, "Tests").resolveNamedProjects(gitRoot)
+	if err == nil || !strings.Contains(err.Error(), "did you mean Core.Tests, Web.Tests?") {
+		t.Errorf("expected unmatched error with suggestions, got %v", err)
+	}
+}
+
+func TestFindChangedOnlyProjects(t *testing.T) {
+	gitRoot := t.TempDir()
+	db, err := cache.Open(filepath.Join(gitRoot, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	coreTests := &project.Project{Name: "Core.Tests", Path: "Core.Tests/Core.Tests.csproj", Dir: "Core.Tests", IsTest: true}
+	webTests := &project.Project{Name: "Web.Tests", Path: "Web.Tests/Web.Tests.csproj", Dir: "Web.Tests", IsTest: true}
+	for _, p := range []*project.Project{coreTests, webTests} {
+		os.MkdirAll(filepath.Join(gitRoot, p.Dir), 0755)
+		os.WriteFile(filepath.Join(gitRoot, p.Dir, "Tests.cs"), []byte("class Tests {}"), 0644)
+	}
+
+	newRunner := func(force bool) *Runner {
+		r := New(&Options{Command: "test", OnlyProjects: []string{"Core.Tests"}, Force: force})
+		r.gitRoot = gitRoot
+		r.db = db
+		r.projects = []*project.Project{coreTests, webTests}
+		r.projectsByPath = map[string]*project.Project{coreTests.Path: coreTests, webTests.Path: webTests}
+		r.forwardGraph = map[string][]string{}
+		matched, err := r.resolveOnlyProjects(gitRoot)
+		if err != nil {
+			t.Fatalf("resolveOnlyProjects() failed: %v", err)
+		}
+		r.onlyPaths = matched
+		return r
+	}
+
+	// Uncached: the allowlisted project runs, the other is never considered
+	argsHash := HashArgs([]string{"test"})
+	changed := newRunner(false).findChangedOnlyProjects(argsHash)
+	if len(changed) != 1 || !changed[coreTests.Path] {
+		t.Errorf("findChangedOnlyProjects() = %v, want only %s", changed, coreTests.Path)
+	}
+
+	// Cached: skipped unless forced
+	db.Mark(ProjectCacheKey(coreTests, gitRoot, map[string][]string{}, argsHash), time.Now(), true, []byte("ok"), "test")
+	if changed := newRunner(false).findChangedOnlyProjects(argsHash); len(changed) != 0 {
+		t.Errorf("findChangedOnlyProjects() = %v, want cached project skipped", changed)
+	}
+	if changed := newRunner(true).findChangedOnlyProjects(argsHash); !changed[coreTests.Path] {
+		t.Errorf("findChangedOnlyProjects() = %v, want forced project to run", changed)
+	}
+
+	r := newRunner(false)
+	r.opts.OnlyProjects = []string{"Core.Test"}
+	if _, err := r.resolveOnlyProjects(gitRoot); err == nil || !strings.Contains(err.Error(), `--only-projects "Core.Test" did not match any discovered project (did you mean Core.Tests?)`) {
+		t.Errorf("expected unmatched error with suggestion, got %v", err)
+	}
+}
+
+func TestAddUntestedBuildTargetsRespectsTargets(t *testing.T) {
+	gitRoot := t.TempDir()
+	db, err := cache.Open(filepath.Join(gitRoot, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	tests := &project.Project{Name: "App.Tests", Path: "App.Tests/App.Tests.csproj", Dir: "App.Tests", IsTest: true}
+	tool := &project.Project{Name: "Tool", Path: "Tool/Tool.csproj", Dir: "Tool"}
+
+	r := New(&Options{Command: "test", Projects: []string{"App.Tests"}})
+	r.gitRoot = gitRoot
+	r.db = db
+	r.projects = []*project.Project{tests, tool}
+	r.forwardGraph = map[string][]string{}
+	r.targetPaths = map[string]bool{tests.Path: true}
+
+	// Only the named project runs, even though the untested Tool is affected
+	affected := map[string]bool{tests.Path: true, tool.Path: true}
+	targets, cached := r.addUntestedBuildTargets(affected, []*project.Project{tests}, nil)
+	if len(targets) != 1 || targets[0] != tests || len(cached) != 0 {
+		t.Errorf("expected only App.Tests to run, got targets %v, cached %v", names(targets), names(cached))
+	}
+}
+
+func TestFailureSummary(t *testing.T) {
+	if got := failureSummary(nil); got != "" {
+		t.Errorf("failureSummary(nil) = %q, want empty", got)
+	}
+
+	failures := []runResult{
+		{project: &project.Project{Name: "Api.Tests"}, output: "Failed!  - Failed:     3, Passed:    10, Skipped:     0, Total:    13"},
+		{project: &project.Project{Name: "Web.Tests"}, output: "error CS1002: ; expected"},
+		{project: &project.Project{Name: "Tool"}, buildOnly: true, output: "error CS0246"},
+	}
+	want := "Failed: Api.Tests (3 tests), Web.Tests, Tool (build)"
+	if got := failureSummary(failures); got != want {
+		t.Errorf("failureSummary() = %q, want %q", got, want)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && len(s) >= len(substr) &&
 		(s == substr || len(s) > len(substr))
 }
+
+func TestResolveOnlyScope(t *testing.T) {
+	core := &project.Project{Name: "Core", Path: "src/Core/Core.csproj"}
+	api := &project.Project{Name: "Api", Path: "src/Api/Api.csproj"}
+	apiTests := &project.Project{Name: "Api.Tests", Path: "tests/Api.Tests/Api.Tests.csproj", IsTest: true}
+	web := &project.Project{Name: "Web", Path: "src/Web/Web.csproj"}
+
+	newRunner := func(only, exclude []string) *Runner {
+		r := New(&Options{Only: only})
+		r.projects = []*project.Project{core, api, apiTests, web}
+		r.graph = map[string][]string{
+			core.Path: {api.Path, web.Path},
+			api.Path:  {apiTests.Path},
+		}
+		r.excludePatterns = exclude
+		return r
+	}
+
+	scope, err := newRunner([]string{"Api"}, nil).resolveOnlyScope()
+	if err != nil {
+		t.Fatalf("resolveOnlyScope() failed: %v", err)
+	}
+	if len(scope) != 2 || !scope[api.Path] || !scope[apiTests.Path] {
+		t.Errorf("scope = %v, want Api and its dependent Api.Tests", scope)
+	}
+
+	scope, err = newRunner([]string{"src/W*/**"}, nil).resolveOnlyScope()
+	if err != nil {
+		t.Fatalf("resolveOnlyScope() with path glob failed: %v", err)
+	}
+	if len(scope) != 1 || !scope[web.Path] {
+		t.Errorf("scope = %v, want only Web", scope)
+	}
+
+	if _, err := newRunner([]string{"Nope"}, nil).resolveOnlyScope(); err == nil || !strings.Contains(err.Error(), "did not match") {
+		t.Errorf("expected no-match error, got %v", err)
+	}
+
+	// Excluded matches are skipped rather than failing the run
+	scope, err = newRunner([]string{"Api*", "Web"}, []string{"*.Tests"}).resolveOnlyScope()
+	if err != nil {
+		t.Fatalf("resolveOnlyScope() with an excluded match failed: %v", err)
+	}
+	if !scope[api.Path] || !scope[web.Path] {
+		t.Errorf("scope = %v, want Api and Web", scope)
+	}
+
+	_, err = newRunner([]string{"*.Tests"}, []string{"*.Tests"}).resolveOnlyScope()
+	if err == nil || !strings.Contains(err.Error(), "only selects excluded projects (Api.Tests") {
+		t.Errorf("expected error for a pattern matching only excluded projects, got %v", err)
+	}
+}
+
+func TestExcludedDependencyDoesNotBlockDependents(t *testing.T) {
+	lib := &project.Project{Name: "Lib", Path: "Lib/Lib.csproj", Dir: "Lib"}
+	app := &project.Project{Name: "App", Path: "App/App.csproj", Dir: "App"}
+	web := &project.Project{Name: "Web", Path: "Web/Web.csproj", Dir: "Web"}
+
+	r := New(&Options{Command: "build"})
+	r.projects = []*project.Project{lib, app, web}
+	r.forwardGraph = map[string][]string{app.Path: {lib.Path}, web.Path: {app.Path}}
+	r.excludePatterns = []string{"Lib"}
+
+	targets, cached, excluded := r.selectTargets(map[string]bool{lib.Path: true, app.Path: true, web.Path: true})
+	if got := names(targets); !reflect.DeepEqual(got, []string{"App", "Web"}) {
+		t.Errorf("targets = %v, want [App Web]", got)
+	}
+	if len(cached) != 0 {
+		t.Errorf("cached = %v, want none", names(cached))
+	}
+	if got := names(excluded); !reflect.DeepEqual(got, []string{"Lib"}) {
+		t.Errorf("excluded = %v, want [Lib]", got)
+	}
+
+	// App is ready right away instead of waiting for the excluded Lib, and
+	// Web still waits for App
+	pending := r.pendingDependencies(targets)
+	if len(pending[app.Path]) != 0 {
+		t.Errorf("App waits for %v, want nothing", sortedKeys(pending[app.Path]))
+	}
+	if !reflect.DeepEqual(sortedKeys(pending[web.Path]), []string{app.Path}) {
+		t.Errorf("Web waits for %v, want [%s]", sortedKeys(pending[web.Path]), app.Path)
+	}
+}
+
+func TestContentHasher(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"Core", "App", "Web"} {
+		os.MkdirAll(filepath.Join(tmpDir, dir), 0755)
+		os.WriteFile(filepath.Join(tmpDir, dir, dir+".cs"), []byte("class "+dir+" {}"), 0644)
+	}
+
+	hasher := NewContentHasher(tmpDir, HashModeContent)
+	app := hasher.Hash([]string{"App", "Core"})
+	web := hasher.Hash([]string{"Web", "Core"})
+
+	// Composing shared directories gives the same hash as a single pass over
+	// all files, whatever the order of the directories
+	if want := referenceContentHash(tmpDir, []string{"Core", "App"}); app != want {
+		t.Errorf("memoized hash %s, want %s", app, want)
+	}
+	if want := referenceContentHash(tmpDir, []string{"Web", "Core"}); web != want {
+		t.Errorf("memoized hash %s, want %s", web, want)
+	}
+	if app == web {
+		t.Error("projects with different directories should hash differently")
+	}
+
+	// Directories are hashed once per hasher
+	os.WriteFile(filepath.Join(tmpDir, "Core", "Core.cs"), []byte("class Core { int x; }"), 0644)
+	if hash := hasher.Hash([]string{"App", "Core"}); hash != app {
+		t.Error("a hasher should reuse the digests of directories it has seen")
+	}
+	if hash := NewContentHasher(tmpDir, HashModeContent).Hash([]string{"App", "Core"}); hash == app {
+		t.Error("a new hasher should see the changed file")
+	}
+}
+
+// referenceContentHash computes a content hash in a single pass, without
+// ignore handling: path and content SHA256 of every file, in path order.
+func referenceContentHash(root string, dirs []string) string {
+	var files []string
+	for _, dir := range dirs {
+		filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				files = append(files, path)
+			}
+			return nil
+		})
+	}
+	sort.Strings(files)
+	h := sha256.New()
+	for _, f := range files {
+		content, _ := os.ReadFile(f)
+		sum := sha256.Sum256(content)
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+		h.Write(sum[:])
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+}
+
+func TestContentHasher_FollowsImports(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(tmpDir, rel)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	write("build/Common.targets", `<Project><Import Project="Nested.props" /></Project>`)
+	write("build/Nested.props", `<Project></Project>`)
+	write("App/App.csproj", `<Project Sdk="Microsoft.NET.Sdk"><Import Project="../build/Common.targets" /></Project>`)
+	write("App/Program.cs", "class Program {}")
+
+	hash := func() string { return NewContentHasher(tmpDir, HashModeContent).Hash([]string{"App"}) }
+	before := hash()
+
+	write("build/Common.targets", `<Project><Import Project="Nested.props" /><!-- changed --></Project>`)
+	afterImport := hash()
+	if afterImport == before {
+		t.Error("hash should change when an imported file changes")
+	}
+
+	write("build/Nested.props", `<Project><!-- changed --></Project>`)
+	if hash() == afterImport {
+		t.Error("hash should change when a transitively imported file changes")
+	}
+}
+
+func TestContentHasher_MtimeMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "Core", "Core.cs")
+	os.MkdirAll(filepath.Dir(file), 0755)
+	os.WriteFile(file, []byte("class Core {}"), 0644)
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(file, old, old)
+
+	content := NewContentHasher(tmpDir, HashModeContent).Hash([]string{"Core"})
+	mtime := NewContentHasher(tmpDir, HashModeMtime).Hash([]string{"Core"})
+	if mtime == "" || mtime == content {
+		t.Errorf("mtime hash %q should be set and differ from content hash %q", mtime, content)
+	}
+
+	// Touching a file changes the mtime hash but not the content hash
+	os.Chtimes(file, old.Add(time.Minute), old.Add(time.Minute))
+	if hash := NewContentHasher(tmpDir, HashModeMtime).Hash([]string{"Core"}); hash == mtime {
+		t.Error("mtime hash should change when the modification time changes")
+	}
+	if hash := NewContentHasher(tmpDir, HashModeContent).Hash([]string{"Core"}); hash != content {
+		t.Error("content hash should not depend on the modification time")
+	}
+}
@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// sortSlowestFirst orders ready projects by their historical duration,
+// longest first, so the slowest projects don't end up starting last.
+// Projects without a recorded duration go first, as they may be the slowest;
+// ties keep their original order. The input slice is sorted in place.
+func sortSlowestFirst(projects []*project.Project, durations map[string]time.Duration) []*project.Project {
+	sort.SliceStable(projects, func(i, j int) bool {
+		di, iKnown := durations[projects[i].Path]
+		dj, jKnown := durations[projects[j].Path]
+		if iKnown != jKnown {
+			return !iKnown
+		}
+		return di > dj
+	})
+	return projects
+}
+
+// sortFailedFirst moves projects whose last run failed to the front, most
+// recent failure first, for faster feedback on what is likely still broken.
+// The remaining projects keep their order, so it composes with
+// sortSlowestFirst. The input slice is sorted in place.
+func sortFailedFirst(projects []*project.Project, lastFailed map[string]time.Time) []*project.Project {
+	sort.SliceStable(projects, func(i, j int) bool {
+		fi, iFailed := lastFailed[projects[i].Path]
+		fj, jFailed := lastFailed[projects[j].Path]
+		if iFailed != jFailed {
+			return iFailed
+		}
+		return fi.After(fj)
+	})
+	return projects
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestSortSlowestFirst(t *testing.T) {
+	fast := &project.Project{Name: "Fast", Path: "Fast/Fast.csproj"}
+	slow := &project.Project{Name: "Slow", Path: "Slow/Slow.csproj"}
+	medium := &project.Project{Name: "Medium", Path: "Medium/Medium.csproj"}
+	unknown := &project.Project{Name: "New", Path: "New/New.csproj"}
+
+	durations := map[string]time.Duration{
+		fast.Path:   2 * time.Second,
+		slow.Path:   90 * time.Second,
+		medium.Path: 30 * time.Second,
+	}
+
+	got := sortSlowestFirst([]*project.Project{fast, slow, unknown, medium}, durations)
+	want := []string{"New", "Slow", "Medium", "Fast"}
+	for i, p := range got {
+		if p.Name != want[i] {
+			t.Fatalf("order = %v, want %v", names(got), want)
+		}
+	}
+}
+
+func TestSortFailedFirst(t *testing.T) {
+	a := &project.Project{Name: "A", Path: "A/A.csproj"}
+	b := &project.Project{Name: "B", Path: "B/B.csproj"}
+	c := &project.Project{Name: "C", Path: "C/C.csproj"}
+	d := &project.Project{Name: "D", Path: "D/D.csproj"}
+
+	lastFailed := map[string]time.Time{
+		c.Path: time.Unix(1700000000, 0),
+		d.Path: time.Unix(1700086400, 0),
+	}
+
+	got := sortFailedFirst([]*project.Project{a, b, c, d}, lastFailed)
+	want := []string{"D", "C", "A", "B"}
+	for i, p := range got {
+		if p.Name != want[i] {
+			t.Fatalf("order = %v, want %v", names(got), want)
+		}
+	}
+}
+
+func names(projects []*project.Project) []string {
+	var result []string
+	for _, p := range projects {
+		result = append(result, p.Name)
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/git"
+)
+
+// resolveScope turns a --scope directory (absolute, or relative to cwd) into
+// a slash-separated path relative to gitRoot. The repository root itself
+// resolves to ".".
+func resolveScope(gitRoot, cwd, scope string) (string, error) {
+	if !filepath.IsAbs(scope) {
+		scope = filepath.Join(cwd, scope)
+	}
+	rel, err := filepath.Rel(gitRoot, scope)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid --scope %q: not inside the repository", scope)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// filterToScope returns the files (slash-separated, relative to the git root)
+// that are inside the scope directory.
+func filterToScope(files []string, scope string) []string {
+	if scope == "." {
+		return files
+	}
+	var inScope []string
+	for _, f := range files {
+		if f == scope || strings.HasPrefix(f, scope+"/") {
+			inScope = append(inScope, f)
+		}
+	}
+	return inScope
+}
+
+// filterChangesToScope returns the changes that touch the scope directory,
+// either through their current path or, for renames, their old one.
+func filterChangesToScope(changes []git.FileChange, scope string) []git.FileChange {
+	if scope == "." {
+		return changes
+	}
+	var inScope []git.FileChange
+	for _, c := range changes {
+		if len(filterToScope([]string{c.Path, c.OldPath}, scope)) > 0 {
+			inScope = append(inScope, c)
+		}
+	}
+	return inScope
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestResolveScope(t *testing.T) {
+	gitRoot := filepath.FromSlash("/repo")
+
+	tests := []struct {
+		cwd, scope, want string
+		wantErr          bool
+	}{
+		{"/repo", "services/payments", "services/payments", false},
+		{"/repo/services", "payments/", "services/payments", false},
+		{"/elsewhere", "/repo/services/payments", "services/payments", false},
+		{"/repo", ".", ".", false},
+		{"/repo", "../other", "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveScope(gitRoot, filepath.FromSlash(tt.cwd), filepath.FromSlash(tt.scope))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveScope(%q, %q) error = %v, wantErr %v", tt.cwd, tt.scope, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveScope(%q, %q) = %q, want %q", tt.cwd, tt.scope, got, tt.want)
+		}
+	}
+}
+
+func TestScopeIgnoresChangesOutsideScope(t *testing.T) {
+	shared := &project.Project{Name: "Shared", Path: "libs/Shared/Shared.csproj", Dir: "libs/Shared"}
+	payments := &project.Project{Name: "Payments", Path: "services/payments/Payments.csproj", Dir: "services/payments"}
+	paymentsTests := &project.Project{Name: "Payments.Tests", Path: "services/payments.tests/Payments.Tests.csproj", Dir: "services/payments.tests", IsTest: true}
+	orders := &project.Project{Name: "Orders", Path: "services/orders/Orders.csproj", Dir: "services/orders"}
+
+	r := New(&Options{Command: "build", Force: true})
+	r.projects = []*project.Project{shared, payments, paymentsTests, orders}
+	r.forwardGraph = map[string][]string{
+		payments.Path:      {shared.Path},
+		paymentsTests.Path: {payments.Path},
+		orders.Path:        {shared.Path},
+	}
+
+	changes := []git.FileChange{
+		{Status: git.ChangeModified, Path: "libs/Shared/Money.cs"},
+		{Status: git.ChangeModified, Path: "services/orders/Order.cs"},
+		{Status: git.ChangeModified, Path: "services/payments/Charge.cs"},
+		{Status: git.ChangeModified, Path: "services/paymentsfoo/Ignored.cs"},
+	}
+	changed := r.findChangedProjects("", filterChangesToScope(changes, "services/payments"), true)
+
+	if len(changed) != 2 || !changed[payments.Path] || !changed[paymentsTests.Path] {
+		t.Errorf("changed = %v, want only Payments and Payments.Tests", changed)
+	}
+}
@@ -0,0 +1,172 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// globalJSON is the part of global.json that pins the .NET SDK.
+type globalJSON struct {
+	SDK struct {
+		Version     string `json:"version"`
+		RollForward string `json:"rollForward"`
+	} `json:"sdk"`
+}
+
+// findGlobalJSON returns the path of the global.json dotnet would use when run
+// from dir: the nearest one in dir or a parent, up to and including gitRoot.
+// Returns "" if there is none.
+func findGlobalJSON(dir, gitRoot string) string {
+	for {
+		path := filepath.Join(dir, "global.json")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		if dir == gitRoot {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// readGlobalJSON parses the SDK pin of a global.json file.
+func readGlobalJSON(path string) (globalJSON, error) {
+	var g globalJSON
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return g, err
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		return g, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// dotnetSDKVersion returns the output of `dotnet --version`.
+func dotnetSDKVersion(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "dotnet", "--version")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseSDKVersion splits an SDK version like "8.0.204" or "9.0.100-preview.1"
+// into major, minor and patch. The pre-release suffix is ignored.
+func parseSDKVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	v, _, _ = strings.Cut(v, "-")
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// sdkSatisfies reports whether SDK version have is one dotnet could select for
+// a global.json pinning want with the given rollForward policy. The default
+// policy is latestPatch: same major, minor and feature band (hundreds digit
+// of the patch).
+func sdkSatisfies(want, have, rollForward string) bool {
+	w, ok := parseSDKVersion(want)
+	h, ok2 := parseSDKVersion(have)
+	if !ok || !ok2 {
+		return want == have
+	}
+	// dotnet never rolls back to an older SDK
+	for i := range w {
+		if h[i] != w[i] {
+			if h[i] < w[i] {
+				return false
+			}
+			break
+		}
+	}
+
+	switch strings.ToLower(rollForward) {
+	case "disable":
+		return h == w
+	case "major", "latestmajor":
+		return true
+	case "minor", "latestminor":
+		return h[0] == w[0]
+	case "feature", "latestfeature":
+		return h[0] == w[0] && h[1] == w[1]
+	default: // patch, latestPatch
+		return h[0] == w[0] && h[1] == w[1] && h[2]/100 == w[2]/100
+	}
+}
+
+// sdkMatchesPrefix reports whether have is the SDK version --sdk asks for:
+// either exactly, or a version starting with it ("8" and "8.0" match 8.0.204).
+func sdkMatchesPrefix(want, have string) bool {
+	return have == want || strings.HasPrefix(have, want+".")
+}
+
+// checkSDK compares the dotnet SDK on PATH against --sdk or, failing that,
+// the nearest global.json. A mismatch with --sdk is an error; a mismatch with
+// global.json is only a warning, returned as a non-empty string.
+func (r *Runner) checkSDK(ctx context.Context, cwd string) (string, error) {
+	var pin globalJSON
+	var pinPath string
+	if r.opts.SDK == "" {
+		pinPath = findGlobalJSON(cwd, r.gitRoot)
+		if pinPath == "" {
+			return "", nil
+		}
+		var err error
+		if pin, err = readGlobalJSON(pinPath); err != nil {
+			return fmt.Sprintf("could not check the .NET SDK version: %v", err), nil
+		}
+		if pin.SDK.Version == "" {
+			return "", nil
+		}
+	}
+
+	have, err := dotnetSDKVersion(ctx, cwd)
+	if err != nil {
+		if r.opts.SDK != "" {
+			return "", fmt.Errorf("--sdk=%s: running dotnet --version: %w", r.opts.SDK, err)
+		}
+		// dotnet fails outright when global.json pins an SDK that isn't
+		// installed; the build reports that itself
+		return "", nil
+	}
+
+	if r.opts.SDK != "" {
+		if !sdkMatchesPrefix(r.opts.SDK, have) {
+			return "", fmt.Errorf("--sdk=%s, but dotnet on PATH is SDK %s", r.opts.SDK, have)
+		}
+		return "", nil
+	}
+
+	if sdkSatisfies(pin.SDK.Version, have, pin.SDK.RollForward) {
+		return "", nil
+	}
+	rollForward := pin.SDK.RollForward
+	if rollForward == "" {
+		rollForward = "latestPatch"
+	}
+	relPath, _ := filepath.Rel(r.gitRoot, pinPath)
+	return fmt.Sprintf("dotnet on PATH is SDK %s, but %s pins %s (rollForward: %s); builds may differ from CI",
+		have, relPath, pin.SDK.Version, rollForward), nil
+}
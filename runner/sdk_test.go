@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSDKSatisfies(t *testing.T) {
+	tests := []struct {
+		want, have, rollForward string
+		ok                      bool
+	}{
+		{"8.0.100", "8.0.100", "", true},
+		{"8.0.100", "8.0.104", "", true},
+		{"8.0.100", "8.0.204", "", false}, // different feature band
+		{"8.0.100", "8.0.204", "latestFeature", true},
+		{"8.0.100", "9.0.100", "latestMinor", false},
+		{"8.0.100", "9.0.100", "latestMajor", true},
+		{"8.0.104", "8.0.100", "latestMajor", false}, // never rolls back
+		{"8.0.100", "8.0.101", "disable", false},
+		{"9.0.100-preview.1", "9.0.100", "", true},
+	}
+	for _, tt := range tests {
+		if got := sdkSatisfies(tt.want, tt.have, tt.rollForward); got != tt.ok {
+			t.Errorf("sdkSatisfies(%q, %q, %q) = %v, want %v", tt.want, tt.have, tt.rollForward, got, tt.ok)
+		}
+	}
+}
+
+func TestSDKMatchesPrefix(t *testing.T) {
+	if !sdkMatchesPrefix("8.0", "8.0.204") || !sdkMatchesPrefix("8.0.204", "8.0.204") {
+		t.Error("expected 8.0 and 8.0.204 to match 8.0.204")
+	}
+	if sdkMatchesPrefix("8.0.2", "8.0.204") || sdkMatchesPrefix("8", "9.0.100") {
+		t.Error("expected partial components and other majors not to match")
+	}
+}
+
+func TestFindGlobalJSON(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "src", "App")
+	os.MkdirAll(sub, 0755)
+
+	if got := findGlobalJSON(sub, root); got != "" {
+		t.Errorf("expected no global.json, got %s", got)
+	}
+
+	path := filepath.Join(root, "global.json")
+	os.WriteFile(path, []byte(`{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}`), 0644)
+	if got := findGlobalJSON(sub, root); got != path {
+		t.Fatalf("findGlobalJSON() = %q, want %q", got, path)
+	}
+	g, err := readGlobalJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.SDK.Version != "8.0.100" || g.SDK.RollForward != "latestFeature" {
+		t.Errorf("unexpected pin: %+v", g.SDK)
+	}
+}
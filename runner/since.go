@@ -0,0 +1,35 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// parseSince parses a --since value: either a duration relative to now
+// (e.g. "24h", "90m") or an RFC3339 timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid --since %q: duration must be positive", s)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: expected a duration (e.g. 24h) or RFC3339 time", s)
+}
+
+// staleSince returns the projects whose most recent successful run is older
+// than cutoff, or that have never run successfully.
+func staleSince(projects []*project.Project, lastSuccess map[string]time.Time, cutoff time.Time) []*project.Project {
+	var stale []*project.Project
+	for _, p := range projects {
+		if last, ok := lastSuccess[p.Path]; !ok || last.Before(cutoff) {
+			stale = append(stale, p)
+		}
+	}
+	return stale
+}
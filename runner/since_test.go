@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2025-05-01T00:00:00Z", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), false},
+		{"-1h", time.Time{}, true},
+		{"yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.input, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStaleSince(t *testing.T) {
+	cutoff := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	projects := []*project.Project{
+		{Name: "Fresh", Path: "fresh.csproj"},
+		{Name: "Old", Path: "old.csproj"},
+		{Name: "Never", Path: "never.csproj"},
+	}
+	lastSuccess := map[string]time.Time{
+		"fresh.csproj": cutoff.Add(time.Hour),
+		"old.csproj":   cutoff.Add(-time.Hour),
+	}
+
+	stale := staleSince(projects, lastSuccess, cutoff)
+	if len(stale) != 2 || stale[0].Name != "Old" || stale[1].Name != "Never" {
+		var names []string
+		for _, p := range stale {
+			names = append(names, p.Name)
+		}
+		t.Errorf("staleSince() = %v, want [Old Never]", names)
+	}
+}
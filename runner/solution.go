@@ -17,7 +17,7 @@ import (
 
 // runSolutionCommand runs a dotnet command on the entire solution instead of individual projects.
 // This avoids parallel build conflicts when projects share dependencies.
-func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution, projects, cached []*project.Project, argsHash, argsForCache string) bool {
+func (r *Runner) runSolutionCommand(ctx context.Context, hasher *ContentHasher, sln *project.Solution, projects, cached []*project.Project, argsHash, argsForCache string) bool {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -43,12 +43,24 @@ func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution,
 	}
 
 	// Build command args
-	slnPath := filepath.Join(r.gitRoot, sln.RelPath)
+	slnPath := r.solutionBuildPath(sln, projects)
 	args := []string{r.opts.Command, slnPath, "--property:WarningLevel=0", "-clp:ErrorsOnly"}
 	if r.opts.Coverage && r.opts.Command == "test" {
 		args = append(args, "--collect:XPlat Code Coverage")
 	}
+	if r.opts.TestHangTimeout > 0 && r.opts.Command == "test" {
+		args = append(args, blameHangArgs(r.opts.TestHangTimeout)...)
+	}
 	args = append(args, r.opts.DotnetArgs...)
+	if r.opts.Command == "test" {
+		args = r.withExcludedTraits(args, projects...)
+	}
+
+	if r.opts.DryRun {
+		term.Printf("%s\n", dryRunSolutionComment(sln, projects))
+		term.Printf("dotnet %s\n", term.ShellQuoteArgs(args))
+		return true
+	}
 
 	cmd := exec.CommandContext(ctx, "dotnet", args...)
 	setupProcessGroup(cmd)
@@ -79,6 +91,9 @@ func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution,
 		if outputStr == "" {
 			outputStr = fmt.Sprintf("Command failed: %v\n", err)
 		}
+		if r.opts.TestHangTimeout > 0 && r.opts.Command == "test" {
+			outputStr += findProjectsHangReport(r.gitRoot, projects, startTime)
+		}
 	}
 
 	// Save console output if reports enabled
@@ -87,6 +102,8 @@ func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution,
 		os.WriteFile(consolePath, []byte(outputStr), 0644)
 	}
 
+	r.addResult(solutionResult(sln, success, outputStr, duration))
+
 	stats := extractTestStats(outputStr)
 
 	if !r.opts.Quiet {
@@ -109,7 +126,7 @@ func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution,
 	// Mark cache for all projects in the solution
 	now := time.Now()
 	for _, p := range projects {
-		key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, argsHash)
+		key := hasher.ProjectCacheKey(p, r.forwardGraph, argsHash)
 		r.db.Mark(key, now, success, nil, argsForCache)
 	}
 
@@ -125,7 +142,7 @@ func (r *Runner) runSolutionCommand(ctx context.Context, sln *project.Solution,
 }
 
 // runSolutionGroups runs multiple solution builds in parallel, then runs remaining projects.
-func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.Solution][]*project.Project, remaining []*project.Project, cached []*project.Project, argsHash, argsForCache string) bool {
+func (r *Runner) runSolutionGroups(ctx context.Context, hasher *ContentHasher, slnGroups map[*project.Solution][]*project.Project, remaining []*project.Project, cached []*project.Project, argsHash, argsForCache string) bool {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -166,6 +183,7 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 		success  bool
 		output   string
 		duration time.Duration
+		dryRun   bool
 	}
 
 	slnResults := make(chan slnResult, len(slnGroups))
@@ -196,12 +214,29 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 				}
 
 				slnStart := time.Now()
-				slnPath := filepath.Join(r.gitRoot, job.sln.RelPath)
+				slnPath := r.solutionBuildPath(job.sln, job.projs)
 				args := []string{r.opts.Command, slnPath, "--property:WarningLevel=0", "-clp:ErrorsOnly"}
 				if r.opts.Coverage && r.opts.Command == "test" {
 					args = append(args, "--collect:XPlat Code Coverage")
 				}
+				if r.opts.TestHangTimeout > 0 && r.opts.Command == "test" {
+					args = append(args, blameHangArgs(r.opts.TestHangTimeout)...)
+				}
 				args = append(args, r.opts.DotnetArgs...)
+				if r.opts.Command == "test" {
+					args = r.withExcludedTraits(args, job.projs...)
+				}
+
+				if r.opts.DryRun {
+					slnResults <- slnResult{
+						sln:      job.sln,
+						projects: job.projs,
+						success:  true,
+						output:   dryRunSolutionComment(job.sln, job.projs) + "\n" + "dotnet " + term.ShellQuoteArgs(args),
+						dryRun:   true,
+					}
+					continue
+				}
 
 				cmd := exec.CommandContext(ctx, "dotnet", args...)
 				setupProcessGroup(cmd)
@@ -223,11 +258,16 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 				term.Verbose("  dotnet %s", term.ShellQuoteArgs(args))
 				err := cmd.Run()
 
+				outputStr := output.String()
+				if err != nil && r.opts.TestHangTimeout > 0 && r.opts.Command == "test" {
+					outputStr += findProjectsHangReport(r.gitRoot, job.projs, slnStart)
+				}
+
 				slnResults <- slnResult{
 					sln:      job.sln,
 					projects: job.projs,
 					success:  err == nil,
-					output:   output.String(),
+					output:   outputStr,
 					duration: time.Since(slnStart),
 				}
 			}
@@ -251,13 +291,26 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 	slnSucceeded := 0
 	slnFailed := 0
 	var failedOutputs []string
+	var failedSolutions []runResult
 
 	for res := range slnResults {
+		if res.dryRun {
+			term.Printf("%s\n", res.output)
+			slnSucceeded += len(res.projects)
+			continue
+		}
+
 		if !r.opts.NoReports {
 			consolePath := filepath.Join(r.reportsDir, filepath.Base(res.sln.RelPath)+".log")
 			os.WriteFile(consolePath, []byte(res.output), 0644)
 		}
 
+		slnResult := solutionResult(res.sln, res.success, res.output, res.duration)
+		r.addResult(slnResult)
+		if !res.success {
+			failedSolutions = append(failedSolutions, slnResult)
+		}
+
 		stats := extractTestStats(res.output)
 
 		if !r.opts.Quiet {
@@ -280,7 +333,7 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 
 		now := time.Now()
 		for _, p := range res.projects {
-			key := ProjectCacheKey(p, r.gitRoot, r.forwardGraph, argsHash)
+			key := hasher.ProjectCacheKey(p, r.forwardGraph, argsHash)
 			r.db.Mark(key, now, res.success, nil, argsForCache)
 		}
 
@@ -309,7 +362,7 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 		if !r.opts.Quiet {
 			term.Printf("\nRunning %d individual projects...\n", len(remaining))
 		}
-		projSuccess := r.runProjects(ctx, remaining, nil, argsHash)
+		projSuccess := r.runProjects(ctx, hasher, remaining, nil, argsHash)
 		if !projSuccess {
 			return false
 		}
@@ -325,7 +378,30 @@ func (r *Runner) runSolutionGroups(ctx context.Context, slnGroups map[*project.S
 
 	if !r.opts.Quiet && len(remaining) == 0 {
 		term.Summary(slnSucceeded, totalProjects, len(cached), time.Since(startTime).Round(time.Millisecond), slnFailed == 0)
+		printFailureSummary(failedSolutions)
 	}
 
 	return slnFailed == 0
 }
+
+// solutionResult wraps a solution-level run as a runResult for reporting.
+// The solution is represented by a pseudo-project named after the .sln file.
+func solutionResult(sln *project.Solution, success bool, output string, duration time.Duration) runResult {
+	return runResult{
+		project:     &project.Project{Path: sln.RelPath, Name: filepath.Base(sln.RelPath)},
+		success:     success,
+		output:      output,
+		duration:    duration,
+		viaSolution: true,
+	}
+}
+
+// dryRunSolutionComment names the projects a solution-level dry-run command
+// covers, as a shell comment so the printed commands stay runnable.
+func dryRunSolutionComment(sln *project.Solution, projects []*project.Project) string {
+	names := make([]string, len(projects))
+	for i, p := range projects {
+		names[i] = p.Name
+	}
+	return fmt.Sprintf("# %s batches %d project(s): %s", sln.RelPath, len(projects), strings.Join(names, ", "))
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/project"
+	"github.com/runar-rkmedia/donotnet/term"
+)
+
+// solutionBuildPath returns the path to pass to dotnet to run projects through
+// sln. With --solution-filter, when projects (plus their dependencies within
+// sln) are only part of it, that is a generated .slnf limited to them, so
+// shared dependencies are still built once without building the whole
+// solution. Otherwise, or if the filter can't be written, it is sln itself.
+func (r *Runner) solutionBuildPath(sln *project.Solution, projects []*project.Project) string {
+	slnPath := filepath.Join(r.gitRoot, sln.RelPath)
+	if !r.opts.SolutionFilter {
+		return slnPath
+	}
+
+	included := solutionClosure(sln, projects, r.forwardGraph, r.gitRoot)
+	if len(included) >= len(sln.Projects) {
+		return slnPath
+	}
+
+	sort.Strings(included)
+	sum := sha256.Sum256([]byte(strings.Join(included, "\n")))
+	name := strings.TrimSuffix(filepath.Base(sln.RelPath), filepath.Ext(sln.RelPath)) + "-" + hex.EncodeToString(sum[:])[:8] + ".slnf"
+	filterPath := filepath.Join(r.cacheDir, "slnf", name)
+	// A dry run only prints the path the filter would be written to
+	if r.opts.DryRun {
+		return filterPath
+	}
+	if err := project.WriteSolutionFilter(filterPath, sln, included); err != nil {
+		term.Warnf("failed to write solution filter for %s, using the whole solution: %v", sln.RelPath, err)
+		return slnPath
+	}
+	return filterPath
+}
+
+// solutionClosure returns the absolute paths of projects and their transitive
+// dependencies (through forwardGraph, keyed by paths relative to gitRoot) that
+// are part of sln.
+func solutionClosure(sln *project.Solution, projects []*project.Project, forwardGraph map[string][]string, gitRoot string) []string {
+	seen := make(map[string]bool)
+	var included []string
+	var visit func(relPath string)
+	visit = func(relPath string) {
+		if seen[relPath] {
+			return
+		}
+		seen[relPath] = true
+		if abs := filepath.Join(gitRoot, relPath); sln.Projects[abs] {
+			included = append(included, abs)
+		}
+		for _, dep := range forwardGraph[relPath] {
+			visit(dep)
+		}
+	}
+	for _, p := range projects {
+		visit(p.Path)
+	}
+	return included
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestSolutionClosure(t *testing.T) {
+	root := "/repo"
+	abs := func(rel string) string { return filepath.Join(root, rel) }
+
+	sln := &project.Solution{
+		RelPath: "App.sln",
+		Projects: map[string]bool{
+			abs("src/Core/Core.csproj"):             true,
+			abs("src/Api/Api.csproj"):               true,
+			abs("src/Web/Web.csproj"):               true,
+			abs("tests/Api.Tests/Api.Tests.csproj"): true,
+		},
+	}
+	forward := map[string][]string{
+		"tests/Api.Tests/Api.Tests.csproj": {"src/Api/Api.csproj"},
+		"src/Api/Api.csproj":               {"shared/Util/Util.csproj"},
+		"shared/Util/Util.csproj":          {"src/Core/Core.csproj"},
+		"src/Web/Web.csproj":               {"src/Core/Core.csproj"},
+	}
+	apiTests := &project.Project{Name: "Api.Tests", Path: "tests/Api.Tests/Api.Tests.csproj"}
+
+	// Dependencies outside the solution are followed, but not included
+	got := solutionClosure(sln, []*project.Project{apiTests}, forward, root)
+	sort.Strings(got)
+	want := []string{abs("src/Api/Api.csproj"), abs("src/Core/Core.csproj"), abs("tests/Api.Tests/Api.Tests.csproj")}
+	sort.Strings(want)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("closure = %v, want %v", got, want)
+	}
+}
+
+func TestSolutionBuildPathDryRun(t *testing.T) {
+	root := t.TempDir()
+	sln := &project.Solution{
+		RelPath: "App.sln",
+		Projects: map[string]bool{
+			filepath.Join(root, "src/Api/Api.csproj"): true,
+			filepath.Join(root, "src/Web/Web.csproj"): true,
+		},
+	}
+	api := &project.Project{Name: "Api", Path: "src/Api/Api.csproj"}
+
+	r := New(&Options{SolutionFilter: true, DryRun: true})
+	r.gitRoot = root
+	r.cacheDir = filepath.Join(root, ".donotnet")
+
+	got := r.solutionBuildPath(sln, []*project.Project{api})
+	if filepath.Dir(got) != filepath.Join(r.cacheDir, "slnf") || filepath.Ext(got) != ".slnf" {
+		t.Errorf("solutionBuildPath() = %q, want a .slnf below the cache dir", got)
+	}
+	if _, err := os.Stat(r.cacheDir); !os.IsNotExist(err) {
+		t.Errorf("a dry run should not write the solution filter, stat err = %v", err)
+	}
+}
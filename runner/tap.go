@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/term"
+	"github.com/runar-rkmedia/donotnet/testresults"
+)
+
+// FormatTAP prints every test result of the run as TAP version 13 on stdout.
+const FormatTAP = "tap"
+
+// tapProject holds the parsed TRX results of one project that was run.
+type tapProject struct {
+	name    string
+	success bool
+	// results is nil when the project produced no (fresh) TRX file,
+	// e.g. because it failed to build.
+	results []testresults.TestResult
+}
+
+// writeTAP prints the results of the projects run since runStart as TAP, when
+// --format=tap is set. Results are read from the TRX files in the reports dir;
+// files older than runStart are left over from an earlier run and ignored.
+func (r *Runner) writeTAP(runStart time.Time) {
+	if r.opts.Format != FormatTAP {
+		return
+	}
+
+	var projects []tapProject
+	for _, res := range r.results {
+		if res.buildOnly || res.dryRun || res.skippedByFilter {
+			continue
+		}
+		tp := tapProject{name: res.project.Name, success: res.success}
+		trxPath := filepath.Join(r.reportsDir, res.project.Name+".trx")
+		if info, err := os.Stat(trxPath); err == nil && !info.ModTime().Before(runStart) {
+			results, err := testresults.ParseTRXResultsFile(trxPath)
+			if err != nil {
+				term.Verbose("  [%s] TRX parse error: %v", res.project.Name, err)
+			} else {
+				tp.results = results
+			}
+		}
+		projects = append(projects, tp)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].name < projects[j].name })
+
+	writeTAPDocument(os.Stdout, projects)
+}
+
+// writeTAPDocument writes the TAP version 13 document for projects to w: one
+// test point per test, numbered across all projects, with a YAML block for
+// each failure and the plan line last. A failed project without results gets
+// a single failing test point of its own.
+func writeTAPDocument(w io.Writer, projects []tapProject) {
+	fmt.Fprintln(w, "TAP version 13")
+	n := 0
+	for _, p := range projects {
+		if p.results == nil {
+			if !p.success {
+				n++
+				fmt.Fprintf(w, "not ok %d - %s\n", n, tapEscape(p.name))
+				writeTAPDiagnostic(w, p.name, 0, "no test results (build or test host failed)", "")
+			}
+			continue
+		}
+		for _, res := range p.results {
+			n++
+			name := tapEscape(tapTestName(res))
+			switch res.Outcome {
+			case testresults.OutcomePassed:
+				fmt.Fprintf(w, "ok %d - %s\n", n, name)
+			case testresults.OutcomeFailed:
+				fmt.Fprintf(w, "not ok %d - %s\n", n, name)
+				writeTAPDiagnostic(w, p.name, res.Duration, res.Message, res.StackTrace)
+			default:
+				fmt.Fprintf(w, "ok %d - %s # SKIP %s\n", n, name, res.Outcome)
+			}
+		}
+	}
+	fmt.Fprintf(w, "1..%d\n", n)
+}
+
+// writeTAPDiagnostic writes the YAML block that follows a failing test point.
+func writeTAPDiagnostic(w io.Writer, projectName string, duration time.Duration, message, stack string) {
+	fmt.Fprintln(w, "  ---")
+	fmt.Fprintf(w, "  project: %q\n", projectName)
+	if duration > 0 {
+		fmt.Fprintf(w, "  duration_ms: %d\n", duration.Milliseconds())
+	}
+	writeTAPBlock(w, "message", message)
+	writeTAPBlock(w, "stack", stack)
+	fmt.Fprintln(w, "  ...")
+}
+
+// writeTAPBlock writes value as a YAML literal block under key, if non-empty.
+func writeTAPBlock(w io.Writer, key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(w, "  %s: |\n", key)
+	for _, line := range strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+}
+
+// tapTestName is the fully qualified name of a test, keeping the parameters of
+// a parameterized test from its display name so each case stays distinct.
+func tapTestName(res testresults.TestResult) string {
+	if idx := strings.Index(res.DisplayName, "("); idx > 0 {
+		return res.FullyQualifiedName + res.DisplayName[idx:]
+	}
+	return res.FullyQualifiedName
+}
+
+// tapEscape escapes '#' so a test name is not read as a TAP directive.
+func tapEscape(s string) string {
+	return strings.ReplaceAll(s, "#", `\#`)
+}
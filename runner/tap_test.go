@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/testresults"
+)
+
+func TestWriteTAPDocument(t *testing.T) {
+	projects := []tapProject{
+		{name: "Api.Tests", success: false, results: []testresults.TestResult{
+			{FullyQualifiedName: "Api.Tests.UserTests.Create", DisplayName: "Create", Outcome: testresults.OutcomePassed},
+			{
+				FullyQualifiedName: "Api.Tests.UserTests.Delete",
+				DisplayName:        "Api.Tests.UserTests.Delete(id: 1)",
+				Outcome:            testresults.OutcomeFailed,
+				Duration:           42 * time.Millisecond,
+				Message:            "Expected: 1\nActual:   2",
+				StackTrace:         "at UserTests.Delete() in UserTests.cs:line 7",
+			},
+			{FullyQualifiedName: "Api.Tests.UserTests.Issue#12", DisplayName: "Issue#12", Outcome: testresults.OutcomeNotExecuted},
+		}},
+		{name: "Broken.Tests", success: false},
+		{name: "Empty.Tests", success: true},
+	}
+
+	var buf bytes.Buffer
+	writeTAPDocument(&buf, projects)
+
+	want := `TAP version 13
+ok 1 - Api.Tests.UserTests.Create
+not ok 2 - Api.Tests.UserTests.Delete(id: 1)
+  ---
+  project: "Api.Tests"
+  duration_ms: 42
+  message: |
+    Expected: 1
+    Actual:   2
+  stack: |
+    at UserTests.Delete() in UserTests.cs:line 7
+  ...
+ok 3 - Api.Tests.UserTests.Issue\#12 # SKIP NotExecuted
+not ok 4 - Broken.Tests
+  ---
+  project: "Broken.Tests"
+  message: |
+    no test results (build or test host failed)
+  ...
+1..4
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeTAPDocument() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteTAPDocumentEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writeTAPDocument(&buf, nil)
+	if got, want := buf.String(), "TAP version 13\n1..0\n"; got != want {
+		t.Errorf("writeTAPDocument(nil) = %q, want %q", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/coverage"
 	"github.com/runar-rkmedia/donotnet/project"
 )
 
@@ -16,7 +17,10 @@ type testListCacheImpl struct {
 	argsHash     string
 }
 
-func newTestListCache(db *cache.DB, gitRoot string, forwardGraph map[string][]string) *testListCacheImpl {
+// NewTestListCache returns a cache of discovered test names. Entries are keyed
+// like run results, so a project's list is reused until its content hash
+// changes.
+func NewTestListCache(db *cache.DB, gitRoot string, forwardGraph map[string][]string) coverage.TestListCache {
 	return &testListCacheImpl{
 		db:           db,
 		gitRoot:      gitRoot,
@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/git"
+)
+
+// vcsChangePaths returns the paths whose owning projects a set of VCS changes
+// invalidates. A rename counts against both its old and its new location.
+// Removed files inside a project whose .csproj was removed too are dropped:
+// that project is gone, and they would otherwise mark a project in a parent
+// directory as changed.
+func vcsChangePaths(changes []git.FileChange) []string {
+	var removedProjectDirs []string
+	for _, c := range changes {
+		if removed := removedPath(c); strings.HasSuffix(removed, ".csproj") {
+			removedProjectDirs = append(removedProjectDirs, path.Dir(removed))
+		}
+	}
+	inRemovedProject := func(file string) bool {
+		for _, dir := range removedProjectDirs {
+			if dir == "." || strings.HasPrefix(file, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var files []string
+	for _, c := range changes {
+		if c.Status != git.ChangeDeleted {
+			files = append(files, c.Path)
+		}
+		if removed := removedPath(c); removed != "" && !inRemovedProject(removed) {
+			files = append(files, removed)
+		}
+	}
+	return files
+}
+
+// removedPath returns the path a change removes: the file itself for a
+// deletion, the old path for a rename, and "" otherwise.
+func removedPath(c git.FileChange) string {
+	switch c.Status {
+	case git.ChangeDeleted:
+		return c.Path
+	case git.ChangeRenamed:
+		return c.OldPath
+	}
+	return ""
+}
+
+// suppliedChanges turns a --changed-files list (paths relative to root) into
+// changes: files that no longer exist count as deleted, the rest as modified.
+func suppliedChanges(root string, paths []string) []git.FileChange {
+	changes := make([]git.FileChange, 0, len(paths))
+	for _, p := range paths {
+		c := git.FileChange{Status: git.ChangeModified, Path: p}
+		if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(p))); os.IsNotExist(err) {
+			c.Status = git.ChangeDeleted
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
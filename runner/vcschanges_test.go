@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestVcsChangePaths(t *testing.T) {
+	changes := []git.FileChange{
+		{Status: git.ChangeModified, Path: "src/App/Program.cs"},
+		{Status: git.ChangeRenamed, Path: "src/Other/Name.cs", OldPath: "src/Lib/Name.cs"},
+		{Status: git.ChangeDeleted, Path: "src/Lib/Gone.cs"},
+		// A whole project removed from under src/
+		{Status: git.ChangeDeleted, Path: "src/Old/Old.csproj"},
+		{Status: git.ChangeDeleted, Path: "src/Old/Thing.cs"},
+	}
+
+	want := []string{
+		"src/App/Program.cs",
+		"src/Other/Name.cs",
+		"src/Lib/Name.cs",
+		"src/Lib/Gone.cs",
+	}
+	if got := vcsChangePaths(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("vcsChangePaths() = %v, want %v", got, want)
+	}
+}
+
+func TestSuppliedChanges(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "src", "App"), 0755)
+	os.WriteFile(filepath.Join(root, "src", "App", "Program.cs"), nil, 0644)
+
+	got := suppliedChanges(root, []string{"src/App/Program.cs", "src/Lib/Gone.cs"})
+	want := []git.FileChange{
+		{Status: git.ChangeModified, Path: "src/App/Program.cs"},
+		{Status: git.ChangeDeleted, Path: "src/Lib/Gone.cs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suppliedChanges() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFindChangedProjectsRenameAndRemovedProject(t *testing.T) {
+	root := &project.Project{Name: "Root", Path: "Root.csproj", Dir: "."}
+	lib := &project.Project{Name: "Lib", Path: "src/Lib/Lib.csproj", Dir: "src/Lib"}
+	other := &project.Project{Name: "Other", Path: "src/Other/Other.csproj", Dir: "src/Other"}
+	app := &project.Project{Name: "App", Path: "src/App/App.csproj", Dir: "src/App"}
+
+	r := New(&Options{Command: "build", Force: true})
+	r.forwardGraph = map[string][]string{}
+
+	// A file moved from Lib to Other invalidates both
+	r.projects = []*project.Project{lib, other, app}
+	changed := r.findChangedProjects("", []git.FileChange{
+		{Status: git.ChangeRenamed, Path: "src/Other/Name.cs", OldPath: "src/Lib/Name.cs"},
+	}, true)
+	if len(changed) != 2 || !changed[lib.Path] || !changed[other.Path] {
+		t.Errorf("changed = %v, want Lib and Other", changed)
+	}
+
+	// Deleting the Old project doesn't mark the project above it changed
+	r.projects = []*project.Project{root, app}
+	changed = r.findChangedProjects("", []git.FileChange{
+		{Status: git.ChangeDeleted, Path: "src/Old/Old.csproj"},
+		{Status: git.ChangeDeleted, Path: "src/Old/Thing.cs"},
+	}, true)
+	if len(changed) != 0 {
+		t.Errorf("changed = %v, want none", changed)
+	}
+}
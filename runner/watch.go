@@ -2,12 +2,15 @@ package runner
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -39,13 +42,16 @@ var ignoredExtensions = map[string]bool{
 	".swo":  true,
 }
 
+// defaultWatchDebounce is used when no debounce interval is configured.
+const defaultWatchDebounce = 100 * time.Millisecond
+
 // runWatch sets up file watchers and re-runs on file changes.
 // The initial run is handled by Run() before calling this.
 func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsHash string) error {
 	// Build coverage map for test project selection
 	var covMap *coverage.Map
 	if r.opts.Command == "test" {
-		covMap = buildCoverageMap(r.gitRoot, r.projects)
+		covMap = buildCoverageMap(r.gitRoot, r.reportsDir, r.projects)
 		if covMap != nil {
 			term.Verbose("Coverage map: %d test projects with coverage, %d files mapped",
 				len(covMap.TestProjectToFiles), len(covMap.FileToTestProjects))
@@ -68,6 +74,7 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 	tf := testfilter.NewTestFilter()
 	tf.SetCoverageMaps(testCovMaps)
 	tf.SetHeuristics(testfilter.ParseHeuristics(r.opts.Heuristics))
+	tf.SetHeuristicsResolver(r.heuristicsResolver)
 
 	// Set up fsnotify watcher
 	watcher, err := fsnotify.NewWatcher()
@@ -119,11 +126,48 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 	defer signal.Stop(sigChan)
 
 	// Debounce state
+	debounce := r.opts.WatchDebounce
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+	term.Verbose("Watch debounce: %s", debounce)
 	var debounceTimer *time.Timer
 	pendingChanges := make(map[string]bool)
 	pendingFiles := make(map[string]struct{})
+	pendingEvents := 0
 	var pendingMu sync.Mutex
 
+	// Optionally rebuild stale per-test coverage in the background, between runs
+	var runInProgress atomic.Bool
+	var rebuilder *coverageRebuilder
+	if r.opts.Command == "test" && r.opts.CoverageAutoRebuild {
+		rebuilder = newCoverageRebuilder(ctx, coverageRebuildDelay, runInProgress.Load,
+			func(ctx context.Context, projects []*project.Project) { r.rebuildCoverage(ctx, projects) },
+			func() {
+				maps := loadAllTestCoverageMaps(r.cacheDir)
+				pendingMu.Lock()
+				testCovMaps = maps
+				pendingMu.Unlock()
+			})
+		defer rebuilder.stop()
+	}
+	// Optionally run a maintenance command once things are idle and green
+	var idle *idleRunner
+	if r.opts.OnIdle != "" {
+		delay := r.opts.OnIdleAfter
+		if delay <= 0 {
+			delay = defaultIdleDelay
+		}
+		idle = newIdleRunner(delay, r.runIdleCommand)
+		defer idle.stop()
+	}
+	hasTestCoverageMap := func(p *project.Project) bool {
+		pendingMu.Lock()
+		defer pendingMu.Unlock()
+		_, ok := testCovMaps[p.Name]
+		return ok
+	}
+
 	// applyOverridesAndRun applies user overrides to the target list, runs the
 	// projects, and updates last-run state. The caller provides the base targets
 	// and an optional test filter (nil means no per-file filtering).
@@ -185,7 +229,27 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 
 		r.opts.TestFilter = filter
 		term.Println()
-		lastSuccess = r.runProjects(ctx, runTargets, nil, argsHash)
+		if idle != nil {
+			idle.activity()
+		}
+		runInProgress.Store(true)
+		// Don't let a background coverage rebuild fight this run over bin/obj
+		if rebuilder != nil {
+			rebuilder.interrupt()
+		}
+		// Each batch reports only its own results (and doesn't keep the
+		// output of every earlier batch alive)
+		r.results = nil
+		batchStart := time.Now()
+		// Files changed since the last run, so hash them afresh
+		hasher := NewContentHasher(r.gitRoot, r.opts.HashMode)
+		lastSuccess = r.runProjects(ctx, hasher, runTargets, nil, argsHash)
+		r.notify(r.results, 0, time.Since(batchStart))
+		r.publishSummary(r.results, 0, time.Since(batchStart))
+		runInProgress.Store(false)
+		if idle != nil && lastSuccess {
+			idle.green()
+		}
 		lastTargets = runTargets
 		r.opts.DotnetArgs = savedArgs
 
@@ -210,14 +274,17 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 		for f := range pendingFiles {
 			changedFiles = append(changedFiles, f)
 		}
+		coalescedEvents := pendingEvents
 		pendingChanges = make(map[string]bool)
 		pendingFiles = make(map[string]struct{})
+		pendingEvents = 0
 
 		// Take the current test filter and set up a fresh one for the next batch
 		currentFilter := tf
 		tf = testfilter.NewTestFilter()
 		tf.SetCoverageMaps(testCovMaps)
 		tf.SetHeuristics(testfilter.ParseHeuristics(r.opts.Heuristics))
+		tf.SetHeuristicsResolver(r.heuristicsResolver)
 		pendingMu.Unlock()
 
 		// Determine target test projects
@@ -268,11 +335,37 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 			}
 		}
 
+		if len(r.excludePatterns) > 0 {
+			var kept []*project.Project
+			for _, p := range watchTargets {
+				if !project.IsExcluded(p, r.excludePatterns) {
+					kept = append(kept, p)
+				}
+			}
+			watchTargets = kept
+		}
+
+		if r.opts.WatchClear {
+			term.ClearScreen()
+			term.Info("%s", watchBatchHeader(changedFiles, time.Now()))
+		}
+
+		var targetNames []string
+		for _, p := range watchTargets {
+			targetNames = append(targetNames, p.Name)
+		}
+		term.Dim("%d event(s) on %d file(s) coalesced, selected %d project(s): %s",
+			coalescedEvents, len(changedFiles), len(watchTargets), strings.Join(targetNames, ", "))
+
 		if len(watchTargets) == 0 {
 			return
 		}
 
 		applyOverridesAndRun(watchTargets, currentFilter)
+
+		if rebuilder != nil {
+			rebuilder.schedule(r.staleCoverageProjects(watchTargets, changedFiles, hasTestCoverageMap))
+		}
 	}
 
 	for {
@@ -329,7 +422,7 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 				// Build failed test filters from TRX/output
 				failedFilters := make(map[string]string)
 				for _, p := range lastTargets {
-					filter := getFailedTestFilter(nil, r.reportsDir, p.Name)
+					filter := r.failedTestFilter(p, nil)
 					if filter != "" {
 						failedFilters[p.Path] = filter
 					}
@@ -408,51 +501,70 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 				return nil
 			}
 
-			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+			// Rename covers editors that save via atomic rename (write temp, rename over)
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
 				continue
 			}
 
-			ext := strings.ToLower(filepath.Ext(event.Name))
-			if ignoredExtensions[ext] {
-				continue
+			// A directory created during the session (e.g. a new feature
+			// folder) isn't watched yet: add it, and treat the files already
+			// written into it as changed
+			paths := []string{event.Name}
+			if event.Op&fsnotify.Create != 0 {
+				if info, statErr := os.Stat(event.Name); statErr == nil && info.IsDir() {
+					if rel, relErr := filepath.Rel(r.gitRoot, event.Name); relErr != nil || containsSkipDir(rel) {
+						continue
+					}
+					paths = watchNewDir(watcher, event.Name, watchedDirs)
+				}
 			}
 
-			relPath, relErr := filepath.Rel(r.gitRoot, event.Name)
-			if relErr != nil {
-				continue
-			}
+			scheduled := false
+			for _, path := range paths {
+				ext := strings.ToLower(filepath.Ext(path))
+				if ignoredExtensions[ext] {
+					continue
+				}
 
-			// Skip events whose path contains a directory we never care about
-			// (e.g. node_modules, bin, obj). The watcher doesn't recurse into
-			// these, but the parent directory still receives events for them.
-			if containsSkipDir(relPath) {
-				continue
-			}
+				relPath, relErr := filepath.Rel(r.gitRoot, path)
+				if relErr != nil {
+					continue
+				}
 
-			var affectedProject *project.Project
-			for _, p := range r.projects {
-				if strings.HasPrefix(relPath, p.Dir+"/") || strings.HasPrefix(relPath, p.Dir+string(os.PathSeparator)) {
-					affectedProject = p
-					break
+				// Skip events whose path contains a directory we never care about
+				// (e.g. node_modules, bin, obj). The watcher doesn't recurse into
+				// these, but the parent directory still receives events for them.
+				if containsSkipDir(relPath) {
+					continue
 				}
-			}
 
-			if affectedProject == nil {
+				affectedProject := owningProject(r.projects, relPath)
+				if affectedProject == nil {
+					continue
+				}
+
+				term.Verbose("  changed: %s (%s)", relPath, affectedProject.Name)
+
+				pendingMu.Lock()
+				pendingChanges[affectedProject.Path] = true
+				pendingFiles[relPath] = struct{}{}
+				pendingEvents++
+				tf.AddChangedFile(affectedProject.Path, relPath)
+				pendingMu.Unlock()
+				scheduled = true
+			}
+			if !scheduled {
 				continue
 			}
 
-			term.Verbose("  changed: %s (%s)", relPath, affectedProject.Name)
-
-			pendingMu.Lock()
-			pendingChanges[affectedProject.Path] = true
-			pendingFiles[relPath] = struct{}{}
-			tf.AddChangedFile(affectedProject.Path, relPath)
-			pendingMu.Unlock()
+			if idle != nil {
+				idle.activity()
+			}
 
 			if debounceTimer != nil {
 				debounceTimer.Stop()
 			}
-			debounceTimer = time.AfterFunc(100*time.Millisecond, runFromFileChanges)
+			debounceTimer = time.AfterFunc(debounce, runFromFileChanges)
 
 		case watchErr, ok := <-watcher.Errors:
 			if !ok {
@@ -467,13 +579,14 @@ func (r *Runner) runWatch(ctx context.Context, targets []*project.Project, argsH
 }
 
 // buildCoverageMap builds a coverage.Map from the project list.
-func buildCoverageMap(gitRoot string, projects []*project.Project) *coverage.Map {
+func buildCoverageMap(gitRoot, reportsDir string, projects []*project.Project) *coverage.Map {
 	var testProjects []coverage.TestProject
 	for _, p := range projects {
 		if p.IsTest {
 			testProjects = append(testProjects, coverage.TestProject{
-				Path: p.Path,
-				Dir:  p.Dir,
+				Path:       p.Path,
+				Dir:        p.Dir,
+				ResultsDir: coverage.RunResultsDir(reportsDir, p.Path),
 			})
 		}
 	}
@@ -515,6 +628,27 @@ func loadAllTestCoverageMaps(cacheDir string) map[string]*testfilter.TestCoverag
 	return result
 }
 
+// watchBatchMaxFiles caps how many changed files the --watch-clear header lists.
+const watchBatchMaxFiles = 10
+
+// watchBatchHeader describes a rerun for --watch-clear: the time and the
+// changed files (sorted, capped at watchBatchMaxFiles).
+func watchBatchHeader(files []string, now time.Time) string {
+	sorted := append([]string{}, files...)
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %d file(s) changed", now.Format("15:04:05"), len(sorted))
+	for i, f := range sorted {
+		if i == watchBatchMaxFiles {
+			fmt.Fprintf(&sb, "\n  ... and %d more", len(sorted)-watchBatchMaxFiles)
+			break
+		}
+		fmt.Fprintf(&sb, "\n  %s", f)
+	}
+	return sb.String()
+}
+
 // containsSkipDir returns true if any component of the path is a directory
 // that should be ignored (e.g. node_modules, bin, obj).
 func containsSkipDir(relPath string) bool {
@@ -526,6 +660,40 @@ func containsSkipDir(relPath string) bool {
 	return false
 }
 
+// owningProject returns the project whose directory contains relPath, or nil.
+func owningProject(projects []*project.Project, relPath string) *project.Project {
+	for _, p := range projects {
+		if strings.HasPrefix(relPath, p.Dir+"/") || strings.HasPrefix(relPath, p.Dir+string(os.PathSeparator)) {
+			return p
+		}
+	}
+	return nil
+}
+
+// watchNewDir starts watching a directory created while watch mode runs,
+// including its subdirectories. Files can be written into it before the watch
+// is in place, so it returns the files it already contains.
+func watchNewDir(watcher *fsnotify.Watcher, dir string, watched map[string]bool) []string {
+	if err := addDirRecursive(watcher, dir, watched); err != nil {
+		term.Verbose("warning: failed to watch %s: %v", dir, err)
+	}
+	var files []string
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if project.ShouldSkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files
+}
+
 // addDirRecursive adds a directory and all subdirectories to the watcher,
 // skipping build output and VCS directories.
 func addDirRecursive(watcher *fsnotify.Watcher, dir string, watched map[string]bool) error {
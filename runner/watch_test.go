@@ -0,0 +1,83 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestWatchBatchHeader(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	got := watchBatchHeader([]string{"src/B.cs", "src/A.cs"}, now)
+	want := "[15:04:05] 2 file(s) changed\n  src/A.cs\n  src/B.cs"
+	if got != want {
+		t.Errorf("watchBatchHeader() = %q, want %q", got, want)
+	}
+
+	var many []string
+	for i := 0; i < watchBatchMaxFiles+3; i++ {
+		many = append(many, fmt.Sprintf("src/F%02d.cs", i))
+	}
+	got = watchBatchHeader(many, now)
+	if !strings.HasSuffix(got, "\n  src/F09.cs\n  ... and 3 more") {
+		t.Errorf("expected the list capped at %d files, got:\n%s", watchBatchMaxFiles, got)
+	}
+}
+
+func TestWatchNewDir(t *testing.T) {
+	root := t.TempDir()
+	app := &project.Project{Name: "App", Path: "src/App/App.csproj", Dir: "src/App"}
+	appDir := filepath.Join(root, app.Dir)
+	os.MkdirAll(appDir, 0755)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	watched := make(map[string]bool)
+	if err := addDirRecursive(watcher, appDir, watched); err != nil {
+		t.Fatal(err)
+	}
+
+	// A nested tree created mid-session, with a file written before it is watched
+	nested := filepath.Join(appDir, "Features", "Orders")
+	os.MkdirAll(filepath.Join(nested, "obj"), 0755)
+	os.WriteFile(filepath.Join(nested, "Order.cs"), []byte("class Order {}"), 0644)
+	os.WriteFile(filepath.Join(nested, "obj", "Order.g.cs"), []byte(""), 0644)
+
+	files := watchNewDir(watcher, filepath.Join(appDir, "Features"), watched)
+	if len(files) != 1 || files[0] != filepath.Join(nested, "Order.cs") {
+		t.Fatalf("expected only the existing source file, got %v", files)
+	}
+	if !watched[nested] || watched[filepath.Join(nested, "obj")] {
+		t.Errorf("expected the nested dir watched and obj skipped, got %v", watched)
+	}
+
+	rel, _ := filepath.Rel(root, files[0])
+	if p := owningProject([]*project.Project{app}, rel); p != app {
+		t.Errorf("expected %s to be scheduled for App, got %v", rel, p)
+	}
+
+	// Later changes inside the new tree are now reported
+	later := filepath.Join(nested, "OrderService.cs")
+	os.WriteFile(later, []byte("class OrderService {}"), 0644)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-watcher.Events:
+			if event.Name == later {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", later)
+		}
+	}
+}
@@ -81,7 +81,8 @@ type watchTestListCache struct {
 }
 
 // newWatchTestListCache starts background test discovery for all test projects.
-// Cached results are returned immediately; cache misses trigger dotnet test --list-tests.
+// Cached results are returned immediately; cache misses are discovered with
+// the configured discovery mode (dotnet test --list-tests by default).
 func newWatchTestListCache(ctx context.Context, r *Runner) *watchTestListCache {
 	c := &watchTestListCache{
 		lists: make(map[string][]string),
@@ -89,7 +90,7 @@ func newWatchTestListCache(ctx context.Context, r *Runner) *watchTestListCache {
 	}
 
 	// Collect what's already cached synchronously (fast)
-	tlc := newTestListCache(r.db, r.gitRoot, r.forwardGraph)
+	tlc := NewTestListCache(r.db, r.gitRoot, r.forwardGraph)
 	var uncached []*project.Project
 	for _, p := range r.projects {
 		if !p.IsTest {
@@ -104,6 +105,7 @@ func newWatchTestListCache(ctx context.Context, r *Runner) *watchTestListCache {
 	}
 
 	// Discover uncached projects in background
+	discovery := coverage.ParseDiscovery(r.opts.Discovery)
 	go func() {
 		defer close(c.done)
 		for _, p := range uncached {
@@ -111,7 +113,7 @@ func newWatchTestListCache(ctx context.Context, r *Runner) *watchTestListCache {
 				return
 			}
 			absPath := filepath.Join(r.gitRoot, p.Path)
-			discovered, err := coverage.ListTests(ctx, r.gitRoot, absPath)
+			discovered, err := coverage.DiscoverTests(ctx, r.gitRoot, absPath, discovery)
 			if err != nil {
 				term.Verbose("  failed to list tests for %s: %v", p.Name, err)
 				continue
@@ -0,0 +1,168 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/git"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// whyTrace explains the run/skip decision for one project, for --why.
+type whyTrace struct {
+	cacheKeyDebug
+	path     string
+	decision string
+	// lastSuccess is the time of the last successful run with these
+	// arguments (zero if none); newerFile is an input modified after it.
+	lastSuccess   time.Time
+	newerFile     string
+	newerFileTime time.Time
+	// chain runs from a changed project to this one along the reverse
+	// dependency graph (just this project when it changed itself; empty when
+	// it is not affected). rootVerdict is the cache verdict of chain[0].
+	chain       []string
+	rootVerdict string
+	// changedFiles are the VCS-changed files within the project's relevant
+	// dirs (nil without a VCS filter)
+	changedFiles []string
+	vcsFilter    bool
+}
+
+// findWhyProjects resolves --why: a project name or path, or else a glob
+// matched against names and paths (e.g. "*" for every project).
+func (r *Runner) findWhyProjects(query string) []*project.Project {
+	if p := r.findProjectByName(query); p != nil {
+		return []*project.Project{p}
+	}
+	var matched []*project.Project
+	for _, p := range r.projects {
+		if project.MatchesAny(p, []string{query}) {
+			matched = append(matched, p)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Name < matched[j].Name })
+	return matched
+}
+
+// explainProject traces why p is selected to run or skipped, given the
+// changed and affected sets and the run/cached split of this invocation.
+func (r *Runner) explainProject(p *project.Project, argsHash string, vcsChanges []git.FileChange, useVcsFilter bool, changed, affected map[string]bool, targets, cached []*project.Project) whyTrace {
+	t := whyTrace{
+		cacheKeyDebug: r.debugCacheKeys([]*project.Project{p}, argsHash, vcsChanges, useVcsFilter)[0],
+		path:          p.Path,
+		decision:      r.whyDecision(p, targets, cached),
+		changedFiles:  r.changedFiles[p.Path],
+		vcsFilter:     useVcsFilter,
+	}
+
+	if entry := r.db.LastSuccessEntry(argsHash, p.Path); entry != nil {
+		t.lastSuccess = time.Unix(entry.LastRun, 0)
+		if file, mod := newerSource(project.GetRelevantDirs(p, r.forwardGraph), r.gitRoot, t.lastSuccess); file != "" {
+			if rel, err := filepath.Rel(r.gitRoot, file); err == nil {
+				file = rel
+			}
+			t.newerFile, t.newerFileTime = filepath.ToSlash(file), mod
+		}
+	}
+
+	if !affected[p.Path] {
+		return t
+	}
+	chain := dependencyChain(p.Path, changed, r.graph)
+	for _, path := range chain {
+		t.chain = append(t.chain, r.projectsByPath[path].Name)
+	}
+	if len(chain) > 0 {
+		root := r.projectsByPath[chain[0]]
+		t.rootVerdict = r.debugCacheKeys([]*project.Project{root}, argsHash, vcsChanges, useVcsFilter)[0].verdict
+		switch {
+		case r.targetPaths[root.Path]:
+			t.rootVerdict = "selected explicitly"
+		case t.rootVerdict == "hit" && r.opts.Since != "":
+			t.rootVerdict = "no successful run since " + r.opts.Since
+		}
+	}
+	return t
+}
+
+// whyDecision mirrors the target filter in Run: it says whether p runs, is
+// cached, or why it was left out.
+func (r *Runner) whyDecision(p *project.Project, targets, cached []*project.Project) string {
+	for _, t := range targets {
+		if t == p {
+			return "runs"
+		}
+	}
+	for _, c := range cached {
+		if c == p {
+			return "skipped (cached, not affected)"
+		}
+	}
+	switch {
+	case r.opts.Command == "test" && !p.IsTest:
+		return "skipped (not a test project)"
+	case r.targetPaths != nil && !r.targetPaths[p.Path]:
+		return "skipped (not among the selected projects)"
+	case r.onlyPaths != nil && !r.onlyPaths[p.Path]:
+		return "skipped (not in --only-projects)"
+	case r.onlyScope != nil && !r.onlyScope[p.Path]:
+		return "skipped (outside --only)"
+	case project.IsExcluded(p, r.excludePatterns):
+		return "skipped (excluded)"
+	}
+	return "skipped"
+}
+
+// dependencyChain returns the shortest path from a changed project to target
+// along graph (dependency -> dependents), or nil if no changed project
+// reaches it.
+func dependencyChain(target string, changed map[string]bool, graph map[string][]string) []string {
+	affected, parents := project.FindAffectedProjectsVia(changed, graph)
+	if !affected[target] {
+		return nil
+	}
+	return append(project.AffectedVia(parents, target), target)
+}
+
+// formatWhy renders a trace as a short human-readable report.
+func formatWhy(t whyTrace) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%s): %s\n", t.name, filepath.ToSlash(t.path), t.decision)
+	fmt.Fprintf(&sb, "  content hash: %s\n", t.contentHash)
+	fmt.Fprintf(&sb, "  cache key:    %s\n", t.key)
+	fmt.Fprintf(&sb, "  cache:        %s\n", t.verdict)
+	if t.lastSuccess.IsZero() {
+		sb.WriteString("  last success: none with these arguments\n")
+	} else {
+		fmt.Fprintf(&sb, "  last success: %s\n", t.lastSuccess.Format(time.RFC3339))
+		if t.newerFile != "" {
+			fmt.Fprintf(&sb, "  newer input:  %s (modified %s)\n", t.newerFile, t.newerFileTime.Format(time.RFC3339))
+		} else {
+			sb.WriteString("  newer input:  none\n")
+		}
+	}
+	if t.vcsFilter {
+		if len(t.changedFiles) == 0 {
+			sb.WriteString("  vcs changes:  none in its or its dependencies' dirs\n")
+		} else {
+			sb.WriteString("  vcs changes:\n")
+			for _, f := range t.changedFiles {
+				fmt.Fprintf(&sb, "    %s\n", f)
+			}
+		}
+	}
+	switch len(t.chain) {
+	case 0:
+		sb.WriteString("  affected:     no, nothing it depends on changed\n")
+	case 1:
+		fmt.Fprintf(&sb, "  affected:     changed itself (%s)\n", t.rootVerdict)
+	default:
+		fmt.Fprintf(&sb, "  affected:     %s changed (%s)\n", t.chain[0], t.rootVerdict)
+		fmt.Fprintf(&sb, "  via:          %s\n", strings.Join(t.chain, " -> "))
+	}
+	return sb.String()
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/runar-rkmedia/donotnet/cache"
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+func TestDependencyChain(t *testing.T) {
+	graph := map[string][]string{
+		"Core":   {"Api", "Worker"},
+		"Api":    {"Api.Tests"},
+		"Worker": {"Api.Tests"},
+	}
+	tests := []struct {
+		name    string
+		target  string
+		changed map[string]bool
+		want    []string
+	}{
+		{"through dependency", "Api.Tests", map[string]bool{"Core": true}, []string{"Core", "Api", "Api.Tests"}},
+		{"shortest path wins", "Api.Tests", map[string]bool{"Core": true, "Worker": true}, []string{"Worker", "Api.Tests"}},
+		{"changed itself", "Api", map[string]bool{"Api": true}, []string{"Api"}},
+		{"unreachable", "Core", map[string]bool{"Api": true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dependencyChain(tt.target, tt.changed, graph); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dependencyChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExplainProject(t *testing.T) {
+	gitRoot := t.TempDir()
+	db, err := cache.Open(filepath.Join(gitRoot, "cache.db"))
+	if err != nil {
+		t.Fatalf("cache.Open() failed: %v", err)
+	}
+	defer db.Close()
+
+	core := &project.Project{Name: "Core", Path: "Core/Core.csproj", Dir: "Core"}
+	tests := &project.Project{Name: "Core.Tests", Path: "Core.Tests/Core.Tests.csproj", Dir: "Core.Tests", IsTest: true}
+	for _, p := range []*project.Project{core, tests} {
+		os.MkdirAll(filepath.Join(gitRoot, p.Dir), 0755)
+		os.WriteFile(filepath.Join(gitRoot, p.Dir, "Class.cs"), []byte("class "+p.Name+" {}"), 0644)
+	}
+	forward := map[string][]string{tests.Path: {core.Path}}
+	reverse := map[string][]string{core.Path: {tests.Path}}
+	argsHash := HashArgs([]string{"test"})
+
+	// Both succeeded an hour ago; then Core was edited
+	lastRun := time.Now().Add(-time.Hour).Truncate(time.Second)
+	db.Mark(cache.MakeKey("old-core", argsHash, core.Path), lastRun, true, nil, "test")
+	db.Mark(cache.MakeKey("old-tests", argsHash, tests.Path), lastRun, true, nil, "test")
+	old := lastRun.Add(-time.Minute)
+	os.Chtimes(filepath.Join(gitRoot, tests.Dir, "Class.cs"), old, old)
+	os.WriteFile(filepath.Join(gitRoot, core.Dir, "Class.cs"), []byte("class Core { int x; }"), 0644)
+
+	r := New(&Options{Command: "test", Why: "Core.Tests"})
+	r.gitRoot = gitRoot
+	r.db = db
+	r.projects = []*project.Project{core, tests}
+	r.projectsByPath = map[string]*project.Project{core.Path: core, tests.Path: tests}
+	r.forwardGraph = forward
+	r.graph = reverse
+
+	changed := map[string]bool{core.Path: true, tests.Path: true}
+	affected := map[string]bool{core.Path: true, tests.Path: true}
+	out := formatWhy(r.explainProject(tests, argsHash, nil, false, changed, affected, []*project.Project{tests}, nil))
+
+	for _, want := range []string{
+		"Core.Tests (Core.Tests/Core.Tests.csproj): runs\n",
+		"  cache:        miss (content new)\n",
+		"  last success: " + lastRun.Format(time.RFC3339) + "\n",
+		"  newer input:  Core/Class.cs (modified ",
+		"  affected:     changed itself (miss (content new))\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Under a VCS filter, the changed files recorded for the project are listed
+	r.changedFiles = map[string][]string{tests.Path: {"Core/Class.cs"}}
+	out = formatWhy(r.explainProject(tests, argsHash, nil, true, changed, affected, []*project.Project{tests}, nil))
+	if !strings.Contains(out, "  vcs changes:\n    Core/Class.cs\n") {
+		t.Errorf("output missing VCS changed files:\n%s", out)
+	}
+	r.changedFiles = nil
+
+	// Core itself is not a test project, so the test command leaves it out
+	out = formatWhy(r.explainProject(core, argsHash, nil, false, changed, affected, []*project.Project{tests}, nil))
+	if !strings.Contains(out, "Core (Core/Core.csproj): skipped (not a test project)\n") {
+		t.Errorf("unexpected decision for Core:\n%s", out)
+	}
+
+	// Only Core changed: the test project is affected through it
+	out = formatWhy(r.explainProject(tests, argsHash, nil, false, map[string]bool{core.Path: true}, affected, []*project.Project{tests}, nil))
+	for _, want := range []string{
+		"  affected:     Core changed (miss (content new))\n",
+		"  via:          Core -> Core.Tests\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFindWhyProjects(t *testing.T) {
+	r := New(&Options{})
+	r.projects = []*project.Project{
+		{Name: "Web.Tests", Path: "tests/Web.Tests/Web.Tests.csproj"},
+		{Name: "Api", Path: "src/Api/Api.csproj"},
+		{Name: "Api.Tests", Path: "tests/Api.Tests/Api.Tests.csproj"},
+	}
+
+	var names []string
+	for _, p := range r.findWhyProjects("*.Tests") {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"Api.Tests", "Web.Tests"}) {
+		t.Errorf("findWhyProjects(*.Tests) = %v", names)
+	}
+	if got := r.findWhyProjects("src/Api/Api.csproj"); len(got) != 1 || got[0].Name != "Api" {
+		t.Errorf("findWhyProjects(path) = %v, want Api", got)
+	}
+	if got := r.findWhyProjects("Nope"); len(got) != 0 {
+		t.Errorf("findWhyProjects(Nope) = %v, want none", got)
+	}
+}
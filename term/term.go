@@ -2,11 +2,13 @@
 package term
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	goterm "golang.org/x/term"
@@ -65,6 +67,23 @@ type Terminal struct {
 	progress bool // when true, show progress indicators
 	isTTY    bool // true if stderr is a terminal
 	rawMode  bool // true when stdin is in raw terminal mode
+
+	// buffer holds back output between StartBuffer and StopBuffer
+	buffer       *lockedBuffer
+	bufferedProg bool // progress setting to restore after buffering
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes, since workers
+// may print failure output directly.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
 }
 
 // rawWriter wraps an io.Writer and translates bare \n to \r\n.
@@ -76,6 +95,9 @@ type rawWriter struct {
 }
 
 func (rw *rawWriter) Write(p []byte) (int, error) {
+	if rw.t.buffer != nil {
+		return rw.t.buffer.Write(p)
+	}
 	if !rw.t.rawMode {
 		return rw.inner.Write(p)
 	}
@@ -108,6 +130,14 @@ func New() *Terminal {
 	return t
 }
 
+// NewWriter creates a plain Terminal without progress indicators that writes
+// to w, e.g. to capture output.
+func NewWriter(w io.Writer) *Terminal {
+	t := &Terminal{plain: true}
+	t.w = &rawWriter{inner: w, t: t}
+	return t
+}
+
 // SetPlain enables or disables plain mode (no ANSI codes)
 func (t *Terminal) SetPlain(p bool) {
 	t.plain = p
@@ -236,6 +266,27 @@ func (t *Terminal) Write(p []byte) (n int, err error) {
 	return t.w.Write(p)
 }
 
+// StartBuffer holds back all output until StopBuffer, e.g. to only show it
+// when something failed. Progress indicators are disabled meanwhile, since
+// they only make sense live. Must not be called while output is written.
+func (t *Terminal) StartBuffer() {
+	t.bufferedProg = t.progress
+	t.progress = false
+	t.buffer = &lockedBuffer{}
+}
+
+// StopBuffer ends buffering and returns the output held back since
+// StartBuffer. Write it to show it after all.
+func (t *Terminal) StopBuffer() []byte {
+	if t.buffer == nil {
+		return nil
+	}
+	out := t.buffer.buf.Bytes()
+	t.buffer = nil
+	t.progress = t.bufferedProg
+	return out
+}
+
 // Println prints without color formatting (with newline)
 func (t *Terminal) Println(args ...any) {
 	fmt.Fprintln(t.w, args...)
@@ -256,6 +307,15 @@ func (t *Terminal) ClearLines(n int) {
 	fmt.Fprintf(t.w, "\033[%dA\033[J", n)
 }
 
+// ClearScreen clears the screen and moves the cursor to the top left.
+// It is a no-op when stderr is not a terminal, even with forced colors, so
+// redirected output keeps every run.
+func (t *Terminal) ClearScreen() {
+	if !t.plain && t.isTTY {
+		fmt.Fprintf(t.w, "\033[2J\033[H")
+	}
+}
+
 // Status prints a status message that overwrites the current line
 func (t *Terminal) Status(format string, args ...any) {
 	if !t.progress {
@@ -308,6 +368,15 @@ func (t *Terminal) CachedLine(name string) {
 	}
 }
 
+// ExcludedLine prints a line for an affected project left out by an exclusion pattern
+func (t *Terminal) ExcludedLine(name string) {
+	if t.plain {
+		fmt.Fprintf(t.w, "  EXCLUDED %s\n", name)
+	} else {
+		fmt.Fprintf(t.w, "  %s⊘ %s (excluded)%s\n", ColorYellow, name, ColorReset)
+	}
+}
+
 // Summary prints the final summary line
 func (t *Terminal) Summary(succeeded, total, cached int, duration time.Duration, success bool) {
 	if t.plain {
@@ -373,13 +442,17 @@ func Printf(format string, args ...any) { Default.Printf(format, args...) }
 func Color(code string) string         { return Default.Color(code) }
 func Write(p []byte) (int, error)      { return Default.Write(p) }
 func Println(args ...any)              { Default.Println(args...) }
+func StartBuffer()                     { Default.StartBuffer() }
+func StopBuffer() []byte               { return Default.StopBuffer() }
 func ClearLine()                       { Default.ClearLine() }
 func ClearLines(n int)                 { Default.ClearLines(n) }
+func ClearScreen()                     { Default.ClearScreen() }
 func Status(format string, args ...any) { Default.Status(format, args...) }
 func ResultLine(success bool, skipIndicator, paddedName, durationStr, stats, filterInfo string) {
 	Default.ResultLine(success, skipIndicator, paddedName, durationStr, stats, filterInfo)
 }
 func CachedLine(name string) { Default.CachedLine(name) }
+func ExcludedLine(name string) { Default.ExcludedLine(name) }
 func Summary(succeeded, total, cached int, duration time.Duration, success bool) {
 	Default.Summary(succeeded, total, cached, duration, success)
 }
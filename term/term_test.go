@@ -0,0 +1,37 @@
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	var out bytes.Buffer
+	term := NewWriter(&out)
+	term.SetProgress(true)
+
+	term.StartBuffer()
+	if term.ShowProgress() {
+		t.Error("ShowProgress() = true while buffering, want progress disabled")
+	}
+	term.Printf("held back\n")
+	if out.Len() != 0 {
+		t.Errorf("wrote %q while buffering, want nothing", out.String())
+	}
+
+	held := term.StopBuffer()
+	if string(held) != "held back\n" {
+		t.Errorf("StopBuffer() = %q, want the held back output", held)
+	}
+	if !term.ShowProgress() {
+		t.Error("ShowProgress() = false after StopBuffer, want the progress setting restored")
+	}
+
+	term.Printf("live\n")
+	if out.String() != "live\n" {
+		t.Errorf("wrote %q after StopBuffer, want output to go through again", out.String())
+	}
+	if term.StopBuffer() != nil {
+		t.Error("StopBuffer() without StartBuffer should return nil")
+	}
+}
@@ -0,0 +1,107 @@
+package testfilter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// HeuristicsFileName is the name of the custom heuristics file inside the
+// .donotnet config directory.
+const HeuristicsFileName = "heuristics.json"
+
+// CustomHeuristics are user-defined heuristics loaded from a heuristics file.
+// They are enabled by "default" since the user explicitly defined them.
+var CustomHeuristics []TestHeuristic
+
+// HeuristicSpec is a user-defined heuristic as stored in the heuristics file.
+//
+// Example:
+//
+//	[
+//	  {
+//	    "name": "IntegrationTests",
+//	    "description": "Baz.cs -> BazIntegrationTests",
+//	    "sourcePattern": "^(\\w+)$",
+//	    "testPattern": "${1}IntegrationTests"
+//	  }
+//	]
+type HeuristicSpec struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// SourcePattern is a regex matched against the changed file name (without extension)
+	SourcePattern string `json:"sourcePattern"`
+	// TestPattern is a template for the test name; $1, ${1} or ${name} expand to captures
+	TestPattern string `json:"testPattern"`
+}
+
+// ParseCustomHeuristics parses heuristic specs from JSON and compiles them
+// into TestHeuristic values.
+func ParseCustomHeuristics(data []byte) ([]TestHeuristic, error) {
+	var specs []HeuristicSpec
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, err
+	}
+
+	builtin := make(map[string]bool)
+	for _, h := range AllHeuristics() {
+		builtin[h.Name] = true
+	}
+
+	var result []TestHeuristic
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("heuristic #%d: missing name", i+1)
+		}
+		if builtin[spec.Name] {
+			return nil, fmt.Errorf("heuristic %q: name conflicts with a built-in heuristic", spec.Name)
+		}
+		if spec.SourcePattern == "" || spec.TestPattern == "" {
+			return nil, fmt.Errorf("heuristic %q: sourcePattern and testPattern are required", spec.Name)
+		}
+		re, err := regexp.Compile(spec.SourcePattern)
+		if err != nil {
+			return nil, fmt.Errorf("heuristic %q: invalid sourcePattern: %w", spec.Name, err)
+		}
+
+		description := spec.Description
+		if description == "" {
+			description = fmt.Sprintf("%s -> %s (custom)", spec.SourcePattern, spec.TestPattern)
+		}
+
+		template := spec.TestPattern
+		result = append(result, TestHeuristic{
+			Name:        spec.Name,
+			Description: description,
+			Apply: func(fileName, dirName string) []string {
+				match := re.FindStringSubmatchIndex(fileName)
+				if match == nil {
+					return nil
+				}
+				return []string{string(re.ExpandString(nil, template, fileName, match))}
+			},
+		})
+	}
+	return result, nil
+}
+
+// LoadCustomHeuristics loads user-defined heuristics from path into CustomHeuristics.
+// A missing file is not an error and leaves no custom heuristics registered.
+func LoadCustomHeuristics(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			CustomHeuristics = nil
+			return nil
+		}
+		return err
+	}
+
+	heuristics, err := ParseCustomHeuristics(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	CustomHeuristics = heuristics
+	return nil
+}
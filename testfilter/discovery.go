@@ -0,0 +1,181 @@
+package testfilter
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// sourceNamespaceRegex matches block and file-scoped namespace declarations
+	// Captures: namespace (group 1), ";" for file-scoped (group 2)
+	sourceNamespaceRegex = regexp.MustCompile(`(?m)^\s*namespace\s+([\w.]+)\s*(;)?`)
+	// dataDrivenAttrRegex matches attributes that make dotnet list a test under
+	// names that can't be derived from source (one entry per data row, custom
+	// display names, parameterized fixtures)
+	dataDrivenAttrRegex = regexp.MustCompile(`\[(?:Theory|TestCase|TestCaseSource|DataTestMethod|DataRow|InlineData|MemberData|ClassData|DynamicData|TestFixtureSource)\b|DisplayName\s*=|\[TestFixture\s*\(\s*[^)\s]`)
+	// classModifiersRegex matches modifiers that stop a class's tests from
+	// being listed under its own name
+	classModifiersRegex = regexp.MustCompile(`\b(?:abstract|static)\b`)
+	// baseListRegex matches a class's base list following its name
+	// Captures: base types (group 1)
+	baseListRegex = regexp.MustCompile(`^\s*:\s*([^{]+)`)
+)
+
+// ListTestsFromSource derives fully qualified test names (Namespace.Class.Method)
+// from the [Fact]/[Test]/[TestMethod] attributes in a project's .cs files,
+// without building or starting a test host.
+//
+// ok is false when the result may not match what the test framework would
+// discover, so callers must fall back to dotnet test --list-tests: no tests
+// found, data-driven tests such as [Theory] with [InlineData], custom display
+// names, tests in abstract, generic or nested classes, test classes used as a
+// base class, or test attributes the method pattern can't account for.
+func ListTestsFromSource(projectDir string) (tests []string, ok bool) {
+	ok = true
+	testClasses := make(map[string]bool) // simple class names with tests
+	var bases []string                   // simple names of all base types
+
+	filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if !ok {
+			return filepath.SkipAll
+		}
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if name := info.Name(); name == "obj" || name == "bin" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".cs") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		fileTests, fileClasses, fileBases, fileOK := parseSourceTests(StripCSharpComments(string(content)))
+		if !fileOK {
+			ok = false
+			return filepath.SkipAll
+		}
+		tests = append(tests, fileTests...)
+		for _, c := range fileClasses {
+			testClasses[c] = true
+		}
+		bases = append(bases, fileBases...)
+		return nil
+	})
+
+	// Derived classes inherit the tests, and are listed with them too
+	for _, b := range bases {
+		if testClasses[b] {
+			ok = false
+		}
+	}
+	if !ok || len(tests) == 0 {
+		return nil, false
+	}
+	sort.Strings(tests)
+	return tests, true
+}
+
+// parseSourceTests extracts fully qualified test names from comment-stripped
+// C# source. Also returns the simple names of classes declaring tests and of
+// all base types, so callers can detect inherited tests. ok is false when the
+// file contains tests that can't be named reliably.
+func parseSourceTests(src string) (tests, classes, bases []string, ok bool) {
+	nsMatches := sourceNamespaceRegex.FindAllStringSubmatch(src, -1)
+	if len(nsMatches) > 1 {
+		return nil, nil, nil, false
+	}
+	var namespace string
+	topDepth := 0
+	if len(nsMatches) == 1 {
+		namespace = nsMatches[0][1]
+		if nsMatches[0][2] == "" {
+			topDepth = 1
+		}
+	}
+
+	attrCount := len(TestAttributeRegex.FindAllStringIndex(src, -1))
+	methodCount := 0
+
+	classMatches := classBlockRegex.FindAllStringSubmatchIndex(src, -1)
+	for i, classMatch := range classMatches {
+		if len(classMatch) < 6 {
+			continue
+		}
+		className := src[classMatch[4]:classMatch[5]]
+		rest := src[classMatch[5]:]
+		if m := baseListRegex.FindStringSubmatch(rest); m != nil {
+			for _, b := range strings.Split(m[1], ",") {
+				bases = append(bases, simpleTypeName(b))
+			}
+		}
+
+		classEnd := len(src)
+		if i+1 < len(classMatches) {
+			classEnd = classMatches[i+1][0]
+		}
+		var methods []string
+		for _, methodMatch := range testMethodBlockRegex.FindAllStringSubmatch(src[classMatch[0]:classEnd], -1) {
+			if len(methodMatch) < 3 || !TestAttributeRegex.MatchString(methodMatch[1]) {
+				continue
+			}
+			if dataDrivenAttrRegex.MatchString(methodMatch[1]) {
+				return nil, nil, nil, false
+			}
+			methodCount += len(TestAttributeRegex.FindAllStringIndex(methodMatch[1], -1))
+			methods = append(methods, methodMatch[2])
+		}
+		if len(methods) == 0 {
+			continue
+		}
+
+		lineStart := strings.LastIndex(src[:classMatch[4]], "\n") + 1
+		modifiers := src[lineStart:classMatch[4]]
+		attrs := ""
+		if classMatch[2] >= 0 {
+			attrs = src[classMatch[2]:classMatch[3]]
+		}
+		nested := strings.Count(src[:classMatch[0]], "{")-strings.Count(src[:classMatch[0]], "}") != topDepth
+		generic := strings.HasPrefix(strings.TrimSpace(rest), "<")
+		if nested || generic || classModifiersRegex.MatchString(modifiers) || dataDrivenAttrRegex.MatchString(attrs) {
+			return nil, nil, nil, false
+		}
+
+		fqClassName := className
+		if namespace != "" {
+			fqClassName = namespace + "." + className
+		}
+		for _, m := range methods {
+			tests = append(tests, fqClassName+"."+m)
+		}
+		classes = append(classes, className)
+	}
+
+	// A test attribute the method pattern didn't pick up means a missed test
+	if methodCount != attrCount {
+		return nil, nil, nil, false
+	}
+	return tests, classes, bases, true
+}
+
+// simpleTypeName strips namespace qualifiers, generic arguments and
+// constraints from a type in a base list.
+func simpleTypeName(t string) string {
+	t = strings.TrimSpace(t)
+	if idx := strings.IndexAny(t, "< \t\r\n"); idx >= 0 {
+		t = t[:idx]
+	}
+	if idx := strings.LastIndex(t, "."); idx >= 0 {
+		t = t[idx+1:]
+	}
+	return t
+}
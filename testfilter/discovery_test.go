@@ -0,0 +1,138 @@
+package testfilter
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeSource(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListTestsFromSource(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, "CalculatorTests.cs", `using Xunit;
+
+namespace MyApp.Tests
+{
+    public class CalculatorTests
+    {
+        [Fact]
+        public void Adds() { }
+
+        [Fact(Skip = "flaky")]
+        public async Task Divides() { }
+
+        // [Fact]
+        // public void Commented() { }
+
+        private void Helper() { }
+    }
+}
+`)
+	writeSource(t, dir, "Parsing/ParserTests.cs", `namespace MyApp.Tests.Parsing;
+
+[TestFixture]
+public sealed class ParserTests
+{
+    [Test]
+    [Category("Slow")]
+    public void ParsesEmpty() { }
+}
+`)
+	writeSource(t, dir, "obj/Debug/Generated.cs", `public class Generated { [Fact] public void Ignored() { } }`)
+
+	tests, ok := ListTestsFromSource(dir)
+	if !ok {
+		t.Fatal("ListTestsFromSource() not ok, want ok")
+	}
+	want := []string{
+		"MyApp.Tests.CalculatorTests.Adds",
+		"MyApp.Tests.CalculatorTests.Divides",
+		"MyApp.Tests.Parsing.ParserTests.ParsesEmpty",
+	}
+	if !reflect.DeepEqual(tests, want) {
+		t.Errorf("ListTestsFromSource() = %v, want %v", tests, want)
+	}
+}
+
+func TestListTestsFromSourceAmbiguous(t *testing.T) {
+	tests := map[string]string{
+		"theory": `namespace N;
+public class T
+{
+    [Theory]
+    [InlineData(1)]
+    public void Works(int x) { }
+}`,
+		"nunit test case": `namespace N;
+public class T
+{
+    [TestCase(1)]
+    public void Works(int x) { }
+}`,
+		"display name": `namespace N;
+public class T
+{
+    [Fact(DisplayName = "It works")]
+    public void Works() { }
+}`,
+		"abstract class": `namespace N;
+public abstract class T
+{
+    [Fact]
+    public void Works() { }
+}`,
+		"generic class": `namespace N;
+public class T<TValue>
+{
+    [Fact]
+    public void Works() { }
+}`,
+		"nested class": `namespace N
+{
+    public class Outer
+    {
+        public class Inner
+        {
+            [Fact]
+            public void Works() { }
+        }
+    }
+}`,
+		"inherited tests": `namespace N;
+public class Base
+{
+    [Fact]
+    public void Works() { }
+}
+public class Derived : N.Base { }`,
+		"unmatched method": `namespace N;
+public class T
+{
+    [Fact]
+    public static void Works() { }
+}`,
+		"no tests": `namespace N;
+public class T { }`,
+	}
+
+	for name, src := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSource(t, dir, "T.cs", src)
+			if got, ok := ListTestsFromSource(dir); ok {
+				t.Errorf("ListTestsFromSource() = %v, ok; want fallback", got)
+			}
+		})
+	}
+}
@@ -20,6 +20,12 @@ type TestFilter struct {
 	CoverageMaps map[string]*TestCoverageMap
 	// Heuristics is the list of enabled heuristics for fallback filtering
 	Heuristics []TestHeuristic
+	// Resolver overrides Heuristics for changed files matching a rule of the
+	// heuristics file (nil = Heuristics apply everywhere)
+	Resolver *HeuristicsResolver
+
+	// referenceIndexes caches the ReferencedByTest index per test project directory
+	referenceIndexes map[string]*referenceIndex
 }
 
 // FilterResult contains the result of analyzing changed files
@@ -52,6 +58,19 @@ func (tf *TestFilter) SetHeuristics(heuristics []TestHeuristic) {
 	tf.Heuristics = heuristics
 }
 
+// SetHeuristicsResolver sets the per-directory heuristics rules
+func (tf *TestFilter) SetHeuristicsResolver(r *HeuristicsResolver) {
+	tf.Resolver = r
+}
+
+// heuristicsFor returns the heuristics that apply to a changed file
+func (tf *TestFilter) heuristicsFor(file string) []TestHeuristic {
+	if heuristics, _, ok := tf.Resolver.Resolve(file); ok {
+		return heuristics
+	}
+	return tf.Heuristics
+}
+
 // AddChangedFile records a changed file for a project
 func (tf *TestFilter) AddChangedFile(projectPath, filePath string) {
 	tf.ChangedFiles[projectPath] = append(tf.ChangedFiles[projectPath], filePath)
@@ -89,7 +108,7 @@ func (tf *TestFilter) GetFilter(projectPath string, gitRoot string, userFilter s
 
 	// Try heuristic-based filtering using all changed files
 	if len(tf.AllChangedFiles) > 0 {
-		result := tf.getFilterWithHeuristics(tf.AllChangedFiles, gitRoot)
+		result := tf.getFilterWithHeuristics(projectPath, tf.AllChangedFiles, gitRoot)
 		if result.CanFilter {
 			// Check if all tests would be excluded by user filter
 			result = tf.checkUserFilterExclusion(result, gitRoot, excludedCategories)
@@ -230,30 +249,36 @@ func (tf *TestFilter) checkUserFilterExclusion(result FilterResult, gitRoot stri
 }
 
 // getFilterWithHeuristics uses naming conventions to guess which tests to run
-func (tf *TestFilter) getFilterWithHeuristics(changedFiles []string, gitRoot string) FilterResult {
-	// If no heuristics are enabled, return early
-	if len(tf.Heuristics) == 0 {
-		return FilterResult{
-			CanFilter: false,
-			Reason:    "heuristics disabled",
-		}
-	}
-
-	// Check if TestFileOnly heuristic is enabled (for safe test file detection)
-	testFileOnlyEnabled := false
-	for _, h := range tf.Heuristics {
-		if h.Name == "TestFileOnly" {
-			testFileOnlyEnabled = true
-			break
-		}
-	}
-
+// in the test project at projectPath
+func (tf *TestFilter) getFilterWithHeuristics(projectPath string, changedFiles []string, gitRoot string) FilterResult {
 	testsToRun := make(map[string]bool)
 	var nonCsFiles []string
 	var usedHeuristics []string
 	var unsafeTestFiles []string // test files that failed safety check
 
 	for _, file := range changedFiles {
+		// If no heuristics are enabled for this file, we can't guess its tests
+		heuristics := tf.heuristicsFor(file)
+		if len(heuristics) == 0 {
+			reason := "heuristics disabled"
+			if _, glob, ok := tf.Resolver.Resolve(file); ok {
+				reason = fmt.Sprintf("heuristics disabled for %s (%s)", file, glob)
+			}
+			return FilterResult{
+				CanFilter: false,
+				Reason:    reason,
+			}
+		}
+
+		// Check if TestFileOnly heuristic is enabled (for safe test file detection)
+		testFileOnlyEnabled := false
+		for _, h := range heuristics {
+			if h.Name == "TestFileOnly" {
+				testFileOnlyEnabled = true
+				break
+			}
+		}
+
 		fileName := filepath.Base(file)
 		ext := strings.ToLower(filepath.Ext(fileName))
 
@@ -323,8 +348,13 @@ func (tf *TestFilter) getFilterWithHeuristics(changedFiles []string, gitRoot str
 		}
 
 		// Apply each enabled heuristic
-		for _, h := range tf.Heuristics {
-			patterns := h.Apply(nameWithoutExt, dirName)
+		for _, h := range heuristics {
+			var patterns []string
+			if h.Name == "ReferencedByTest" {
+				patterns = tf.referencingTests(projectPath, gitRoot, fullPath)
+			} else {
+				patterns = h.Apply(nameWithoutExt, dirName)
+			}
 			for _, p := range patterns {
 				if p != "" {
 					testsToRun[p] = true
@@ -3,6 +3,7 @@ package testfilter
 import (
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -307,6 +308,27 @@ func TestHeuristic_InterfaceToImpl(t *testing.T) {
 	}
 }
 
+func TestHeuristic_PartialClassToTests(t *testing.T) {
+	tf := NewTestFilter()
+	tf.SetHeuristics(ParseHeuristics("PartialClassToTests"))
+
+	tf.AddChangedFile("project", "src/Lib/Foo.Commands.cs")
+
+	result := tf.GetFilter("project", "/tmp/gitroot", "")
+
+	if !result.CanFilter {
+		t.Errorf("expected CanFilter=true, got false. Reason: %s", result.Reason)
+	}
+	if result.TestFilter != "FullyQualifiedName~FooTests" {
+		t.Errorf("expected only FooTests in filter, got: %s", result.TestFilter)
+	}
+
+	// Files without a partial suffix are left to other heuristics
+	if got := ParseHeuristics("PartialClassToTests")[0].Apply("Foo", "Lib"); got != nil {
+		t.Errorf("PartialClassToTests.Apply(Foo) = %v, want nil", got)
+	}
+}
+
 func TestHeuristic_AlwaysCompositionRoot(t *testing.T) {
 	tf := NewTestFilter()
 	tf.SetHeuristics(ParseHeuristics("AlwaysCompositionRoot"))
@@ -323,6 +345,95 @@ func TestHeuristic_AlwaysCompositionRoot(t *testing.T) {
 	}
 }
 
+func TestParseCustomHeuristics(t *testing.T) {
+	data := []byte(`[
+		{"name": "IntegrationTests", "sourcePattern": "^(\\w+)$", "testPattern": "${1}IntegrationTests"},
+		{"name": "HandlerToFeature", "description": "FooHandler -> FooFeatureTests", "sourcePattern": "^(?P<feature>\\w+)Handler$", "testPattern": "${feature}FeatureTests"}
+	]`)
+
+	heuristics, err := ParseCustomHeuristics(data)
+	if err != nil {
+		t.Fatalf("ParseCustomHeuristics failed: %v", err)
+	}
+	if len(heuristics) != 2 {
+		t.Fatalf("expected 2 heuristics, got %d", len(heuristics))
+	}
+
+	if got := heuristics[0].Apply("Baz", "Bar"); len(got) != 1 || got[0] != "BazIntegrationTests" {
+		t.Errorf("IntegrationTests.Apply(Baz) = %v, want [BazIntegrationTests]", got)
+	}
+	if got := heuristics[1].Apply("OrderHandler", ""); len(got) != 1 || got[0] != "OrderFeatureTests" {
+		t.Errorf("HandlerToFeature.Apply(OrderHandler) = %v, want [OrderFeatureTests]", got)
+	}
+	if got := heuristics[1].Apply("Order", ""); got != nil {
+		t.Errorf("HandlerToFeature.Apply(Order) = %v, want nil", got)
+	}
+	if heuristics[1].Description != "FooHandler -> FooFeatureTests" {
+		t.Errorf("unexpected description %q", heuristics[1].Description)
+	}
+}
+
+func TestParseCustomHeuristics_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"missing name", `[{"sourcePattern": "x", "testPattern": "y"}]`},
+		{"missing patterns", `[{"name": "Foo"}]`},
+		{"bad regex", `[{"name": "Foo", "sourcePattern": "(", "testPattern": "y"}]`},
+		{"builtin conflict", `[{"name": "NameToNameTests", "sourcePattern": "x", "testPattern": "y"}]`},
+		{"bad json", `{`},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseCustomHeuristics([]byte(tt.data)); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+		}
+	}
+}
+
+func TestLoadCustomHeuristics(t *testing.T) {
+	t.Cleanup(func() { CustomHeuristics = nil })
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, HeuristicsFileName)
+
+	// Missing file is not an error
+	if err := LoadCustomHeuristics(path); err != nil {
+		t.Fatalf("expected no error for missing file, got %v", err)
+	}
+	if len(CustomHeuristics) != 0 {
+		t.Errorf("expected no custom heuristics, got %d", len(CustomHeuristics))
+	}
+
+	os.WriteFile(path, []byte(`[{"name": "IntegrationTests", "sourcePattern": "^(\\w+)$", "testPattern": "${1}IntegrationTests"}]`), 0644)
+	if err := LoadCustomHeuristics(path); err != nil {
+		t.Fatalf("LoadCustomHeuristics failed: %v", err)
+	}
+
+	// Custom heuristics are part of "default" and can be selected by name
+	if h := ParseHeuristics("default"); len(h) != len(AvailableHeuristics)+1 {
+		t.Errorf("expected custom heuristic in default, got %d heuristics", len(h))
+	}
+	if h := ParseHeuristics("IntegrationTests"); len(h) != 1 || h[0].Name != "IntegrationTests" {
+		t.Errorf("expected IntegrationTests by name, got %v", h)
+	}
+	if h := ParseHeuristics("default,-IntegrationTests"); len(h) != len(AvailableHeuristics) {
+		t.Errorf("expected custom heuristic to be disabled, got %d heuristics", len(h))
+	}
+
+	tf := NewTestFilter()
+	tf.SetHeuristics(ParseHeuristics("default"))
+	tf.AddChangedFile("project", "src/Foo/Bar/Baz.cs")
+
+	result := tf.GetFilter("project", "/tmp/gitroot", "")
+	if !result.CanFilter {
+		t.Fatalf("expected CanFilter=true, got false. Reason: %s", result.Reason)
+	}
+	if !strings.Contains(result.TestFilter, "BazIntegrationTests") {
+		t.Errorf("expected BazIntegrationTests in filter, got: %s", result.TestFilter)
+	}
+}
+
 func TestGetFilterWithHeuristics_Disabled(t *testing.T) {
 	tf := NewTestFilter()
 	tf.SetHeuristics(nil) // Disable default heuristics
@@ -500,6 +611,27 @@ func TestParseFilterExclusions(t *testing.T) {
 	}
 }
 
+func TestExcludeTraitsFilter(t *testing.T) {
+	tests := []struct {
+		packages []string
+		want     string
+	}{
+		{[]string{"xunit", "Microsoft.NET.Test.Sdk"}, "Category!=Live&Category!=Slow"},
+		{[]string{"NUnit", "NUnit3TestAdapter"}, "Category!=Live&Category!=Slow"},
+		{[]string{"MSTest.TestFramework", "MSTest.TestAdapter"}, "TestCategory!=Live&TestCategory!=Slow"},
+	}
+	for _, tt := range tests {
+		got := ExcludeTraitsFilter(TraitFilterProperty(tt.packages), []string{"Live", "Slow"})
+		if got != tt.want {
+			t.Errorf("ExcludeTraitsFilter(%v) = %q, want %q", tt.packages, got, tt.want)
+		}
+		// The generated filter must be recognized as excluding the traits
+		if excl := ParseFilterExclusions(got); len(excl) != 2 || excl[0] != "Live" || excl[1] != "Slow" {
+			t.Errorf("ParseFilterExclusions(%q) = %v", got, excl)
+		}
+	}
+}
+
 func TestAreAllTraitsExcluded(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -804,3 +936,186 @@ public class ServiceIntegrationTests : IntegrationTests {
 		t.Errorf("expected reason to mention referenced, got: %s", result.Reason)
 	}
 }
+
+func TestHeuristicsResolver_Precedence(t *testing.T) {
+	r, err := ParseHeuristicsRules([]byte(`# legacy code uses its own conventions
+legacy/Billing/**   NameToNameTests
+legacy/**           none
+./src/**/*.cs	ExtensionsToBase, InterfaceToImpl
+`))
+	if err != nil {
+		t.Fatalf("ParseHeuristicsRules failed: %v", err)
+	}
+
+	tests := []struct {
+		file  string
+		glob  string
+		names []string
+		ok    bool
+	}{
+		// The first matching rule wins, so the narrower glob must come first
+		{"legacy/Billing/Invoice.cs", "legacy/Billing/**", []string{"NameToNameTests"}, true},
+		{"legacy/Shipping/Parcel.cs", "legacy/**", nil, true},
+		{"src/Api/Deep/FooExtensions.cs", "src/**/*.cs", []string{"ExtensionsToBase", "InterfaceToImpl"}, true},
+		{"tools/Gen/Program.cs", "", nil, false},
+	}
+	for _, tt := range tests {
+		heuristics, glob, ok := r.Resolve(tt.file)
+		if ok != tt.ok || glob != tt.glob {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tt.file, glob, ok, tt.glob, tt.ok)
+			continue
+		}
+		var names []string
+		for _, h := range heuristics {
+			names = append(names, h.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+			t.Errorf("Resolve(%q) heuristics = %v, want %v", tt.file, names, tt.names)
+		}
+	}
+
+	var nilResolver *HeuristicsResolver
+	if _, _, ok := nilResolver.Resolve("src/Foo.cs"); ok {
+		t.Error("expected a nil resolver to match nothing")
+	}
+
+	if _, err := ParseHeuristicsRules([]byte("legacy/**\n")); err == nil {
+		t.Error("expected an error for a rule without heuristics")
+	}
+}
+
+func TestGetFilterWithHeuristics_Resolver(t *testing.T) {
+	r, err := ParseHeuristicsRules([]byte("legacy/**  none\nsrc/New/** InterfaceToImpl\n"))
+	if err != nil {
+		t.Fatalf("ParseHeuristicsRules failed: %v", err)
+	}
+	tf := NewTestFilter()
+	tf.SetHeuristics(ParseHeuristics("NameToNameTests"))
+	tf.SetHeuristicsResolver(r)
+
+	// Files matching no rule use the flag's heuristics, others their rule's
+	tf.AddChangedFile("proj", "src/New/IFoo.cs")
+	tf.AddChangedFile("proj", "src/Old/Bar.cs")
+	result := tf.GetFilter("proj", "/tmp/gitroot", "")
+	if !result.CanFilter {
+		t.Fatalf("expected CanFilter=true, got false. Reason: %s", result.Reason)
+	}
+	for _, want := range []string{"FooTests", "BarTests"} {
+		if !strings.Contains(result.TestFilter, want) {
+			t.Errorf("expected %s in filter, got: %s", want, result.TestFilter)
+		}
+	}
+	if strings.Contains(result.TestFilter, "IFooTests") {
+		t.Errorf("NameToNameTests should not apply under src/New, got: %s", result.TestFilter)
+	}
+
+	// A file under a "none" rule disables filtering for the whole project
+	tf.AddChangedFile("proj", "legacy/Thing.cs")
+	result = tf.GetFilter("proj", "/tmp/gitroot", "")
+	if result.CanFilter {
+		t.Errorf("expected CanFilter=false with a change under legacy/, got filter %s", result.TestFilter)
+	}
+	if reason := tf.getFilterWithHeuristics("proj", tf.AllChangedFiles, "/tmp/gitroot").Reason; !strings.Contains(reason, "legacy/**") {
+		t.Errorf("expected the rule in the heuristics reason, got %q", reason)
+	}
+}
+
+func TestHeuristic_ReferencedByTest(t *testing.T) {
+	gitRoot := t.TempDir()
+	files := map[string]string{
+		"src/App/Billing/InvoiceCalculator.cs": `namespace App.Billing;
+public class InvoiceCalculator { }
+public record InvoiceLine(decimal Amount);
+`,
+		"src/App/Shipping/Calculator.cs": `namespace App.Shipping
+{
+    public class Calculator { }
+}
+`,
+		"tests/App.Tests/GlobalUsings.cs": `global using App.Shipping;
+`,
+		// Uses InvoiceCalculator through a using directive
+		"tests/App.Tests/Billing/TotalsTests.cs": `using App.Billing;
+namespace App.Tests.Billing;
+public class TotalsTests {
+    [Fact]
+    public void Sums() { var c = new InvoiceCalculator(); }
+}
+`,
+		// Uses InvoiceLine fully qualified
+		"tests/App.Tests/LinesTests.cs": `namespace App.Tests;
+public class LinesTests {
+    [Fact]
+    public void Works() { var l = new App.Billing.InvoiceLine(1); }
+}
+`,
+		// Has its own Calculator; App.Shipping.Calculator comes from the global using
+		"tests/App.Tests/Other/CalculatorTests.cs": `namespace App.Tests.Other;
+public class CalculatorTests {
+    [Fact]
+    public void Adds() { var c = new Calculator(); }
+}
+`,
+		// Mentions InvoiceCalculator only in a comment
+		"tests/App.Tests/CommentTests.cs": `namespace App.Tests;
+public class CommentTests {
+    // InvoiceCalculator is tested elsewhere
+    [Fact]
+    public void Nothing() { }
+}
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(gitRoot, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	tf := NewTestFilter()
+	tf.SetHeuristics(ParseHeuristics("ReferencedByTest"))
+	tf.AddChangedFile("src/App/App.csproj", "src/App/Billing/InvoiceCalculator.cs")
+
+	result := tf.GetFilter("tests/App.Tests/App.Tests.csproj", gitRoot, "")
+	if !result.CanFilter {
+		t.Fatalf("expected CanFilter=true, got false. Reason: %s", result.Reason)
+	}
+	sort.Strings(result.TestClasses)
+	want := []string{"App.Tests.Billing.TotalsTests", "App.Tests.LinesTests"}
+	if strings.Join(result.TestClasses, ",") != strings.Join(want, ",") {
+		t.Errorf("TestClasses = %v, want %v", result.TestClasses, want)
+	}
+
+	// Global usings bring a namespace into every test file
+	tf = NewTestFilter()
+	tf.SetHeuristics(ParseHeuristics("ReferencedByTest"))
+	tf.AddChangedFile("src/App/App.csproj", "src/App/Shipping/Calculator.cs")
+	result = tf.GetFilter("tests/App.Tests/App.Tests.csproj", gitRoot, "")
+	if strings.Join(result.TestClasses, ",") != "App.Tests.Other.CalculatorTests" {
+		t.Errorf("TestClasses = %v, want [App.Tests.Other.CalculatorTests] (reason: %s)", result.TestClasses, result.Reason)
+	}
+}
+
+func TestReferenceIndex_Resolves(t *testing.T) {
+	idx := &referenceIndex{}
+	refs := &testFileRefs{namespace: "App.Tests.Core", usings: []string{"Lib.Text"}, qualified: map[string]bool{"Other.Thing": true}}
+
+	tests := []struct {
+		namespace, typeName string
+		want                bool
+	}{
+		{"App.Tests.Core", "Foo", true}, // same namespace
+		{"App.Tests", "Foo", true},      // enclosing namespace
+		{"App", "Foo", true},
+		{"App.Tests.Core.Inner", "Foo", false}, // nested namespaces are not in scope
+		{"Lib.Text", "Parser", true},           // using directive
+		{"Lib", "Parser", false},
+		{"Other", "Thing", true}, // fully qualified
+		{"Other", "Stuff", false},
+		{"", "Anything", true}, // global namespace
+	}
+	for _, tt := range tests {
+		if got := idx.resolves(refs, tt.namespace, tt.typeName); got != tt.want {
+			t.Errorf("resolves(%q, %q) = %v, want %v", tt.namespace, tt.typeName, got, tt.want)
+		}
+	}
+}
@@ -73,6 +73,26 @@ var OptInHeuristics = []TestHeuristic{
 			return nil
 		},
 	},
+	{
+		Name:        "PartialClassToTests",
+		Description: "Foo.Commands.cs -> FooTests (partial class split across Foo.*.cs files)",
+		Apply: func(fileName, dirName string) []string {
+			// fileName has its extension removed, so any remaining dot is a partial suffix
+			if idx := strings.Index(fileName, "."); idx > 0 {
+				return []string{fileName[:idx] + "Tests"}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "ReferencedByTest",
+		Description: "Foo.cs -> test classes that use Foo (resolved through namespaces and usings in the test project)",
+		Apply: func(fileName, dirName string) []string {
+			// Needs the file contents and the test project, so the lookup
+			// happens in getFilterWithHeuristics
+			return nil
+		},
+	},
 	{
 		Name:        "AlwaysCompositionRoot",
 		Description: "Any .cs -> CompositionRootTests (DI container tests)",
@@ -95,8 +115,21 @@ func AllHeuristics() []TestHeuristic {
 	return all
 }
 
+// enabledByDefault returns the built-in default heuristics plus any custom
+// heuristics loaded from the heuristics file.
+func enabledByDefault() []TestHeuristic {
+	if len(CustomHeuristics) == 0 {
+		return AvailableHeuristics
+	}
+	result := make([]TestHeuristic, 0, len(AvailableHeuristics)+len(CustomHeuristics))
+	result = append(result, AvailableHeuristics...)
+	result = append(result, CustomHeuristics...)
+	return result
+}
+
 // ParseHeuristics parses a comma-separated list of heuristic names
-// Returns the enabled heuristics.
+// Returns the enabled heuristics. Custom heuristics (see LoadCustomHeuristics)
+// can be referenced by name and are included in "default".
 //   - "default" = default heuristics only
 //   - "none" = no heuristics
 //   - "default,ExtensionsToBase" = defaults + specific opt-in
@@ -104,7 +137,7 @@ func AllHeuristics() []TestHeuristic {
 //   - "NameToNameTests,InterfaceToImpl" = only specified ones
 func ParseHeuristics(spec string) []TestHeuristic {
 	if spec == "" || spec == "default" {
-		return AvailableHeuristics
+		return enabledByDefault()
 	}
 	if spec == "none" {
 		return nil
@@ -118,6 +151,9 @@ func ParseHeuristics(spec string) []TestHeuristic {
 	for _, h := range OptInHeuristics {
 		allByName[h.Name] = h
 	}
+	for _, h := range CustomHeuristics {
+		allByName[h.Name] = h
+	}
 
 	// First pass: collect additions and removals
 	var additions []string
@@ -149,7 +185,7 @@ func ParseHeuristics(spec string) []TestHeuristic {
 
 		if name == "default" {
 			// Add all default heuristics (unless disabled)
-			for _, h := range AvailableHeuristics {
+			for _, h := range enabledByDefault() {
 				if !seen[h.Name] && !disabled[h.Name] {
 					seen[h.Name] = true
 					result = append(result, h)
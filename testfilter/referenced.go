@@ -0,0 +1,171 @@
+package testfilter
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+var (
+	// typeDeclRegex matches type declarations in a source file
+	// Captures: type name (group 1)
+	typeDeclRegex = regexp.MustCompile(`\b(?:class|interface|struct|record|enum)\s+(\w+)`)
+	// usingRegex matches using directives (not using statements or aliases)
+	// Captures: "global" (group 1), namespace (group 2)
+	usingRegex = regexp.MustCompile(`(?m)^\s*(global\s+)?using\s+(?:static\s+)?([\w.]+)\s*;`)
+	// qualifiedNameRegex matches identifiers, including dotted ones like Foo.Bar.Baz
+	qualifiedNameRegex = regexp.MustCompile(`\b[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*`)
+)
+
+// testFileRefs is what a test file references, with the context needed to
+// tell which namespace an unqualified type name resolves to.
+type testFileRefs struct {
+	namespace string
+	usings    []string
+	qualified map[string]bool // dotted names used in the file, e.g. "MyApp.Core.Foo"
+	classes   []string        // fully qualified test classes declared in the file
+}
+
+// referenceIndex is a reverse index of a test project: identifier -> test
+// files using it, plus the global usings that apply to every file.
+type referenceIndex struct {
+	byName       map[string][]*testFileRefs
+	globalUsings []string
+}
+
+// buildReferenceIndex scans the test files (files with test attributes) below
+// projectDir and indexes the identifiers they use.
+func buildReferenceIndex(projectDir string) *referenceIndex {
+	idx := &referenceIndex{byName: make(map[string][]*testFileRefs)}
+	filepath.WalkDir(projectDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if project.ShouldSkipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".cs") {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		content := StripCSharpComments(string(data))
+
+		refs := &testFileRefs{qualified: make(map[string]bool)}
+		for _, m := range usingRegex.FindAllStringSubmatch(content, -1) {
+			if m[1] != "" {
+				idx.globalUsings = append(idx.globalUsings, m[2])
+			} else {
+				refs.usings = append(refs.usings, m[2])
+			}
+		}
+		// Files without tests only contribute global usings (e.g. GlobalUsings.cs)
+		if !TestAttributeRegex.MatchString(content) {
+			return nil
+		}
+		if m := sourceNamespaceRegex.FindStringSubmatch(content); m != nil {
+			refs.namespace = m[1]
+		}
+		for _, m := range ClassRegex.FindAllStringSubmatch(content, -1) {
+			refs.classes = append(refs.classes, qualify(refs.namespace, m[1]))
+		}
+
+		seen := make(map[string]bool)
+		for _, name := range qualifiedNameRegex.FindAllString(content, -1) {
+			if strings.Contains(name, ".") {
+				refs.qualified[name] = true
+			}
+			for _, part := range strings.Split(name, ".") {
+				if !seen[part] {
+					seen[part] = true
+					idx.byName[part] = append(idx.byName[part], refs)
+				}
+			}
+		}
+		return nil
+	})
+	return idx
+}
+
+// testsReferencing returns the fully qualified test classes whose files use
+// the type namespace.typeName: by name from within that namespace (or a
+// nested one), by name with a using directive for it, or fully qualified.
+func (idx *referenceIndex) testsReferencing(namespace, typeName string) []string {
+	var classes []string
+	for _, refs := range idx.byName[typeName] {
+		if idx.resolves(refs, namespace, typeName) {
+			classes = append(classes, refs.classes...)
+		}
+	}
+	return classes
+}
+
+// resolves reports whether a use of typeName in the file refs can refer to
+// namespace.typeName.
+func (idx *referenceIndex) resolves(refs *testFileRefs, namespace, typeName string) bool {
+	if namespace == "" || refs.qualified[namespace+"."+typeName] {
+		return true
+	}
+	if refs.namespace == namespace || strings.HasPrefix(refs.namespace, namespace+".") {
+		return true
+	}
+	for _, usings := range [][]string{refs.usings, idx.globalUsings} {
+		for _, u := range usings {
+			if u == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referencingTests returns the test classes of the test project at
+// projectPath (relative to gitRoot) that reference a type declared in the
+// source file at fullPath. Indexes are built once per test project.
+func (tf *TestFilter) referencingTests(projectPath, gitRoot, fullPath string) []string {
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil
+	}
+	content := StripCSharpComments(string(data))
+	var namespace string
+	if m := sourceNamespaceRegex.FindStringSubmatch(content); m != nil {
+		namespace = m[1]
+	}
+
+	projectDir := filepath.Join(gitRoot, filepath.Dir(projectPath))
+	if tf.referenceIndexes == nil {
+		tf.referenceIndexes = make(map[string]*referenceIndex)
+	}
+	idx, ok := tf.referenceIndexes[projectDir]
+	if !ok {
+		idx = buildReferenceIndex(projectDir)
+		tf.referenceIndexes[projectDir] = idx
+	}
+
+	var classes []string
+	for _, m := range typeDeclRegex.FindAllStringSubmatch(content, -1) {
+		classes = append(classes, idx.testsReferencing(namespace, m[1])...)
+	}
+	classes = uniqueStrings(classes)
+	sort.Strings(classes)
+	return classes
+}
+
+// qualify joins a namespace and a type name.
+func qualify(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
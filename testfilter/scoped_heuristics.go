@@ -0,0 +1,93 @@
+package testfilter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/runar-rkmedia/donotnet/project"
+)
+
+// ScopedHeuristicsFileName is the name of the file in the git root that maps
+// directory globs to heuristic specs, so parts of a repository with different
+// naming conventions can use different heuristics.
+//
+// Example:
+//
+//	# glob          heuristics (as for --heuristics)
+//	legacy/**       none
+//	src/Modules/**  default,ExtensionsToBase,InterfaceToImpl
+const ScopedHeuristicsFileName = ".donotnet-heuristics"
+
+// HeuristicsResolver picks the heuristics for a changed file from the rules of
+// a heuristics file. The first rule whose glob matches the file's path
+// (relative to the git root) applies; files matching no rule use the
+// heuristics set on the TestFilter.
+type HeuristicsResolver struct {
+	rules []heuristicsRule
+}
+
+type heuristicsRule struct {
+	glob       string
+	heuristics []TestHeuristic
+}
+
+// ParseHeuristicsRules parses a heuristics file: one "<glob> <spec>" rule per
+// line, where spec is anything ParseHeuristics accepts. Blank lines and lines
+// starting with # are ignored. Globs use forward slashes and "**" matches any
+// number of directories.
+func ParseHeuristicsRules(data []byte) (*HeuristicsResolver, error) {
+	r := &HeuristicsResolver{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected \"<glob> <heuristics>\", got %q", n, line)
+		}
+		r.rules = append(r.rules, heuristicsRule{
+			glob:       strings.TrimPrefix(filepath.ToSlash(line[:i]), "./"),
+			heuristics: ParseHeuristics(strings.TrimSpace(line[i:])),
+		})
+	}
+	return r, scanner.Err()
+}
+
+// LoadHeuristicsRules reads a heuristics file (see ParseHeuristicsRules).
+// Custom heuristics must be loaded first so rules can reference them.
+// A missing file is not an error and yields a nil resolver.
+func LoadHeuristicsRules(path string) (*HeuristicsResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	r, err := ParseHeuristicsRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+// Resolve returns the heuristics of the first rule matching file (relative to
+// the git root) and the rule's glob. ok is false if no rule matches.
+func (r *HeuristicsResolver) Resolve(file string) (heuristics []TestHeuristic, glob string, ok bool) {
+	if r == nil {
+		return nil, "", false
+	}
+	file = filepath.ToSlash(file)
+	for _, rule := range r.rules {
+		if project.MatchGlob(rule.glob, file) {
+			return rule.heuristics, rule.glob, true
+		}
+	}
+	return nil, "", false
+}
@@ -106,6 +106,36 @@ func ParseFilterExclusions(filter string) []string {
 	return exclusions
 }
 
+// TraitFilterProperty returns the dotnet test --filter property holding test
+// categories for a project with the given package references. MSTest only
+// knows TestCategory; xUnit (Trait "Category") and NUnit use Category.
+func TraitFilterProperty(packages []string) string {
+	mstest, xunit := false, false
+	for _, pkg := range packages {
+		pkgLower := strings.ToLower(pkg)
+		switch {
+		case strings.HasPrefix(pkgLower, "mstest"):
+			mstest = true
+		case pkgLower == "xunit" || strings.HasPrefix(pkgLower, "xunit."):
+			xunit = true
+		}
+	}
+	if mstest && !xunit {
+		return "TestCategory"
+	}
+	return "Category"
+}
+
+// ExcludeTraitsFilter builds a filter expression that excludes tests with any
+// of the given categories, e.g. "Category!=Live&Category!=Slow".
+func ExcludeTraitsFilter(property string, traits []string) string {
+	parts := make([]string, len(traits))
+	for i, trait := range traits {
+		parts[i] = property + "!=" + trait
+	}
+	return strings.Join(parts, "&")
+}
+
 // AreAllTraitsExcluded checks if all traits in the list are excluded by the filter
 // Returns true if the traits list is non-empty and ALL of them are in the exclusion list
 func AreAllTraitsExcluded(traits []string, excludedCategories []string) bool {
@@ -1,4 +1,4 @@
-// Package testresults provides parsers for extracting failed test names from
+// Package testresults provides parsers for extracting test results from
 // dotnet test output (TRX files and stdout).
 package testresults
 
@@ -7,7 +7,9 @@ import (
 	"encoding/xml"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // FailedTest represents a test that failed
@@ -16,6 +18,23 @@ type FailedTest struct {
 	DisplayName        string // e.g., "TestMethod" or "TestMethod(param: value)"
 }
 
+// Test outcomes as reported in TRX files.
+const (
+	OutcomePassed      = "Passed"
+	OutcomeFailed      = "Failed"
+	OutcomeNotExecuted = "NotExecuted"
+)
+
+// TestResult is a single test result from a TRX file, passing or not.
+type TestResult struct {
+	FullyQualifiedName string // e.g., "MyNamespace.MyClass.TestMethod"
+	DisplayName        string // e.g., "TestMethod" or "TestMethod(param: value)"
+	Outcome            string // e.g., "Passed", "Failed", "NotExecuted"
+	Duration           time.Duration
+	Message            string // error message for failed tests
+	StackTrace         string // stack trace for failed tests
+}
+
 // TRX XML structures (Microsoft Visual Studio Test Results format)
 // Namespace: http://microsoft.com/schemas/VisualStudio/TeamTest/2010
 
@@ -33,6 +52,13 @@ type trxUnitTestResult struct {
 	TestName string `xml:"testName,attr"`
 	Outcome  string `xml:"outcome,attr"`
 	TestId   string `xml:"testId,attr"`
+	Duration string `xml:"duration,attr"`
+	Output   struct {
+		ErrorInfo struct {
+			Message    string `xml:"Message"`
+			StackTrace string `xml:"StackTrace"`
+		} `xml:"ErrorInfo"`
+	} `xml:"Output"`
 }
 
 type trxTestDefs struct {
@@ -61,6 +87,36 @@ func ParseTRXFile(path string) ([]FailedTest, error) {
 
 // ParseTRX parses TRX XML content and returns the list of failed tests.
 func ParseTRX(data []byte) ([]FailedTest, error) {
+	results, err := ParseTRXResults(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []FailedTest
+	for _, res := range results {
+		if res.Outcome == OutcomeFailed {
+			failed = append(failed, FailedTest{
+				FullyQualifiedName: res.FullyQualifiedName,
+				DisplayName:        res.DisplayName,
+			})
+		}
+	}
+
+	return failed, nil
+}
+
+// ParseTRXResultsFile parses a TRX file and returns all test results.
+func ParseTRXResultsFile(path string) ([]TestResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTRXResults(data)
+}
+
+// ParseTRXResults parses TRX XML content and returns every test result, in
+// the order they appear in the file.
+func ParseTRXResults(data []byte) ([]TestResult, error) {
 	var testRun trxTestRun
 	if err := xml.Unmarshal(data, &testRun); err != nil {
 		return nil, err
@@ -72,32 +128,56 @@ func ParseTRX(data []byte) ([]FailedTest, error) {
 		testDefs[ut.Id] = ut
 	}
 
-	var failed []FailedTest
+	var results []TestResult
 	for _, result := range testRun.Results.UnitTestResults {
-		if result.Outcome == "Failed" {
-			ft := FailedTest{
-				DisplayName: result.TestName,
-			}
+		res := TestResult{
+			DisplayName: result.TestName,
+			Outcome:     result.Outcome,
+			Duration:    parseTRXDuration(result.Duration),
+			Message:     strings.TrimSpace(result.Output.ErrorInfo.Message),
+			StackTrace:  strings.TrimSpace(result.Output.ErrorInfo.StackTrace),
+		}
 
-			// Try to get fully qualified name from test definition
-			if def, ok := testDefs[result.TestId]; ok {
-				className := def.TestMethod.ClassName
-				// ClassName might have assembly suffix: "Namespace.ClassName, AssemblyName"
-				if idx := strings.Index(className, ","); idx > 0 {
-					className = strings.TrimSpace(className[:idx])
-				}
-				ft.FullyQualifiedName = className + "." + def.TestMethod.Name
-			} else {
-				// Fall back to testName which contains the FQN with parameters
-				// e.g., "eDF.Common.Tests.Helpers.UtilsTests.MatchesEMail_ValidCandidate(candidate: \"test@domain.com\")"
-				ft.FullyQualifiedName = extractFQNFromTestName(result.TestName)
+		// Try to get fully qualified name from test definition
+		if def, ok := testDefs[result.TestId]; ok {
+			className := def.TestMethod.ClassName
+			// ClassName might have assembly suffix: "Namespace.ClassName, AssemblyName"
+			if idx := strings.Index(className, ","); idx > 0 {
+				className = strings.TrimSpace(className[:idx])
 			}
-
-			failed = append(failed, ft)
+			res.FullyQualifiedName = className + "." + def.TestMethod.Name
+		} else {
+			// Fall back to testName which contains the FQN with parameters
+			// e.g., "eDF.Common.Tests.Helpers.UtilsTests.MatchesEMail_ValidCandidate(candidate: \"test@domain.com\")"
+			res.FullyQualifiedName = extractFQNFromTestName(result.TestName)
 		}
+
+		results = append(results, res)
 	}
 
-	return failed, nil
+	return results, nil
+}
+
+// parseTRXDuration parses a TRX duration such as "00:00:01.2345678".
+// Returns 0 if the value is missing or malformed.
+func parseTRXDuration(s string) time.Duration {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return 0
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0
+	}
+	secs, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(secs*float64(time.Second))
 }
 
 // extractFQNFromTestName tries to extract a fully qualified name from the test name.
@@ -1,7 +1,9 @@
 package testresults
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseTRX(t *testing.T) {
@@ -192,3 +194,65 @@ func TestBuildFilterStringEmpty(t *testing.T) {
 		t.Errorf("expected empty filter for empty input, got %q", filter)
 	}
 }
+
+func TestParseTRXResults(t *testing.T) {
+	trxContent := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<TestRun xmlns="http://microsoft.com/schemas/VisualStudio/TeamTest/2010">
+  <Results>
+    <UnitTestResult testId="id-1" testName="TestPassing" outcome="Passed" duration="00:00:00.0120000" />
+    <UnitTestResult testId="id-2" testName="TestFailing" outcome="Failed" duration="00:01:02.5000000">
+      <Output>
+        <ErrorInfo>
+          <Message>Assert.Equal() Failure
+Expected: 1
+Actual:   2</Message>
+          <StackTrace>   at MyApp.Tests.SampleTests.TestFailing() in SampleTests.cs:line 12</StackTrace>
+        </ErrorInfo>
+      </Output>
+    </UnitTestResult>
+    <UnitTestResult testId="id-3" testName="TestSkipped" outcome="NotExecuted" />
+  </Results>
+  <TestDefinitions>
+    <UnitTest id="id-1" name="TestPassing">
+      <TestMethod className="MyApp.Tests.SampleTests, MyApp.Tests" name="TestPassing" />
+    </UnitTest>
+    <UnitTest id="id-2" name="TestFailing">
+      <TestMethod className="MyApp.Tests.SampleTests, MyApp.Tests" name="TestFailing" />
+    </UnitTest>
+  </TestDefinitions>
+</TestRun>`)
+
+	results, err := ParseTRXResults(trxContent)
+	if err != nil {
+		t.Fatalf("ParseTRXResults failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if results[0].FullyQualifiedName != "MyApp.Tests.SampleTests.TestPassing" || results[0].Outcome != OutcomePassed {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	if results[0].Duration != 12*time.Millisecond {
+		t.Errorf("expected duration 12ms, got %v", results[0].Duration)
+	}
+
+	failing := results[1]
+	if failing.Outcome != OutcomeFailed {
+		t.Errorf("expected outcome Failed, got %q", failing.Outcome)
+	}
+	if failing.Duration != time.Minute+2500*time.Millisecond {
+		t.Errorf("expected duration 1m2.5s, got %v", failing.Duration)
+	}
+	if !strings.HasPrefix(failing.Message, "Assert.Equal() Failure") || !strings.Contains(failing.Message, "Actual:   2") {
+		t.Errorf("unexpected message %q", failing.Message)
+	}
+	if !strings.Contains(failing.StackTrace, "SampleTests.cs:line 12") {
+		t.Errorf("unexpected stack trace %q", failing.StackTrace)
+	}
+
+	// No definition: the FQN falls back to the test name
+	if results[2].FullyQualifiedName != "TestSkipped" || results[2].Outcome != OutcomeNotExecuted {
+		t.Errorf("unexpected third result: %+v", results[2])
+	}
+}